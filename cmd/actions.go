@@ -4,10 +4,20 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
+	actions_model "code.gitea.io/gitea/models/actions"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/private"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	actions_service "code.gitea.io/gitea/services/actions"
 
 	"github.com/urfave/cli/v2"
 )
@@ -20,6 +30,8 @@ var (
 		Description: "Commands for managing Gitea Actions",
 		Subcommands: []*cli.Command{
 			subcmdActionsGenRunnerToken,
+			subcmdActionsCleanup,
+			subcmdActionsListRunners,
 		},
 	}
 
@@ -33,7 +45,42 @@ var (
 				Name:    "scope",
 				Aliases: []string{"s"},
 				Value:   "",
-				Usage:   "{owner}[/{repo}] - leave empty for a global runner",
+				Usage:   "global, org:{org}, user:{user}, repo:{owner}/{repo} or {owner}[/{repo}] - leave empty for a global runner",
+			},
+		},
+	}
+
+	subcmdActionsListRunners = &cli.Command{
+		Name:   "list-runners",
+		Usage:  "List the registered runners",
+		Action: runActionsListRunners,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "owner",
+				Usage: "Only list the runners registered for the user or organization",
+			},
+			&cli.StringFlag{
+				Name:  "repo",
+				Usage: "Only list the runners registered for the repository, as {owner}/{repo}",
+			},
+			listFormatFlag,
+		},
+	}
+
+	subcmdActionsCleanup = &cli.Command{
+		Name:  "cleanup",
+		Usage: "Remove the expired artifacts and task logs",
+		Description: `By default, the artifacts older than [actions] ARTIFACT_RETENTION_DAYS and the logs of the tasks stopped before [actions] LOG_RETENTION_DAYS are removed,
+a category whose retention days are 0 is skipped. The same cleanup is run by the "cleanup_actions" cron task.`,
+		Action: runActionsCleanup,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "older-than",
+				Usage: "Remove the artifacts and logs older than the duration (eg: 30d, 720h) instead of the retention days of the config",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Only report what would be removed",
 			},
 		},
 	}
@@ -54,3 +101,158 @@ func runGenerateActionsRunnerToken(c *cli.Context) error {
 	_, _ = fmt.Printf("%s\n", respText)
 	return nil
 }
+
+func runActionsCleanup(c *cli.Context) error {
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+	// the storages of the actions aren't initialized if the actions are disabled
+	if !setting.Actions.Enabled {
+		return errors.New("Gitea Actions is not enabled")
+	}
+
+	opts := actions_service.RetentionCleanupOptions()
+	if c.IsSet("older-than") {
+		olderThan, err := parseOlderThan(c.String("older-than"))
+		if err != nil {
+			return err
+		}
+		opts.ArtifactsOlderThan, opts.LogsOlderThan = olderThan, olderThan
+	}
+	opts.DryRun = c.Bool("dry-run")
+
+	if err := storage.Init(); err != nil {
+		return err
+	}
+
+	result, err := actions_service.Cleanup(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	verb := "removed"
+	if opts.DryRun {
+		verb = "would be removed"
+	}
+	report := func(category string, olderThan time.Duration, stats actions_service.CleanupStats) {
+		if olderThan <= 0 {
+			_, _ = fmt.Fprintf(c.App.Writer, "%s: skipped, their retention days are 0\n", category)
+			return
+		}
+		_, _ = fmt.Fprintf(c.App.Writer, "%s: %d %s (%s)\n", category, stats.Count, verb, base.FileSize(stats.Size))
+	}
+	report("Artifacts", opts.ArtifactsOlderThan, result.Artifacts)
+	report("Task logs", opts.LogsOlderThan, result.Logs)
+	_, _ = fmt.Fprintf(c.App.Writer, "Total: %d %s (%s)\n", result.Artifacts.Count+result.Logs.Count, verb, base.FileSize(result.Artifacts.Size+result.Logs.Size))
+	return nil
+}
+
+// parseOlderThan parses a positive duration, which can also be a number of days like "30d"
+func parseOlderThan(s string) (time.Duration, error) {
+	var olderThan time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.ParseInt(days, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %w", s, err)
+		}
+		olderThan = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if olderThan, err = time.ParseDuration(s); err != nil {
+			return 0, err
+		}
+	}
+	if olderThan <= 0 {
+		return 0, fmt.Errorf("the duration %q must be positive", s)
+	}
+	return olderThan, nil
+}
+
+func runActionsListRunners(c *cli.Context) error {
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if c.IsSet("owner") && c.IsSet("repo") {
+		return errors.New("--owner and --repo can't be used together")
+	}
+
+	formatter, err := newListFormatter(c.String("format"), c.App.Writer)
+	if err != nil {
+		return err
+	}
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	opts := actions_model.FindRunnerOptions{}
+	if c.IsSet("owner") {
+		owner, err := user_model.GetUserByName(ctx, c.String("owner"))
+		if err != nil {
+			return err
+		}
+		opts.OwnerID = owner.ID
+	} else if c.IsSet("repo") {
+		ownerName, repoName, ok := strings.Cut(c.String("repo"), "/")
+		if !ok {
+			return fmt.Errorf("invalid repository %q, it should be {owner}/{repo}", c.String("repo"))
+		}
+		repo, err := repo_model.GetRepositoryByOwnerAndName(ctx, ownerName, repoName)
+		if err != nil {
+			return err
+		}
+		opts.RepoID = repo.ID
+	}
+
+	runners, err := actions_model.FindRunners(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if err := runners.LoadAttributes(ctx); err != nil {
+		return err
+	}
+
+	if err = formatter.WriteHeader([]listColumn{
+		{Title: "ID", Key: "id"},
+		{Title: "Name", Key: "name"},
+		{Title: "Scope", Key: "scope"},
+		{Title: "Status", Key: "status"},
+		{Title: "LastOnline", Key: "last_online"},
+		{Title: "Version", Key: "version"},
+		{Title: "Labels", Key: "labels"},
+	}); err != nil {
+		return err
+	}
+	for _, runner := range runners {
+		lastOnline := "never"
+		if runner.LastOnline > 0 {
+			lastOnline = runner.LastOnline.AsTime().Format(time.RFC3339)
+		}
+		labels := runner.AgentLabels
+		if labels == nil {
+			labels = []string{}
+		}
+		if err = formatter.WriteRow(runner.ID, runner.Name, runnerScope(runner), runner.StatusName(), lastOnline, runner.Version, labels); err != nil {
+			return err
+		}
+	}
+	return formatter.Flush()
+}
+
+// runnerScope returns where the runner is registered: "global", the name of its owner or the full name of its repository
+func runnerScope(runner *actions_model.ActionRunner) string {
+	switch {
+	case runner.RepoID != 0 && runner.Repo != nil:
+		return runner.Repo.FullName()
+	case runner.RepoID != 0:
+		return fmt.Sprintf("repo(%d)", runner.RepoID)
+	case runner.OwnerID != 0 && runner.Owner != nil:
+		return runner.Owner.Name
+	case runner.OwnerID != 0:
+		return fmt.Sprintf("owner(%d)", runner.OwnerID)
+	}
+	return "global"
+}
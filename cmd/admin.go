@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"sync"
 	"text/tabwriter"
 
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
@@ -37,10 +38,13 @@ var (
 		Usage: "Command line interface to perform common administrative operations",
 		Subcommands: []*cli.Command{
 			subcmdUser,
+			subcmdRepo,
+			subcmdEmail,
 			subcmdRepoSyncReleases,
 			subcmdRegenerate,
 			subcmdAuth,
 			subcmdSendMail,
+			subcmdSendNotification,
 		},
 	}
 
@@ -60,15 +64,34 @@ var (
 	}
 
 	microcmdRegenHooks = &cli.Command{
-		Name:   "hooks",
-		Usage:  "Regenerate git-hooks",
-		Action: runRegenerateHooks,
+		Name:         "hooks",
+		Usage:        "Regenerate git-hooks",
+		Action:       runRegenerateHooks,
+		BashComplete: completeFlagValues(map[string]completionValuesFunc{"repo": completeRepositories}),
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "repo",
+				Usage: "Only regenerate the hooks of the repository (owner/name), can be repeated. All repositories by default",
+			},
+			&cli.IntFlag{
+				Name:  "concurrency",
+				Value: 1,
+				Usage: "The number of the repositories whose hooks are regenerated at the same time",
+			},
+		},
 	}
 
 	microcmdRegenKeys = &cli.Command{
 		Name:   "keys",
 		Usage:  "Regenerate authorized_keys file",
 		Action: runRegenerateKeys,
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "concurrency",
+				Value: 1,
+				Usage: "The number of the keys whose authorized_keys lines are generated at the same time",
+			},
+		},
 	}
 
 	subcmdAuth = &cli.Command{
@@ -81,6 +104,7 @@ var (
 			cmdAuthUpdateLdapBindDn,
 			cmdAuthAddLdapSimpleAuth,
 			cmdAuthUpdateLdapSimpleAuth,
+			cmdAuthSyncLdap,
 			microcmdAuthAddSMTP,
 			microcmdAuthUpdateSMTP,
 			microcmdAuthList,
@@ -117,6 +141,7 @@ var (
 				Name:  "vertical-bars",
 				Usage: "Set to true to print vertical bars between columns",
 			},
+			listFormatFlag,
 		},
 	}
 
@@ -126,9 +151,19 @@ var (
 	}
 
 	microcmdAuthDelete = &cli.Command{
-		Name:   "delete",
-		Usage:  "Delete specific auth source",
-		Flags:  []cli.Flag{idFlag},
+		Name:  "delete",
+		Usage: "Delete specific auth source",
+		Flags: []cli.Flag{
+			idFlag,
+			&cli.BoolFlag{
+				Name:  "cascade",
+				Usage: "Delete the auth source even if it is in use: its users are converted to local accounts (which need a password to sign in) and the accounts linked with it are unlinked",
+			},
+			&cli.BoolFlag{
+				Name:  "prohibit-login",
+				Usage: "Prohibit the users converted by --cascade from logging in",
+			},
+		},
 		Action: runDeleteAuth,
 	}
 
@@ -242,7 +277,16 @@ var (
 		Name:   "update-oauth",
 		Usage:  "Update existing Oauth authentication source",
 		Action: runUpdateOauth,
-		Flags:  append(oauthCLIFlags[:1], append([]cli.Flag{idFlag}, oauthCLIFlags[1:]...)...),
+		Flags: append(append([]cli.Flag{oauthCLIFlags[0], idFlag}, oauthCLIFlags[1:]...),
+			&cli.StringFlag{
+				Name:  "new-key",
+				Usage: "New Client ID (Key), same as --key",
+			},
+			&cli.StringFlag{
+				Name:  "new-secret",
+				Usage: "New Client Secret to rotate the secret in place, the ID of the source and the linked users are kept, same as --secret",
+			},
+		),
 	}
 
 	microcmdAuthAddOauth = &cli.Command{
@@ -254,19 +298,28 @@ var (
 
 	subcmdSendMail = &cli.Command{
 		Name:   "sendmail",
-		Usage:  "Send a message to all users",
+		Usage:  "Send a message to all users, or a test message to the given addresses",
 		Action: runSendMail,
 		Flags: []cli.Flag{
 			&cli.StringFlag{
-				Name:  "title",
-				Usage: `a title of a message`,
-				Value: "",
+				Name:    "title",
+				Aliases: []string{"subject"},
+				Usage:   `a title of a message`,
+				Value:   "",
 			},
 			&cli.StringFlag{
 				Name:  "content",
 				Usage: "a content of a message",
 				Value: "",
 			},
+			&cli.StringFlag{
+				Name:  "body-file",
+				Usage: "read the content of the message from the file, can't be used together with --content",
+			},
+			&cli.StringSliceFlag{
+				Name:  "to",
+				Usage: "send the message directly by the configured mailer to the email address instead of all users, can be repeated. The server doesn't need to be running",
+			},
 			&cli.BoolFlag{
 				Name:    "force",
 				Aliases: []string{"f"},
@@ -417,24 +470,89 @@ func getReleaseCount(ctx context.Context, id int64) (int64, error) {
 	)
 }
 
-func runRegenerateHooks(_ *cli.Context) error {
+func runRegenerateHooks(c *cli.Context) error {
 	ctx, cancel := installSignals()
 	defer cancel()
 
+	concurrency := c.Int("concurrency")
+	if concurrency < 1 {
+		return fmt.Errorf("invalid --concurrency %d, it should be 1 or more", concurrency)
+	}
+	type ownerAndName struct{ owner, name string }
+	var repoNames []ownerAndName
+	for _, s := range c.StringSlice("repo") {
+		owner, name, ok := strings.Cut(s, "/")
+		if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			return fmt.Errorf("invalid repository %q, it should be in the format of owner/name", s)
+		}
+		repoNames = append(repoNames, ownerAndName{owner, name})
+	}
+
 	if err := initDB(ctx); err != nil {
 		return err
 	}
-	return repo_service.SyncRepositoryHooks(graceful.GetManager().ShutdownContext())
+	if len(repoNames) == 0 {
+		return repo_service.SyncRepositoryHooksConcurrently(graceful.GetManager().ShutdownContext(), concurrency)
+	}
+
+	// the results are printed in the order of the repositories once all of them are done
+	type hookResult struct {
+		fullName string
+		err      error
+	}
+	results := make([]hookResult, len(repoNames))
+	var wg sync.WaitGroup
+	idxCh := make(chan int)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range idxCh {
+				r := repoNames[idx]
+				results[idx].fullName = r.owner + "/" + r.name
+				repo, err := repo_model.GetRepositoryByOwnerAndName(ctx, r.owner, r.name)
+				if err == nil {
+					results[idx].fullName = repo.FullName()
+					err = repo_service.SyncRepositoryHook(repo)
+				}
+				results[idx].err = err
+			}
+		}()
+	}
+	for idx := range repoNames {
+		idxCh <- idx
+	}
+	close(idxCh)
+	wg.Wait()
+
+	failed := 0
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+			_, _ = fmt.Fprintf(c.App.ErrWriter, "Failed to regenerate the hooks of %s: %v\n", result.fullName, result.err)
+			continue
+		}
+		_, _ = fmt.Fprintf(c.App.Writer, "Regenerated the hooks of %s\n", result.fullName)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to regenerate the hooks of %d of %d repositories", failed, len(repoNames))
+	}
+	return nil
 }
 
-func runRegenerateKeys(_ *cli.Context) error {
+func runRegenerateKeys(c *cli.Context) error {
+	concurrency := c.Int("concurrency")
+	if concurrency < 1 {
+		return fmt.Errorf("invalid --concurrency %d, it should be 1 or more", concurrency)
+	}
+
 	ctx, cancel := installSignals()
 	defer cancel()
 
 	if err := initDB(ctx); err != nil {
 		return err
 	}
-	return asymkey_model.RewriteAllPublicKeys()
+	return asymkey_model.RewriteAllPublicKeysConcurrently(concurrency)
 }
 
 func parseOAuth2Config(c *cli.Context) *oauth2.Source {
@@ -498,6 +616,12 @@ func runUpdateOauth(c *cli.Context) error {
 		return fmt.Errorf("--id flag is missing")
 	}
 
+	for _, flags := range [][2]string{{"key", "new-key"}, {"secret", "new-secret"}} {
+		if c.IsSet(flags[0]) && c.IsSet(flags[1]) {
+			return fmt.Errorf("--%s and --%s can't be used together", flags[0], flags[1])
+		}
+	}
+
 	ctx, cancel := installSignals()
 	defer cancel()
 
@@ -505,10 +629,19 @@ func runUpdateOauth(c *cli.Context) error {
 		return err
 	}
 
+	return updateOauth(c)
+}
+
+// updateOauth applies the set flags to the OAuth2 authentication source given by --id,
+// --new-key and --new-secret are applied like --key and --secret
+func updateOauth(c *cli.Context) error {
 	source, err := auth_model.GetSourceByID(c.Int64("id"))
 	if err != nil {
 		return err
 	}
+	if source.Type != auth_model.OAuth2 {
+		return fmt.Errorf("Invalid authentication type. expected: %s, actual: %s", auth_model.OAuth2.String(), source.Type.String())
+	}
 
 	oAuth2Config := source.Cfg.(*oauth2.Source)
 
@@ -522,10 +655,14 @@ func runUpdateOauth(c *cli.Context) error {
 
 	if c.IsSet("key") {
 		oAuth2Config.ClientID = c.String("key")
+	} else if c.IsSet("new-key") {
+		oAuth2Config.ClientID = c.String("new-key")
 	}
 
 	if c.IsSet("secret") {
 		oAuth2Config.ClientSecret = c.String("secret")
+	} else if c.IsSet("new-secret") {
+		oAuth2Config.ClientSecret = c.String("new-secret")
 	}
 
 	if c.IsSet("auto-discover-url") {
@@ -715,6 +852,15 @@ func runListAuth(c *cli.Context) error {
 	ctx, cancel := installSignals()
 	defer cancel()
 
+	// the table options only apply to the "text" format, which keeps the formatted table as before
+	var formatter listFormatter
+	if format := c.String("format"); format != "" && format != "text" {
+		var err error
+		if formatter, err = newListFormatter(format, os.Stdout); err != nil {
+			return err
+		}
+	}
+
 	if err := initDB(ctx); err != nil {
 		return err
 	}
@@ -724,6 +870,23 @@ func runListAuth(c *cli.Context) error {
 		return err
 	}
 
+	if formatter != nil {
+		if err = formatter.WriteHeader([]listColumn{
+			{Title: "ID", Key: "id"},
+			{Title: "Name", Key: "name"},
+			{Title: "Type", Key: "type"},
+			{Title: "Enabled", Key: "is_active"},
+		}); err != nil {
+			return err
+		}
+		for _, source := range authSources {
+			if err = formatter.WriteRow(source.ID, source.Name, source.Type.String(), source.IsActive); err != nil {
+				return err
+			}
+		}
+		return formatter.Flush()
+	}
+
 	flags := tabwriter.AlignRight
 	if c.Bool("vertical-bars") {
 		flags |= tabwriter.Debug
@@ -749,6 +912,9 @@ func runDeleteAuth(c *cli.Context) error {
 	if !c.IsSet("id") {
 		return fmt.Errorf("--id flag is missing")
 	}
+	if c.Bool("prohibit-login") && !c.Bool("cascade") {
+		return errors.New("--prohibit-login can only be used with --cascade")
+	}
 
 	ctx, cancel := installSignals()
 	defer cancel()
@@ -762,5 +928,24 @@ func runDeleteAuth(c *cli.Context) error {
 		return err
 	}
 
-	return auth_service.DeleteSource(source)
+	if !c.Bool("cascade") {
+		if err := auth_service.DeleteSource(source); err != nil {
+			if auth_model.IsErrSourceInUse(err) {
+				return fmt.Errorf("%w, use --cascade to convert its users to local accounts", err)
+			}
+			return err
+		}
+		return nil
+	}
+
+	result, err := auth_service.DeleteSourceCascade(ctx, source, c.Bool("prohibit-login"))
+	if err != nil {
+		return err
+	}
+	converted := fmt.Sprintf("Converted %d user(s) to local accounts, they need a password to sign in", result.ConvertedUsers)
+	if c.Bool("prohibit-login") {
+		converted = fmt.Sprintf("Converted %d user(s) to local accounts prohibited from logging in", result.ConvertedUsers)
+	}
+	_, _ = fmt.Fprintf(c.App.Writer, "Deleted the auth source %q. %s, unlinked %d external account(s)\n", source.Name, converted, result.UnlinkedAccounts)
+	return nil
 }
@@ -5,10 +5,13 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
 	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/services/auth/source/ldap"
 
 	"github.com/urfave/cli/v2"
@@ -170,6 +173,26 @@ var (
 		},
 		Flags: append([]cli.Flag{idFlag}, ldapSimpleAuthCLIFlags...),
 	}
+
+	cmdAuthSyncLdap = &cli.Command{
+		Name:  "sync-ldap",
+		Usage: "Synchronize the users of LDAP (via Bind DN) authentication sources now",
+		Description: `The synchronization is the same as the scheduled one with the settings of the source, it is run even if the synchronization of users isn't enabled for the given --id.
+The numbers of the created, updated and deactivated users are printed for each source.`,
+		Action: runSyncLdap,
+		Flags: []cli.Flag{
+			idFlag,
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Synchronize all the active LDAP sources which have the synchronization of users enabled",
+			},
+			&cli.BoolFlag{
+				Name:  "update-existing",
+				Value: true,
+				Usage: "Update the existing users and deactivate the ones missing from LDAP, like the scheduled synchronization does by default",
+			},
+		},
+	}
 )
 
 // newAuthService creates a service with default functions.
@@ -407,3 +430,73 @@ func (a *authService) updateLdapSimpleAuth(c *cli.Context) error {
 
 	return a.updateAuthSource(authSource)
 }
+
+func runSyncLdap(c *cli.Context) error {
+	if c.IsSet("id") == c.Bool("all") {
+		return errors.New("either --id or --all must be provided")
+	}
+
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	var sources []*auth.Source
+	if c.IsSet("id") {
+		source, err := auth.GetSourceByID(c.Int64("id"))
+		if err != nil {
+			return err
+		}
+		if source.Type != auth.LDAP {
+			return fmt.Errorf("auth source %d (%s) is of type %s, only the %s sources can be synchronized", source.ID, source.Name, source.Type.String(), auth.LDAP.String())
+		}
+		if !source.IsActive {
+			return fmt.Errorf("auth source %d (%s) is not active", source.ID, source.Name)
+		}
+		sources = append(sources, source)
+	} else {
+		all, err := auth.Sources()
+		if err != nil {
+			return err
+		}
+		for _, source := range all {
+			if source.Type == auth.LDAP && source.IsActive && source.IsSyncEnabled {
+				sources = append(sources, source)
+			}
+		}
+		if len(sources) == 0 {
+			_, _ = fmt.Fprintln(c.App.Writer, "No active LDAP source has the synchronization of users enabled")
+			return nil
+		}
+	}
+
+	for _, source := range sources {
+		// the storage is only needed to synchronize the avatars of the users
+		if source.Cfg.(*ldap.Source).AttributeAvatar != "" {
+			if err := storage.Init(); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	failed := 0
+	for _, source := range sources {
+		result, err := source.Cfg.(*ldap.Source).SyncUsers(ctx, c.Bool("update-existing"))
+		if db.IsErrCancelled(err) {
+			return err
+		} else if err != nil {
+			_, _ = fmt.Fprintf(c.App.ErrWriter, "Unable to synchronize auth source %d (%s): %v\n", source.ID, source.Name, err)
+			failed++
+			continue
+		}
+		_, _ = fmt.Fprintf(c.App.Writer, "Synchronized auth source %d (%s): %d created, %d updated, %d deactivated\n",
+			source.ID, source.Name, result.Created, result.Updated, result.Deactivated)
+	}
+	if failed > 0 {
+		return cli.Exit(fmt.Sprintf("%d of %d auth sources failed to synchronize", failed, len(sources)), 1)
+	}
+	return nil
+}
@@ -1323,3 +1323,15 @@ func TestUpdateLdapSimpleAuth(t *testing.T) {
 		}
 	}
 }
+
+func TestSyncLdapFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"./gitea"},
+		{"./gitea", "--id", "1", "--all"},
+	} {
+		app := cli.NewApp()
+		app.Flags = cmdAuthSyncLdap.Flags
+		app.Action = runSyncLdap
+		assert.EqualError(t, app.Run(args), "either --id or --all must be provided", "%v", args)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"strconv"
+	"testing"
+
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/services/auth/source/oauth2"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+func TestUpdateOauthFlagConflicts(t *testing.T) {
+	for _, args := range [][]string{
+		{"--id", "1", "--key", "a", "--new-key", "b"},
+		{"--id", "1", "--secret", "a", "--new-secret", "b"},
+	} {
+		app := cli.NewApp()
+		app.Flags = microcmdAuthUpdateOauth.Flags
+		app.Action = runUpdateOauth
+		err := app.Run(append([]string{"./gitea"}, args...))
+		assert.ErrorContains(t, err, "can't be used together", "args %v", args)
+	}
+}
+
+func TestUpdateOauthNewKeyAliases(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	update := func(args ...string) *oauth2.Source {
+		source := &auth_model.Source{
+			Type:     auth_model.OAuth2,
+			Name:     "oauth-" + args[0],
+			IsActive: true,
+			Cfg: &oauth2.Source{
+				Provider:     "gitea",
+				ClientID:     "old-key",
+				ClientSecret: "old-secret",
+			},
+		}
+		assert.NoError(t, auth_model.CreateSource(source))
+
+		app := cli.NewApp()
+		app.Flags = microcmdAuthUpdateOauth.Flags
+		app.Action = updateOauth
+		assert.NoError(t, app.Run(append([]string{"./gitea", "--id", strconv.FormatInt(source.ID, 10)}, args...)))
+
+		source, err := auth_model.GetSourceByID(source.ID)
+		assert.NoError(t, err)
+		return source.Cfg.(*oauth2.Source)
+	}
+
+	byOldFlags := update("--key", "rotated-key", "--secret", "rotated-secret")
+	byNewFlags := update("--new-key", "rotated-key", "--new-secret", "rotated-secret")
+	for _, cfg := range []*oauth2.Source{byOldFlags, byNewFlags} {
+		assert.Equal(t, "rotated-key", cfg.ClientID)
+		assert.Equal(t, "rotated-secret", cfg.ClientSecret)
+		assert.Equal(t, "gitea", cfg.Provider)
+	}
+
+	onlySecret := update("--new-secret", "rotated-secret")
+	assert.Equal(t, "old-key", onlySecret.ClientID)
+	assert.Equal(t, "rotated-secret", onlySecret.ClientSecret)
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/container"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	subcmdEmail = &cli.Command{
+		Name:  "email",
+		Usage: "Manage email addresses",
+		Subcommands: []*cli.Command{
+			microcmdEmailListDuplicates,
+		},
+	}
+
+	microcmdEmailListDuplicates = &cli.Command{
+		Name:  "list-duplicates",
+		Usage: "List the email addresses used by more than one user or organization, case-insensitively",
+		Description: `The email addresses of the users and the emails of the user table (like the ones of the organizations) are grouped by the lowercased email address,
+each account sharing it is listed with the address as it is stored and whether it is the primary one and activated.`,
+		Action: runEmailListDuplicates,
+		Flags: []cli.Flag{
+			listFormatFlag,
+		},
+	}
+)
+
+func runEmailListDuplicates(c *cli.Context) error {
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	formatter, err := newListFormatter(c.String("format"), c.App.Writer)
+	if err != nil {
+		return err
+	}
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	emails, err := user_model.FindDuplicateEmails(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = formatter.WriteHeader([]listColumn{
+		{Title: "Email", Key: "email"},
+		{Title: "ID", Key: "id"},
+		{Title: "Name", Key: "name"},
+		{Title: "Type", Key: "type"},
+		{Title: "Address", Key: "address"},
+		{Title: "IsPrimary", Key: "is_primary"},
+		{Title: "IsActivated", Key: "is_activated"},
+	}); err != nil {
+		return err
+	}
+	groups := make(container.Set[string])
+	for _, email := range emails {
+		groups.Add(email.LowerEmail)
+		if err = formatter.WriteRow(email.LowerEmail, email.UID, email.Name, accountTypeName(email.Type), email.Email, email.IsPrimary, email.IsActivated); err != nil {
+			return err
+		}
+	}
+	if err = formatter.Flush(); err != nil {
+		return err
+	}
+	// the total is written to stderr to keep the output parsable
+	_, _ = fmt.Fprintf(c.App.ErrWriter, "%d email addresses are used by more than one account\n", len(groups))
+	return nil
+}
+
+func accountTypeName(t user_model.UserType) string {
+	switch t {
+	case user_model.UserTypeIndividual:
+		return "user"
+	case user_model.UserTypeOrganization:
+		return "organization"
+	case user_model.UserTypeBot:
+		return "bot"
+	case user_model.UserTypeRemoteUser:
+		return "remote"
+	}
+	return fmt.Sprintf("reserved(%d)", t)
+}
@@ -0,0 +1,85 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	activities_model "code.gitea.io/gitea/models/activities"
+	auth_model "code.gitea.io/gitea/models/auth"
+	user_model "code.gitea.io/gitea/models/user"
+
+	"github.com/urfave/cli/v2"
+)
+
+var subcmdSendNotification = &cli.Command{
+	Name:  "send-notification",
+	Usage: "Send a system notification to the users",
+	Description: `The notification is shown in the notification list of the active users who are allowed to log in,
+--to and --source-id narrow down the receivers.`,
+	Action: runSendNotification,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "message",
+			Usage:    "The text of the notification",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "to",
+			Value: "all",
+			Usage: "The receivers of the notification: all or admins",
+		},
+		&cli.Int64Flag{
+			Name:  "source-id",
+			Usage: "Send the notification only to the users of the authentication source",
+		},
+	},
+}
+
+func runSendNotification(c *cli.Context) error {
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	message := strings.TrimSpace(c.String("message"))
+	if message == "" {
+		return fmt.Errorf("the message can't be empty")
+	}
+
+	opts := activities_model.SystemNotificationOptions{}
+	switch c.String("to") {
+	case "all":
+	case "admins":
+		opts.OnlyAdmins = true
+	default:
+		return fmt.Errorf("invalid --to %q, it should be all or admins", c.String("to"))
+	}
+	if c.IsSet("source-id") && c.Int64("source-id") <= 0 {
+		return fmt.Errorf("invalid --source-id %d, it should be the ID of an authentication source", c.Int64("source-id"))
+	}
+	opts.SourceID = c.Int64("source-id")
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	// an unknown source is more likely a typo than a source without users
+	if opts.SourceID > 0 {
+		if _, err := auth_model.GetSourceByID(opts.SourceID); err != nil {
+			return err
+		}
+	}
+
+	doer, err := user_model.GetAdminUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	count, err := activities_model.CreateSystemNotifications(ctx, doer, message, opts)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(c.App.Writer, "Created %d notification(s)\n", count)
+	return nil
+}
@@ -0,0 +1,736 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/private"
+	repo_module "code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/util"
+	repo_service "code.gitea.io/gitea/services/repository"
+
+	"github.com/urfave/cli/v2"
+	"xorm.io/builder"
+)
+
+var (
+	subcmdRepo = &cli.Command{
+		Name:  "repo",
+		Usage: "Manage repositories",
+		Subcommands: []*cli.Command{
+			microcmdRepoListUnadopted,
+			microcmdRepoAdopt,
+			microcmdRepoDeleteMissing,
+			microcmdRepoSyncReleaseTags,
+			microcmdRepoTransfer,
+			microcmdRepoArchive,
+			microcmdRepoUnarchive,
+			microcmdRepoReindex,
+		},
+	}
+
+	microcmdRepoListUnadopted = &cli.Command{
+		Name:   "list-unadopted",
+		Usage:  "List the repositories on disk which are not in the database",
+		Action: runRepoListUnadopted,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "query",
+				Usage: "Only list the repositories matching the glob of the owner and optionally the glob of the name, e.g. 'myorg' or 'myorg/test-*'",
+			},
+			&cli.IntFlag{
+				Name:  "page",
+				Value: 1,
+				Usage: "Page number of the results",
+			},
+			&cli.IntFlag{
+				Name:  "limit",
+				Value: 50,
+				Usage: "Page size of the results",
+			},
+			listFormatFlag,
+		},
+	}
+
+	microcmdRepoAdopt = &cli.Command{
+		Name:         "adopt",
+		Usage:        "Adopt the repositories on disk which are not in the database",
+		Action:       runRepoAdopt,
+		BashComplete: completeFlagValues(map[string]completionValuesFunc{"owner": completeOwners}),
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Adopt the unadopted repositories of all the users and organizations",
+			},
+			&cli.StringFlag{
+				Name:  "owner",
+				Usage: "Adopt the unadopted repositories of the user or organization",
+			},
+		},
+	}
+
+	microcmdRepoDeleteMissing = &cli.Command{
+		Name:   "delete-missing",
+		Usage:  "List the repositories in the database whose Git files are missing on disk, and delete them with --confirm",
+		Action: runRepoDeleteMissing,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "confirm",
+				Usage: "Delete the listed repositories from the database, only listing them by default",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Skip the confirmation prompt of --confirm",
+			},
+		},
+	}
+
+	microcmdRepoSyncReleaseTags = &cli.Command{
+		Name:  "sync-release-tags",
+		Usage: "Reconcile the releases with the Git tags of the repositories",
+		Description: `A release is created for each tag without one, the releases whose tag is gone are marked as draft
+(or deleted if they only represent the tag), and the releases whose tag points to another commit are updated.
+Only the repositories with changes are listed with their changes.`,
+		Action:       runRepoSyncReleaseTags,
+		BashComplete: completeFlagValues(map[string]completionValuesFunc{"repo": completeRepositories}),
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "repo",
+				Usage: "Reconcile the repository (owner/name), can be repeated",
+			},
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Reconcile all the repositories",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Only list the changes without making them",
+			},
+		},
+	}
+
+	microcmdRepoTransfer = &cli.Command{
+		Name:  "transfer",
+		Usage: "Transfer a repository to a new owner",
+		Description: `Transfer a repository to another user or organization like the web UI does for the admins: the Git files are moved
+and the old name redirects to the new one. The new owner must be able to own one more repository and must not have
+a repository of the same name.`,
+		Action: runRepoTransfer,
+		BashComplete: completeFlagValues(map[string]completionValuesFunc{
+			"repo":      completeRepositories,
+			"new-owner": completeOwners,
+		}),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "repo",
+				Usage: "The repository to transfer (owner/name)",
+			},
+			&cli.StringFlag{
+				Name:  "new-owner",
+				Usage: "The user or organization to transfer the repository to",
+			},
+		},
+	}
+
+	microcmdRepoArchive = &cli.Command{
+		Name:  "archive",
+		Usage: "Archive repositories",
+		Description: `Archive the given repositories, or the repositories which haven't been updated (e.g. by a push) for the --older-than period,
+like the repository settings do. The mirrors can't be archived. Each archived repository is listed.`,
+		Action:       runRepoArchive,
+		BashComplete: completeFlagValues(map[string]completionValuesFunc{"repo": completeRepositories}),
+		Flags:        repoArchiveFlags("archive"),
+	}
+
+	microcmdRepoUnarchive = &cli.Command{
+		Name:  "unarchive",
+		Usage: "Unarchive repositories",
+		Description: `Unarchive the given repositories, or the archived repositories which haven't been updated for the --older-than period,
+like the repository settings do. Each unarchived repository is listed.`,
+		Action:       runRepoUnarchive,
+		BashComplete: completeFlagValues(map[string]completionValuesFunc{"repo": completeRepositories}),
+		Flags:        repoArchiveFlags("unarchive"),
+	}
+	microcmdRepoReindex = &cli.Command{
+		Name:  "reindex",
+		Usage: "Rebuild the code and issue indexes of repositories",
+		Description: `Remove the repositories from the indexes and queue them to the indexers of the running Gitea, like after
+upgrading an indexer. The code is indexed from scratch and all the issues and pull requests are indexed again.
+By default the command returns once the repositories are queued, --wait waits until they are indexed.`,
+		Action:       runRepoReindex,
+		BashComplete: completeFlagValues(map[string]completionValuesFunc{"repo": completeRepositories}),
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "repo",
+				Usage: "The repository to reindex (owner/name), can be repeated",
+			},
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Reindex all the repositories",
+			},
+			&cli.StringSliceFlag{
+				Name:  "type",
+				Value: cli.NewStringSlice("code", "issues"),
+				Usage: "The index to rebuild: code or issues, can be repeated",
+			},
+			&cli.BoolFlag{
+				Name:  "wait",
+				Usage: "Wait until the repositories are indexed",
+			},
+		},
+	}
+)
+
+func repoArchiveFlags(verb string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "repo",
+			Usage: fmt.Sprintf("The repository to %s (owner/name), can be repeated", verb),
+		},
+		&cli.StringFlag{
+			Name:  "older-than",
+			Usage: fmt.Sprintf("%s the repositories which haven't been updated for the duration (eg: 180d, 4320h) instead of --repo", util.ToTitleCase(verb)),
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: fmt.Sprintf("Only list the repositories which would be %sd", verb),
+		},
+	}
+}
+
+func runRepoListUnadopted(c *cli.Context) error {
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if c.Int("page") < 1 || c.Int("limit") < 1 {
+		return errors.New("page and limit must be greater than 0")
+	}
+	formatter, err := newListFormatter(c.String("format"), c.App.Writer)
+	if err != nil {
+		return err
+	}
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	repoNames, count, err := repo_service.ListUnadoptedRepositories(ctx, c.String("query"), &db.ListOptions{
+		Page:     c.Int("page"),
+		PageSize: c.Int("limit"),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = formatter.WriteHeader([]listColumn{
+		{Title: "Owner", Key: "owner"},
+		{Title: "Name", Key: "name"},
+	}); err != nil {
+		return err
+	}
+	for _, repoName := range repoNames {
+		owner, name, _ := strings.Cut(repoName, "/")
+		if err = formatter.WriteRow(owner, name); err != nil {
+			return err
+		}
+	}
+	if err = formatter.Flush(); err != nil {
+		return err
+	}
+	// the total is written to stderr to keep the output parsable
+	_, _ = fmt.Fprintf(c.App.ErrWriter, "%d unadopted repositories in total\n", count)
+	return nil
+}
+
+// unadoptedOwnerQuery returns the query of the unadopted repositories of the owner,
+// the directories of the repository root are named by the lower case names
+func unadoptedOwnerQuery(ctx context.Context, ownerName string) (string, error) {
+	owner, err := user_model.GetUserByName(ctx, ownerName)
+	if err != nil {
+		return "", fmt.Errorf("unable to find the owner %q: %w", ownerName, err)
+	}
+	return owner.LowerName + "/*", nil
+}
+
+func runRepoAdopt(c *cli.Context) error {
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if c.Bool("all") == c.IsSet("owner") {
+		return errors.New("one of --all or --owner is required")
+	}
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+	if err := git.InitSimple(ctx); err != nil {
+		return err
+	}
+
+	query := ""
+	if c.IsSet("owner") {
+		var err error
+		if query, err = unadoptedOwnerQuery(ctx, c.String("owner")); err != nil {
+			return err
+		}
+	}
+
+	doer, err := user_model.GetAdminUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	// the unadopted repositories are adopted while walking the repository root, so they don't need to be kept in memory
+	var adopted, failed int
+	err = repo_service.IterateUnadoptedRepositories(ctx, query, func(repoName string) error {
+		ownerName, name, _ := strings.Cut(repoName, "/")
+		owner, err := user_model.GetUserByName(ctx, ownerName)
+		if err != nil {
+			return err
+		}
+		if _, err = repo_service.AdoptRepository(ctx, doer, owner, repo_module.CreateRepoOptions{
+			Name:      name,
+			IsPrivate: true,
+		}); err != nil {
+			failed++
+			_, _ = fmt.Fprintf(c.App.ErrWriter, "Failed to adopt %s: %v\n", repoName, err)
+			return nil
+		}
+		adopted++
+		_, _ = fmt.Fprintf(c.App.Writer, "Adopted %s\n", repoName)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(c.App.Writer, "Adopted %d repositories\n", adopted)
+	if failed > 0 {
+		return fmt.Errorf("failed to adopt %d repositories", failed)
+	}
+	return nil
+}
+
+func runRepoDeleteMissing(c *cli.Context) error {
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if c.Bool("yes") && !c.Bool("confirm") {
+		return errors.New("--yes can only be used with --confirm")
+	}
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	repos, err := repo_service.FindMissingRepositories(ctx)
+	if err != nil {
+		return err
+	}
+	if len(repos) == 0 {
+		_, _ = fmt.Fprintln(c.App.Writer, "No repositories are missing on disk")
+		return nil
+	}
+
+	formatter, err := newListFormatter("text", c.App.Writer)
+	if err != nil {
+		return err
+	}
+	if err = formatter.WriteHeader([]listColumn{
+		{Title: "ID"},
+		{Title: "Repository"},
+		{Title: "Missing path"},
+	}); err != nil {
+		return err
+	}
+	for _, repo := range repos {
+		if err = formatter.WriteRow(repo.ID, repo.FullName(), repo.RepoPath()); err != nil {
+			return err
+		}
+	}
+	if err = formatter.Flush(); err != nil {
+		return err
+	}
+
+	if !c.Bool("confirm") {
+		_, _ = fmt.Fprintf(c.App.Writer, "%d repositories are missing on disk, run with --confirm to delete them from the database\n", len(repos))
+		return nil
+	}
+	if !c.Bool("yes") {
+		_, _ = fmt.Fprintf(c.App.Writer, "Delete these %d repositories and all their issues, pull requests, releases and other data from the database? [y/n] ", len(repos))
+		isConfirmed, err := confirm()
+		if err != nil {
+			return err
+		} else if !isConfirmed {
+			_, _ = fmt.Fprintln(c.App.Writer, "No repositories were deleted")
+			return nil
+		}
+	}
+
+	// the attachments, LFS objects and other files of the repositories are deleted too
+	if err := storage.Init(); err != nil {
+		return err
+	}
+	doer, err := user_model.GetAdminUser(ctx)
+	if err != nil {
+		return err
+	}
+	var deleted, failed int
+	for _, repo := range repos {
+		// the files might have been restored while waiting for the confirmation
+		if isDir, err := util.IsDir(repo.RepoPath()); err != nil || isDir {
+			_, _ = fmt.Fprintf(c.App.ErrWriter, "Skipped %s: its Git files are no longer missing\n", repo.FullName())
+			continue
+		}
+		if err := models.DeleteRepository(doer, repo.OwnerID, repo.ID); err != nil {
+			failed++
+			_, _ = fmt.Fprintf(c.App.ErrWriter, "Failed to delete %s: %v\n", repo.FullName(), err)
+			continue
+		}
+		deleted++
+		_, _ = fmt.Fprintf(c.App.Writer, "Deleted %s\n", repo.FullName())
+	}
+
+	_, _ = fmt.Fprintf(c.App.Writer, "Deleted %d of %d repositories missing on disk\n", deleted, len(repos))
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d repositories", failed)
+	}
+	return nil
+}
+
+func runRepoSyncReleaseTags(c *cli.Context) error {
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if c.Bool("all") == c.IsSet("repo") {
+		return errors.New("one of --all or --repo is required")
+	}
+	type ownerAndName struct{ owner, name string }
+	var repoNames []ownerAndName
+	for _, s := range c.StringSlice("repo") {
+		owner, name, ok := strings.Cut(s, "/")
+		if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			return fmt.Errorf("invalid repository %q, it should be in the format of owner/name", s)
+		}
+		repoNames = append(repoNames, ownerAndName{owner, name})
+	}
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+	if err := git.InitSimple(ctx); err != nil {
+		return err
+	}
+
+	var synced, changed, failed int
+	syncRepo := func(repo *repo_model.Repository) {
+		result, err := syncRepoReleaseTags(ctx, repo, c.Bool("dry-run"))
+		if err != nil {
+			failed++
+			_, _ = fmt.Fprintf(c.App.ErrWriter, "Failed to reconcile the releases of %s: %v\n", repo.FullName(), err)
+			return
+		}
+		synced++
+		if result.IsEmpty() {
+			return
+		}
+		changed++
+		_, _ = fmt.Fprintf(c.App.Writer, "%s: %d added, %d updated, %d deleted, %d marked as draft\n",
+			repo.FullName(), len(result.Added), len(result.Updated), len(result.Deleted), len(result.Drafted))
+		for _, change := range []struct {
+			name string
+			tags []string
+		}{{"added", result.Added}, {"updated", result.Updated}, {"deleted", result.Deleted}, {"marked as draft", result.Drafted}} {
+			if len(change.tags) > 0 {
+				_, _ = fmt.Fprintf(c.App.Writer, "  %s: %s\n", change.name, strings.Join(change.tags, ", "))
+			}
+		}
+	}
+
+	if c.Bool("all") {
+		for page := 1; ; page++ {
+			repos, _, err := repo_model.SearchRepositoryByName(ctx, &repo_model.SearchRepoOptions{
+				ListOptions: db.ListOptions{
+					PageSize: repo_model.RepositoryListDefaultPageSize,
+					Page:     page,
+				},
+				Private: true,
+			})
+			if err != nil {
+				return fmt.Errorf("SearchRepositoryByName: %w", err)
+			}
+			if len(repos) == 0 {
+				break
+			}
+			for _, repo := range repos {
+				if repo.IsEmpty {
+					continue
+				}
+				syncRepo(repo)
+			}
+		}
+	} else {
+		for _, r := range repoNames {
+			repo, err := repo_model.GetRepositoryByOwnerAndName(ctx, r.owner, r.name)
+			if err != nil {
+				failed++
+				_, _ = fmt.Fprintf(c.App.ErrWriter, "Failed to reconcile the releases of %s/%s: %v\n", r.owner, r.name, err)
+				continue
+			}
+			syncRepo(repo)
+		}
+	}
+
+	if c.Bool("dry-run") {
+		_, _ = fmt.Fprintf(c.App.Writer, "Dry run: the releases of %d of %d repositories would be changed\n", changed, synced)
+	} else {
+		_, _ = fmt.Fprintf(c.App.Writer, "The releases of %d of %d repositories were changed\n", changed, synced)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to reconcile the releases of %d repositories", failed)
+	}
+	return nil
+}
+
+func syncRepoReleaseTags(ctx context.Context, repo *repo_model.Repository, dryRun bool) (*repo_module.ReleasesSyncResult, error) {
+	gitRepo, err := git.OpenRepository(ctx, repo.RepoPath())
+	if err != nil {
+		return nil, err
+	}
+	defer gitRepo.Close()
+	return repo_module.SyncReleasesWithTagsResult(repo, gitRepo, dryRun)
+}
+
+func runRepoTransfer(c *cli.Context) error {
+	if err := argsSet(c, "repo", "new-owner"); err != nil {
+		return err
+	}
+	ownerName, repoName, ok := strings.Cut(c.String("repo"), "/")
+	if !ok || ownerName == "" || repoName == "" || strings.Contains(repoName, "/") {
+		return fmt.Errorf("invalid repository %q, it should be in the format of owner/name", c.String("repo"))
+	}
+
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	oldFullName, newFullName, err := transferRepo(ctx, ownerName, repoName, c.String("new-owner"))
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(c.App.Writer, "Transferred %s to %s\n", oldFullName, newFullName)
+	return nil
+}
+
+// transferRepo transfers the repository to the new owner like the web UI does for the admins,
+// it returns the old and the new full names of the repository
+func transferRepo(ctx context.Context, ownerName, repoName, newOwnerName string) (string, string, error) {
+	repo, err := repo_model.GetRepositoryByOwnerAndName(ctx, ownerName, repoName)
+	if err != nil {
+		return "", "", err
+	}
+	if err = models.TestRepositoryReadyForTransfer(repo.Status); err != nil {
+		return "", "", fmt.Errorf("unable to transfer %s: %w", repo.FullName(), err)
+	}
+	newOwner, err := user_model.GetUserByName(ctx, newOwnerName)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to find the new owner %q: %w", newOwnerName, err)
+	}
+	if !newOwner.IsIndividual() && !newOwner.IsOrganization() {
+		return "", "", fmt.Errorf("%s can't own repositories, it is neither a user nor an organization", newOwner.Name)
+	}
+	if newOwner.ID == repo.OwnerID {
+		return "", "", fmt.Errorf("%s is already owned by %s", repo.FullName(), newOwner.Name)
+	}
+	if !newOwner.CanCreateRepo() {
+		return "", "", fmt.Errorf("%s has reached the limit of %d repositories", newOwner.Name, newOwner.MaxCreationLimit())
+	}
+	if exist, err := repo_model.IsRepositoryModelOrDirExist(ctx, newOwner, repo.Name); err != nil {
+		return "", "", err
+	} else if exist {
+		return "", "", fmt.Errorf("%s already has a repository named %s", newOwner.Name, repo.Name)
+	}
+
+	doer, err := user_model.GetAdminUser(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	oldFullName := repo.FullName()
+	if err = repo_service.TransferOwnership(ctx, doer, newOwner, repo, nil); err != nil {
+		return "", "", fmt.Errorf("unable to transfer %s to %s: %w", oldFullName, newOwner.Name, err)
+	}
+	return oldFullName, newOwner.Name + "/" + repo.Name, nil
+}
+
+func runRepoArchive(c *cli.Context) error {
+	return runRepoSetArchived(c, true)
+}
+
+func runRepoUnarchive(c *cli.Context) error {
+	return runRepoSetArchived(c, false)
+}
+
+// runRepoSetArchived archives or unarchives the repositories of --repo or --older-than, all the repositories are
+// looked up before changing any of them, so a typo in a repository name doesn't leave a half done job
+func runRepoSetArchived(c *cli.Context, archive bool) error {
+	verb := "unarchive"
+	if archive {
+		verb = "archive"
+	}
+	if c.IsSet("repo") == c.IsSet("older-than") {
+		return errors.New("one of --repo or --older-than is required")
+	}
+	var olderThan time.Duration
+	if c.IsSet("older-than") {
+		var err error
+		if olderThan, err = parseOlderThan(c.String("older-than")); err != nil {
+			return err
+		}
+	}
+	type ownerAndName struct{ owner, name string }
+	var repoNames []ownerAndName
+	for _, s := range c.StringSlice("repo") {
+		owner, name, ok := strings.Cut(s, "/")
+		if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			return fmt.Errorf("invalid repository %q, it should be in the format of owner/name", s)
+		}
+		repoNames = append(repoNames, ownerAndName{owner, name})
+	}
+
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	var repos []*repo_model.Repository
+	if len(repoNames) > 0 {
+		for _, n := range repoNames {
+			repo, err := repo_model.GetRepositoryByOwnerAndName(ctx, n.owner, n.name)
+			if err != nil {
+				return err
+			}
+			repos = append(repos, repo)
+		}
+	} else {
+		var err error
+		if repos, err = findReposNotUpdatedSince(ctx, archive, time.Now().Add(-olderThan)); err != nil {
+			return err
+		}
+	}
+
+	var changed, failed int
+	for _, repo := range repos {
+		if repo.IsArchived == archive {
+			_, _ = fmt.Fprintf(c.App.ErrWriter, "Skipped %s: it is already %sd\n", repo.FullName(), verb)
+			continue
+		}
+		if archive && repo.IsMirror {
+			_, _ = fmt.Fprintf(c.App.ErrWriter, "Skipped %s: a mirror can't be archived\n", repo.FullName())
+			continue
+		}
+		lastUpdated := repo.UpdatedUnix.FormatDate()
+		if c.Bool("dry-run") {
+			changed++
+			_, _ = fmt.Fprintf(c.App.Writer, "Would %s %s (last updated %s)\n", verb, repo.FullName(), lastUpdated)
+			continue
+		}
+		if err := repo_model.SetArchiveRepoState(repo, archive); err != nil {
+			failed++
+			_, _ = fmt.Fprintf(c.App.ErrWriter, "Failed to %s %s: %v\n", verb, repo.FullName(), err)
+			continue
+		}
+		changed++
+		_, _ = fmt.Fprintf(c.App.Writer, "%sd %s (last updated %s)\n", util.ToTitleCase(verb), repo.FullName(), lastUpdated)
+	}
+
+	if c.Bool("dry-run") {
+		_, _ = fmt.Fprintf(c.App.Writer, "%d repositories would be %sd\n", changed, verb)
+	} else {
+		_, _ = fmt.Fprintf(c.App.Writer, "%d repositories %sd\n", changed, verb)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to %s %d of %d repositories", verb, failed, changed+failed)
+	}
+	return nil
+}
+
+// reindexBatchSize is the number of repositories reindexed by each call of --all, the progress is printed after each
+const reindexBatchSize = 50
+
+func runRepoReindex(c *cli.Context) error {
+	if c.Bool("all") == c.IsSet("repo") {
+		return errors.New("one of --all or --repo is required")
+	}
+	types := c.StringSlice("type")
+	for _, t := range types {
+		if t != "code" && t != "issues" {
+			return fmt.Errorf("invalid --type %q, it should be code or issues", t)
+		}
+	}
+
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	setting.MustInstalled()
+	verb := "Queued"
+	if c.Bool("wait") {
+		verb = "Reindexed"
+	}
+	opts := private.ReindexOptions{
+		Repos:     c.StringSlice("repo"),
+		Types:     types,
+		BatchSize: reindexBatchSize,
+		Wait:      c.Bool("wait"),
+	}
+	var done int
+	for {
+		result, extra := private.Reindex(ctx, opts)
+		if extra.HasError() {
+			return handleCliResponseExtra(extra)
+		}
+		done += len(result.Repos)
+		if !c.Bool("all") {
+			for _, repo := range result.Repos {
+				_, _ = fmt.Fprintf(c.App.Writer, "%s %s (%s)\n", verb, repo, strings.Join(types, ", "))
+			}
+			break
+		}
+		// a batch can have no repository to reindex, e.g. when all its repositories are empty, so the batches end with an empty one
+		if result.LastID == 0 {
+			break
+		}
+		_, _ = fmt.Fprintf(c.App.Writer, "%s %d of %d repositories\n", verb, done, result.Total)
+		opts.AfterID = result.LastID
+	}
+	_, _ = fmt.Fprintf(c.App.Writer, "%s %d repositories for the %s index(es)\n", verb, done, strings.Join(types, " and "))
+	return nil
+}
+
+// findReposNotUpdatedSince returns the repositories to archive (or unarchive) which haven't been updated since the time,
+// the mirrors are never returned: they can't be archived, and an archived mirror is only unarchived on purpose
+func findReposNotUpdatedSince(ctx context.Context, archive bool, since time.Time) ([]*repo_model.Repository, error) {
+	var repos []*repo_model.Repository
+	cond := builder.Eq{"is_archived": !archive, "is_mirror": false}.
+		And(builder.Lt{"updated_unix": since.Unix()})
+	return repos, db.GetEngine(ctx).Where(cond).OrderBy("owner_name, lower_name").Find(&repos)
+}
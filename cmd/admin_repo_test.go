@@ -0,0 +1,127 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+	repo_service "code.gitea.io/gitea/services/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+func TestUnadoptedOwnerQuery(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	ctx := context.Background()
+
+	// the owner's name is mixed case, the directory of its repositories is lower case
+	_, err := db.GetEngine(ctx).ID(2).Cols("name").Update(&user_model.User{Name: "User2"})
+	assert.NoError(t, err)
+	assert.NoError(t, os.MkdirAll(filepath.Join(setting.RepoRootPath, "user2", "unadopted-mixed.git"), 0o755))
+	defer os.RemoveAll(filepath.Join(setting.RepoRootPath, "user2", "unadopted-mixed.git"))
+
+	query, err := unadoptedOwnerQuery(ctx, "User2")
+	assert.NoError(t, err)
+	assert.Equal(t, "user2/*", query)
+
+	var repoNames []string
+	assert.NoError(t, repo_service.IterateUnadoptedRepositories(ctx, query, func(repoName string) error {
+		repoNames = append(repoNames, repoName)
+		return nil
+	}))
+	assert.Equal(t, []string{"user2/unadopted-mixed"}, repoNames)
+
+	_, err = unadoptedOwnerQuery(ctx, "no-such-user")
+	assert.ErrorContains(t, err, `unable to find the owner "no-such-user"`)
+}
+
+func TestRepoDeleteMissingFlags(t *testing.T) {
+	app := cli.NewApp()
+	app.Flags = microcmdRepoDeleteMissing.Flags
+	app.Action = runRepoDeleteMissing
+	// the confirmation can only be skipped for the deletion
+	assert.EqualError(t, app.Run([]string{"./gitea", "--yes"}), "--yes can only be used with --confirm")
+}
+
+func TestTransferRepo(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	ctx := context.Background()
+
+	_, _, err := transferRepo(ctx, "user2", "repo1", "user2")
+	assert.EqualError(t, err, "user2/repo1 is already owned by user2")
+	_, _, err = transferRepo(ctx, "user2", "repo1", "no-such-user")
+	assert.ErrorContains(t, err, `unable to find the new owner "no-such-user"`)
+	_, _, err = transferRepo(ctx, "user2", "no-such-repo", "user4")
+	assert.True(t, repo_model.IsErrRepoNotExist(err))
+
+	// the Git files of an unadopted repository have the name
+	assert.NoError(t, os.MkdirAll(filepath.Join(setting.RepoRootPath, "user4", "repo1.git"), 0o755))
+	_, _, err = transferRepo(ctx, "user2", "repo1", "user4")
+	assert.EqualError(t, err, "user4 already has a repository named repo1")
+	assert.NoError(t, os.RemoveAll(filepath.Join(setting.RepoRootPath, "user4", "repo1.git")))
+
+	oldFullName, newFullName, err := transferRepo(ctx, "user2", "repo1", "user4")
+	assert.NoError(t, err)
+	assert.Equal(t, "user2/repo1", oldFullName)
+	assert.Equal(t, "user4/repo1", newFullName)
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1, OwnerID: 4, OwnerName: "user4"})
+	isDir, err := util.IsDir(filepath.Join(setting.RepoRootPath, "user4", "repo1.git"))
+	assert.NoError(t, err)
+	assert.True(t, isDir)
+
+	// the Git files are moved back for the other tests
+	_, _, err = transferRepo(ctx, "user4", "repo1", "user2")
+	assert.NoError(t, err)
+}
+
+func TestFindReposNotUpdatedSince(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	ctx := context.Background()
+
+	now := time.Now()
+	_, err := db.GetEngine(ctx).Where("id > 0").Cols("updated_unix").NoAutoTime().Update(&repo_model.Repository{UpdatedUnix: timeutil.TimeStamp(now.Unix())})
+	assert.NoError(t, err)
+	// the repository 5 is a mirror, the repository 51 is archived
+	_, err = db.GetEngine(ctx).In("id", 1, 5, 51).Cols("updated_unix").NoAutoTime().Update(&repo_model.Repository{UpdatedUnix: timeutil.TimeStamp(now.Add(-48 * time.Hour).Unix())})
+	assert.NoError(t, err)
+
+	repoIDs := func(archive bool, since time.Time) []int64 {
+		repos, err := findReposNotUpdatedSince(ctx, archive, since)
+		assert.NoError(t, err)
+		ids := make([]int64, 0, len(repos))
+		for _, repo := range repos {
+			ids = append(ids, repo.ID)
+		}
+		return ids
+	}
+	assert.Equal(t, []int64{1}, repoIDs(true, now.Add(-24*time.Hour)))
+	assert.Equal(t, []int64{51}, repoIDs(false, now.Add(-24*time.Hour)))
+	assert.Empty(t, repoIDs(true, now.Add(-72*time.Hour)))
+}
+
+func TestRepoArchiveFlags(t *testing.T) {
+	for args, msg := range map[string]string{
+		"":                                   "one of --repo or --older-than is required",
+		"--repo user2/repo1 --older-than 1d": "one of --repo or --older-than is required",
+		"--repo user2":                       `invalid repository "user2", it should be in the format of owner/name`,
+	} {
+		app := cli.NewApp()
+		app.Flags = microcmdRepoArchive.Flags
+		app.Action = runRepoArchive
+		assert.EqualError(t, app.Run(append([]string{"./gitea"}, strings.Fields(args)...)), msg, args)
+	}
+}
@@ -15,7 +15,11 @@ var subcmdUser = &cli.Command{
 		microcmdUserList,
 		microcmdUserChangePassword,
 		microcmdUserDelete,
+		microcmdUserRename,
 		microcmdUserGenerateAccessToken,
 		microcmdUserMustChangePassword,
+		microcmdUserSetQuota,
+		microcmdUserExportKeys,
+		microcmdUserImport,
 	},
 }
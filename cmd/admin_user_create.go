@@ -6,7 +6,9 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	auth_model "code.gitea.io/gitea/models/auth"
 	user_model "code.gitea.io/gitea/models/user"
@@ -14,6 +16,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
 
+	"github.com/mattn/go-isatty"
 	"github.com/urfave/cli/v2"
 )
 
@@ -34,6 +37,10 @@ var microcmdUserCreate = &cli.Command{
 			Name:  "password",
 			Usage: "User password",
 		},
+		&cli.BoolFlag{
+			Name:  "password-stdin",
+			Usage: "Read the user password from stdin, a single trailing newline is trimmed",
+		},
 		&cli.StringFlag{
 			Name:  "email",
 			Usage: "User email address",
@@ -81,6 +88,9 @@ func runCreateUser(c *cli.Context) error {
 	if c.IsSet("password") && c.IsSet("random-password") {
 		return errors.New("cannot set both -random-password and -password flags")
 	}
+	if c.Bool("password-stdin") && (c.IsSet("password") || c.IsSet("random-password")) {
+		return errors.New("cannot set --password-stdin with --password or --random-password flags")
+	}
 
 	var username string
 	if c.IsSet("username") {
@@ -90,6 +100,18 @@ func runCreateUser(c *cli.Context) error {
 		fmt.Fprintf(os.Stderr, "--name flag is deprecated. Use --username instead.\n")
 	}
 
+	// read the password before initializing the database, so a wrong input fails fast
+	var password string
+	if c.Bool("password-stdin") {
+		if f, ok := c.App.Reader.(*os.File); ok && isatty.IsTerminal(f.Fd()) {
+			return errors.New("--password-stdin requires the password to be piped to stdin, but stdin is a terminal")
+		}
+		var err error
+		if password, err = readPasswordFromReader(c.App.Reader); err != nil {
+			return err
+		}
+	}
+
 	ctx, cancel := installSignals()
 	defer cancel()
 
@@ -97,7 +119,6 @@ func runCreateUser(c *cli.Context) error {
 		return err
 	}
 
-	var password string
 	if c.IsSet("password") {
 		password = c.String("password")
 	} else if c.IsSet("random-password") {
@@ -107,8 +128,8 @@ func runCreateUser(c *cli.Context) error {
 			return err
 		}
 		fmt.Printf("generated random password is '%s'\n", password)
-	} else {
-		return errors.New("must set either password or random-password flag")
+	} else if !c.Bool("password-stdin") {
+		return errors.New("must set either password, password-stdin or random-password flag")
 	}
 
 	// always default to true
@@ -167,3 +188,16 @@ func runCreateUser(c *cli.Context) error {
 	fmt.Printf("New user '%s' has been successfully created!\n", username)
 	return nil
 }
+
+// readPasswordFromReader reads the whole password from the reader, only a single trailing newline is trimmed
+func readPasswordFromReader(r io.Reader) (string, error) {
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("unable to read the password: %w", err)
+	}
+	password := strings.TrimSuffix(strings.TrimSuffix(string(bs), "\n"), "\r")
+	if password == "" {
+		return "", errors.New("the password read from stdin is empty")
+	}
+	return password, nil
+}
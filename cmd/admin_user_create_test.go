@@ -0,0 +1,28 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadPasswordFromReader(t *testing.T) {
+	for input, expected := range map[string]string{
+		"secret":       "secret",
+		"secret\n":     "secret",
+		"secret\r\n":   "secret",
+		"secret\n\n":   "secret\n",
+		" with space ": " with space ",
+	} {
+		password, err := readPasswordFromReader(strings.NewReader(input))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, password, "input %q", input)
+	}
+
+	_, err := readPasswordFromReader(strings.NewReader("\n"))
+	assert.Error(t, err)
+}
@@ -36,6 +36,10 @@ var microcmdUserDelete = &cli.Command{
 			Name:  "purge",
 			Usage: "Purge user, all their repositories, organizations and comments",
 		},
+		&cli.StringFlag{
+			Name:  "transfer-to",
+			Usage: "Transfer the repositories of the user to this user or organization before deleting the user",
+		},
 	},
 	Action: runDeleteUser,
 }
@@ -76,5 +80,23 @@ func runDeleteUser(c *cli.Context) error {
 		return fmt.Errorf("The user %s does not match the provided id %d", user.Name, c.Int64("id"))
 	}
 
+	if c.IsSet("transfer-to") {
+		newOwner, err := user_model.GetUserByName(ctx, c.String("transfer-to"))
+		if err != nil {
+			return fmt.Errorf("unable to find the user to transfer the repositories to: %w", err)
+		}
+		doer, err := user_model.GetAdminUser(ctx)
+		if err != nil {
+			return err
+		}
+		count, err := user_service.TransferUserRepositories(ctx, doer, user, newOwner)
+		if count > 0 || err == nil {
+			fmt.Printf("Transferred %d repositories of %s to %s\n", count, user.Name, newOwner.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
 	return user_service.DeleteUser(ctx, user, c.Bool("purge"))
 }
@@ -0,0 +1,131 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+
+	"github.com/urfave/cli/v2"
+)
+
+var microcmdUserExportKeys = &cli.Command{
+	Name:        "export-keys",
+	Usage:       "Export the public SSH or GPG keys of a user or of all the users",
+	Description: "Print the public SSH keys in the authorized_keys format, or the public GPG keys as an armored block, e.g. to migrate them to another system",
+	Action:      runExportUserKeys,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "username",
+			Aliases: []string{"u"},
+			Usage:   "The user whose keys are exported",
+		},
+		&cli.BoolFlag{
+			Name:  "all",
+			Usage: "Export the keys of all the users",
+		},
+		&cli.StringFlag{
+			Name:  "type",
+			Value: "ssh",
+			Usage: "The type of the keys: ssh or gpg",
+		},
+	},
+}
+
+func runExportUserKeys(c *cli.Context) error {
+	if c.IsSet("username") == c.Bool("all") {
+		return errors.New("either --username or --all is required")
+	}
+	keyType := c.String("type")
+	if keyType != "ssh" && keyType != "gpg" {
+		return fmt.Errorf("unknown key type %q, it should be one of: ssh, gpg", keyType)
+	}
+
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	if !c.Bool("all") {
+		user, err := user_model.GetUserByName(ctx, c.String("username"))
+		if err != nil {
+			return err
+		}
+		if user.IsOrganization() {
+			return fmt.Errorf("%s is an organization not a user", user.Name)
+		}
+		if keyType == "ssh" {
+			err = exportUserSSHKeys(c.App.Writer, user, false)
+		} else {
+			err = exportUserGPGKeys(ctx, c.App.Writer, user, false)
+		}
+		return err
+	}
+
+	users, err := user_model.GetAllUsers()
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if keyType == "ssh" {
+			err = exportUserSSHKeys(c.App.Writer, user, true)
+		} else {
+			err = exportUserGPGKeys(ctx, c.App.Writer, user, true)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to export the keys of %s: %w", user.Name, err)
+		}
+	}
+	return nil
+}
+
+// exportUserSSHKeys writes the public SSH keys of the user in the authorized_keys format, without the comments of
+// the keys (like the /{username}.keys page). With several users each of them is introduced by a "# username" line,
+// the users without keys are skipped.
+func exportUserSSHKeys(out io.Writer, user *user_model.User, several bool) error {
+	keys, err := asymkey_model.ListPublicKeys(user.ID, db.ListOptions{})
+	if err != nil {
+		return err
+	}
+	if several && len(keys) == 0 {
+		return nil
+	}
+	if several {
+		if _, err := fmt.Fprintf(out, "# %s\n", user.Name); err != nil {
+			return err
+		}
+	}
+	for _, key := range keys {
+		if _, err := fmt.Fprintln(out, key.OmitEmail()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportUserGPGKeys writes the public GPG keys of the user as an armored block (like the /{username}.gpg page).
+// With several users each of them has its own block with the name in the "Comment" header, the users without keys
+// are skipped.
+func exportUserGPGKeys(ctx context.Context, out io.Writer, user *user_model.User, several bool) error {
+	keys, err := asymkey_model.ListGPGKeys(ctx, user.ID, db.ListOptions{})
+	if err != nil {
+		return err
+	}
+	if several && len(keys) == 0 {
+		return nil
+	}
+	var headers map[string]string
+	if several {
+		headers = map[string]string{"Comment": user.Name}
+	}
+	return asymkey_model.WriteArmoredGPGKeys(out, keys, headers)
+}
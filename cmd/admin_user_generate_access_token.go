@@ -5,6 +5,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	auth_model "code.gitea.io/gitea/models/auth"
 	user_model "code.gitea.io/gitea/models/user"
@@ -34,7 +35,7 @@ var microcmdUserGenerateAccessToken = &cli.Command{
 		&cli.StringFlag{
 			Name:  "scopes",
 			Value: "",
-			Usage: "Comma separated list of scopes to apply to access token",
+			Usage: "Comma separated list of scopes to apply to access token, e.g. read:repository,write:issue",
 		},
 	},
 	Action: runGenerateAccessToken,
@@ -45,6 +46,12 @@ func runGenerateAccessToken(c *cli.Context) error {
 		return fmt.Errorf("You must provide a username to generate a token for")
 	}
 
+	// make sure the scopes are valid before touching the database
+	accessTokenScope, err := parseAccessTokenScopes(c.String("scopes"))
+	if err != nil {
+		return err
+	}
+
 	ctx, cancel := installSignals()
 	defer cancel()
 
@@ -71,11 +78,6 @@ func runGenerateAccessToken(c *cli.Context) error {
 		return fmt.Errorf("access token name has been used already")
 	}
 
-	// make sure the scopes are valid
-	accessTokenScope, err := auth_model.AccessTokenScope(c.String("scopes")).Normalize()
-	if err != nil {
-		return fmt.Errorf("invalid access token scope provided: %w", err)
-	}
 	t.Scope = accessTokenScope
 
 	// create the token
@@ -91,3 +93,26 @@ func runGenerateAccessToken(c *cli.Context) error {
 
 	return nil
 }
+
+// parseAccessTokenScopes parses the comma separated scopes, all the unknown ones are reported with the known ones
+func parseAccessTokenScopes(s string) (auth_model.AccessTokenScope, error) {
+	var scopes, unknownScopes []string
+	for _, scope := range strings.Split(s, ",") {
+		scope = strings.TrimSpace(scope)
+		if scope == "" {
+			continue
+		}
+		if _, err := auth_model.AccessTokenScope(scope).Normalize(); err != nil {
+			unknownScopes = append(unknownScopes, scope)
+		}
+		scopes = append(scopes, scope)
+	}
+	if len(unknownScopes) > 0 {
+		knownScopes := make([]string, 0, len(auth_model.AllAccessTokenScopes()))
+		for _, scope := range auth_model.AllAccessTokenScopes() {
+			knownScopes = append(knownScopes, string(scope))
+		}
+		return "", fmt.Errorf("invalid access token scopes provided: %s, the valid scopes are: %s", strings.Join(unknownScopes, ", "), strings.Join(knownScopes, ", "))
+	}
+	return auth_model.AccessTokenScope(strings.Join(scopes, ",")).Normalize()
+}
@@ -0,0 +1,185 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	user_model "code.gitea.io/gitea/models/user"
+	pwd "code.gitea.io/gitea/modules/auth/password"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var microcmdUserImport = &cli.Command{
+	Name:  "import",
+	Usage: "Create users from an htpasswd file",
+	Description: `Create a user for each "username:hash" line of the htpasswd file, the users must change their password at
+the first sign-in. The bcrypt hashes are kept, so the users sign in with their current password. The other hashes
+(MD5, SHA-1, crypt...) can't be used by Gitea, these users get a random password which needs to be reset.
+The existing users are skipped. The result of each line is printed, then a summary.`,
+	Action: runImportUsers,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "htpasswd",
+			Usage: "The htpasswd file to import",
+		},
+		&cli.StringFlag{
+			Name:  "email-domain",
+			Usage: "The domain of the email addresses of the users, they are username@domain since htpasswd has no email addresses",
+		},
+	},
+}
+
+// htpasswdEntry is a user of an htpasswd file
+type htpasswdEntry struct {
+	Line int
+	Name string
+	Hash string
+}
+
+// parseHtpasswdLine parses a "username:hash" line, ok is false for the blank lines and the comments
+func parseHtpasswdLine(line string) (entry htpasswdEntry, ok bool, err error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return entry, false, nil
+	}
+	name, hash, found := strings.Cut(line, ":")
+	if !found || name == "" || hash == "" {
+		return entry, false, errors.New(`invalid line, it should be "username:hash"`)
+	}
+	return htpasswdEntry{Name: name, Hash: hash}, true, nil
+}
+
+// htpasswdHashType returns the name of the algorithm of the htpasswd hash, only "bcrypt" can be used by Gitea
+func htpasswdHashType(hash string) string {
+	switch {
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+			return "invalid bcrypt"
+		}
+		return "bcrypt"
+	case strings.HasPrefix(hash, "$apr1$"):
+		return "MD5"
+	case strings.HasPrefix(hash, "{SHA}"):
+		return "SHA-1"
+	case strings.HasPrefix(hash, "$5$"):
+		return "SHA-256 crypt"
+	case strings.HasPrefix(hash, "$6$"):
+		return "SHA-512 crypt"
+	default:
+		return "crypt"
+	}
+}
+
+func readHtpasswdFile(path string) ([]htpasswdEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []htpasswdEntry
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		entry, ok, err := parseHtpasswdLine(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		} else if ok {
+			entry.Line = lineNum
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// importedPasswordLength is the length of the random passwords of the imported users, until they reset it
+const importedPasswordLength = 32
+
+func runImportUsers(c *cli.Context) error {
+	if err := argsSet(c, "htpasswd", "email-domain"); err != nil {
+		return err
+	}
+	emailDomain := strings.TrimPrefix(c.String("email-domain"), "@")
+
+	// the whole file is parsed first, so a malformed line doesn't leave a half done import
+	entries, err := readHtpasswdFile(c.String("htpasswd"))
+	if err != nil {
+		return fmt.Errorf("unable to read the htpasswd file: %w", err)
+	}
+
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	return importUsers(ctx, c.App.Writer, entries, emailDomain)
+}
+
+// importUsers creates the users of the htpasswd entries and prints the result of each one, then a summary
+func importUsers(ctx context.Context, w io.Writer, entries []htpasswdEntry, emailDomain string) error {
+	var created, needReset, skipped, failed int
+	for _, entry := range entries {
+		hashType := htpasswdHashType(entry.Hash)
+		// the users whose hash can't be used get a password nobody knows
+		password, err := pwd.Generate(importedPasswordLength)
+		if err != nil {
+			return err
+		}
+		u := &user_model.User{
+			Name:               entry.Name,
+			Email:              entry.Name + "@" + emailDomain,
+			Passwd:             password,
+			MustChangePassword: true,
+			Visibility:         setting.Service.DefaultUserVisibilityMode,
+		}
+		overwriteDefault := &user_model.CreateUserOverwriteOptions{
+			IsActive:     util.OptionalBoolTrue,
+			IsRestricted: util.OptionalBoolNone,
+		}
+		if err := user_model.CreateUser(u, overwriteDefault); err != nil {
+			if user_model.IsErrUserAlreadyExist(err) {
+				skipped++
+				_, _ = fmt.Fprintf(w, "line %d: %s: skipped, the user already exists\n", entry.Line, entry.Name)
+				continue
+			}
+			failed++
+			_, _ = fmt.Fprintf(w, "line %d: %s: error: %v\n", entry.Line, entry.Name, err)
+			continue
+		}
+		created++
+
+		if hashType != "bcrypt" {
+			needReset++
+			_, _ = fmt.Fprintf(w, "line %d: %s: created, the password needs to be reset (%s hash)\n", entry.Line, entry.Name, hashType)
+			continue
+		}
+		// Gitea checks the bcrypt hash as is, it's rehashed with the configured algorithm at the first sign-in
+		u.Passwd = entry.Hash
+		u.PasswdHashAlgo = "bcrypt"
+		if err := user_model.UpdateUserCols(ctx, u, "passwd", "passwd_hash_algo"); err != nil {
+			needReset++
+			_, _ = fmt.Fprintf(w, "line %d: %s: created, the password needs to be reset (unable to keep the bcrypt hash: %v)\n", entry.Line, entry.Name, err)
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "line %d: %s: created\n", entry.Line, entry.Name)
+	}
+
+	_, _ = fmt.Fprintf(w, "Created %d users (%d need a password reset), skipped %d existing users, %d errors\n", created, needReset, skipped, failed)
+	if failed > 0 {
+		return fmt.Errorf("failed to import %d of %d users", failed, len(entries))
+	}
+	return nil
+}
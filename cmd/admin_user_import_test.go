@@ -0,0 +1,85 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestParseHtpasswdLine(t *testing.T) {
+	entry, ok, err := parseHtpasswdLine("  alice:$apr1$salt$hash\n")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "alice", entry.Name)
+	assert.Equal(t, "$apr1$salt$hash", entry.Hash)
+
+	for _, line := range []string{"", "   ", "# a comment"} {
+		_, ok, err = parseHtpasswdLine(line)
+		assert.NoError(t, err, "line %q", line)
+		assert.False(t, ok, "line %q", line)
+	}
+
+	for _, line := range []string{"alice", "alice:", ":hash"} {
+		_, _, err = parseHtpasswdLine(line)
+		assert.Error(t, err, "line %q", line)
+	}
+}
+
+func TestHtpasswdHashType(t *testing.T) {
+	for hash, expected := range map[string]string{
+		"$2y$05$yVkVUnKuCpbfIjxqTKcSE.8FEZqRvRQ0H0/6hhM31Kj1xDVyU1wvK": "bcrypt",
+		"$2a$10$truncated":                      "invalid bcrypt",
+		"$apr1$8KjzhCQA$rBDaUfWEc2/PMKuCzrrUe/": "MD5",
+		"{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=":     "SHA-1",
+		"$6$salt$hash":                          "SHA-512 crypt",
+		"rqXexS6ZhobKA":                         "crypt",
+	} {
+		assert.Equal(t, expected, htpasswdHashType(hash), "hash %q", hash)
+	}
+}
+
+func TestImportUsers(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("imported-password"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	entries := []htpasswdEntry{
+		{Line: 1, Name: "imported-bcrypt", Hash: string(hash)},
+		{Line: 2, Name: "imported-sha1", Hash: "{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g="},
+		{Line: 3, Name: "user2", Hash: string(hash)},
+	}
+	out := &strings.Builder{}
+	assert.NoError(t, importUsers(db.DefaultContext, out, entries, "example.com"))
+	assert.Equal(t, `line 1: imported-bcrypt: created
+line 2: imported-sha1: created, the password needs to be reset (SHA-1 hash)
+line 3: user2: skipped, the user already exists
+Created 2 users (1 need a password reset), skipped 1 existing users, 0 errors
+`, out.String())
+
+	// the bcrypt user signs in with the password of the htpasswd file, but must change it
+	u := unittest.AssertExistsAndLoadBean(t, &user_model.User{Name: "imported-bcrypt"})
+	assert.Equal(t, "imported-bcrypt@example.com", u.Email)
+	assert.True(t, u.IsActive)
+	assert.True(t, u.MustChangePassword)
+	assert.True(t, u.ValidatePassword("imported-password"))
+
+	// the password of the other users is unknown, it must be reset
+	u = unittest.AssertExistsAndLoadBean(t, &user_model.User{Name: "imported-sha1"})
+	assert.True(t, u.MustChangePassword)
+	assert.False(t, u.ValidatePassword("password"))
+
+	// the existing users are not changed
+	u = unittest.AssertExistsAndLoadBean(t, &user_model.User{Name: "user2"})
+	assert.Equal(t, "user2@example.com", u.Email)
+	assert.False(t, u.MustChangePassword)
+	assert.False(t, u.ValidatePassword("imported-password"))
+}
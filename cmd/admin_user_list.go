@@ -4,24 +4,39 @@
 package cmd
 
 import (
-	"fmt"
 	"os"
-	"text/tabwriter"
+	"time"
 
+	auth_model "code.gitea.io/gitea/models/auth"
 	user_model "code.gitea.io/gitea/models/user"
 
 	"github.com/urfave/cli/v2"
 )
 
 var microcmdUserList = &cli.Command{
-	Name:   "list",
-	Usage:  "List users",
+	Name:  "list",
+	Usage: "List users",
+	Description: `The users can be filtered by --admin, --inactive, --prohibit-login and --source-id,
+only the users matching all the given filters are listed.`,
 	Action: runListUsers,
 	Flags: []cli.Flag{
 		&cli.BoolFlag{
 			Name:  "admin",
 			Usage: "List only admin users",
 		},
+		&cli.BoolFlag{
+			Name:  "inactive",
+			Usage: "List only the users who are not activated",
+		},
+		&cli.BoolFlag{
+			Name:  "prohibit-login",
+			Usage: "List only the users who are prohibited from logging in",
+		},
+		&cli.Int64Flag{
+			Name:  "source-id",
+			Usage: "List only the users of the authentication source, 0 for the local users",
+		},
+		listFormatFlag,
 	},
 }
 
@@ -29,32 +44,78 @@ func runListUsers(c *cli.Context) error {
 	ctx, cancel := installSignals()
 	defer cancel()
 
+	formatter, err := newListFormatter(c.String("format"), os.Stdout)
+	if err != nil {
+		return err
+	}
+
 	if err := initDB(ctx); err != nil {
 		return err
 	}
 
+	// an unknown source is more likely a typo than a source without users
+	if sourceID := c.Int64("source-id"); sourceID != 0 {
+		if _, err := auth_model.GetSourceByID(sourceID); err != nil {
+			return err
+		}
+	}
+
 	users, err := user_model.GetAllUsers()
 	if err != nil {
 		return err
 	}
+	twofa := user_model.UserList(users).GetTwoFaStatus()
 
-	w := tabwriter.NewWriter(os.Stdout, 5, 0, 1, ' ', 0)
-
-	if c.IsSet("admin") {
-		fmt.Fprintf(w, "ID\tUsername\tEmail\tIsActive\n")
-		for _, u := range users {
-			if u.IsAdmin {
-				fmt.Fprintf(w, "%d\t%s\t%s\t%t\n", u.ID, u.Name, u.Email, u.IsActive)
-			}
+	columns := userListColumns(c.String("format"), c.IsSet("admin"))
+	if err = formatter.WriteHeader(columns); err != nil {
+		return err
+	}
+	for _, u := range users {
+		if !isUserListed(c, u) {
+			continue
 		}
-	} else {
-		twofa := user_model.UserList(users).GetTwoFaStatus()
-		fmt.Fprintf(w, "ID\tUsername\tEmail\tIsActive\tIsAdmin\t2FA\n")
-		for _, u := range users {
-			fmt.Fprintf(w, "%d\t%s\t%s\t%t\t%t\t%t\n", u.ID, u.Name, u.Email, u.IsActive, u.IsAdmin, twofa[u.ID])
+		row := []any{u.ID, u.Name, u.Email, u.IsActive, u.IsAdmin, twofa[u.ID], u.CreatedUnix.AsTime().Format(time.RFC3339)}
+		if err = formatter.WriteRow(row[:len(columns)]...); err != nil {
+			return err
 		}
 	}
+	return formatter.Flush()
+}
+
+// userListColumns returns the columns of the listed users, the text format keeps the columns of its former output:
+// the admins are listed without the IsAdmin and 2FA columns, and the new columns are only in the csv and json formats
+func userListColumns(format string, adminOnly bool) []listColumn {
+	columns := []listColumn{
+		{Title: "ID", Key: "id"},
+		{Title: "Username", Key: "username"},
+		{Title: "Email", Key: "email"},
+		{Title: "IsActive", Key: "is_active"},
+		{Title: "IsAdmin", Key: "is_admin"},
+		{Title: "2FA", Key: "two_factor"},
+		{Title: "Created", Key: "created"},
+	}
+	if format != "" && format != "text" {
+		return columns
+	}
+	if adminOnly {
+		return columns[:4]
+	}
+	return columns[:6]
+}
 
-	w.Flush()
-	return nil
+// isUserListed tells whether the user matches all the filter flags
+func isUserListed(c *cli.Context, u *user_model.User) bool {
+	if c.IsSet("admin") && !u.IsAdmin {
+		return false
+	}
+	if c.Bool("inactive") && u.IsActive {
+		return false
+	}
+	if c.Bool("prohibit-login") && !u.ProhibitLogin {
+		return false
+	}
+	if c.IsSet("source-id") && u.LoginSource != c.Int64("source-id") {
+		return false
+	}
+	return true
 }
@@ -0,0 +1,64 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"testing"
+
+	user_model "code.gitea.io/gitea/models/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+func TestIsUserListed(t *testing.T) {
+	users := []*user_model.User{
+		{ID: 1, IsActive: true, IsAdmin: true},
+		{ID: 2, IsActive: true},
+		{ID: 3, IsActive: false},
+		{ID: 4, IsActive: true, ProhibitLogin: true, LoginSource: 1},
+		{ID: 5, IsActive: false, ProhibitLogin: true, LoginSource: 2},
+	}
+	listed := func(args ...string) []int64 {
+		var ids []int64
+		app := cli.NewApp()
+		app.Flags = microcmdUserList.Flags
+		app.Action = func(c *cli.Context) error {
+			for _, u := range users {
+				if isUserListed(c, u) {
+					ids = append(ids, u.ID)
+				}
+			}
+			return nil
+		}
+		assert.NoError(t, app.Run(append([]string{"./gitea"}, args...)))
+		return ids
+	}
+
+	assert.Equal(t, []int64{1, 2, 3, 4, 5}, listed())
+	assert.Equal(t, []int64{1}, listed("--admin"))
+	assert.Equal(t, []int64{3, 5}, listed("--inactive"))
+	assert.Equal(t, []int64{4, 5}, listed("--prohibit-login"))
+	assert.Equal(t, []int64{4}, listed("--source-id", "1"))
+	// 0 is the local users
+	assert.Equal(t, []int64{1, 2, 3}, listed("--source-id", "0"))
+	// all the filters must match
+	assert.Equal(t, []int64{5}, listed("--inactive", "--prohibit-login"))
+	assert.Empty(t, listed("--inactive", "--source-id", "1"))
+}
+
+func TestUserListColumns(t *testing.T) {
+	titles := func(format string, adminOnly bool) []string {
+		var titles []string
+		for _, col := range userListColumns(format, adminOnly) {
+			titles = append(titles, col.Title)
+		}
+		return titles
+	}
+	// the text format keeps its former columns
+	assert.Equal(t, []string{"ID", "Username", "Email", "IsActive", "IsAdmin", "2FA"}, titles("text", false))
+	assert.Equal(t, []string{"ID", "Username", "Email", "IsActive"}, titles("text", true))
+	assert.Equal(t, []string{"ID", "Username", "Email", "IsActive", "IsAdmin", "2FA", "Created"}, titles("csv", false))
+	assert.Equal(t, []string{"ID", "Username", "Email", "IsActive", "IsAdmin", "2FA", "Created"}, titles("json", true))
+}
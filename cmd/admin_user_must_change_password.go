@@ -14,7 +14,7 @@ import (
 
 var microcmdUserMustChangePassword = &cli.Command{
 	Name:   "must-change-password",
-	Usage:  "Set the must change password flag for the provided users or all users",
+	Usage:  "Set the must change password flag for the provided users, all admins or all users",
 	Action: runMustChangePassword,
 	Flags: []cli.Flag{
 		&cli.BoolFlag{
@@ -22,6 +22,10 @@ var microcmdUserMustChangePassword = &cli.Command{
 			Aliases: []string{"A"},
 			Usage:   "All users must change password, except those explicitly excluded with --exclude",
 		},
+		&cli.BoolFlag{
+			Name:  "all-admins",
+			Usage: "All admins must change password (in addition to the provided users), except those explicitly excluded with --exclude",
+		},
 		&cli.StringSliceFlag{
 			Name:    "exclude",
 			Aliases: []string{"e"},
@@ -38,19 +42,26 @@ func runMustChangePassword(c *cli.Context) error {
 	ctx, cancel := installSignals()
 	defer cancel()
 
-	if c.NArg() == 0 && !c.IsSet("all") {
-		return errors.New("either usernames or --all must be provided")
+	if c.NArg() == 0 && !c.IsSet("all") && !c.IsSet("all-admins") {
+		return errors.New("either usernames, --all or --all-admins must be provided")
+	}
+	if c.IsSet("all") && c.IsSet("all-admins") {
+		return errors.New("--all and --all-admins cannot both be set")
 	}
 
 	mustChangePassword := !c.Bool("unset")
-	all := c.Bool("all")
-	exclude := c.StringSlice("exclude")
+	opts := user_model.SetMustChangePasswordOptions{
+		All:       c.Bool("all"),
+		AllAdmins: c.Bool("all-admins"),
+		Include:   c.Args().Slice(),
+		Exclude:   c.StringSlice("exclude"),
+	}
 
 	if err := initDB(ctx); err != nil {
 		return err
 	}
 
-	n, err := user_model.SetMustChangePassword(ctx, all, mustChangePassword, c.Args().Slice(), exclude)
+	n, err := user_model.SetMustChangePassword(ctx, mustChangePassword, opts)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,76 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	user_model "code.gitea.io/gitea/models/user"
+	user_service "code.gitea.io/gitea/services/user"
+
+	"github.com/urfave/cli/v2"
+)
+
+var microcmdUserRename = &cli.Command{
+	Name:        "rename",
+	Usage:       "Rename a user",
+	Description: "Rename a user like the web UI does, the repositories are moved and the old name redirects to the new one",
+	Action:      runRenameUser,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "username",
+			Aliases: []string{"u"},
+			Usage:   "The current name of the user",
+		},
+		&cli.StringFlag{
+			Name:  "new-name",
+			Usage: "The new name of the user",
+		},
+	},
+}
+
+func runRenameUser(c *cli.Context) error {
+	if err := argsSet(c, "username", "new-name"); err != nil {
+		return err
+	}
+
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	oldName, err := renameUser(ctx, c.String("username"), c.String("new-name"))
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(c.App.Writer, "Renamed user %s to %s\n", oldName, c.String("new-name"))
+	return nil
+}
+
+// renameUser renames the user like the web UI does, it returns the old name of the user
+func renameUser(ctx context.Context, username, newName string) (string, error) {
+	user, err := user_model.GetUserByName(ctx, username)
+	if err != nil {
+		return "", err
+	}
+	if user.IsOrganization() {
+		return "", fmt.Errorf("%s is an organization not a user", user.Name)
+	}
+
+	oldName := user.Name
+	if err := user_service.RenameUser(ctx, user, newName); err != nil {
+		switch {
+		case user_model.IsErrUserAlreadyExist(err):
+			return "", fmt.Errorf("the name %q is already taken", newName)
+		case user_model.IsErrUsernameNotChanged(err):
+			return "", fmt.Errorf("the user is already named %q", newName)
+		}
+		return "", err
+	}
+	return oldName, nil
+}
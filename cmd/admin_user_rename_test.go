@@ -0,0 +1,41 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenameUser(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	ctx := context.Background()
+
+	_, err := renameUser(ctx, "user3", "org3-renamed")
+	assert.EqualError(t, err, "user3 is an organization not a user")
+	_, err = renameUser(ctx, "user2", "user4")
+	assert.EqualError(t, err, `the name "user4" is already taken`)
+	_, err = renameUser(ctx, "user2", "user2")
+	assert.EqualError(t, err, `the user is already named "user2"`)
+	_, err = renameUser(ctx, "no-such-user", "user2-renamed")
+	assert.True(t, user_model.IsErrUserNotExist(err))
+
+	oldName, err := renameUser(ctx, "user5", "user5-renamed")
+	assert.NoError(t, err)
+	assert.Equal(t, "user5", oldName)
+	unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 5, Name: "user5-renamed"})
+	// the old name redirects to the new one
+	redirectID, err := user_model.LookupUserRedirect("user5")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, redirectID)
+
+	// the repositories are moved back for the other tests
+	_, err = renameUser(ctx, "user5-renamed", "user5")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,106 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
+
+	"github.com/dustin/go-humanize"
+	"github.com/urfave/cli/v2"
+)
+
+var microcmdUserSetQuota = &cli.Command{
+	Name:  "set-quota",
+	Usage: "Set the limits of the number and the total size of the repositories of a user or an organization",
+	Description: `The limits are checked when a repository is created, forked, generated, adopted or migrated,
+and the size one also when objects are pushed. The admins can still create repositories for the owner.`,
+	Action: runSetUserQuota,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "username",
+			Aliases: []string{"u"},
+			Usage:   "The name of the user or the organization",
+		},
+		&cli.IntFlag{
+			Name:  "max-repos",
+			Usage: "The maximum number of repositories, -1 means the global default (MAX_CREATION_LIMIT)",
+		},
+		&cli.StringFlag{
+			Name:  "max-size",
+			Usage: `The maximum total size of the repositories (git and LFS), like "5GB" or "500MiB", 0 means no limit`,
+		},
+		&cli.BoolFlag{
+			Name:  "clear",
+			Usage: "Remove the quota: use the global default number of repositories and no size limit",
+		},
+	},
+}
+
+func runSetUserQuota(c *cli.Context) error {
+	if err := argsSet(c, "username"); err != nil {
+		return err
+	}
+	hasLimit := c.IsSet("max-repos") || c.IsSet("max-size")
+	if c.Bool("clear") && hasLimit {
+		return errors.New("--clear can't be used with --max-repos or --max-size")
+	} else if !c.Bool("clear") && !hasLimit {
+		return errors.New("either --clear or at least one of --max-repos and --max-size must be provided")
+	}
+
+	maxRepos, maxSize := -1, int64(0)
+	if c.IsSet("max-repos") {
+		if maxRepos = c.Int("max-repos"); maxRepos < -1 {
+			return fmt.Errorf("invalid --max-repos %d, it should be -1 or more", maxRepos)
+		}
+	}
+	if c.IsSet("max-size") {
+		size, err := humanize.ParseBytes(c.String("max-size"))
+		if err != nil || size > math.MaxInt64 {
+			return fmt.Errorf("invalid --max-size %q, it should be a size like 5GB", c.String("max-size"))
+		}
+		maxSize = int64(size)
+	}
+
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	user, err := user_model.GetUserByName(ctx, c.String("username"))
+	if err != nil {
+		return err
+	}
+
+	// a limit which isn't given is left as it is
+	var cols []string
+	if c.Bool("clear") || c.IsSet("max-repos") {
+		user.MaxRepoCreation = maxRepos
+		cols = append(cols, "max_repo_creation")
+	}
+	if c.Bool("clear") || c.IsSet("max-size") {
+		user.MaxRepoSize = maxSize
+		cols = append(cols, "max_repo_size")
+	}
+	if err := user_model.UpdateUserCols(ctx, user, cols...); err != nil {
+		return err
+	}
+
+	repos := "global default"
+	if user.MaxRepoCreation > -1 {
+		repos = fmt.Sprint(user.MaxRepoCreation)
+	}
+	size := "no limit"
+	if user.MaxRepoSize > 0 {
+		size = base.FileSize(user.MaxRepoSize)
+	}
+	_, _ = fmt.Fprintf(c.App.Writer, "Set the quota of %s: max repositories %s, max size %s\n", user.Name, repos, size)
+	return nil
+}
@@ -7,15 +7,18 @@ package cmd
 
 import (
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"fmt"
 	"log"
 	"math/big"
 	"net"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -36,15 +39,27 @@ Outputs to 'cert.pem' and 'key.pem' and will overwrite existing files.`,
 			Value: "",
 			Usage: "Comma-separated hostnames and IPs to generate a certificate for",
 		},
+		&cli.StringSliceFlag{
+			Name:  "ip",
+			Usage: "IP address to add to the certificate's subject alternative names, can be repeated",
+		},
+		&cli.StringSliceFlag{
+			Name:  "uri",
+			Usage: "Absolute URI to add to the certificate's subject alternative names, can be repeated",
+		},
 		&cli.StringFlag{
 			Name:  "ecdsa-curve",
 			Value: "",
 			Usage: "ECDSA curve to use to generate a key. Valid values are P224, P256, P384, P521",
 		},
+		&cli.BoolFlag{
+			Name:  "ed25519",
+			Usage: "Generate an Ed25519 key",
+		},
 		&cli.IntFlag{
 			Name:  "rsa-bits",
 			Value: 2048,
-			Usage: "Size of RSA key to generate. Ignored if --ecdsa-curve is set",
+			Usage: "Size of RSA key to generate. Can't be used with --ecdsa-curve or --ed25519",
 		},
 		&cli.StringFlag{
 			Name:  "start-date",
@@ -69,6 +84,8 @@ func publicKey(priv any) any {
 		return &k.PublicKey
 	case *ecdsa.PrivateKey:
 		return &k.PublicKey
+	case ed25519.PrivateKey:
+		return k.Public().(ed25519.PublicKey)
 	default:
 		return nil
 	}
@@ -84,21 +101,64 @@ func pemBlockForKey(priv any) *pem.Block {
 			log.Fatalf("Unable to marshal ECDSA private key: %v", err)
 		}
 		return &pem.Block{Type: "EC PRIVATE KEY", Bytes: b}
+	case ed25519.PrivateKey:
+		b, err := x509.MarshalPKCS8PrivateKey(k)
+		if err != nil {
+			log.Fatalf("Unable to marshal Ed25519 private key: %v", err)
+		}
+		return &pem.Block{Type: "PRIVATE KEY", Bytes: b}
 	default:
 		return nil
 	}
 }
 
+// parseCertSANs parses the IP and URI subject alternative names, the offending value is reported if any of them is invalid
+func parseCertSANs(ips, uris []string) ([]net.IP, []*url.URL, error) {
+	parsedIPs := make([]net.IP, 0, len(ips))
+	for _, s := range ips {
+		ip := net.ParseIP(strings.TrimSpace(s))
+		if ip == nil {
+			return nil, nil, fmt.Errorf("invalid IP address: %q", s)
+		}
+		parsedIPs = append(parsedIPs, ip)
+	}
+	parsedURIs := make([]*url.URL, 0, len(uris))
+	for _, s := range uris {
+		u, err := url.Parse(strings.TrimSpace(s))
+		if err != nil || !u.IsAbs() {
+			return nil, nil, fmt.Errorf("invalid absolute URI: %q", s)
+		}
+		parsedURIs = append(parsedURIs, u)
+	}
+	return parsedIPs, parsedURIs, nil
+}
+
 func runCert(c *cli.Context) error {
-	if err := argsSet(c, "host"); err != nil {
+	if !c.IsSet("ip") && !c.IsSet("uri") {
+		if err := argsSet(c, "host"); err != nil {
+			return err
+		}
+	}
+	ips, uris, err := parseCertSANs(c.StringSlice("ip"), c.StringSlice("uri"))
+	if err != nil {
 		return err
 	}
 
+	if c.IsSet("ecdsa-curve") && c.Bool("ed25519") {
+		return fmt.Errorf("--ecdsa-curve and --ed25519 can't be used together")
+	}
+	if c.IsSet("rsa-bits") && (c.IsSet("ecdsa-curve") || c.Bool("ed25519")) {
+		return fmt.Errorf("--rsa-bits can't be used with --ecdsa-curve or --ed25519")
+	}
+
 	var priv any
-	var err error
 	switch c.String("ecdsa-curve") {
 	case "":
-		priv, err = rsa.GenerateKey(rand.Reader, c.Int("rsa-bits"))
+		if c.Bool("ed25519") {
+			_, priv, err = ed25519.GenerateKey(rand.Reader)
+		} else {
+			priv, err = rsa.GenerateKey(rand.Reader, c.Int("rsa-bits"))
+		}
 	case "P224":
 		priv, err = ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
 	case "P256":
@@ -141,19 +201,28 @@ func runCert(c *cli.Context) error {
 		NotBefore: notBefore,
 		NotAfter:  notAfter,
 
-		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
 		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
 		BasicConstraintsValid: true,
 	}
 
-	hosts := strings.Split(c.String("host"), ",")
-	for _, h := range hosts {
-		if ip := net.ParseIP(h); ip != nil {
-			template.IPAddresses = append(template.IPAddresses, ip)
-		} else {
-			template.DNSNames = append(template.DNSNames, h)
+	// only RSA keys are used for key encipherment, ECDSA and Ed25519 keys are only used for signatures
+	if _, isRSA := priv.(*rsa.PrivateKey); isRSA {
+		template.KeyUsage |= x509.KeyUsageKeyEncipherment
+	}
+
+	if c.String("host") != "" {
+		hosts := strings.Split(c.String("host"), ",")
+		for _, h := range hosts {
+			if ip := net.ParseIP(h); ip != nil {
+				template.IPAddresses = append(template.IPAddresses, ip)
+			} else {
+				template.DNSNames = append(template.DNSNames, h)
+			}
 		}
 	}
+	template.IPAddresses = append(template.IPAddresses, ips...)
+	template.URIs = uris
 
 	if c.Bool("ca") {
 		template.IsCA = true
@@ -0,0 +1,84 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+func TestParseCertSANs(t *testing.T) {
+	ips, uris, err := parseCertSANs([]string{"10.0.0.1", "::1"}, []string{"spiffe://cluster.local/ns/gitea/sa/web"})
+	assert.NoError(t, err)
+	if assert.Len(t, ips, 2) {
+		assert.Equal(t, "10.0.0.1", ips[0].String())
+		assert.Equal(t, "::1", ips[1].String())
+	}
+	if assert.Len(t, uris, 1) {
+		assert.Equal(t, "spiffe://cluster.local/ns/gitea/sa/web", uris[0].String())
+	}
+
+	_, _, err = parseCertSANs([]string{"10.0.0.256"}, nil)
+	assert.EqualError(t, err, `invalid IP address: "10.0.0.256"`)
+
+	_, _, err = parseCertSANs(nil, []string{"/relative/path"})
+	assert.EqualError(t, err, `invalid absolute URI: "/relative/path"`)
+}
+
+func TestRunCertKeyTypes(t *testing.T) {
+	// the certificate and the key are written to the working directory
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, os.Chdir(wd)) }()
+	assert.NoError(t, os.Chdir(t.TempDir()))
+
+	runCertApp := func(args ...string) (*x509.Certificate, error) {
+		app := cli.NewApp()
+		app.Flags = CmdCert.Flags
+		app.Action = runCert
+		if err := app.Run(append([]string{"./gitea", "--host", "localhost"}, args...)); err != nil {
+			return nil, err
+		}
+		certPEM, err := os.ReadFile("cert.pem")
+		if !assert.NoError(t, err) {
+			return nil, err
+		}
+		block, _ := pem.Decode(certPEM)
+		return x509.ParseCertificate(block.Bytes)
+	}
+
+	cert, err := runCertApp("--ed25519")
+	if assert.NoError(t, err) {
+		assert.IsType(t, ed25519.PublicKey{}, cert.PublicKey)
+		assert.Equal(t, x509.KeyUsageDigitalSignature, cert.KeyUsage)
+		keyPEM, err := os.ReadFile("key.pem")
+		assert.NoError(t, err)
+		block, _ := pem.Decode(keyPEM)
+		assert.Equal(t, "PRIVATE KEY", block.Type)
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		assert.NoError(t, err)
+		assert.IsType(t, ed25519.PrivateKey{}, key)
+	}
+
+	// only the RSA keys are used for key encipherment
+	cert, err = runCertApp("--rsa-bits", "1024")
+	if assert.NoError(t, err) {
+		assert.IsType(t, &rsa.PublicKey{}, cert.PublicKey)
+		assert.Equal(t, x509.KeyUsageDigitalSignature|x509.KeyUsageKeyEncipherment, cert.KeyUsage)
+	}
+
+	_, err = runCertApp("--ecdsa-curve", "P256", "--ed25519")
+	assert.EqualError(t, err, "--ecdsa-curve and --ed25519 can't be used together")
+	_, err = runCertApp("--ed25519", "--rsa-bits", "4096")
+	assert.EqualError(t, err, "--rsa-bits can't be used with --ecdsa-curve or --ed25519")
+	_, err = runCertApp("--ecdsa-curve", "P256", "--rsa-bits", "4096")
+	assert.EqualError(t, err, "--rsa-bits can't be used with --ecdsa-curve or --ed25519")
+}
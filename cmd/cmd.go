@@ -129,6 +129,23 @@ func PrepareConsoleLoggerLevel(defaultLevel log.Level) func(*cli.Context) error
 		if globalBool(c, "debug") || globalBool(c, "verbose") {
 			level = log.TRACE
 		}
+		logLevel, logLevelSet, err := globalLogLevel(c)
+		if err != nil {
+			return err
+		}
+		if logLevelSet {
+			level = logLevel
+		}
+		logFormatJSON, _, err := globalLogFormatJSON(c)
+		if err != nil {
+			return err
+		}
+		colorize, err := globalColor(c)
+		if err != nil {
+			return err
+		}
+		log.SetConsoleFormatJSON(logFormatJSON)
+		setConsoleColor(colorize)
 		log.SetConsoleLogger(log.DEFAULT, "console-default", level)
 		return nil
 	}
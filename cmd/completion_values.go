@@ -0,0 +1,122 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// completionLimit caps the number of the values printed to complete a flag (the searches return at most
+	// MAX_RESPONSE_ITEMS anyway). The shell filters them by the typed prefix, which isn't passed to the command,
+	// so on big instances only the most recently updated ones are completed.
+	completionLimit = 50
+	// completionTimeout bounds the database queries, the shell waits for the completion
+	completionTimeout = 2 * time.Second
+)
+
+// completionValuesFunc returns at most "limit" values to complete a flag
+type completionValuesFunc func(ctx context.Context, limit int) ([]string, error)
+
+// completeFlagValues returns a BashComplete func which completes the values of the flags (by the names without dashes)
+// from the database, the other words are completed like urfave/cli does by default (sub-commands and flags)
+func completeFlagValues(valuesFuncs map[string]completionValuesFunc) cli.BashCompleteFunc {
+	return func(c *cli.Context) {
+		// like cli.DefaultCompleteWithFlags, the word before the completion flag is taken from os.Args
+		var lastArg string
+		if len(os.Args) > 2 {
+			lastArg = os.Args[len(os.Args)-2]
+		}
+		valuesFunc, ok := valuesFuncs[strings.TrimLeft(lastArg, "-")]
+		if !ok || !strings.HasPrefix(lastArg, "-") {
+			cli.DefaultCompleteWithFlags(c.Command)(c)
+			return
+		}
+		// the errors can't be shown during the completion, there are just no values then
+		values, _ := completionValues(c, valuesFunc)
+		for _, v := range values {
+			_, _ = fmt.Fprintln(c.App.Writer, v)
+		}
+	}
+}
+
+func completionValues(c *cli.Context, valuesFunc completionValuesFunc) ([]string, error) {
+	// the console logger writes to stdout, which is read by the shell as the values
+	log.GetManager().GetLogger(log.DEFAULT).ReplaceAllWriters()
+
+	// the completion doesn't run the Action, so the config is not loaded yet
+	args, err := argWorkPathAndCustomConf(c)
+	if err != nil {
+		return nil, err
+	}
+	setting.InitWorkPathAndCommonConfig(os.Getenv, args)
+	if !setting.InstallLock {
+		return nil, nil
+	}
+	setting.LoadDBSetting()
+
+	ctx, cancel := context.WithTimeout(c.Context, completionTimeout)
+	defer cancel()
+	if err := db.InitEngine(ctx); err != nil {
+		return nil, err
+	}
+	return valuesFunc(ctx, completionLimit)
+}
+
+// completeRepositories returns the full names (owner/name) of the most recently updated repositories
+func completeRepositories(ctx context.Context, limit int) ([]string, error) {
+	repos, _, err := repo_model.SearchRepository(ctx, &repo_model.SearchRepoOptions{
+		ListOptions: db.ListOptions{Page: 1, PageSize: limit},
+		Private:     true,
+		OrderBy:     db.SearchOrderByRecentUpdated,
+	})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		names = append(names, repo.OwnerName+"/"+repo.Name)
+	}
+	return names, nil
+}
+
+// completeOwners returns the names of the most recently updated users and organizations
+func completeOwners(ctx context.Context, limit int) ([]string, error) {
+	// the admin can see the private users and organizations too
+	doer, err := user_model.GetAdminUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, userType := range []user_model.UserType{user_model.UserTypeIndividual, user_model.UserTypeOrganization} {
+		users, _, err := user_model.SearchUsers(&user_model.SearchUserOptions{
+			ListOptions: db.ListOptions{Page: 1, PageSize: limit - len(names)},
+			Type:        userType,
+			Actor:       doer,
+			OrderBy:     db.SearchOrderByRecentUpdated,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			names = append(names, u.Name)
+		}
+		if len(names) >= limit {
+			break
+		}
+	}
+	return names, nil
+}
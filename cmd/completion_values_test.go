@@ -0,0 +1,102 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompleteRepositories(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	for _, tc := range []struct {
+		limit    int
+		count    int
+		first    []string
+		contains []string
+	}{
+		{
+			limit: 1,
+			count: 1,
+			first: []string{"user2/repo-release"},
+		},
+		{
+			limit: 3,
+			count: 3,
+			first: []string{"user2/repo-release", "user2/readme-test", "user2/scoped_label"},
+		},
+		{
+			limit:    completionLimit,
+			count:    completionLimit,
+			first:    []string{"user2/repo-release", "user2/readme-test", "user2/scoped_label", "user2/lfs", "user30/renderer"},
+			contains: []string{"privated_org/private_repo_on_private_org", "limited_org/private_repo_on_limited_org", "user15/big_test_private_1"},
+		},
+		{
+			limit:    100,
+			count:    57,
+			contains: []string{"user2/repo1", "user2/repo2"},
+		},
+	} {
+		names, err := completeRepositories(db.DefaultContext, tc.limit)
+		assert.NoError(t, err)
+		assert.Len(t, names, tc.count, "limit %d", tc.limit)
+		if len(tc.first) > 0 && len(names) >= len(tc.first) {
+			assert.Equal(t, tc.first, names[:len(tc.first)], "limit %d", tc.limit)
+		}
+		assert.Subset(t, names, tc.contains, "limit %d", tc.limit)
+	}
+}
+
+func TestCompleteOwners(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	for _, tc := range []struct {
+		limit    int
+		count    int
+		first    []string
+		contains []string
+		excludes []string
+	}{
+		{
+			limit:    1,
+			count:    1,
+			first:    []string{"the_34-user.with.all.allowedChars"},
+			excludes: []string{"user33"},
+		},
+		{
+			limit:    3,
+			count:    3,
+			first:    []string{"the_34-user.with.all.allowedChars", "user33", "user32"},
+			excludes: []string{"limited_org36"},
+		},
+		{
+			// the users come first, the rest of the limit is filled with the organizations
+			limit:    26,
+			count:    26,
+			contains: []string{"user1", "user2", "limited_org36"},
+			excludes: []string{"private_org35", "user3"},
+		},
+		{
+			limit:    completionLimit,
+			count:    36,
+			contains: []string{"user1", "user2", "user3", "privated_org", "private_org35", "limited_org36"},
+		},
+	} {
+		names, err := completeOwners(db.DefaultContext, tc.limit)
+		assert.NoError(t, err)
+		assert.Len(t, names, tc.count, "limit %d", tc.limit)
+		if len(tc.first) > 0 && len(names) >= len(tc.first) {
+			assert.Equal(t, tc.first, names[:len(tc.first)], "limit %d", tc.limit)
+		}
+		assert.Subset(t, names, tc.contains, "limit %d", tc.limit)
+		for _, name := range tc.excludes {
+			assert.NotContains(t, names, name, "limit %d", tc.limit)
+		}
+	}
+}
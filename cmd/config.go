@@ -0,0 +1,57 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// CmdConfig represents the available config sub-commands.
+	CmdConfig = &cli.Command{
+		Name:        "config",
+		Usage:       "Manage Gitea configuration",
+		Description: "Commands for working with Gitea configuration files",
+		Subcommands: []*cli.Command{
+			subcmdConfigValidate,
+		},
+	}
+
+	subcmdConfigValidate = &cli.Command{
+		Name:  "validate",
+		Usage: "Validate the configuration without starting the server",
+		Description: `Load the configuration like the startup does and report unknown keys, deprecated keys and invalid values,
+one issue per line. It exits with code 2 if any issue is found, or 1 if the config can't be loaded.`,
+		Action: runConfigValidate,
+	}
+)
+
+func runConfigValidate(c *cli.Context) error {
+	// load the config file again, the default config provider has been used to load the common settings,
+	// the validator needs a fresh one to record which keys are read by the setting loaders
+	cfg, err := setting.NewConfigProviderFromFile(setting.CustomConf)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Unable to load config file %q: %v", setting.CustomConf, err), 1)
+	}
+	if cfg.IsLoadedFromEmpty() {
+		return cli.Exit(fmt.Sprintf("Config file %q does not exist", setting.CustomConf), 1)
+	}
+
+	issues, err := setting.ValidateConfig(cfg)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Unable to load settings from config: %v", err), 1)
+	}
+	for _, issue := range issues {
+		_, _ = fmt.Fprintln(c.App.Writer, issue.String())
+	}
+	if len(issues) > 0 {
+		return cli.Exit(fmt.Sprintf("Found %d issue(s) in config %q", len(issues), setting.CustomConf), setting.ConfigValidateIssuesExitCode)
+	}
+	_, _ = fmt.Fprintf(c.App.Writer, "Config %q is valid\n", setting.CustomConf)
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+// runConfigValidateProcess runs "gitea config validate" for the config in a child process of the test,
+// because the validation changes the global settings used by the other tests
+func runConfigValidateProcess(conf string) (string, error) {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestConfigValidateExitCodes$")
+	cmd.Env = append(os.Environ(), "GITEA_TEST_CONFIG_VALIDATE="+conf)
+	output, err := cmd.Output()
+	return string(output), err
+}
+
+func TestConfigValidateExitCodes(t *testing.T) {
+	if conf := os.Getenv("GITEA_TEST_CONFIG_VALIDATE"); conf != "" {
+		setting.CustomConf = conf
+		setting.AppWorkPath = filepath.Dir(conf)
+		app := cli.NewApp()
+		app.Action = runConfigValidate
+		cli.ErrWriter = os.Stdout
+		// the app exits with the code of the error, any other error is unexpected
+		if err := app.Run([]string{"./gitea"}); err != nil {
+			os.Exit(3)
+		}
+		os.Exit(0)
+	}
+
+	dir := t.TempDir()
+	validate := func(content string) (int, string) {
+		conf := filepath.Join(dir, "app.ini")
+		_ = os.Remove(conf)
+		if content != "" {
+			assert.NoError(t, os.WriteFile(conf, []byte(content), 0o644))
+		}
+		output, err := runConfigValidateProcess(conf)
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), output
+		}
+		assert.NoError(t, err)
+		return 0, output
+	}
+
+	code, output := validate("APP_NAME = Gitea\n")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, output, "is valid")
+
+	// the config can be loaded but it has issues
+	code, output = validate("APP_NAME = Gitea\nNO_SUCH_KEY = 1\n")
+	assert.Equal(t, setting.ConfigValidateIssuesExitCode, code)
+	assert.Contains(t, output, "[DEFAULT] NO_SUCH_KEY: unknown key")
+
+	// the config can't be loaded
+	code, output = validate("")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, output, "does not exist")
+	code, output = validate("[server\n")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, output, "Unable to load config file")
+}
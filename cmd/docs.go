@@ -6,6 +6,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/urfave/cli/v2"
@@ -15,13 +16,17 @@ import (
 var CmdDocs = &cli.Command{
 	Name:        "docs",
 	Usage:       "Output CLI documentation",
-	Description: "A command to output Gitea's CLI documentation, optionally to a file.",
+	Description: "A command to output Gitea's CLI documentation or a shell completion script, optionally to a file.",
 	Action:      runDocs,
 	Flags: []cli.Flag{
 		&cli.BoolFlag{
 			Name:  "man",
 			Usage: "Output man pages instead",
 		},
+		&cli.StringFlag{
+			Name:  "completion",
+			Usage: "Output the completion script of the shell instead: fish or powershell",
+		},
 		&cli.StringFlag{
 			Name:    "output",
 			Aliases: []string{"o"},
@@ -31,15 +36,26 @@ var CmdDocs = &cli.Command{
 }
 
 func runDocs(ctx *cli.Context) error {
-	docs, err := ctx.App.ToMarkdown()
-	if ctx.Bool("man") {
+	if ctx.Bool("man") && ctx.IsSet("completion") {
+		return fmt.Errorf("--man and --completion can't be used together")
+	}
+
+	var docs string
+	var err error
+	switch {
+	case ctx.IsSet("completion"):
+		// complete the executable's name, not the display name of the app
+		docs, err = completionScript(ctx.String("completion"), strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe"))
+	case ctx.Bool("man"):
 		docs, err = ctx.App.ToMan()
+	default:
+		docs, err = ctx.App.ToMarkdown()
 	}
 	if err != nil {
 		return err
 	}
 
-	if !ctx.Bool("man") {
+	if !ctx.Bool("man") && !ctx.IsSet("completion") {
 		// Clean up markdown. The following bug was fixed in v2, but is present in v1.
 		// It affects markdown output (even though the issue is referring to man pages)
 		// https://github.com/urfave/cli/issues/1040
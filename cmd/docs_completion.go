@@ -0,0 +1,60 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The completion scripts ask the binary itself for the candidates by calling it with "--generate-bash-completion"
+// (the app has EnableBashCompletion), so the completions always reflect the commands and flags of the running version.
+// The bash and zsh scripts are in "contrib/autocompletion" and work in the same way.
+
+const fishCompletionTemplate = `# fish completion for %[1]s, generated by "%[1]s docs --completion fish"
+function __fish_%[2]s_complete
+    set -l args (commandline -opc)
+    set -l current (commandline -ct)
+    # a partial flag makes the command list the flags instead of the sub-commands
+    if string match -q -- '-*' $current
+        set -a args $current
+    end
+    $args --generate-bash-completion 2>/dev/null
+end
+
+complete -c %[1]s -a '(__fish_%[2]s_complete)'
+`
+
+const powershellCompletionTemplate = `# powershell completion for %[1]s, generated by "%[1]s docs --completion powershell"
+Register-ArgumentCompleter -Native -CommandName '%[1]s', '%[1]s.exe' -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $elements = @($commandAst.CommandElements | Where-Object { $_.Extent.EndOffset -le $cursorPosition } | ForEach-Object { $_.ToString() })
+    # the word being completed is only passed if it is a partial flag, then the command lists the flags instead of the sub-commands
+    if ($wordToComplete -ne '' -and -not $wordToComplete.StartsWith('-')) {
+        $elements = @($elements | Select-Object -SkipLast 1)
+    }
+    $arguments = @($elements | Select-Object -Skip 1) + '--generate-bash-completion'
+    & $elements[0] @arguments 2>$null | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}
+`
+
+// completionScript returns the completion script of the shell for the app
+func completionScript(shell, appName string) (string, error) {
+	switch shell {
+	case "fish":
+		// fish function names can't contain all the characters a file name can
+		funcName := strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+				return r
+			}
+			return '_'
+		}, appName)
+		return fmt.Sprintf(fishCompletionTemplate, appName, funcName), nil
+	case "powershell":
+		return fmt.Sprintf(powershellCompletionTemplate, appName), nil
+	}
+	return "", fmt.Errorf("unsupported shell %q for completion, it should be one of: fish, powershell (bash and zsh scripts are in contrib/autocompletion)", shell)
+}
@@ -0,0 +1,33 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+func TestCompletionScript(t *testing.T) {
+	script, err := completionScript("fish", "gitea-1.22")
+	assert.NoError(t, err)
+	assert.Contains(t, script, "function __fish_gitea_1_22_complete\n")
+	assert.Contains(t, script, "complete -c gitea-1.22 -a '(__fish_gitea_1_22_complete)'\n")
+
+	script, err = completionScript("powershell", "gitea")
+	assert.NoError(t, err)
+	assert.Contains(t, script, "-CommandName 'gitea', 'gitea.exe'")
+
+	_, err = completionScript("bash", "gitea")
+	assert.ErrorContains(t, err, `unsupported shell "bash" for completion`)
+}
+
+func TestRunDocsCompletion(t *testing.T) {
+	app := cli.NewApp()
+	app.Flags = CmdDocs.Flags
+	app.Action = runDocs
+	assert.EqualError(t, app.Run([]string{"./gitea", "--man", "--completion", "fish"}), "--man and --completion can't be used together")
+	assert.ErrorContains(t, app.Run([]string{"./gitea", "--completion", "tcsh"}), `unsupported shell "tcsh" for completion`)
+}
@@ -5,18 +5,22 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	golog "log"
 	"os"
 	"path/filepath"
-	"strings"
+	"regexp"
 	"text/tabwriter"
+	"time"
 
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/migrations"
 	migrate_base "code.gitea.io/gitea/models/migrations/base"
 	"code.gitea.io/gitea/modules/doctor"
+	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
 
 	"github.com/urfave/cli/v2"
 	"xorm.io/xorm"
@@ -40,17 +44,41 @@ var cmdDoctorCheck = &cli.Command{
 			Name:  "run",
 			Usage: "Run the provided checks - (if --default is set, the default checks will also run)",
 		},
+		&cli.StringSliceFlag{
+			Name:  "only",
+			Usage: "Run only the provided checks (comma separated names), it can't be used with --default, --run or --all",
+		},
 		&cli.BoolFlag{
 			Name:  "all",
 			Usage: "Run all the available checks",
 		},
 		&cli.BoolFlag{
 			Name:  "fix",
-			Usage: "Automatically fix what we can",
+			Usage: "Automatically fix what we can, the fixes which delete or rewrite data are only applied once confirmed",
+		},
+		&cli.BoolFlag{
+			Name:    "yes",
+			Aliases: []string{"y"},
+			Usage:   "Apply the fixes which delete or rewrite data without asking for a confirmation, for automation",
+		},
+		&cli.DurationFlag{
+			Name:  "timeout",
+			Value: 10 * time.Minute,
+			Usage: "Timeout of each check, a check running longer is marked as failed and the next check is run, 0 means no timeout",
+		},
+		&cli.DurationFlag{
+			Name:  "webhooks-window",
+			Value: doctor.WebhookDeliveryWindow,
+			Usage: `How far back the "webhooks" check looks at the deliveries`,
+		},
+		&cli.StringFlag{
+			Name:  "format",
+			Value: "text",
+			Usage: `Output format: text or json. The "json" format outputs the check results as an array and exits with code 0 (ok), 1 (warn) or 2 (error) by the worst result`,
 		},
 		&cli.StringFlag{
 			Name:  "log-file",
-			Usage: `Name of the log file (no verbose log output by default). Set to "-" to output to stdout`,
+			Usage: `Name of the log file (no verbose log output by default), it is created fresh for each run and contains the full check output and all the logs. Set to "-" to output the logs to stdout`,
 		},
 		&cli.BoolFlag{
 			Name:    "color",
@@ -82,10 +110,16 @@ var cmdRecreateTable = &cli.Command{
 			Name:  "debug",
 			Usage: "Print SQL commands sent",
 		},
+		&cli.StringSliceFlag{
+			Name:  "table",
+			Usage: "Name of a table to recreate, like the TABLE arguments, can be used multiple times",
+		},
 	},
 	Description: `The database definitions Gitea uses change across versions, sometimes changing default values and leaving old unused columns.
 
 This command will cause Xorm to recreate tables, copying over the data and deleting the old table.
+The row counts of each table are printed before and after, the tables are restored if any of them differs.
+The rollback is only guaranteed on PostgreSQL, SQLite and MSSQL, MySQL commits the schema changes implicitly.
 
 You should back-up your database before doing this and ensure that your database is up-to-date first.`,
 	Action: runRecreateTable,
@@ -119,6 +153,7 @@ func runRecreateTable(ctx *cli.Context) error {
 
 	args := ctx.Args()
 	names := make([]string, 0, ctx.NArg())
+	names = append(names, ctx.StringSlice("table")...)
 	for i := 0; i < ctx.NArg(); i++ {
 		names = append(names, args.Get(i))
 	}
@@ -127,35 +162,102 @@ func runRecreateTable(ctx *cli.Context) error {
 	if err != nil {
 		return err
 	}
-	recreateTables := migrate_base.RecreateTables(beans...)
 
 	return db.InitEngineWithMigration(stdCtx, func(x *xorm.Engine) error {
 		if err := migrations.EnsureUpToDate(x); err != nil {
 			return err
 		}
-		return recreateTables(x)
+		return recreateTablesCheckingRows(x, ctx.App.Writer, beans...)
 	})
 }
 
-func setupDoctorDefaultLogger(ctx *cli.Context, colorize bool) {
+// recreateTablesCheckingRows recreates the tables in one transaction like migrate_base.RecreateTables,
+// and prints the row counts of each table before and after. If any of them differs, the transaction is rolled back.
+// The rollback is only guaranteed on PostgreSQL, SQLite and MSSQL, on MySQL every DDL statement commits implicitly,
+// so the tables recreated before the difference was found are kept.
+func recreateTablesCheckingRows(x *xorm.Engine, out io.Writer, beans ...any) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	for _, bean := range beans {
+		tableName := x.TableName(bean)
+		before, err := sess.Table(tableName).Count()
+		if err != nil {
+			return fmt.Errorf("unable to count the rows of table %s: %w", tableName, err)
+		}
+		if err := migrate_base.RecreateTable(sess.StoreEngine("InnoDB"), bean); err != nil {
+			return fmt.Errorf("unable to recreate table %s: %w", tableName, err)
+		}
+		after, err := sess.Table(tableName).Count()
+		if err != nil {
+			return fmt.Errorf("unable to count the rows of recreated table %s: %w", tableName, err)
+		}
+		_, _ = fmt.Fprintf(out, "Recreated table %s: %d rows before, %d rows after\n", tableName, before, after)
+		if before != after {
+			return fmt.Errorf("the rows of table %s changed from %d to %d, the transaction is rolled back", tableName, before, after)
+		}
+	}
+	return sess.Commit()
+}
+
+// setupDoctorDefaultLogger sets up the default logger by the "log-file" flag.
+// A log file is created fresh for each run and returned, so the check output can be written to it too.
+func setupDoctorDefaultLogger(ctx *cli.Context, colorize bool) (*os.File, error) {
 	// Silence the default loggers
 	setupConsoleLogger(log.FATAL, log.CanColorStderr, os.Stderr)
 
 	logFile := ctx.String("log-file")
 	if logFile == "" {
-		return // if no doctor log-file is set, do not show any log from default logger
+		return nil, nil // if no doctor log-file is set, do not show any log from default logger
 	} else if logFile == "-" {
 		setupConsoleLogger(log.TRACE, colorize, os.Stdout)
-	} else {
-		logFile, _ = filepath.Abs(logFile)
-		writeMode := log.WriterMode{Level: log.TRACE, WriterOption: log.WriterFileOption{FileName: logFile}}
-		writer, err := log.NewEventWriter("console-to-file", "file", writeMode)
-		if err != nil {
-			log.FallbackErrorf("unable to create file log writer: %v", err)
-			return
-		}
-		log.GetManager().GetLogger(log.DEFAULT).ReplaceAllWriters(writer)
+		return nil, nil
+	}
+
+	logFile, _ = filepath.Abs(logFile)
+	// both the file log writer and the check output append to the file, so it is only truncated here
+	f, err := os.OpenFile(logFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|os.O_APPEND, 0o660)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the log file: %w", err)
+	}
+	writeMode := log.WriterMode{Level: log.TRACE, WriterOption: log.WriterFileOption{FileName: logFile}}
+	writer, err := log.NewEventWriter("console-to-file", "file", writeMode)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("unable to create file log writer: %w", err)
+	}
+	log.GetManager().GetLogger(log.DEFAULT).ReplaceAllWriters(writer)
+	return f, nil
+}
+
+// ansiColorRegexp matches the color escape sequences of the colorized check output
+var ansiColorRegexp = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// noColorWriter writes to the underlying writer without the color escape sequences,
+// the check output is written line by line so a sequence is never split between two writes
+type noColorWriter struct {
+	w io.Writer
+}
+
+func (w noColorWriter) Write(p []byte) (int, error) {
+	if _, err := w.w.Write(ansiColorRegexp.ReplaceAll(p, nil)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// confirmDoctorFix asks whether the destructive fix of the check should be applied, the prompt is written to stderr
+// so that it doesn't get mixed with the output of the checks
+func confirmDoctorFix(out io.Writer, check *doctor.Check) bool {
+	_, _ = fmt.Fprintf(out, "The fix of %q will %s. Apply it? [y/n] ", check.Name, check.DestructiveFix)
+	isConfirmed, err := confirm()
+	if err != nil {
+		_, _ = fmt.Fprintf(out, "\nUnable to read the confirmation: %v\n", err)
+		return false
 	}
+	return isConfirmed
 }
 
 func runDoctorCheck(ctx *cli.Context) error {
@@ -167,7 +269,18 @@ func runDoctorCheck(ctx *cli.Context) error {
 		colorize = ctx.Bool("color")
 	}
 
-	setupDoctorDefaultLogger(ctx, colorize)
+	logFile, err := setupDoctorDefaultLogger(ctx, colorize)
+	if err != nil {
+		return err
+	}
+	if logFile != nil {
+		defer func() {
+			// replacing the file log writer flushes the pending logs before telling where the report is
+			setupConsoleLogger(log.FATAL, log.CanColorStderr, os.Stderr)
+			_ = logFile.Close()
+			_, _ = fmt.Fprintf(ctx.App.ErrWriter, "The full report is written to %s\n", logFile.Name())
+		}()
+	}
 
 	// Finally redirect the default golang's log to here
 	golog.SetFlags(0)
@@ -190,26 +303,30 @@ func runDoctorCheck(ctx *cli.Context) error {
 		return w.Flush()
 	}
 
+	if ctx.Bool("yes") && !ctx.Bool("fix") {
+		return fmt.Errorf("--yes can only be used with --fix")
+	}
+
 	var checks []*doctor.Check
-	if ctx.Bool("all") {
+	if ctx.IsSet("only") {
+		if ctx.IsSet("default") || ctx.IsSet("run") || ctx.IsSet("all") {
+			return fmt.Errorf("--only can't be used with --default, --run or --all")
+		}
+		var err error
+		if checks, err = doctor.ChecksByNames(ctx.StringSlice("only")); err != nil {
+			return err
+		}
+	} else if ctx.Bool("all") {
 		checks = doctor.Checks
 	} else if ctx.IsSet("run") {
-		addDefault := ctx.Bool("default")
-		names := ctx.StringSlice("run")
-		for i, name := range names {
-			names[i] = strings.ToLower(strings.TrimSpace(name))
+		runChecks, err := doctor.ChecksByNames(ctx.StringSlice("run"))
+		if err != nil {
+			return err
 		}
-
+		addDefault := ctx.Bool("default")
 		for _, check := range doctor.Checks {
-			if addDefault && check.IsDefault {
+			if (addDefault && check.IsDefault) || util.SliceContains(runChecks, check) {
 				checks = append(checks, check)
-				continue
-			}
-			for _, name := range names {
-				if name == check.Name {
-					checks = append(checks, check)
-					break
-				}
 			}
 		}
 	} else {
@@ -220,5 +337,70 @@ func runDoctorCheck(ctx *cli.Context) error {
 		}
 	}
 
-	return doctor.RunChecks(stdCtx, colorize, ctx.Bool("fix"), checks)
+	// the log file contains the full check output besides the logs
+	var out, jsonOut io.Writer = os.Stdout, io.Discard
+	if logFile != nil {
+		out = io.MultiWriter(os.Stdout, noColorWriter{logFile})
+		jsonOut = logFile
+	}
+
+	if ctx.IsSet("webhooks-window") {
+		if ctx.Duration("webhooks-window") <= 0 {
+			return fmt.Errorf("--webhooks-window must be positive")
+		}
+		doctor.WebhookDeliveryWindow = ctx.Duration("webhooks-window")
+	}
+
+	var confirmFix func(*doctor.Check) bool
+	if !ctx.Bool("yes") {
+		confirmFix = func(check *doctor.Check) bool {
+			return confirmDoctorFix(ctx.App.ErrWriter, check)
+		}
+	}
+
+	switch format := ctx.String("format"); format {
+	case "", "text":
+		_, err := doctor.RunChecks(stdCtx, out, colorize, ctx.Bool("fix"), ctx.Duration("timeout"), confirmFix, checks)
+		return err
+	case "json":
+		results, err := doctor.RunChecks(stdCtx, jsonOut, false, ctx.Bool("fix"), ctx.Duration("timeout"), confirmFix, checks)
+		if err := writeDoctorJSONResults(ctx.App.Writer, results); err != nil {
+			return err
+		}
+		if err != nil {
+			return cli.Exit("", doctorStatusExitCodes[doctor.CheckStatusError])
+		}
+		if code := doctorWorstExitCode(results); code != 0 {
+			return cli.Exit("", code)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q, it should be one of: text, json", format)
+	}
+}
+
+// doctorStatusExitCodes are the exit codes of "doctor check --format json", they follow the monitoring plugin conventions
+var doctorStatusExitCodes = map[doctor.CheckStatus]int{
+	doctor.CheckStatusOK:    0,
+	doctor.CheckStatusWarn:  1,
+	doctor.CheckStatusError: 2,
+}
+
+func doctorWorstExitCode(results []*doctor.CheckResult) int {
+	code := 0
+	for _, result := range results {
+		if c := doctorStatusExitCodes[result.Status]; c > code {
+			code = c
+		}
+	}
+	return code
+}
+
+func writeDoctorJSONResults(out io.Writer, results []*doctor.CheckResult) error {
+	bs, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(bs))
+	return err
 }
@@ -4,21 +4,38 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
 
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
 
 	"github.com/urfave/cli/v2"
 )
 
 // cmdDoctorConvert represents the available convert sub-command.
 var cmdDoctorConvert = &cli.Command{
-	Name:        "convert",
-	Usage:       "Convert the database",
-	Description: "A command to convert an existing MySQL database from utf8 to utf8mb4 or MSSQL database from varchar to nvarchar",
-	Action:      runDoctorConvert,
+	Name:  "convert",
+	Usage: "Convert the database",
+	Description: `A command to convert the character set and collation of an existing MySQL database (to utf8mb4 by default) or MSSQL database from varchar to nvarchar.
+For MySQL, a table which can't be converted (eg: because of the index length limit) is reported and left unchanged, the other tables are still converted.`,
+	Action: runDoctorConvert,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "target",
+			Value: "utf8mb4",
+			Usage: "The character set to convert the MySQL database to: " + strings.Join(db.MySQLCharsets(), " or "),
+		},
+		&cli.BoolFlag{
+			Name:  "check",
+			Usage: "Only report the collations of the MySQL database and its tables without changing anything",
+		},
+	},
 }
 
 func runDoctorConvert(ctx *cli.Context) error {
@@ -37,20 +54,69 @@ func runDoctorConvert(ctx *cli.Context) error {
 
 	switch {
 	case setting.Database.Type.IsMySQL():
-		if err := db.ConvertUtf8ToUtf8mb4(); err != nil {
-			log.Fatal("Failed to convert database from utf8 to utf8mb4: %v", err)
+		target := ctx.String("target")
+		if !util.SliceContainsString(db.MySQLCharsets(), target) {
+			return fmt.Errorf("unsupported target %q, it should be one of: %s", target, strings.Join(db.MySQLCharsets(), ", "))
+		}
+		if ctx.Bool("check") {
+			return checkMySQLCollations(stdCtx, ctx.App.Writer, target)
+		}
+		skipped, err := db.ConvertMySQLCharset(stdCtx, target)
+		if err != nil {
+			log.Fatal("Failed to convert database to %s: %v", target, err)
 			return err
 		}
-		fmt.Println("Converted successfully, please confirm your database's character set is now utf8mb4")
+		for _, table := range skipped {
+			_, _ = fmt.Fprintf(ctx.App.Writer, "Skipped table %s: %s\n", table.Name, table.Reason)
+		}
+		if len(skipped) > 0 {
+			_, _ = fmt.Fprintf(ctx.App.Writer, "Converted with %d table(s) skipped, please fix them and run the conversion again\n", len(skipped))
+			return nil
+		}
+		_, _ = fmt.Fprintf(ctx.App.Writer, "Converted successfully, please confirm your database's character set is now %s\n", target)
 	case setting.Database.Type.IsMSSQL():
+		if ctx.IsSet("target") || ctx.IsSet("check") {
+			return fmt.Errorf("--target and --check can only be used with a MySQL database")
+		}
 		if err := db.ConvertVarcharToNVarchar(); err != nil {
 			log.Fatal("Failed to convert database from varchar to nvarchar: %v", err)
 			return err
 		}
-		fmt.Println("Converted successfully, please confirm your database's all columns character is NVARCHAR now")
+		_, _ = fmt.Fprintln(ctx.App.Writer, "Converted successfully, please confirm your database's all columns character is NVARCHAR now")
 	default:
-		fmt.Println("This command can only be used with a MySQL or MSSQL database")
+		_, _ = fmt.Fprintln(ctx.App.Writer, "This command can only be used with a MySQL or MSSQL database")
 	}
 
 	return nil
 }
+
+// checkMySQLCollations outputs the collations of the database and its tables, the tables not using the target character set are marked
+func checkMySQLCollations(ctx context.Context, out io.Writer, target string) error {
+	dbCollation, tables, err := db.GetMySQLCollations(ctx)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "Database %s: %s\n\n", setting.Database.Name, dbCollation)
+
+	w := tabwriter.NewWriter(out, 5, 0, 1, ' ', 0)
+	_, _ = fmt.Fprintf(w, "TABLE\tCOLLATION\t\n")
+	notConverted := 0
+	for _, table := range tables {
+		mark := ""
+		if db.MySQLCharsetOfCollation(table.Collation) != target {
+			mark = "not " + target
+			notConverted++
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", table.Name, table.Collation, mark)
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+
+	if db.MySQLCharsetOfCollation(dbCollation) != target || notConverted > 0 {
+		_, _ = fmt.Fprintf(out, "\n%d of %d table(s) don't use %s\n", notConverted, len(tables), target)
+	} else {
+		_, _ = fmt.Fprintf(out, "\nThe database and all %d table(s) use %s\n", len(tables), target)
+	}
+	return nil
+}
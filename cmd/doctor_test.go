@@ -0,0 +1,39 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecreateTablesCheckingRows(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	x := unittest.GetXORMEngine()
+	before, err := x.Count(&repo_model.Star{})
+	assert.NoError(t, err)
+	assert.NotZero(t, before)
+
+	var out strings.Builder
+	assert.NoError(t, recreateTablesCheckingRows(x, &out, &repo_model.Star{}))
+	assert.Equal(t, fmt.Sprintf("Recreated table star: %d rows before, %d rows after\n", before, before), out.String())
+
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Star{UID: 2, RepoID: 4})
+	unittest.AssertCount(t, &repo_model.Star{}, before)
+}
+
+func TestNoColorWriter(t *testing.T) {
+	var out strings.Builder
+	_, err := fmt.Fprintf(noColorWriter{&out}, " - %s message\n", log.NewColoredValue("[W]", log.WARN.ColorAttributes()...))
+	assert.NoError(t, err)
+	assert.Equal(t, " - [W] message\n", out.String())
+}
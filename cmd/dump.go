@@ -5,27 +5,166 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/migrations"
 	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 
+	"filippo.io/age"
 	"gitea.com/go-chi/session"
+	"github.com/klauspost/compress/zstd"
 	"github.com/mholt/archiver/v3"
 	"github.com/urfave/cli/v2"
 )
 
+// dumpArchiveWriter is the archive writer of the dump, it also knows which paths should be skipped by "--exclude-glob"
+// and reports the added files to the progress
+type dumpArchiveWriter struct {
+	archiver.Writer
+	excludeGlobs []string
+	progress     *dumpProgress
+	zstdWriter   *zstd.Encoder  // the compressor under the tar writer of "tar.zst", archiver can't set its level
+	encWriter    io.WriteCloser // the age encryption under the archive of "--encrypt"
+
+	mu         sync.Mutex // the files are written one by one, also by the workers of "--parallel-repos"
+	concurrent bool       // the small files are read before taking the lock, so reading them overlaps with writing the others
+}
+
+// dumpBufferedFileSize is the maximum size of the files read before taking the lock of the archive writer
+const dumpBufferedFileSize = 1 << 20
+
+// Write adds a file to the archive and counts it in the progress, it can be called concurrently
+func (w *dumpArchiveWriter) Write(f archiver.File) error {
+	if w.concurrent && f.ReadCloser != nil && f.Mode().IsRegular() && f.Size() <= dumpBufferedFileSize {
+		content, err := io.ReadAll(f.ReadCloser)
+		if err != nil {
+			return err
+		}
+		f.ReadCloser = io.NopCloser(bytes.NewReader(content)) // the caller still closes the original one
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.Writer.Write(f); err != nil {
+		return err
+	}
+	w.progress.Add(f.Size())
+	return nil
+}
+
+// create starts writing the archive of the archiver type to out,
+// the compression level is only changed if setLevel is true, its range depends on the type
+func (w *dumpArchiveWriter) create(iface any, out io.Writer, level int, setLevel bool) error {
+	switch a := iface.(type) {
+	case *archiver.TarZstd:
+		zstdLevel := zstd.SpeedDefault
+		if setLevel {
+			if level < 1 || level > 22 {
+				return fmt.Errorf("invalid compression level %d, it should be 1-22", level)
+			}
+			zstdLevel = zstd.EncoderLevelFromZstd(level)
+		}
+		zstdWriter, err := zstd.NewWriter(out, zstd.WithEncoderLevel(zstdLevel))
+		if err != nil {
+			return err
+		}
+		w.Writer, w.zstdWriter, out = archiver.NewTar(), zstdWriter, zstdWriter
+	case *archiver.Zip:
+		if setLevel {
+			a.CompressionLevel = level
+		}
+		w.Writer = a
+	case *archiver.TarGz:
+		if setLevel {
+			a.CompressionLevel = level
+		}
+		w.Writer = a
+	case *archiver.TarBz2:
+		if setLevel {
+			a.CompressionLevel = level
+		}
+		w.Writer = a
+	case *archiver.TarLz4:
+		if setLevel {
+			a.CompressionLevel = level
+		}
+		w.Writer = a
+	case *archiver.TarBrotli:
+		if setLevel {
+			a.Quality = level
+		}
+		w.Writer = a
+	default:
+		aw, ok := iface.(archiver.Writer)
+		if !ok {
+			return fmt.Errorf("%T can't write archives", iface)
+		}
+		if setLevel {
+			return errors.New("the compression level isn't supported")
+		}
+		w.Writer = aw
+	}
+	return w.Writer.Create(out)
+}
+
+// Close closes the archive writer and then the zstd compressor and the encryption under it,
+// it is safe to call it more than once
+func (w *dumpArchiveWriter) Close() error {
+	err := w.Writer.Close()
+	if w.zstdWriter != nil {
+		if zstdErr := w.zstdWriter.Close(); err == nil {
+			err = zstdErr
+		}
+		w.zstdWriter = nil
+	}
+	if w.encWriter != nil {
+		// the last encrypted chunk is only written when closing
+		if encErr := w.encWriter.Close(); err == nil {
+			err = encErr
+		}
+		w.encWriter = nil
+	}
+	return err
+}
+
+// isExcludedByGlob checks whether the path inside the dump or one of its parent directories matches an exclude glob
+func isExcludedByGlob(w archiver.Writer, insidePath string, verbose bool) bool {
+	dw, ok := w.(*dumpArchiveWriter)
+	if !ok {
+		return false
+	}
+	for p := insidePath; p != "." && p != "/" && p != ""; p = path.Dir(p) {
+		for _, glob := range dw.excludeGlobs {
+			if matched, _ := filepath.Match(glob, p); matched {
+				if verbose {
+					log.Info("Skipping %s, it matches the exclude glob %q", insidePath, glob)
+				}
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func addReader(w archiver.Writer, r io.ReadCloser, info os.FileInfo, customName string, verbose bool) error {
+	if isExcludedByGlob(w, customName, verbose) {
+		return nil
+	}
 	if verbose {
 		log.Info("Adding file %s", customName)
 	}
@@ -40,6 +179,9 @@ func addReader(w archiver.Writer, r io.ReadCloser, info os.FileInfo, customName
 }
 
 func addFile(w archiver.Writer, filePath, absPath string, verbose bool) error {
+	if isExcludedByGlob(w, filePath, verbose) {
+		return nil
+	}
 	file, err := os.Open(absPath)
 	if err != nil {
 		return err
@@ -101,6 +243,7 @@ var CmdDump = &cli.Command{
 	Usage: "Dump Gitea files and database",
 	Description: `Dump compresses all related files and database into zip file.
 It can be used for backup and capture Gitea server image to send to maintainer`,
+	Before: prepareDumpConsoleLogger,
 	Action: runDump,
 	Flags: []cli.Flag{
 		&cli.StringFlag{
@@ -117,7 +260,7 @@ It can be used for backup and capture Gitea server image to send to maintainer`,
 		&cli.BoolFlag{
 			Name:    "quiet",
 			Aliases: []string{"q"},
-			Usage:   "Only display warnings and errors",
+			Usage:   "Only display warnings and errors, no progress is shown",
 		},
 		&cli.StringFlag{
 			Name:    "tempdir",
@@ -130,6 +273,10 @@ It can be used for backup and capture Gitea server image to send to maintainer`,
 			Aliases: []string{"d"},
 			Usage:   "Specify the database SQL syntax",
 		},
+		&cli.StringFlag{
+			Name:  "db-since",
+			Usage: "Best-effort incremental database dump: only dump the rows created since the time (YYYY-MM-DD or RFC 3339) of the tables with a created time, the other tables are dumped fully",
+		},
 		&cli.BoolFlag{
 			Name:    "skip-repository",
 			Aliases: []string{"R"},
@@ -160,34 +307,194 @@ It can be used for backup and capture Gitea server image to send to maintainer`,
 			Name:  "skip-index",
 			Usage: "Skip bleve index data",
 		},
+		&cli.BoolFlag{
+			Name:  "skip-repo-archives",
+			Usage: "Skip the generated repository archives, they are generated again when they are downloaded",
+		},
+		&cli.BoolFlag{
+			Name:  "skip-actions-data",
+			Usage: "Skip the logs and the artifacts of the actions",
+		},
+		&cli.StringSliceFlag{
+			Name:  "exclude-glob",
+			Usage: "Skip the paths inside the dump (eg: 'data/repo-avatars/*') matching the glob, it can be used multiple times",
+		},
 		&cli.GenericFlag{
 			Name:  "type",
 			Value: outputTypeEnum,
 			Usage: fmt.Sprintf("Dump output format: %s", outputTypeEnum.Join()),
 		},
+		&cli.IntFlag{
+			Name:  "parallel-repos",
+			Value: 1,
+			Usage: "Number of the repositories added to the dump at the same time, the archive is still written file by file",
+		},
+		&cli.IntFlag{
+			Name:  "compression-level",
+			Usage: "Compression level of the dump, the range depends on the type: zip and tar.gz -1-9, tar.bz2 1-9, tar.lz4 0-12, tar.br 0-11, tar.zst 1-22. Other types don't support it",
+		},
+		&cli.BoolFlag{
+			Name:  "encrypt",
+			Usage: "Encrypt the dump with age for the --recipient or with the passphrase of the --passphrase-file, \".age\" is appended to the file name",
+		},
+		&cli.StringSliceFlag{
+			Name:  "recipient",
+			Usage: "The age public key (age1...) the dump is encrypted for, it can be used multiple times",
+		},
+		&cli.StringFlag{
+			Name:  "passphrase-file",
+			Usage: "The file whose first line is the passphrase the dump is encrypted with",
+		},
 	},
 }
 
+// dumpEncryptExt is the extension of an encrypted dump
+const dumpEncryptExt = ".age"
+
+// dumpEncryptRecipients returns the age recipients of the dump, either the public keys or a passphrase read from a file,
+// the passphrase is never given on the command line as it would be visible to the other users of the host
+func dumpEncryptRecipients(publicKeys []string, passphraseFile string) ([]age.Recipient, error) {
+	if (len(publicKeys) > 0) == (passphraseFile != "") {
+		return nil, errors.New("--encrypt requires either --recipient or --passphrase-file")
+	}
+	if passphraseFile != "" {
+		content, err := os.ReadFile(passphraseFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the passphrase: %w", err)
+		}
+		passphrase, _, _ := strings.Cut(string(content), "\n")
+		passphrase = strings.TrimSuffix(passphrase, "\r")
+		if passphrase == "" {
+			return nil, fmt.Errorf("the passphrase file %s is empty", passphraseFile)
+		}
+		recipient, err := age.NewScryptRecipient(passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return []age.Recipient{recipient}, nil
+	}
+	recipients := make([]age.Recipient, 0, len(publicKeys))
+	for _, publicKey := range publicKeys {
+		recipient, err := age.ParseX25519Recipient(publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --recipient %q: %w", publicKey, err)
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients, nil
+}
+
+// parseDumpSince parses the time of --db-since, a date is in the local time zone
+func parseDumpSince(s string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --db-since %q, it should be a date (YYYY-MM-DD) or an RFC 3339 time (YYYY-MM-DDTHH:MM:SSZ)", s)
+	}
+	return t, nil
+}
+
+// dumpFileNameAndType returns the dump file name and type: the type is inferred from the extension of the given file name,
+// the --type (or its default) is only used when the extension isn't the one of a type, then it is appended to the name.
+// An explicit --type contradicting the extension is an error, to avoid writing a mislabeled archive.
+func dumpFileNameAndType(fileName string, fileSet bool, outType string, typeSet bool) (string, string, error) {
+	if fileName == "-" {
+		return fileName, outType, nil
+	}
+	extType := ""
+	for _, suffix := range outputTypeEnum.Enum {
+		if strings.HasSuffix(fileName, "."+suffix) && len(suffix) > len(extType) {
+			extType = suffix
+		}
+	}
+	if !fileSet {
+		// the default file name has the extension of the default type
+		return strings.TrimSuffix(fileName, "."+extType) + "." + outType, outType, nil
+	}
+	if extType == "" {
+		return fileName + "." + outType, outType, nil
+	}
+	if typeSet && outType != extType {
+		return "", "", fmt.Errorf("the extension of the dump file %q is the one of the type %s, it contradicts --type %s", fileName, extType, outType)
+	}
+	return fileName, extType, nil
+}
+
+// dumpManifestName is the name of the manifest inside the dump
+const dumpManifestName = "gitea-dump.json"
+
+// dumpManifest describes the content of a dump, it is written as JSON inside the dump to make it self-describing
+type dumpManifest struct {
+	GiteaVersion string     `json:"gitea_version"`
+	DBVersion    int64      `json:"db_version"`
+	DatabaseType string     `json:"database_type"`
+	DBSince      *time.Time `json:"db_since,omitempty"`
+	Created      time.Time  `json:"created"`
+	Included     []string   `json:"included"`
+	Excluded     []string   `json:"excluded"`
+	ExcludeGlobs []string   `json:"exclude_globs,omitempty"`
+	Encrypted    bool       `json:"encrypted"`
+}
+
+// include records that the category is in the dump
+func (m *dumpManifest) include(category string) {
+	m.Included = append(m.Included, category)
+}
+
+// exclude records that the category isn't in the dump, because it was skipped or because it isn't used
+func (m *dumpManifest) exclude(category string) {
+	m.Excluded = append(m.Excluded, category)
+}
+
 func fatal(format string, args ...any) {
 	fmt.Fprintf(os.Stderr, format+"\n", args...)
 	log.Fatal(format, args...)
 }
 
+// prepareDumpConsoleLogger makes sure the logs (including the ones when loading the config) don't go into the dump written to stdout
+func prepareDumpConsoleLogger(ctx *cli.Context) error {
+	if ctx.String("file") == "-" {
+		level := log.INFO
+		if ctx.Bool("quiet") {
+			level = log.WARN
+		}
+		setupConsoleLogger(level, log.CanColorStderr, os.Stderr)
+	}
+	return nil
+}
+
 func runDump(ctx *cli.Context) error {
 	var file *os.File
-	fileName := ctx.String("file")
-	outType := ctx.String("type")
+	var recipients []age.Recipient
+	encrypt := ctx.Bool("encrypt")
+	if encrypt {
+		var err error
+		if recipients, err = dumpEncryptRecipients(ctx.StringSlice("recipient"), ctx.String("passphrase-file")); err != nil {
+			return err
+		}
+	} else if ctx.IsSet("recipient") || ctx.IsSet("passphrase-file") {
+		return errors.New("--recipient and --passphrase-file can only be used with --encrypt")
+	}
+	if ctx.Int("parallel-repos") < 1 {
+		return errors.New("--parallel-repos should be at least 1")
+	}
+
+	// the type is the one of the archive inside the encrypted dump
+	archiveName := ctx.String("file")
+	if encrypt {
+		archiveName = strings.TrimSuffix(archiveName, dumpEncryptExt)
+	}
+	archiveName, outType, err := dumpFileNameAndType(archiveName, ctx.IsSet("file"), ctx.String("type"), ctx.IsSet("type"))
+	if err != nil {
+		return err
+	}
+	fileName := archiveName
 	if fileName == "-" {
 		file = os.Stdout
-		setupConsoleLogger(log.FATAL, log.CanColorStderr, os.Stderr)
-	} else {
-		for _, suffix := range outputTypeEnum.Enum {
-			if strings.HasSuffix(fileName, "."+suffix) {
-				fileName = strings.TrimSuffix(fileName, "."+suffix)
-				break
-			}
-		}
-		fileName += "." + outType
+	} else if encrypt {
+		fileName += dumpEncryptExt
 	}
 	setting.MustInstalled()
 
@@ -218,10 +525,24 @@ func runDump(ctx *cli.Context) error {
 		return fmt.Errorf("--quiet and --verbose cannot both be set")
 	}
 
+	excludeGlobs := ctx.StringSlice("exclude-glob")
+	for _, glob := range excludeGlobs {
+		if _, err := filepath.Match(glob, ""); err != nil {
+			return fmt.Errorf("invalid exclude glob %q: %w", glob, err)
+		}
+	}
+
+	var dbSince time.Time
+	if ctx.IsSet("db-since") {
+		if dbSince, err = parseDumpSince(ctx.String("db-since")); err != nil {
+			return err
+		}
+	}
+
 	stdCtx, cancel := installSignals()
 	defer cancel()
 
-	err := db.InitEngine(stdCtx)
+	err = db.InitEngine(stdCtx)
 	if err != nil {
 		return err
 	}
@@ -247,39 +568,64 @@ func runDump(ctx *cli.Context) error {
 	if fileName == "-" {
 		iface, err = archiver.ByExtension(fmt.Sprintf(".%s", outType))
 	} else {
-		iface, err = archiver.ByExtension(fileName)
+		iface, err = archiver.ByExtension(archiveName)
 	}
 	if err != nil {
 		fatal("Unable to get archiver for extension: %v", err)
 	}
 
-	w, _ := iface.(archiver.Writer)
-	if err := w.Create(file); err != nil {
-		fatal("Creating archiver.Writer failed: %v", err)
+	w := &dumpArchiveWriter{excludeGlobs: excludeGlobs, progress: newDumpProgress(ctx.Bool("quiet"), verbose)}
+	var out io.Writer = file
+	if encrypt {
+		if w.encWriter, err = age.Encrypt(file, recipients...); err != nil {
+			if fileName != "-" {
+				_ = util.Remove(fileName)
+			}
+			fatal("Unable to encrypt the dump: %v", err)
+		}
+		out = w.encWriter
+	}
+	if err := w.create(iface, out, ctx.Int("compression-level"), ctx.IsSet("compression-level")); err != nil {
+		if fileName != "-" {
+			_ = util.Remove(fileName)
+		}
+		fatal("Creating archiver.Writer of type %s failed: %v", outType, err)
 	}
 	defer w.Close()
 
+	manifest := &dumpManifest{
+		GiteaVersion: setting.AppVer,
+		DatabaseType: setting.Database.Type.String(),
+		Created:      time.Now(),
+		Included:     []string{},
+		Excluded:     []string{},
+		ExcludeGlobs: excludeGlobs,
+		Encrypted:    encrypt,
+	}
+
 	if ctx.IsSet("skip-repository") && ctx.Bool("skip-repository") {
 		log.Info("Skip dumping local repositories")
+		manifest.exclude("repositories")
+		manifest.exclude("lfs")
 	} else {
 		log.Info("Dumping local repositories... %s", setting.RepoRootPath)
-		if err := addRecursiveExclude(w, "repos", setting.RepoRootPath, []string{absFileName}, verbose); err != nil {
+		w.progress.Phase("repositories")
+		if err := addRepositories(w, "repos", setting.RepoRootPath, []string{absFileName}, verbose, ctx.Int("parallel-repos")); err != nil {
 			fatal("Failed to include repositories: %v", err)
 		}
+		w.progress.Done()
+		manifest.include("repositories")
 
 		if ctx.IsSet("skip-lfs-data") && ctx.Bool("skip-lfs-data") {
 			log.Info("Skip dumping LFS data")
+			manifest.exclude("lfs")
 		} else if !setting.LFS.StartServer {
 			log.Info("LFS isn't enabled. Skip dumping LFS data")
-		} else if err := storage.LFS.IterateObjects("", func(objPath string, object storage.Object) error {
-			info, err := object.Stat()
-			if err != nil {
-				return err
-			}
-
-			return addReader(w, object, info, path.Join("data", "lfs", objPath), verbose)
-		}); err != nil {
+			manifest.exclude("lfs")
+		} else if err := dumpStorageObjects(w, "LFS data", storage.LFS, "lfs", verbose); err != nil {
 			fatal("Failed to dump LFS objects: %v", err)
+		} else {
+			manifest.include("lfs")
 		}
 	}
 
@@ -302,39 +648,75 @@ func runDump(ctx *cli.Context) error {
 	targetDBType := ctx.String("database")
 	if len(targetDBType) > 0 && targetDBType != setting.Database.Type.String() {
 		log.Info("Dumping database %s => %s...", setting.Database.Type, targetDBType)
+		manifest.DatabaseType = targetDBType
 	} else {
 		log.Info("Dumping database...")
 	}
 
-	if err := db.DumpDatabase(dbDump.Name(), targetDBType); err != nil {
-		fatal("Failed to dump database: %v", err)
+	// the restored database must be opened by a Gitea which knows this version
+	version := migrations.Version{ID: 1}
+	if has, err := db.GetEngine(stdCtx).Get(&version); err != nil || !has {
+		log.Warn("Unable to get the database version for the manifest: %v", err)
+	} else {
+		manifest.DBVersion = version.Version
+	}
+
+	w.progress.Phase("database")
+	if dbSince.IsZero() {
+		if err := db.DumpDatabase(dbDump.Name(), targetDBType); err != nil {
+			fatal("Failed to dump database: %v", err)
+		}
+	} else {
+		log.Warn("Dumping only the database rows created since %s, it is a best-effort incremental dump: the updated and deleted rows are missing", dbSince.Format(time.RFC3339))
+		fullTables, err := db.DumpDatabaseSince(stdCtx, dbDump.Name(), targetDBType, timeutil.TimeStamp(dbSince.Unix()))
+		if err != nil {
+			fatal("Failed to dump database: %v", err)
+		}
+		log.Info("These tables have no created time, all their rows are dumped: %s", strings.Join(fullTables, ", "))
+		manifest.DBSince = &dbSince
 	}
 
 	if err := addFile(w, "gitea-db.sql", dbDump.Name(), verbose); err != nil {
 		fatal("Failed to include gitea-db.sql: %v", err)
 	}
+	w.progress.Done()
+	manifest.include("database")
 
-	if len(setting.CustomConf) > 0 {
+	w.progress.Phase("configuration")
+	if isDir, _ := util.IsDir(setting.CustomConf); isDir {
+		log.Info("Adding custom configuration directory from %s", setting.CustomConf)
+		if err := addRecursiveExclude(w, "app.ini.d", setting.CustomConf, []string{absFileName}, verbose); err != nil {
+			fatal("Failed to include custom configuration directory: %v", err)
+		}
+	} else if len(setting.CustomConf) > 0 {
 		log.Info("Adding custom configuration file from %s", setting.CustomConf)
 		if err := addFile(w, "app.ini", setting.CustomConf, verbose); err != nil {
 			fatal("Failed to include specified app.ini: %v", err)
 		}
 	}
+	w.progress.Done()
+	manifest.include("config")
 
 	if ctx.IsSet("skip-custom-dir") && ctx.Bool("skip-custom-dir") {
 		log.Info("Skipping custom directory")
+		manifest.exclude("custom")
 	} else {
 		customDir, err := os.Stat(setting.CustomPath)
 		if err == nil && customDir.IsDir() {
 			if is, _ := isSubdir(setting.AppDataPath, setting.CustomPath); !is {
+				w.progress.Phase("custom directory")
 				if err := addRecursiveExclude(w, "custom", setting.CustomPath, []string{absFileName}, verbose); err != nil {
 					fatal("Failed to include custom: %v", err)
 				}
+				w.progress.Done()
+				manifest.include("custom")
 			} else {
 				log.Info("Custom dir %s is inside data dir %s, skipped", setting.CustomPath, setting.AppDataPath)
+				manifest.include("custom")
 			}
 		} else {
 			log.Info("Custom dir %s doesn't exist, skipped", setting.CustomPath)
+			manifest.exclude("custom")
 		}
 	}
 
@@ -344,6 +726,7 @@ func runDump(ctx *cli.Context) error {
 	}
 	if isExist {
 		log.Info("Packing data directory...%s", setting.AppDataPath)
+		w.progress.Phase("data directory")
 
 		var excludes []string
 		if setting.SessionConfig.OriginalProvider == "file" {
@@ -357,6 +740,26 @@ func runDump(ctx *cli.Context) error {
 		if ctx.IsSet("skip-index") && ctx.Bool("skip-index") {
 			excludes = append(excludes, setting.Indexer.RepoPath)
 			excludes = append(excludes, setting.Indexer.IssuePath)
+			manifest.exclude("index")
+		} else {
+			manifest.include("index")
+		}
+
+		// only the local storages are inside the data directory
+		if ctx.IsSet("skip-repo-archives") && ctx.Bool("skip-repo-archives") {
+			log.Info("Skip dumping repository archives")
+			excludes = append(excludes, setting.RepoArchive.Storage.Path)
+			manifest.exclude("repo-archives")
+		} else {
+			manifest.include("repo-archives")
+		}
+		if ctx.IsSet("skip-actions-data") && ctx.Bool("skip-actions-data") {
+			log.Info("Skip dumping actions logs and artifacts")
+			excludes = append(excludes, setting.Actions.LogStorage.Path)
+			excludes = append(excludes, setting.Actions.ArtifactStorage.Path)
+			manifest.exclude("actions-data")
+		} else {
+			manifest.include("actions-data")
 		}
 
 		excludes = append(excludes, setting.RepoRootPath)
@@ -368,34 +771,31 @@ func runDump(ctx *cli.Context) error {
 		if err := addRecursiveExclude(w, "data", setting.AppDataPath, excludes, verbose); err != nil {
 			fatal("Failed to include data directory: %v", err)
 		}
+		w.progress.Done()
+		manifest.include("data")
+	} else {
+		manifest.exclude("data")
 	}
 
 	if ctx.IsSet("skip-attachment-data") && ctx.Bool("skip-attachment-data") {
 		log.Info("Skip dumping attachment data")
-	} else if err := storage.Attachments.IterateObjects("", func(objPath string, object storage.Object) error {
-		info, err := object.Stat()
-		if err != nil {
-			return err
-		}
-
-		return addReader(w, object, info, path.Join("data", "attachments", objPath), verbose)
-	}); err != nil {
+		manifest.exclude("attachments")
+	} else if err := dumpStorageObjects(w, "attachments", storage.Attachments, "attachments", verbose); err != nil {
 		fatal("Failed to dump attachments: %v", err)
+	} else {
+		manifest.include("attachments")
 	}
 
 	if ctx.IsSet("skip-package-data") && ctx.Bool("skip-package-data") {
 		log.Info("Skip dumping package data")
+		manifest.exclude("packages")
 	} else if !setting.Packages.Enabled {
 		log.Info("Packages isn't enabled. Skip dumping package data")
-	} else if err := storage.Packages.IterateObjects("", func(objPath string, object storage.Object) error {
-		info, err := object.Stat()
-		if err != nil {
-			return err
-		}
-
-		return addReader(w, object, info, path.Join("data", "packages", objPath), verbose)
-	}); err != nil {
+		manifest.exclude("packages")
+	} else if err := dumpStorageObjects(w, "packages", storage.Packages, "packages", verbose); err != nil {
 		fatal("Failed to dump packages: %v", err)
+	} else {
+		manifest.include("packages")
 	}
 
 	// Doesn't check if LogRootPath exists before processing --skip-log intentionally,
@@ -403,18 +803,29 @@ func runDump(ctx *cli.Context) error {
 	// yet or not.
 	if ctx.IsSet("skip-log") && ctx.Bool("skip-log") {
 		log.Info("Skip dumping log files")
+		manifest.exclude("log")
 	} else {
 		isExist, err := util.IsExist(setting.Log.RootPath)
 		if err != nil {
 			log.Error("Unable to check if %s exists. Error: %v", setting.Log.RootPath, err)
 		}
 		if isExist {
+			w.progress.Phase("log files")
 			if err := addRecursiveExclude(w, "log", setting.Log.RootPath, []string{absFileName}, verbose); err != nil {
 				fatal("Failed to include log: %v", err)
 			}
+			w.progress.Done()
+			manifest.include("log")
+		} else {
+			manifest.exclude("log")
 		}
 	}
 
+	if err := addDumpManifest(w, tmpDir, manifest, verbose); err != nil {
+		fatal("Failed to include %s: %v", dumpManifestName, err)
+	}
+
+	// the archive must be closed explicitly to report the error, the last compressed blocks are only written when closing
 	if fileName != "-" {
 		if err = w.Close(); err != nil {
 			_ = util.Remove(fileName)
@@ -424,6 +835,8 @@ func runDump(ctx *cli.Context) error {
 		if err := os.Chmod(fileName, 0o600); err != nil {
 			log.Info("Can't change file access permissions mask to 0600: %v", err)
 		}
+	} else if err = w.Close(); err != nil {
+		fatal("Failed to write the dump to stdout: %v", err)
 	}
 
 	if fileName != "-" {
@@ -435,8 +848,109 @@ func runDump(ctx *cli.Context) error {
 	return nil
 }
 
+// addDumpManifest adds the manifest as the last file of the dump, when all the categories are known
+func addDumpManifest(w archiver.Writer, tmpDir string, manifest *dumpManifest, verbose bool) error {
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(tmpDir, dumpManifestName)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = util.Remove(f.Name())
+	}()
+	if _, err := f.Write(content); err != nil {
+		return err
+	}
+	return addFile(w, dumpManifestName, f.Name(), verbose)
+}
+
+// dumpStorageObjects adds all objects of the storage to the insideDir of the "data" directory inside the dump
+func dumpStorageObjects(w *dumpArchiveWriter, phase string, st storage.ObjectStorage, insideDir string, verbose bool) error {
+	w.progress.Phase(phase)
+	defer w.progress.Done()
+	return st.IterateObjects("", func(objPath string, object storage.Object) error {
+		info, err := object.Stat()
+		if err != nil {
+			return err
+		}
+
+		return addReader(w, object, info, path.Join("data", insideDir, objPath), verbose)
+	})
+}
+
+// addRepositories adds the repositories ("owner/name.git" directories) of absPath to insidePath inside the dump,
+// with several workers the repositories are added concurrently
+func addRepositories(w *dumpArchiveWriter, insidePath, absPath string, excludeAbsPath []string, verbose bool, workers int) error {
+	if workers <= 1 {
+		return addRecursiveExclude(w, insidePath, absPath, excludeAbsPath, verbose)
+	}
+
+	w.concurrent = true
+	defer func() {
+		w.concurrent = false
+	}()
+
+	type repoDir struct{ insidePath, absPath string }
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var workerErr error
+	repoCh := make(chan repoDir)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for repo := range repoCh {
+				err := addFile(w, repo.insidePath, repo.absPath, false)
+				if err == nil {
+					err = addRecursiveExclude(w, repo.insidePath, repo.absPath, excludeAbsPath, verbose)
+				}
+				if err != nil {
+					mu.Lock()
+					if workerErr == nil {
+						workerErr = fmt.Errorf("%s: %w", repo.insidePath, err)
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	// the owner directories (and the other files) are added by this goroutine, the repositories by the workers
+	err := addRecursiveExcludeFunc(w, insidePath, absPath, excludeAbsPath, verbose, func(ownerInsidePath, ownerAbsPath string) error {
+		if err := addFile(w, ownerInsidePath, ownerAbsPath, false); err != nil {
+			return err
+		}
+		return addRecursiveExcludeFunc(w, ownerInsidePath, ownerAbsPath, excludeAbsPath, verbose, func(repoInsidePath, repoAbsPath string) error {
+			mu.Lock()
+			failed := workerErr != nil
+			mu.Unlock()
+			if failed {
+				return errors.New("a repository couldn't be added")
+			}
+			repoCh <- repoDir{insidePath: repoInsidePath, absPath: repoAbsPath}
+			return nil
+		})
+	})
+	close(repoCh)
+	wg.Wait()
+	if workerErr != nil {
+		return workerErr
+	}
+	return err
+}
+
 // addRecursiveExclude zips absPath to specified insidePath inside writer excluding excludeAbsPath
 func addRecursiveExclude(w archiver.Writer, insidePath, absPath string, excludeAbsPath []string, verbose bool) error {
+	return addRecursiveExcludeFunc(w, insidePath, absPath, excludeAbsPath, verbose, nil)
+}
+
+// addRecursiveExcludeFunc is addRecursiveExclude, but the sub-directories which aren't excluded
+// are passed to addDir instead of being added recursively if it isn't nil
+func addRecursiveExcludeFunc(w archiver.Writer, insidePath, absPath string, excludeAbsPath []string, verbose bool, addDir func(insidePath, absPath string) error) error {
 	absPath, err := filepath.Abs(absPath)
 	if err != nil {
 		return err
@@ -455,7 +969,13 @@ func addRecursiveExclude(w archiver.Writer, insidePath, absPath string, excludeA
 		currentAbsPath := path.Join(absPath, file.Name())
 		currentInsidePath := path.Join(insidePath, file.Name())
 		if file.IsDir() {
-			if !util.SliceContainsString(excludeAbsPath, currentAbsPath) {
+			if !util.SliceContainsString(excludeAbsPath, currentAbsPath) && !isExcludedByGlob(w, currentInsidePath, verbose) {
+				if addDir != nil {
+					if err := addDir(currentInsidePath, currentAbsPath); err != nil {
+						return err
+					}
+					continue
+				}
 				if err := addFile(w, currentInsidePath, currentAbsPath, false); err != nil {
 					return err
 				}
@@ -0,0 +1,93 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/mattn/go-isatty"
+)
+
+// dumpProgress reports the current phase of the dump and the number and size of the files added in it.
+// On a terminal a status line is redrawn on stderr, otherwise the progress is logged periodically.
+// The dump adds the files in one goroutine, so there is no lock.
+type dumpProgress struct {
+	out      io.Writer // the terminal to draw the status line, nil means logging the progress
+	interval time.Duration
+
+	phase      string
+	files      int64
+	size       int64
+	lastReport time.Time
+}
+
+// newDumpProgress returns nil (no progress) if quiet, the status line is only used if the logs won't be mixed into it
+func newDumpProgress(quiet, verbose bool) *dumpProgress {
+	if quiet {
+		return nil
+	}
+	if !verbose && isatty.IsTerminal(os.Stderr.Fd()) {
+		return &dumpProgress{out: os.Stderr, interval: 200 * time.Millisecond}
+	}
+	return &dumpProgress{interval: 10 * time.Second}
+}
+
+// Phase starts a new phase, the current phase is finished if Done hasn't been called
+func (p *dumpProgress) Phase(phase string) {
+	if p == nil {
+		return
+	}
+	p.finish()
+	p.phase, p.files, p.size = phase, 0, 0
+	p.lastReport = time.Now()
+}
+
+// Add counts a file added to the dump
+func (p *dumpProgress) Add(size int64) {
+	if p == nil {
+		return
+	}
+	p.files++
+	p.size += size
+	if time.Since(p.lastReport) >= p.interval {
+		p.report()
+	}
+}
+
+// Done finishes the current phase and reports its total, it should be called before logging anything else
+// because the status line on the terminal is only ended by it
+func (p *dumpProgress) Done() {
+	if p == nil {
+		return
+	}
+	p.finish()
+	p.phase = ""
+}
+
+func (p *dumpProgress) report() {
+	p.lastReport = time.Now()
+	if p.out == nil {
+		log.Info("Dumping %s: %d files, %s so far", p.phase, p.files, base.FileSize(p.size))
+		return
+	}
+	_, _ = fmt.Fprintf(p.out, "\r\033[KDumping %s: %d files, %s", p.phase, p.files, base.FileSize(p.size))
+}
+
+func (p *dumpProgress) finish() {
+	if p.phase == "" || p.files == 0 {
+		return
+	}
+	if p.out == nil {
+		log.Info("Dumped %s: %d files, %s", p.phase, p.files, base.FileSize(p.size))
+		return
+	}
+	p.report()
+	_, _ = fmt.Fprintln(p.out)
+}
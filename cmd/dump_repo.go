@@ -7,14 +7,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
 	base "code.gitea.io/gitea/modules/migration"
 	"code.gitea.io/gitea/modules/setting"
-	"code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/services/convert"
 	"code.gitea.io/gitea/services/migrations"
@@ -45,6 +48,15 @@ var CmdDumpRepository = &cli.Command{
 			Value: "",
 			Usage: "The URL will be clone, currently could be a git/github/gitea/gitlab http/https URL",
 		},
+		&cli.StringSliceFlag{
+			Name:  "repo",
+			Usage: "The URL of a repository to dump like clone_addr, can be given several times to dump many repositories, each one is stored in an \"owner-repo\" directory in the repo_dir",
+		},
+		&cli.StringFlag{
+			Name:  "repo-list",
+			Value: "",
+			Usage: "A file with the URLs of the repositories to dump like --repo, one per line",
+		},
 		&cli.StringFlag{
 			Name:  "auth_username",
 			Value: "",
@@ -80,6 +92,18 @@ wiki, issues, labels, releases, release_assets, milestones, pull_requests, comme
 }
 
 func runDumpRepository(ctx *cli.Context) error {
+	cloneAddrs, err := dumpRepoCloneAddrs(ctx)
+	if err != nil {
+		return err
+	}
+	if len(cloneAddrs) > 0 {
+		for _, name := range []string{"clone_addr", "owner_name", "repo_name"} {
+			if ctx.IsSet(name) {
+				return fmt.Errorf("--%s can't be used with --repo or --repo-list", name)
+			}
+		}
+	}
+
 	stdCtx, cancel := installSignals()
 	defer cancel()
 
@@ -98,31 +122,11 @@ func runDumpRepository(ctx *cli.Context) error {
 	log.Info("Log path: %s", setting.Log.RootPath)
 	log.Info("Configuration file: %s", setting.CustomConf)
 
-	var (
-		serviceType structs.GitServiceType
-		cloneAddr   = ctx.String("clone_addr")
-		serviceStr  = ctx.String("git_service")
-	)
-
-	if strings.HasPrefix(strings.ToLower(cloneAddr), "https://github.com/") {
-		serviceStr = "github"
-	} else if strings.HasPrefix(strings.ToLower(cloneAddr), "https://gitlab.com/") {
-		serviceStr = "gitlab"
-	} else if strings.HasPrefix(strings.ToLower(cloneAddr), "https://gitea.com/") {
-		serviceStr = "gitea"
-	}
-	if serviceStr == "" {
-		return errors.New("git_service missed or clone_addr cannot be recognized")
-	}
-	serviceType = convert.ToGitServiceType(serviceStr)
-
 	opts := base.MigrateOptions{
-		GitServiceType: serviceType,
-		CloneAddr:      cloneAddr,
-		AuthUsername:   ctx.String("auth_username"),
-		AuthPassword:   ctx.String("auth_password"),
-		AuthToken:      ctx.String("auth_token"),
-		RepoName:       ctx.String("repo_name"),
+		AuthUsername: ctx.String("auth_username"),
+		AuthPassword: ctx.String("auth_password"),
+		AuthToken:    ctx.String("auth_token"),
+		RepoName:     ctx.String("repo_name"),
 	}
 
 	if len(ctx.String("units")) == 0 {
@@ -162,9 +166,88 @@ func runDumpRepository(ctx *cli.Context) error {
 		}
 	}
 
+	if len(cloneAddrs) == 0 {
+		if err := dumpOneRepository(ctx.String("repo_dir"), ctx.String("owner_name"), ctx.String("clone_addr"), ctx.String("git_service"), opts); err != nil {
+			log.Fatal("Failed to dump repository: %v", err)
+			return err
+		}
+		log.Trace("Dump finished!!!")
+		return nil
+	}
+
+	// every repository is dumped to its own "owner-repo" directory in the repo_dir,
+	// a failed repository doesn't stop the others
+	failed := 0
+	for _, cloneAddr := range cloneAddrs {
+		dirName, err := dumpRepoDirName(cloneAddr)
+		if err == nil {
+			repoDir := filepath.Join(ctx.String("repo_dir"), dirName)
+			if err = dumpOneRepository(repoDir, "", cloneAddr, ctx.String("git_service"), opts); err == nil {
+				_, _ = fmt.Fprintf(ctx.App.Writer, "Dumped %s to %s\n", cloneAddr, repoDir)
+				continue
+			}
+		}
+		failed++
+		log.Error("Failed to dump repository %s: %v", cloneAddr, err)
+	}
+	_, _ = fmt.Fprintf(ctx.App.Writer, "Dumped %d of %d repositories\n", len(cloneAddrs)-failed, len(cloneAddrs))
+	if failed > 0 {
+		return cli.Exit(fmt.Sprintf("Failed to dump %d of %d repositories", failed, len(cloneAddrs)), 1)
+	}
+	return nil
+}
+
+// dumpRepoCloneAddrs returns the clone addresses of the --repo flags and the lines of the --repo-list file,
+// empty lines and lines starting with "#" in the file are ignored
+func dumpRepoCloneAddrs(ctx *cli.Context) ([]string, error) {
+	cloneAddrs := ctx.StringSlice("repo")
+	if listFile := ctx.String("repo-list"); listFile != "" {
+		content, err := os.ReadFile(listFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the repository list: %w", err)
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			cloneAddrs = append(cloneAddrs, line)
+		}
+	}
+	return cloneAddrs, nil
+}
+
+// dumpRepoDirName returns the "owner-repo" directory name of the repository to dump,
+// they are the last two parts of the clone address path
+func dumpRepoDirName(cloneAddr string) (string, error) {
+	u, err := url.Parse(cloneAddr)
+	if err != nil {
+		return "", fmt.Errorf("invalid clone address %q: %w", cloneAddr, err)
+	}
+	parts := strings.Split(strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git"), "/")
+	if len(parts) < 2 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+		return "", fmt.Errorf("unable to get the owner and repository name from the clone address %q", cloneAddr)
+	}
+	return parts[len(parts)-2] + "-" + parts[len(parts)-1], nil
+}
+
+// dumpOneRepository dumps the repository at the clone address to the repoDir
+func dumpOneRepository(repoDir, ownerName, cloneAddr, serviceStr string, opts base.MigrateOptions) error {
+	if strings.HasPrefix(strings.ToLower(cloneAddr), "https://github.com/") {
+		serviceStr = "github"
+	} else if strings.HasPrefix(strings.ToLower(cloneAddr), "https://gitlab.com/") {
+		serviceStr = "gitlab"
+	} else if strings.HasPrefix(strings.ToLower(cloneAddr), "https://gitea.com/") {
+		serviceStr = "gitea"
+	}
+	if serviceStr == "" {
+		return errors.New("git_service missed or clone_addr cannot be recognized")
+	}
+	opts.GitServiceType = convert.ToGitServiceType(serviceStr)
+	opts.CloneAddr = cloneAddr
+
 	// the repo_dir will be removed if error occurs in DumpRepository
 	// make sure the directory doesn't exist or is empty, prevent from deleting user files
-	repoDir := ctx.String("repo_dir")
 	if exists, err := util.IsExist(repoDir); err != nil {
 		return fmt.Errorf("unable to stat repo_dir %q: %w", repoDir, err)
 	} else if exists {
@@ -179,14 +262,44 @@ func runDumpRepository(ctx *cli.Context) error {
 	if err := migrations.DumpRepository(
 		context.Background(),
 		repoDir,
-		ctx.String("owner_name"),
+		ownerName,
 		opts,
 	); err != nil {
-		log.Fatal("Failed to dump repository: %v", err)
 		return err
 	}
+	return writeRepoDumpManifest(repoDir, opts)
+}
 
-	log.Trace("Dump finished!!!")
-
-	return nil
+// writeRepoDumpManifest writes the manifest of the repository dump, restore-repo reads it to check the version of Gitea
+func writeRepoDumpManifest(repoDir string, opts base.MigrateOptions) error {
+	manifest := &dumpManifest{
+		GiteaVersion: setting.AppVer,
+		Created:      time.Now(),
+		Included:     []string{},
+		Excluded:     []string{},
+	}
+	for _, unit := range []struct {
+		name     string
+		included bool
+	}{
+		{"wiki", opts.Wiki},
+		{"issues", opts.Issues},
+		{"milestones", opts.Milestones},
+		{"labels", opts.Labels},
+		{"releases", opts.Releases},
+		{"release_assets", opts.ReleaseAssets},
+		{"comments", opts.Comments},
+		{"pull_requests", opts.PullRequests},
+	} {
+		if unit.included {
+			manifest.include(unit.name)
+		} else {
+			manifest.exclude(unit.name)
+		}
+	}
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(repoDir, dumpManifestName), content, 0o644)
 }
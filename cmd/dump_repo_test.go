@@ -0,0 +1,56 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	base "code.gitea.io/gitea/modules/migration"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDumpRepoDirName(t *testing.T) {
+	cases := map[string]string{
+		"https://github.com/go-gitea/gitea":           "go-gitea-gitea",
+		"https://gitea.com/gitea/tea.git":             "gitea-tea",
+		"https://gitlab.com/group/subgroup/project/":  "subgroup-project",
+		"http://localhost:3000/user2/repo1.git?x=y#z": "user2-repo1",
+	}
+	for cloneAddr, expected := range cases {
+		name, err := dumpRepoDirName(cloneAddr)
+		assert.NoError(t, err, cloneAddr)
+		assert.Equal(t, expected, name, cloneAddr)
+	}
+
+	for _, cloneAddr := range []string{"https://github.com/go-gitea", "https://github.com/", "://bad"} {
+		_, err := dumpRepoDirName(cloneAddr)
+		assert.Error(t, err, cloneAddr)
+	}
+}
+
+func TestRepoDumpManifestVersion(t *testing.T) {
+	defer func(appVer string) { setting.AppVer = appVer }(setting.AppVer)
+	setting.AppVer = "1.22.0"
+
+	// a dump without a manifest isn't checked
+	dir := t.TempDir()
+	out := &strings.Builder{}
+	assert.NoError(t, warnDumpManifestVersion(out, dir, "1.23.0"))
+	assert.Empty(t, out.String())
+
+	assert.NoError(t, writeRepoDumpManifest(dir, base.MigrateOptions{Issues: true, Comments: true}))
+	assert.NoError(t, warnDumpManifestVersion(out, dir, "1.22.0"))
+	assert.Empty(t, out.String())
+
+	assert.NoError(t, warnDumpManifestVersion(out, dir, "1.23.0"))
+	assert.Equal(t, "Warning: the dump was created by Gitea 1.22.0, it is restored into Gitea 1.23.0\n", out.String())
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, dumpManifestName), []byte("{"), 0o644))
+	assert.ErrorContains(t, warnDumpManifestVersion(out, dir, "1.23.0"), "unable to parse the manifest of the dump")
+}
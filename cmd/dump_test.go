@@ -0,0 +1,230 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/json"
+
+	"filippo.io/age"
+	"github.com/mholt/archiver/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDumpExcludeGlob(t *testing.T) {
+	w := &dumpArchiveWriter{excludeGlobs: []string{"data/repo-avatars/*", "log", "*.bak"}}
+	assert.True(t, isExcludedByGlob(w, "data/repo-avatars/1", false))
+	assert.True(t, isExcludedByGlob(w, "data/repo-avatars/1/a.png", false))
+	assert.True(t, isExcludedByGlob(w, "log/gitea.log", false))
+	assert.True(t, isExcludedByGlob(w, "app.ini.bak", false))
+	assert.False(t, isExcludedByGlob(w, "data/repo-avatars", false))
+	assert.False(t, isExcludedByGlob(w, "data/avatars/1", false))
+	assert.False(t, isExcludedByGlob(w, "custom/app.ini.bak", false))
+	assert.False(t, isExcludedByGlob(nil, "log", false))
+}
+
+func TestDumpProgress(t *testing.T) {
+	out := &strings.Builder{}
+	p := &dumpProgress{out: out, interval: time.Hour}
+	p.Phase("repositories")
+	p.Add(1024)
+	p.Add(1024)
+	p.Done()
+	p.Phase("database") // no file is added, nothing is drawn
+	p.Done()
+	p.Phase("attachments")
+	p.Add(10)
+	p.Done()
+	assert.Equal(t, "\r\033[KDumping repositories: 2 files, 2.0 KiB\n\r\033[KDumping attachments: 1 files, 10 B\n", out.String())
+
+	// quiet
+	p = newDumpProgress(true, false)
+	assert.Nil(t, p)
+	p.Phase("repositories")
+	p.Add(1)
+	p.Done()
+}
+
+func TestDumpArchiveCompressionLevel(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "a.txt")
+	assert.NoError(t, os.WriteFile(src, []byte("content"), 0o644))
+
+	for _, level := range []int{1, 19} {
+		buf := &bytes.Buffer{}
+		w := &dumpArchiveWriter{}
+		assert.NoError(t, w.create(archiver.NewTarZstd(), buf, level, true))
+		assert.NoError(t, addFile(w, "a.txt", src, false))
+		assert.NoError(t, w.Close())
+		assert.NoError(t, w.Close())
+
+		// the dump can be read by a standard zstd reader
+		r := archiver.NewTarZstd()
+		assert.NoError(t, r.Open(buf, 0))
+		f, err := r.Read()
+		assert.NoError(t, err)
+		assert.Equal(t, "a.txt", f.Name())
+		content, err := io.ReadAll(f)
+		assert.NoError(t, err)
+		assert.Equal(t, "content", string(content))
+		assert.NoError(t, r.Close())
+	}
+
+	w := &dumpArchiveWriter{}
+	assert.ErrorContains(t, w.create(archiver.NewTarZstd(), io.Discard, 23, true), "invalid compression level")
+	assert.ErrorContains(t, w.create(archiver.NewTarXz(), io.Discard, 1, true), "isn't supported")
+	assert.NoError(t, w.create(archiver.NewTarXz(), io.Discard, 0, false))
+}
+
+func TestDumpFileNameAndType(t *testing.T) {
+	check := func(fileName string, fileSet bool, outType string, typeSet bool, expectedName, expectedType string) {
+		name, typ, err := dumpFileNameAndType(fileName, fileSet, outType, typeSet)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedName, name)
+		assert.Equal(t, expectedType, typ)
+	}
+	check("-", true, "tar.gz", true, "-", "tar.gz")
+	check("gitea-dump-1.zip", false, "zip", false, "gitea-dump-1.zip", "zip")
+	check("gitea-dump-1.zip", false, "tar.zst", true, "gitea-dump-1.tar.zst", "tar.zst")
+
+	// the extension tells the type
+	check("backup.zip", true, "zip", false, "backup.zip", "zip")
+	check("backup.tar.gz", true, "zip", false, "backup.tar.gz", "tar.gz")
+	check("backup.tar", true, "zip", false, "backup.tar", "tar")
+	check("backup.tar.gz", true, "tar.gz", true, "backup.tar.gz", "tar.gz")
+
+	// without a known extension, the type is appended
+	check("backup", true, "zip", false, "backup.zip", "zip")
+	check("backup.2024", true, "tar.xz", true, "backup.2024.tar.xz", "tar.xz")
+
+	_, _, err := dumpFileNameAndType("backup.zip", true, "tar.gz", true)
+	assert.ErrorContains(t, err, "contradicts --type tar.gz")
+}
+
+func TestAddDumpManifest(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := &dumpArchiveWriter{}
+	assert.NoError(t, w.create(archiver.NewTar(), buf, 0, false))
+	manifest := &dumpManifest{GiteaVersion: "1.22.0", DBVersion: 271, Included: []string{}, Excluded: []string{}}
+	manifest.include("database")
+	manifest.exclude("repo-archives")
+	assert.NoError(t, addDumpManifest(w, t.TempDir(), manifest, false))
+	assert.NoError(t, w.Close())
+
+	r := archiver.NewTar()
+	assert.NoError(t, r.Open(buf, 0))
+	f, err := r.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, dumpManifestName, f.Name())
+	var read dumpManifest
+	assert.NoError(t, json.NewDecoder(f).Decode(&read))
+	assert.Equal(t, "1.22.0", read.GiteaVersion)
+	assert.EqualValues(t, 271, read.DBVersion)
+	assert.Equal(t, []string{"database"}, read.Included)
+	assert.Equal(t, []string{"repo-archives"}, read.Excluded)
+	assert.NoError(t, r.Close())
+}
+
+func TestDumpEncryptRecipients(t *testing.T) {
+	// the dump is written through the encryption when the archive is closed
+	encryptTar := func(recipients []age.Recipient) *bytes.Buffer {
+		buf := &bytes.Buffer{}
+		enc, err := age.Encrypt(buf, recipients...)
+		assert.NoError(t, err)
+		w := &dumpArchiveWriter{encWriter: enc}
+		assert.NoError(t, w.create(archiver.NewTar(), enc, 0, false))
+		assert.NoError(t, addDumpManifest(w, t.TempDir(), &dumpManifest{Encrypted: true}, false))
+		assert.NoError(t, w.Close())
+		return buf
+	}
+	decryptedManifest := func(buf *bytes.Buffer, identity age.Identity) *dumpManifest {
+		dec, err := age.Decrypt(buf, identity)
+		if !assert.NoError(t, err) {
+			return nil
+		}
+		r := archiver.NewTar()
+		assert.NoError(t, r.Open(dec, 0))
+		defer r.Close()
+		f, err := r.Read()
+		assert.NoError(t, err)
+		var manifest dumpManifest
+		assert.NoError(t, json.NewDecoder(f).Decode(&manifest))
+		return &manifest
+	}
+
+	passphraseFile := filepath.Join(t.TempDir(), "passphrase")
+	assert.NoError(t, os.WriteFile(passphraseFile, []byte("correct horse\r\nignored\n"), 0o600))
+	recipients, err := dumpEncryptRecipients(nil, passphraseFile)
+	assert.NoError(t, err)
+	identity, err := age.NewScryptIdentity("correct horse")
+	assert.NoError(t, err)
+	manifest := decryptedManifest(encryptTar(recipients), identity)
+	if assert.NotNil(t, manifest) {
+		assert.True(t, manifest.Encrypted)
+	}
+
+	key, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+	recipients, err = dumpEncryptRecipients([]string{key.Recipient().String()}, "")
+	assert.NoError(t, err)
+	assert.NotNil(t, decryptedManifest(encryptTar(recipients), key))
+
+	_, err = dumpEncryptRecipients(nil, "")
+	assert.ErrorContains(t, err, "either --recipient or --passphrase-file")
+	_, err = dumpEncryptRecipients([]string{key.Recipient().String()}, passphraseFile)
+	assert.ErrorContains(t, err, "either --recipient or --passphrase-file")
+	_, err = dumpEncryptRecipients([]string{"age1invalid"}, "")
+	assert.ErrorContains(t, err, "invalid --recipient")
+	assert.NoError(t, os.WriteFile(passphraseFile, []byte("\n"), 0o600))
+	_, err = dumpEncryptRecipients(nil, passphraseFile)
+	assert.ErrorContains(t, err, "is empty")
+}
+
+func TestDumpParallelRepos(t *testing.T) {
+	root := t.TempDir()
+	for _, repo := range []string{"user1/repo1.git", "user1/repo2.git", "user2/repo1.git", "org3/repo3.git"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(root, repo, "objects"), os.ModePerm))
+		assert.NoError(t, os.WriteFile(filepath.Join(root, repo, "HEAD"), []byte("ref: refs/heads/main\n"), 0o644))
+		assert.NoError(t, os.WriteFile(filepath.Join(root, repo, "objects", "pack"), bytes.Repeat([]byte(repo), dumpBufferedFileSize/8), 0o644))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "README"), []byte("not a repository"), 0o644))
+
+	dumpRepos := func(workers int) map[string]string {
+		buf := &bytes.Buffer{}
+		w := &dumpArchiveWriter{excludeGlobs: []string{"repos/org3"}}
+		assert.NoError(t, w.create(archiver.NewTar(), buf, 0, false))
+		assert.NoError(t, addRepositories(w, "repos", root, nil, false, workers))
+		assert.NoError(t, w.Close())
+
+		entries := map[string]string{}
+		r := archiver.NewTar()
+		assert.NoError(t, r.Open(buf, 0))
+		for {
+			f, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+			name := f.Header.(*tar.Header).Name
+			content, err := io.ReadAll(f)
+			assert.NoError(t, err)
+			entries[name] = string(content)
+		}
+		assert.NoError(t, r.Close())
+		return entries
+	}
+
+	sequential := dumpRepos(1)
+	assert.Contains(t, sequential, "repos/user2/repo1.git/objects/pack")
+	assert.Contains(t, sequential, "repos/README")
+	assert.NotContains(t, sequential, "repos/org3")
+	assert.Equal(t, sequential, dumpRepos(3))
+}
@@ -6,6 +6,7 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -88,6 +89,14 @@ var (
 				Aliases: []string{"dest-dir"},
 				Usage:   "Extract to the specified directory",
 			},
+			&cli.StringSliceFlag{
+				Name:  "file",
+				Usage: "Pattern of the files to extract, it can be used together with the patterns in the arguments",
+			},
+			&cli.BoolFlag{
+				Name:  "stdout",
+				Usage: "Write the only matched file to stdout instead of extracting it, it's an error if more than one file matches",
+			},
 		},
 	}
 
@@ -103,7 +112,7 @@ type assetFile struct {
 func initEmbeddedExtractor(c *cli.Context) error {
 	setupConsoleLogger(log.ERROR, log.CanColorStderr, os.Stderr)
 
-	patterns, err := compileCollectPatterns(c.Args().Slice())
+	patterns, err := compileCollectPatterns(append(c.Args().Slice(), c.StringSlice("file")...))
 	if err != nil {
 		return err
 	}
@@ -156,6 +165,11 @@ func runViewDo(c *cli.Context) error {
 		return err
 	}
 
+	return writeSingleMatchedAsset(os.Stdout)
+}
+
+// writeSingleMatchedAsset writes the content of the only matched asset file, it's an error if none or more than one file matched
+func writeSingleMatchedAsset(out io.Writer) error {
 	if len(matchedAssetFiles) == 0 {
 		return fmt.Errorf("no files matched the given pattern")
 	} else if len(matchedAssetFiles) > 1 {
@@ -167,7 +181,7 @@ func runViewDo(c *cli.Context) error {
 		return fmt.Errorf("%s: %w", matchedAssetFiles[0].path, err)
 	}
 
-	if _, err = os.Stdout.Write(data); err != nil {
+	if _, err = out.Write(data); err != nil {
 		return fmt.Errorf("%s: %w", matchedAssetFiles[0].path, err)
 	}
 
@@ -179,10 +193,17 @@ func runExtractDo(c *cli.Context) error {
 		return err
 	}
 
-	if c.NArg() == 0 {
+	if c.NArg() == 0 && len(c.StringSlice("file")) == 0 {
 		return fmt.Errorf("a list of pattern of files to extract is mandatory (e.g. '**' for all)")
 	}
 
+	if c.Bool("stdout") {
+		if c.IsSet("destination") || c.Bool("custom") || c.Bool("overwrite") || c.Bool("rename") {
+			return fmt.Errorf("--stdout can't be used with --destination, --custom, --overwrite or --rename")
+		}
+		return writeSingleMatchedAsset(os.Stdout)
+	}
+
 	destdir := "."
 
 	if c.IsSet("destination") {
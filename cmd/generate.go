@@ -5,10 +5,15 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"code.gitea.io/gitea/modules/generate"
+	"code.gitea.io/gitea/modules/git"
+	repo_module "code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
 
 	"github.com/mattn/go-isatty"
 	"github.com/urfave/cli/v2"
@@ -21,6 +26,35 @@ var (
 		Usage: "Command line interface for running generators",
 		Subcommands: []*cli.Command{
 			subcmdSecret,
+			subcmdCheckSecret,
+			subcmdGenerateHook,
+			subcmdGenerateActionsRunnerConfig,
+			subcmdGenerateEnvToIni,
+		},
+	}
+
+	subcmdCheckSecret = &cli.Command{
+		Name:      "check-secret",
+		Usage:     "Check whether a secret in the config file looks valid, without printing it",
+		ArgsUsage: "INTERNAL_TOKEN|JWT_SECRET|LFS_JWT_SECRET|SECRET_KEY",
+		Description: `Read the secret from the config file (set by the global '--config' flag), or from the file of its "_URI" option,
+and check its format and length, a JWT (like a generated INTERNAL_TOKEN) is checked to be complete.
+It helps to find the truncated or malformed secrets pasted into the config file. The exit code is 1 if the secret looks invalid.`,
+		Action: runCheckSecret,
+	}
+
+	subcmdGenerateHook = &cli.Command{
+		Name:      "hook",
+		Usage:     "Print a hook script which Gitea writes into the repositories",
+		ArgsUsage: "pre-receive|update|post-receive|proc-receive",
+		Description: `Print the hook script "hooks/<name>.d/gitea" (or "hooks/<name>" with --delegate) exactly like Gitea writes it into the repositories,
+with the paths of the current config (set by the global '--config' flag). The proc-receive hook is only available with Git >= 2.29.`,
+		Action: runGenerateHook,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "delegate",
+				Usage: `Print "hooks/<name>" which runs all the scripts in "hooks/<name>.d" instead`,
+			},
 		},
 	}
 
@@ -29,28 +63,49 @@ var (
 		Usage: "Generate a secret token",
 		Subcommands: []*cli.Command{
 			microcmdGenerateInternalToken,
+			microcmdGenerateJwtSecret,
 			microcmdGenerateLfsJwtSecret,
 			microcmdGenerateSecretKey,
 		},
 	}
 
+	microcmdGenerateSecretFlags = []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "write",
+			Usage: "Write the secret into the config file (set by the global '--config' flag) instead of printing it",
+		},
+		&cli.BoolFlag{
+			Name:  "force",
+			Usage: "Overwrite the existing non-empty value in the config file when '--write' is used",
+		},
+	}
+
 	microcmdGenerateInternalToken = &cli.Command{
 		Name:   "INTERNAL_TOKEN",
 		Usage:  "Generate a new INTERNAL_TOKEN",
 		Action: runGenerateInternalToken,
+		Flags:  microcmdGenerateSecretFlags,
+	}
+
+	microcmdGenerateJwtSecret = &cli.Command{
+		Name:   "JWT_SECRET",
+		Usage:  "Generate a new JWT_SECRET",
+		Action: runGenerateJwtSecret,
+		Flags:  microcmdGenerateSecretFlags,
 	}
 
 	microcmdGenerateLfsJwtSecret = &cli.Command{
-		Name:    "JWT_SECRET",
-		Aliases: []string{"LFS_JWT_SECRET"},
-		Usage:   "Generate a new JWT_SECRET",
-		Action:  runGenerateLfsJwtSecret,
+		Name:   "LFS_JWT_SECRET",
+		Usage:  "Generate a new LFS_JWT_SECRET",
+		Action: runGenerateLfsJwtSecret,
+		Flags:  microcmdGenerateSecretFlags,
 	}
 
 	microcmdGenerateSecretKey = &cli.Command{
 		Name:   "SECRET_KEY",
 		Usage:  "Generate a new SECRET_KEY",
 		Action: runGenerateSecretKey,
+		Flags:  microcmdGenerateSecretFlags,
 	}
 )
 
@@ -59,14 +114,15 @@ func runGenerateInternalToken(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	return outputSecret(c, "security", "INTERNAL_TOKEN", internalToken)
+}
 
-	fmt.Printf("%s", internalToken)
-
-	if isatty.IsTerminal(os.Stdout.Fd()) {
-		fmt.Printf("\n")
+func runGenerateJwtSecret(c *cli.Context) error {
+	JWTSecretBase64, err := generate.NewJwtSecretBase64()
+	if err != nil {
+		return err
 	}
-
-	return nil
+	return outputSecret(c, "oauth2", "JWT_SECRET", JWTSecretBase64)
 }
 
 func runGenerateLfsJwtSecret(c *cli.Context) error {
@@ -74,27 +130,123 @@ func runGenerateLfsJwtSecret(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	return outputSecret(c, "server", "LFS_JWT_SECRET", JWTSecretBase64)
+}
 
-	fmt.Printf("%s", JWTSecretBase64)
+func runGenerateSecretKey(c *cli.Context) error {
+	secretKey, err := generate.NewSecretKey()
+	if err != nil {
+		return err
+	}
+	return outputSecret(c, "security", "SECRET_KEY", secretKey)
+}
 
-	if isatty.IsTerminal(os.Stdout.Fd()) {
-		fmt.Printf("\n")
+func runGenerateHook(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("the name of the hook is required, it should be one of: pre-receive, update, post-receive, proc-receive")
 	}
 
-	return nil
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	// the "generate" command doesn't load the config by default, but the hook scripts contain the paths from it
+	args, err := argWorkPathAndCustomConf(c)
+	if err != nil {
+		return err
+	}
+	setting.InitWorkPathAndCommonConfig(os.Getenv, args)
+	if err = git.InitSimple(ctx); err != nil {
+		return err
+	}
+	// set it like git.InitFull does, but without changing the git config
+	git.SupportProcReceive = git.CheckGitVersionAtLeast("2.29") == nil
+
+	hookTpl, giteaHookTpl, err := repo_module.HookScripts(c.Args().First())
+	if err != nil {
+		return err
+	}
+	script := giteaHookTpl
+	if c.Bool("delegate") || script == "" {
+		script = hookTpl
+	}
+	_, err = fmt.Fprint(c.App.Writer, script)
+	return err
 }
 
-func runGenerateSecretKey(c *cli.Context) error {
-	secretKey, err := generate.NewSecretKey()
+// checkedSecret is a secret which can be checked by "check-secret"
+type checkedSecret struct {
+	section string
+	check   func(string) error
+}
+
+var checkedSecrets = map[string]checkedSecret{
+	"INTERNAL_TOKEN": {"security", generate.CheckInternalToken},
+	"JWT_SECRET":     {"oauth2", generate.CheckJwtSecretBase64},
+	"LFS_JWT_SECRET": {"server", generate.CheckJwtSecretBase64},
+	"SECRET_KEY":     {"security", generate.CheckSecretKey},
+}
+
+func runCheckSecret(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("the name of the secret is required, it should be one of: INTERNAL_TOKEN, JWT_SECRET, LFS_JWT_SECRET, SECRET_KEY")
+	}
+	key := strings.ToUpper(c.Args().First())
+	secret, ok := checkedSecrets[key]
+	if !ok {
+		return fmt.Errorf("unknown secret %q, it should be one of: INTERNAL_TOKEN, JWT_SECRET, LFS_JWT_SECRET, SECRET_KEY", c.Args().First())
+	}
+
+	// the "generate" command doesn't load the config by default, only read the config file without loading the settings,
+	// otherwise Gitea would generate the missing or invalid secrets like it does on start
+	args, err := argWorkPathAndCustomConf(c)
 	if err != nil {
 		return err
 	}
+	setting.InitWorkPathAndCfgProvider(os.Getenv, args)
+
+	value, file, err := setting.ReadSecret(setting.CfgProvider.Section(secret.section), key+"_URI", key)
+	if err != nil {
+		return fmt.Errorf("[%s] %s: %w", secret.section, key, err)
+	}
+	source := fmt.Sprintf("config file %q", setting.CustomConf)
+	if file != "" {
+		source = fmt.Sprintf("file %q", file)
+	}
+	if err = secret.check(value); err != nil {
+		return cli.Exit(fmt.Sprintf("[%s] %s from %s looks invalid: %v", secret.section, key, source, err), 1)
+	}
+	_, _ = fmt.Fprintf(c.App.Writer, "[%s] %s from %s looks valid\n", secret.section, key, source)
+	return nil
+}
 
-	fmt.Printf("%s", secretKey)
+// outputSecret prints the secret, or writes it into the config file if "--write" is used
+func outputSecret(c *cli.Context, section, key, secret string) error {
+	if !c.Bool("write") {
+		fmt.Printf("%s", secret)
 
-	if isatty.IsTerminal(os.Stdout.Fd()) {
-		fmt.Printf("\n")
+		if isatty.IsTerminal(os.Stdout.Fd()) {
+			fmt.Printf("\n")
+		}
+		return nil
 	}
 
+	// the "generate" command doesn't load the config by default, only find the config file when writing
+	args, err := argWorkPathAndCustomConf(c)
+	if err != nil {
+		return err
+	}
+	setting.InitWorkPathAndCfgProvider(os.Getenv, args)
+
+	// the secret can't be in the config file if it is loaded from the "_URI" option, otherwise Gitea refuses to start
+	if uri := setting.ConfigSectionKeyString(setting.CfgProvider.Section(section), key+"_URI"); uri != "" {
+		return fmt.Errorf("[%s] %s_URI is set in config file %q, the secret should be written to %s", section, key, setting.CustomConf, uri)
+	}
+	err = setting.UpdateConfigFileKey(setting.CustomConf, section, key, secret, c.Bool("force"))
+	if errors.Is(err, setting.ErrConfigKeyHasValue) {
+		return fmt.Errorf("[%s] %s already has a value in config file %q, use --force to overwrite it", section, key, setting.CustomConf)
+	} else if err != nil {
+		return fmt.Errorf("failed to write [%s] %s to config file %q: %w", section, key, setting.CustomConf, err)
+	}
+	fmt.Printf("Updated [%s] %s in %s\n", section, key, setting.CustomConf)
 	return nil
 }
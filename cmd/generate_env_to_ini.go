@@ -0,0 +1,97 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/urfave/cli/v2"
+)
+
+var subcmdGenerateEnvToIni = &cli.Command{
+	Name:  "env-to-ini",
+	Usage: "Print the config options set by the environment variables",
+	Description: `Print the ini sections and keys which the "GITEA__SECTION__KEY" (and "GITEA__SECTION__KEY__FILE") environment
+variables of the current environment map to, like environment-to-ini and GITEA_ENV_CONFIG do, nothing is written.
+With --out, the config file (set by the global '--config' flag) merged with the environment variables is written to the file,
+or printed with --dry-run, so it can be compared with the config file.`,
+	Action: runGenerateEnvToIni,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "prefix",
+			Value: setting.EnvConfigKeyPrefixGitea,
+			Usage: "The prefix of the environment variables",
+		},
+		&cli.StringFlag{
+			Name:  "out",
+			Usage: "Write the config file merged with the environment variables to the file",
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Print the merged config instead of writing it to the file of --out",
+		},
+	},
+}
+
+func runGenerateEnvToIni(c *cli.Context) error {
+	prefix := c.String("prefix")
+	if prefix == "" {
+		return errors.New("the prefix can't be empty")
+	}
+
+	// the config system may clear the environment variables, so get a copy first,
+	// sort it to write the keys in a stable order which is easy to compare
+	var envs []string
+	for _, env := range os.Environ() {
+		if strings.HasPrefix(env, prefix) {
+			envs = append(envs, env)
+		}
+	}
+	sort.Strings(envs)
+	if len(envs) == 0 {
+		_, _ = fmt.Fprintf(c.App.ErrWriter, "No environment variable starts with %q\n", prefix)
+	}
+
+	if !c.IsSet("out") {
+		// an empty config from "file" writes the keys like the config files: "key = value"
+		cfg, err := setting.NewConfigProviderFromFile("")
+		if err != nil {
+			return err
+		}
+		setting.EnvironmentToConfigWithPrefix(cfg, prefix, envs)
+		_, err = cfg.WriteTo(c.App.Writer)
+		return err
+	}
+
+	// the "generate" command doesn't load the config by default, only read the config file without loading the settings
+	args, err := argWorkPathAndCustomConf(c)
+	if err != nil {
+		return err
+	}
+	setting.InitWorkPathAndCfgProvider(os.Getenv, args)
+	cfg, err := setting.NewConfigProviderFromFile(setting.CustomConf)
+	if err != nil {
+		return fmt.Errorf("unable to load config file %q: %w", setting.CustomConf, err)
+	}
+	if cfg.IsLoadedFromEmpty() {
+		_, _ = fmt.Fprintf(c.App.ErrWriter, "Config file %q does not exist, only the environment variables are used\n", setting.CustomConf)
+	}
+	setting.EnvironmentToConfigWithPrefix(cfg, prefix, envs)
+
+	if c.Bool("dry-run") {
+		_, err = cfg.WriteTo(c.App.Writer)
+		return err
+	}
+	if err = cfg.SaveTo(c.String("out")); err != nil {
+		return fmt.Errorf("unable to write %q: %w", c.String("out"), err)
+	}
+	_, _ = fmt.Fprintf(c.App.Writer, "Wrote config %q merged with %d environment variable(s) to %q\n", setting.CustomConf, len(envs), c.String("out"))
+	return nil
+}
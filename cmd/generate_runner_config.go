@@ -0,0 +1,159 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/urfave/cli/v2"
+)
+
+// defaultRunnerLabels are the labels of act_runner when none is configured
+var defaultRunnerLabels = []string{
+	"ubuntu-latest:docker://gitea/runner-images:ubuntu-latest",
+	"ubuntu-22.04:docker://gitea/runner-images:ubuntu-22.04",
+	"ubuntu-20.04:docker://gitea/runner-images:ubuntu-20.04",
+}
+
+var subcmdGenerateActionsRunnerConfig = &cli.Command{
+	Name:  "actions-runner-config",
+	Usage: "Print a config file of act_runner for this instance",
+	Description: `Print a commented config file skeleton of act_runner with the given labels. The ROOT_URL of the config
+(set by the global '--config' flag) is filled in the command which registers the runner, the instance URL isn't part
+of the config file of act_runner.`,
+	Action: runGenerateActionsRunnerConfig,
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "labels",
+			Usage: `The labels of the runner like "ubuntu-latest:docker://node:16-bullseye" or "linux:host", comma separated or repeated, defaults to the labels of act_runner`,
+		},
+		&cli.IntFlag{
+			Name:  "capacity",
+			Value: 1,
+			Usage: "The number of the jobs the runner runs at the same time",
+		},
+	},
+}
+
+var runnerConfigTemplate = template.Must(template.New("").Parse(`# The config file of act_runner for {{.AppURL}}, generated by "gitea generate actions-runner-config".
+#
+# Register the runner with a token of "gitea actions generate-runner-token" (or of the runners settings page):
+#
+#   act_runner register --no-interactive --config config.yaml --instance {{.AppURL}} --token <registration_token>
+#
+# The instance URL and the runner token are stored in the file of "runner.file", not in this file.
+# Then start the runner: act_runner daemon --config config.yaml
+# All the options are described in the example config of act_runner: act_runner generate-config
+
+log:
+  # The level of the logs, one of trace, debug, info, warn, error and fatal.
+  level: info
+
+runner:
+  # Where the registration result is stored, it contains the instance URL and the runner token.
+  file: .runner
+  # The number of the jobs the runner runs at the same time.
+  capacity: {{.Capacity}}
+  # The extra environment variables of the jobs.
+  envs: {}
+  # The extra environment variables of the jobs read from the file, it is ignored if it doesn't exist.
+  env_file: .env
+  # The timeout of a job, the job is cancelled after it.
+  timeout: 3h
+  # Whether to skip the verification of the TLS certificate of the instance.
+  insecure: false
+  # The timeout and the interval of fetching a new job from the instance.
+  fetch_timeout: 5s
+  fetch_interval: 2s
+  # The labels tell which jobs the runner runs ("runs-on" of the workflows) and how: "<label>:docker://<image>"
+  # runs the job in a container of the image, "<label>:host" runs it directly on the host.
+  # They override the labels given when registering the runner.
+  labels:
+{{- range .Labels}}
+    - {{printf "%q" .}}
+{{- end}}
+
+cache:
+  # Whether to enable the cache server of the actions/cache action.
+  enabled: true
+  # Where the cache is stored, defaults to $HOME/.cache/actcache.
+  dir: ""
+  # The host and the port the job containers reach the cache server with, they are detected if they are empty.
+  host: ""
+  port: 0
+
+container:
+  # The docker network of the job containers, a new network is created for each job if it is empty.
+  network: ""
+  # Whether to run the job containers in privileged mode (needed by docker in docker).
+  privileged: false
+  # The extra options of the job containers, like "--add-host=my.gitea.example:host-gateway".
+  options:
+  # The parent directory of the working directory of the jobs in the containers.
+  workdir_parent:
+  # The volumes (and bind mounts) the workflows can mount into the job containers, glob patterns are supported.
+  valid_volumes: []
+  # The docker daemon, detected if it is empty, "-" means the job containers can't access it.
+  docker_host: ""
+  # Whether to always pull the latest version of the images.
+  force_pull: true
+
+host:
+  # The parent directory of the working directory of the jobs run on the host, defaults to $HOME/.cache/act.
+  workdir_parent:
+`))
+
+// checkRunnerLabel checks the format of an act_runner label: "<name>", "<name>:host" or "<name>:docker://<image>"
+func checkRunnerLabel(label string) error {
+	name, schema, _ := strings.Cut(label, ":")
+	if name == "" || strings.ContainsAny(label, " \t\r\n\"") {
+		return fmt.Errorf("invalid label %q", label)
+	}
+	if schema == "" || schema == "host" || (strings.HasPrefix(schema, "docker://") && schema != "docker://") {
+		return nil
+	}
+	return fmt.Errorf("invalid label %q, it should be <name>:host or <name>:docker://<image>", label)
+}
+
+func runGenerateActionsRunnerConfig(c *cli.Context) error {
+	if c.Int("capacity") < 1 {
+		return fmt.Errorf("invalid --capacity %d, it should be 1 or more", c.Int("capacity"))
+	}
+	var labels []string
+	for _, label := range c.StringSlice("labels") {
+		for _, label := range strings.Split(label, ",") {
+			if label = strings.TrimSpace(label); label == "" {
+				continue
+			}
+			if err := checkRunnerLabel(label); err != nil {
+				return err
+			}
+			labels = append(labels, label)
+		}
+	}
+	if len(labels) == 0 {
+		labels = defaultRunnerLabels
+	}
+
+	// the "generate" command doesn't load the config by default, but the ROOT_URL is needed
+	args, err := argWorkPathAndCustomConf(c)
+	if err != nil {
+		return err
+	}
+	setting.InitWorkPathAndCommonConfig(os.Getenv, args)
+	if !setting.Actions.Enabled {
+		_, _ = fmt.Fprintln(c.App.ErrWriter, "Warning: Actions are disabled, set ENABLED=true in the [actions] section of the config to use the runner")
+	}
+
+	return runnerConfigTemplate.Execute(c.App.Writer, map[string]any{
+		"AppURL":   strings.TrimSuffix(setting.AppURL, "/"),
+		"Capacity": c.Int("capacity"),
+		"Labels":   labels,
+	})
+}
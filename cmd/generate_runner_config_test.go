@@ -0,0 +1,46 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestCheckRunnerLabel(t *testing.T) {
+	for _, label := range []string{"linux", "linux:host", "ubuntu-latest:docker://node:16-bullseye"} {
+		assert.NoError(t, checkRunnerLabel(label), label)
+	}
+	for _, label := range []string{":host", "linux:docker://", "linux:vm", "with space:host", `quote"d:host`} {
+		assert.Error(t, checkRunnerLabel(label), label)
+	}
+}
+
+func TestRunnerConfigTemplate(t *testing.T) {
+	out := &strings.Builder{}
+	assert.NoError(t, runnerConfigTemplate.Execute(out, map[string]any{
+		"AppURL":   "https://gitea.example.com",
+		"Capacity": 4,
+		"Labels":   []string{"linux:host", "ubuntu-latest:docker://node:16-bullseye"},
+	}))
+	assert.Contains(t, out.String(), "--instance https://gitea.example.com --token <registration_token>")
+
+	// the config is valid YAML for act_runner
+	var config struct {
+		Runner struct {
+			Capacity int      `yaml:"capacity"`
+			Labels   []string `yaml:"labels"`
+		} `yaml:"runner"`
+		Cache struct {
+			Enabled bool `yaml:"enabled"`
+		} `yaml:"cache"`
+	}
+	assert.NoError(t, yaml.Unmarshal([]byte(out.String()), &config))
+	assert.Equal(t, 4, config.Runner.Capacity)
+	assert.Equal(t, []string{"linux:host", "ubuntu-latest:docker://node:16-bullseye"}, config.Runner.Labels)
+	assert.True(t, config.Cache.Enabled)
+}
@@ -51,6 +51,11 @@ var (
 			&cli.BoolFlag{
 				Name: "debug",
 			},
+			&cli.BoolFlag{
+				Name:    "verbose",
+				Usage:   "Report the update of each reference and whether it is accepted or rejected (with the reason) to the pusher",
+				EnvVars: []string{"GITEA_HOOK_VERBOSE"},
+			},
 		},
 	}
 	subcmdHookUpdate = &cli.Command{
@@ -161,6 +166,39 @@ func (n *nilWriter) WriteString(s string) (int, error) {
 	return len(s), nil
 }
 
+// hookPreReceive is private.HookPreReceive, it is replaced by the tests
+var hookPreReceive = private.HookPreReceive
+
+// reportRefUpdate writes the decision about the update of a reference to w (stderr, which git relays to the pusher)
+func reportRefUpdate(w io.Writer, oldCommitID, newCommitID string, refFullName git.RefName, decision string) {
+	_, _ = fmt.Fprintf(w, "Gitea: %s %s -> %s: %s\n", refFullName, oldCommitID, newCommitID, decision)
+}
+
+// checkRefUpdateVerbose checks the update of a reference on its own and reports the decision about it to w,
+// the returned response has an error if the update is rejected
+func checkRefUpdateVerbose(ctx context.Context, w io.Writer, username, reponame string, hookOptions private.HookOptions, supportProcReceive bool,
+	oldCommitID, newCommitID string, refFullName git.RefName,
+) private.ResponseExtra {
+	if !supportProcReceive && !refFullName.IsBranch() && !refFullName.IsTag() {
+		reportRefUpdate(w, oldCommitID, newCommitID, refFullName, "accepted, only branches and tags are checked")
+		return private.ResponseExtra{}
+	}
+	hookOptions.OldCommitIDs = []string{oldCommitID}
+	hookOptions.NewCommitIDs = []string{newCommitID}
+	hookOptions.RefFullNames = []git.RefName{refFullName}
+	extra := hookPreReceive(ctx, username, reponame, hookOptions)
+	if extra.HasError() {
+		reason := extra.UserMsg
+		if reason == "" {
+			reason = "Internal Server Error (no specific error)"
+		}
+		reportRefUpdate(w, oldCommitID, newCommitID, refFullName, "rejected: "+reason)
+		return extra
+	}
+	reportRefUpdate(w, oldCommitID, newCommitID, refFullName, "accepted")
+	return extra
+}
+
 func runHookPreReceive(c *cli.Context) error {
 	if isInternal, _ := strconv.ParseBool(os.Getenv(repo_module.EnvIsInternal)); isInternal {
 		return nil
@@ -225,12 +263,11 @@ Gitea or set your environment appropriately.`, "")
 		supportProcReceive = true
 	}
 
-	for scanner.Scan() {
-		// TODO: support news feeds for wiki
-		if isWiki {
-			continue
-		}
+	verbose := c.Bool("verbose")
+	rejected := 0
+	var firstRejection private.ResponseExtra
 
+	for scanner.Scan() {
 		fields := bytes.Fields(scanner.Bytes())
 		if len(fields) != 3 {
 			continue
@@ -239,9 +276,28 @@ Gitea or set your environment appropriately.`, "")
 		oldCommitID := string(fields[0])
 		newCommitID := string(fields[1])
 		refFullName := git.RefName(fields[2])
+
+		// TODO: support news feeds for wiki
+		if isWiki {
+			if verbose {
+				reportRefUpdate(os.Stderr, oldCommitID, newCommitID, refFullName, "accepted, the wiki is not checked")
+			}
+			continue
+		}
 		total++
 		lastline++
 
+		if verbose {
+			// check each reference on its own to report the decision about every one of them, also after a rejection
+			if extra := checkRefUpdateVerbose(ctx, os.Stderr, username, reponame, hookOptions, supportProcReceive, oldCommitID, newCommitID, refFullName); extra.HasError() {
+				if rejected == 0 {
+					firstRejection = extra
+				}
+				rejected++
+			}
+			continue
+		}
+
 		// If the ref is a branch or tag, check if it's protected
 		// if supportProcReceive all ref should be checked because
 		// permission check was delayed
@@ -258,7 +314,7 @@ Gitea or set your environment appropriately.`, "")
 				hookOptions.OldCommitIDs = oldCommitIDs
 				hookOptions.NewCommitIDs = newCommitIDs
 				hookOptions.RefFullNames = refFullNames
-				extra := private.HookPreReceive(ctx, username, reponame, hookOptions)
+				extra := hookPreReceive(ctx, username, reponame, hookOptions)
 				if extra.HasError() {
 					return fail(ctx, extra.UserMsg, "HookPreReceive(batch) failed: %v", extra.Error)
 				}
@@ -274,6 +330,14 @@ Gitea or set your environment appropriately.`, "")
 		}
 	}
 
+	if verbose {
+		_, _ = fmt.Fprintf(os.Stderr, "Gitea: checked %d references, %d rejected\n", total, rejected)
+		if rejected > 0 {
+			return fail(ctx, firstRejection.UserMsg, "HookPreReceive(verbose) failed: %v", firstRejection.Error)
+		}
+		return nil
+	}
+
 	if count > 0 {
 		hookOptions.OldCommitIDs = oldCommitIDs[:count]
 		hookOptions.NewCommitIDs = newCommitIDs[:count]
@@ -281,7 +345,7 @@ Gitea or set your environment appropriately.`, "")
 
 		fmt.Fprintf(out, " Checking %d references\n", count)
 
-		extra := private.HookPreReceive(ctx, username, reponame, hookOptions)
+		extra := hookPreReceive(ctx, username, reponame, hookOptions)
 		if extra.HasError() {
 			return fail(ctx, extra.UserMsg, "HookPreReceive(last) failed: %v", extra.Error)
 		}
@@ -7,9 +7,14 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
+	"net/http"
 	"strings"
 	"testing"
 
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/private"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -40,3 +45,39 @@ func TestPktLine(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, []byte("0007a\nb"), w.Bytes())
 }
+
+func TestCheckRefUpdateVerbose(t *testing.T) {
+	defer func(fn func(context.Context, string, string, private.HookOptions) private.ResponseExtra) {
+		hookPreReceive = fn
+	}(hookPreReceive)
+	var checked []git.RefName
+	hookPreReceive = func(ctx context.Context, ownerName, repoName string, opts private.HookOptions) private.ResponseExtra {
+		checked = append(checked, opts.RefFullNames...)
+		switch opts.RefFullNames[0] {
+		case "refs/heads/protected":
+			return private.ResponseExtra{StatusCode: http.StatusForbidden, UserMsg: "branch protected is protected", Error: errors.New("forbidden")}
+		case "refs/tags/broken":
+			return private.ResponseExtra{StatusCode: http.StatusInternalServerError, Error: errors.New("internal")}
+		}
+		return private.ResponseExtra{}
+	}
+
+	out := &strings.Builder{}
+	check := func(refFullName git.RefName, supportProcReceive bool) private.ResponseExtra {
+		return checkRefUpdateVerbose(context.Background(), out, "user2", "repo1", private.HookOptions{}, supportProcReceive, "aaa", "bbb", refFullName)
+	}
+	assert.NoError(t, check("refs/heads/main", false).Error)
+	assert.Equal(t, "branch protected is protected", check("refs/heads/protected", false).UserMsg)
+	assert.Error(t, check("refs/tags/broken", false).Error)
+	// the other references are only checked if the permission check is delayed to proc-receive
+	assert.NoError(t, check("refs/for/main", false).Error)
+	assert.NoError(t, check("refs/for/main", true).Error)
+
+	assert.Equal(t, []git.RefName{"refs/heads/main", "refs/heads/protected", "refs/tags/broken", "refs/for/main"}, checked)
+	assert.Equal(t, `Gitea: refs/heads/main aaa -> bbb: accepted
+Gitea: refs/heads/protected aaa -> bbb: rejected: branch protected is protected
+Gitea: refs/tags/broken aaa -> bbb: rejected: Internal Server Error (no specific error)
+Gitea: refs/for/main aaa -> bbb: accepted, only branches and tags are checked
+Gitea: refs/for/main aaa -> bbb: accepted
+`, out.String())
+}
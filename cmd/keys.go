@@ -4,12 +4,20 @@
 package cmd
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/private"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
 
 	"github.com/urfave/cli/v2"
 )
@@ -45,15 +53,34 @@ var CmdKeys = &cli.Command{
 			Value:   "",
 			Usage:   "Base64 encoded content of the SSH key provided to the SSH Server (requires type to be provided too)",
 		},
+		&cli.StringFlag{
+			Name:    "fingerprint",
+			Aliases: []string{"f"},
+			Value:   "",
+			Usage:   "SHA256 fingerprint of the SSH key provided to the SSH Server, e.g. SHA256:... (only with --check-only)",
+		},
+		&cli.BoolFlag{
+			Name:  "check-only",
+			Usage: "Print nothing, only exit with 0 if the key is usable by an active user (or is a deploy key) and non-zero otherwise",
+		},
+		&cli.DurationFlag{
+			Name:  "cache-ttl",
+			Value: 0,
+			Usage: "Cache the found authorized key on disk for the duration (eg: 10s) to avoid querying Gitea for repeated connections, 0 disables the cache",
+		},
 	},
 }
 
 func runKeys(c *cli.Context) error {
-	if !c.IsSet("username") {
+	checkOnly := c.Bool("check-only")
+	if !c.IsSet("username") && !checkOnly {
 		return errors.New("No username provided")
 	}
-	// Check username matches the expected username
-	if strings.TrimSpace(c.String("username")) != strings.TrimSpace(c.String("expected")) {
+	// Check username matches the expected username, the username is optional for checking a key
+	if c.IsSet("username") && strings.TrimSpace(c.String("username")) != strings.TrimSpace(c.String("expected")) {
+		if checkOnly {
+			return cli.Exit("", 1)
+		}
 		return nil
 	}
 
@@ -63,7 +90,15 @@ func runKeys(c *cli.Context) error {
 		content = fmt.Sprintf("%s %s", strings.TrimSpace(c.String("type")), strings.TrimSpace(c.String("content")))
 	}
 
-	if content == "" {
+	fingerprint := strings.TrimSpace(c.String("fingerprint"))
+	if fingerprint != "" && !checkOnly {
+		return errors.New("The fingerprint can only be used with --check-only")
+	}
+
+	if content == "" && fingerprint == "" {
+		if checkOnly {
+			return errors.New("No key type and content or fingerprint provided")
+		}
 		return errors.New("No key type and content provided")
 	}
 
@@ -72,11 +107,88 @@ func runKeys(c *cli.Context) error {
 
 	setup(ctx, false)
 
+	if checkOnly {
+		// the exit code is the only result, nothing is printed even for an unusable key
+		if extra := private.CheckPublicKey(ctx, content, fingerprint); extra.Error != nil {
+			return cli.Exit("", 1)
+		}
+		return nil
+	}
+
+	cacheTTL := c.Duration("cache-ttl")
+	cacheFile := ""
+	if cacheTTL > 0 {
+		cacheFile = keysCacheFile(filepath.Join(setting.AppDataPath, "tmp", "keys-cache"), c.String("content"))
+		if authorizedString, ok := readKeysCache(cacheFile, cacheTTL); ok {
+			_, _ = fmt.Fprintln(c.App.Writer, authorizedString)
+			return nil
+		}
+	}
+
 	authorizedString, extra := private.AuthorizedPublicKeyByContent(ctx, content)
 	// do not use handleCliResponseExtra or cli.NewExitError, if it exists immediately, it breaks some tests like Test_CmdKeys
 	if extra.Error != nil {
 		return extra.Error
 	}
-	_, _ = fmt.Fprintln(c.App.Writer, strings.TrimSpace(authorizedString))
+	authorizedString = strings.TrimSpace(authorizedString)
+	if cacheFile != "" {
+		if err := writeKeysCache(cacheFile, authorizedString); err != nil {
+			log.Error("Unable to write the keys cache %q: %v", cacheFile, err)
+		}
+	}
+	_, _ = fmt.Fprintln(c.App.Writer, authorizedString)
 	return nil
 }
+
+// keysCacheFile returns the cache file of the key in the cache directory, the file name is the SHA256 fingerprint of the key,
+// an empty string is returned if the key content can't be decoded
+func keysCacheFile(cacheDir, keyContent string) string {
+	blob, err := base64.StdEncoding.DecodeString(strings.TrimSpace(keyContent))
+	if err != nil || len(blob) == 0 {
+		return ""
+	}
+	fingerprint := sha256.Sum256(blob)
+	return filepath.Join(cacheDir, hex.EncodeToString(fingerprint[:]))
+}
+
+// readKeysCache returns the cached authorized key if the cache file has been written within the ttl
+func readKeysCache(cacheFile string, ttl time.Duration) (string, bool) {
+	if cacheFile == "" {
+		return "", false
+	}
+	fi, err := os.Stat(cacheFile)
+	if err != nil || time.Since(fi.ModTime()) > ttl {
+		return "", false
+	}
+	content, err := os.ReadFile(cacheFile)
+	if err != nil || len(content) == 0 {
+		return "", false
+	}
+	return string(content), true
+}
+
+// writeKeysCache writes the authorized key to the cache file atomically by renaming a temp file,
+// so that the concurrent invocations by sshd never read a partially written file
+func writeKeysCache(cacheFile, authorizedString string) error {
+	if cacheFile == "" || authorizedString == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0o700); err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(cacheFile), filepath.Base(cacheFile)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tmpFile.Close()
+		_ = util.Remove(tmpFile.Name())
+	}()
+	if _, err = tmpFile.WriteString(authorizedString); err != nil {
+		return err
+	}
+	if err = tmpFile.Close(); err != nil {
+		return err
+	}
+	return util.Rename(tmpFile.Name(), cacheFile)
+}
@@ -0,0 +1,36 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeysCache(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "keys-cache")
+	assert.Empty(t, keysCacheFile(cacheDir, "not base64!"))
+
+	cacheFile := keysCacheFile(cacheDir, "AAAAC3NzaC1lZDI1NTE5AAAAIOmVj4ShmEOdWB2pzvL8QNAIrvaJuYUWrOFQgYJ6ePHV")
+	// the same as "ssh-keygen -l -E sha256" but in hex
+	assert.Equal(t, filepath.Join(cacheDir, "b63e0c2ba9d75e4ea114afb60ed4fc36fd1141b11d9d6d2ce7e2f3ad2882029a"), cacheFile)
+
+	_, ok := readKeysCache(cacheFile, time.Minute)
+	assert.False(t, ok)
+
+	assert.NoError(t, writeKeysCache(cacheFile, `command="gitea serv key-1" ssh-ed25519 AAAA`))
+	authorized, ok := readKeysCache(cacheFile, time.Minute)
+	assert.True(t, ok)
+	assert.Equal(t, `command="gitea serv key-1" ssh-ed25519 AAAA`, authorized)
+
+	// the expired cache is ignored
+	old := time.Now().Add(-2 * time.Minute)
+	assert.NoError(t, os.Chtimes(cacheFile, old, old))
+	_, ok = readKeysCache(cacheFile, time.Minute)
+	assert.False(t, ok)
+}
@@ -0,0 +1,143 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"code.gitea.io/gitea/modules/json"
+
+	"github.com/urfave/cli/v2"
+)
+
+// listColumn is a column of the list commands' output
+type listColumn struct {
+	Title string // used by the "text" format
+	Key   string // used by the "csv" header and the "json" object keys
+}
+
+// listFormatter writes the header and rows of the list commands in different formats.
+// The rows are only guaranteed to be written after Flush.
+type listFormatter interface {
+	WriteHeader(columns []listColumn) error
+	WriteRow(values ...any) error
+	Flush() error
+}
+
+var listFormatFlag = &cli.StringFlag{
+	Name:  "format",
+	Value: "text",
+	Usage: "Output format: text, csv or json",
+}
+
+func newListFormatter(format string, out io.Writer) (listFormatter, error) {
+	switch format {
+	case "", "text":
+		return &textListFormatter{w: tabwriter.NewWriter(out, 5, 0, 1, ' ', 0)}, nil
+	case "csv":
+		return &csvListFormatter{w: csv.NewWriter(out)}, nil
+	case "json":
+		return &jsonListFormatter{out: out, rows: []map[string]any{}}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q, it should be one of: text, csv, json", format)
+}
+
+// formatListValue formats a value for the text and csv formats, the lists are comma separated (they are arrays in json)
+func formatListValue(v any) string {
+	if list, ok := v.([]string); ok {
+		return strings.Join(list, ",")
+	}
+	return fmt.Sprint(v)
+}
+
+type textListFormatter struct {
+	w *tabwriter.Writer
+}
+
+func (f *textListFormatter) WriteHeader(columns []listColumn) error {
+	titles := make([]any, 0, len(columns))
+	for _, col := range columns {
+		titles = append(titles, col.Title)
+	}
+	return f.WriteRow(titles...)
+}
+
+func (f *textListFormatter) WriteRow(values ...any) error {
+	for i, v := range values {
+		sep := "\t"
+		if i == len(values)-1 {
+			sep = "\n"
+		}
+		if _, err := fmt.Fprintf(f.w, "%s%s", formatListValue(v), sep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *textListFormatter) Flush() error {
+	return f.w.Flush()
+}
+
+type csvListFormatter struct {
+	w *csv.Writer
+}
+
+func (f *csvListFormatter) WriteHeader(columns []listColumn) error {
+	keys := make([]string, 0, len(columns))
+	for _, col := range columns {
+		keys = append(keys, col.Key)
+	}
+	return f.w.Write(keys)
+}
+
+func (f *csvListFormatter) WriteRow(values ...any) error {
+	record := make([]string, 0, len(values))
+	for _, v := range values {
+		record = append(record, formatListValue(v))
+	}
+	return f.w.Write(record)
+}
+
+func (f *csvListFormatter) Flush() error {
+	f.w.Flush()
+	return f.w.Error()
+}
+
+// jsonListFormatter outputs a JSON array, each row is an object with the column keys
+type jsonListFormatter struct {
+	out     io.Writer
+	columns []listColumn
+	rows    []map[string]any
+}
+
+func (f *jsonListFormatter) WriteHeader(columns []listColumn) error {
+	f.columns = columns
+	return nil
+}
+
+func (f *jsonListFormatter) WriteRow(values ...any) error {
+	if len(values) != len(f.columns) {
+		return fmt.Errorf("expect %d values but got %d", len(f.columns), len(values))
+	}
+	row := make(map[string]any, len(values))
+	for i, v := range values {
+		row[f.columns[i].Key] = v
+	}
+	f.rows = append(f.rows, row)
+	return nil
+}
+
+func (f *jsonListFormatter) Flush() error {
+	bs, err := json.MarshalIndent(f.rows, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(f.out, string(bs))
+	return err
+}
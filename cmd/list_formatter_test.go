@@ -0,0 +1,32 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListFormatter(t *testing.T) {
+	columns := []listColumn{{Title: "ID", Key: "id"}, {Title: "Name", Key: "name"}, {Title: "IsAdmin", Key: "is_admin"}, {Title: "Labels", Key: "labels"}}
+	format := func(format string) string {
+		out := &strings.Builder{}
+		f, err := newListFormatter(format, out)
+		assert.NoError(t, err)
+		assert.NoError(t, f.WriteHeader(columns))
+		assert.NoError(t, f.WriteRow(1, "a, b", true, []string{"x", "y"}))
+		assert.NoError(t, f.WriteRow(2, `c"d`, false, []string{}))
+		assert.NoError(t, f.Flush())
+		return out.String()
+	}
+
+	assert.Equal(t, "ID   Name IsAdmin Labels\n1    a, b true    x,y\n2    c\"d  false   \n", format("text"))
+	assert.Equal(t, "id,name,is_admin,labels\n1,\"a, b\",true,\"x,y\"\n2,\"c\"\"d\",false,\n", format("csv"))
+	assert.JSONEq(t, `[{"id":1,"name":"a, b","is_admin":true,"labels":["x","y"]},{"id":2,"name":"c\"d","is_admin":false,"labels":[]}]`, format("json"))
+
+	_, err := newListFormatter("xml", &strings.Builder{})
+	assert.Error(t, err)
+}
@@ -5,9 +5,11 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	"code.gitea.io/gitea/modules/private"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/mailer"
 
 	"github.com/urfave/cli/v2"
 )
@@ -25,6 +27,20 @@ func runSendMail(c *cli.Context) error {
 	subject := c.String("title")
 	confirmSkiped := c.Bool("force")
 	body := c.String("content")
+	if c.IsSet("body-file") {
+		if c.IsSet("content") {
+			return fmt.Errorf("--content and --body-file can't be used together")
+		}
+		content, err := os.ReadFile(c.String("body-file"))
+		if err != nil {
+			return fmt.Errorf("unable to read the body file: %w", err)
+		}
+		body = string(content)
+	}
+
+	if c.IsSet("to") {
+		return sendTestMails(c, subject, body)
+	}
 
 	if !confirmSkiped {
 		if len(body) == 0 {
@@ -48,3 +64,27 @@ func runSendMail(c *cli.Context) error {
 	_, _ = fmt.Printf("Sent %s email(s) to all users\n", respText)
 	return nil
 }
+
+// sendTestMails sends the message to the addresses by the mailer configured in the config file,
+// it waits for the result of each message, so it is useful to check the mailer settings
+func sendTestMails(c *cli.Context, subject, body string) error {
+	setting.LoadMailerSetting()
+	if setting.MailService == nil {
+		return fmt.Errorf("mail service is not enabled in %q", setting.CustomConf)
+	}
+
+	failed := 0
+	to := c.StringSlice("to")
+	for _, addr := range to {
+		if err := mailer.SendSync(mailer.NewMessage(addr, subject, body)); err != nil {
+			failed++
+			_, _ = fmt.Fprintf(c.App.ErrWriter, "Failed to send the email to %s: %v\n", addr, err)
+			continue
+		}
+		_, _ = fmt.Fprintf(c.App.Writer, "Sent the email to %s\n", addr)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to send %d of %d email(s)", failed, len(to))
+	}
+	return nil
+}
@@ -4,11 +4,15 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
 
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
@@ -23,12 +27,25 @@ func cmdHelp() *cli.Command {
 		Aliases:   []string{"h"},
 		Usage:     "Shows a list of commands or help for one command",
 		ArgsUsage: "[command]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Output the command tree, flags and default configuration as JSON",
+			},
+		},
 		Action: func(c *cli.Context) (err error) {
 			lineage := c.Lineage() // The order is from child to parent: help, doctor, Gitea, {Command:nil}
 			targetCmdIdx := 0
 			if c.Command.Name == "help" {
 				targetCmdIdx = 1
 			}
+			if c.Bool("json") {
+				cmds := c.App.Commands
+				if lineage[targetCmdIdx+1].Command != nil {
+					cmds = []*cli.Command{lineage[targetCmdIdx].Command}
+				}
+				return writeHelpJSON(c, cmds)
+			}
 			if lineage[targetCmdIdx+1].Command != nil {
 				err = cli.ShowCommandHelp(lineage[targetCmdIdx+1], lineage[targetCmdIdx].Command.Name)
 			} else {
@@ -48,12 +65,127 @@ DEFAULT CONFIGURATION:
 	return c
 }
 
+type helpFlagJSON struct {
+	Name     string   `json:"name"`
+	Aliases  []string `json:"aliases,omitempty"`
+	Type     string   `json:"type"`
+	Usage    string   `json:"usage,omitempty"`
+	Default  string   `json:"default,omitempty"`
+	Required bool     `json:"required,omitempty"`
+}
+
+type helpCommandJSON struct {
+	Name        string             `json:"name"`
+	Aliases     []string           `json:"aliases,omitempty"`
+	Usage       string             `json:"usage,omitempty"`
+	Description string             `json:"description,omitempty"`
+	ArgsUsage   string             `json:"args_usage,omitempty"`
+	Hidden      bool               `json:"hidden,omitempty"`
+	Flags       []*helpFlagJSON    `json:"flags,omitempty"`
+	Subcommands []*helpCommandJSON `json:"subcommands,omitempty"`
+}
+
+type helpJSON struct {
+	Name                 string             `json:"name"`
+	Version              string             `json:"version"`
+	Flags                []*helpFlagJSON    `json:"flags"`
+	Commands             []*helpCommandJSON `json:"commands"`
+	DefaultConfiguration struct {
+		AppPath    string `json:"app_path"`
+		WorkPath   string `json:"work_path"`
+		CustomPath string `json:"custom_path"`
+		ConfigFile string `json:"config_file"`
+	} `json:"default_configuration"`
+}
+
+// toHelpFlagJSON converts the flags, the flags whose names are in "skip" (eg: the global flags) are ignored
+func toHelpFlagJSON(flags []cli.Flag, skip container.Set[string]) []*helpFlagJSON {
+	var ret []*helpFlagJSON
+	for _, flag := range flags {
+		names := flag.Names()
+		if len(names) == 0 || skip.Contains(names[0]) {
+			continue
+		}
+		f := &helpFlagJSON{
+			Name:    names[0],
+			Aliases: names[1:],
+			Type:    strings.ToLower(strings.TrimSuffix(reflect.TypeOf(flag).Elem().Name(), "Flag")),
+		}
+		if df, ok := flag.(cli.DocGenerationFlag); ok {
+			f.Usage = df.GetUsage()
+			f.Default = df.GetDefaultText()
+			if f.Default == "" {
+				f.Default = df.GetValue()
+			}
+		}
+		if rf, ok := flag.(cli.RequiredFlag); ok {
+			f.Required = rf.IsRequired()
+		}
+		ret = append(ret, f)
+	}
+	return ret
+}
+
+func toHelpCommandJSON(cmds []*cli.Command, globalFlagNames container.Set[string]) []*helpCommandJSON {
+	var ret []*helpCommandJSON
+	for _, cmd := range cmds {
+		if cmd.Name == "help" {
+			continue // the "help" sub-command is added to every command, no need to list it again and again
+		}
+		ret = append(ret, &helpCommandJSON{
+			Name:        cmd.Name,
+			Aliases:     cmd.Aliases,
+			Usage:       cmd.Usage,
+			Description: cmd.Description,
+			ArgsUsage:   cmd.ArgsUsage,
+			Hidden:      cmd.Hidden,
+			Flags:       toHelpFlagJSON(cmd.Flags, globalFlagNames),
+			Subcommands: toHelpCommandJSON(cmd.Subcommands, globalFlagNames),
+		})
+	}
+	return ret
+}
+
+// writeHelpJSON outputs the machine-readable help: the command tree with flags and the default configuration paths
+func writeHelpJSON(c *cli.Context, cmds []*cli.Command) error {
+	globalFlagNames := make(container.Set[string])
+	for _, flag := range c.App.Flags {
+		globalFlagNames.AddMultiple(flag.Names()...)
+	}
+
+	help := &helpJSON{
+		Name:     c.App.Name,
+		Version:  c.App.Version,
+		Flags:    toHelpFlagJSON(c.App.Flags, nil),
+		Commands: toHelpCommandJSON(cmds, globalFlagNames),
+	}
+	help.DefaultConfiguration.AppPath = setting.AppPath
+	help.DefaultConfiguration.WorkPath = setting.AppWorkPath
+	help.DefaultConfiguration.CustomPath = setting.CustomPath
+	help.DefaultConfiguration.ConfigFile = setting.CustomConf
+
+	data, err := json.MarshalIndent(help, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(c.App.Writer, string(data))
+	return err
+}
+
 var helpFlag = cli.HelpFlag
 
 func init() {
 	// cli.HelpFlag = nil TODO: after https://github.com/urfave/cli/issues/1794 we can use this
 }
 
+// globalShortFlagAliases returns the short aliases of a global flag, there is none if the build disables them
+func globalShortFlagAliases(aliases ...string) []string {
+	if !globalShortFlags {
+		return nil
+	}
+	return aliases
+}
+
 func appGlobalFlags() []cli.Flag {
 	return []cli.Flag{
 		// make the builtin flags at the top
@@ -62,28 +194,60 @@ func appGlobalFlags() []cli.Flag {
 
 		// shared configuration flags, they are for global and for each sub-command at the same time
 		// eg: such command is valid: "./gitea --config /tmp/app.ini web --config /tmp/app.ini", while it's discouraged indeed
-		// keep in mind that the short flags like "-C", "-c" and "-w" are globally polluted, they can't be used for sub-commands anymore.
+		// keep in mind that the short flags like "-C", "-c" and "-w" are globally polluted, they can't be used for sub-commands anymore,
+		// unless Gitea is built with the "noglobalshortflags" tag.
 		&cli.StringFlag{
 			Name:    "custom-path",
-			Aliases: []string{"C"},
+			Aliases: globalShortFlagAliases("C"),
 			Usage:   "Set custom path (defaults to '{WorkPath}/custom')",
 		},
 		&cli.StringFlag{
 			Name:    "config",
-			Aliases: []string{"c"},
+			Aliases: globalShortFlagAliases("c"),
 			Value:   setting.CustomConf,
 			Usage:   "Set custom config file (defaults to '{WorkPath}/custom/conf/app.ini')",
 		},
+		&cli.StringFlag{
+			Name:  "config-dir",
+			Usage: "Load all '*.ini' files in the directory in lexical order as the config, can't be used together with '--config'",
+		},
 		&cli.StringFlag{
 			Name:    "work-path",
-			Aliases: []string{"w"},
+			Aliases: globalShortFlagAliases("w"),
 			Usage:   "Set Gitea's working path (defaults to the Gitea's binary directory)",
 		},
+		&cli.StringFlag{
+			Name:  "log-level",
+			Usage: "Override the level of the console logger and all the configured loggers: trace, debug, info, warn, error or fatal",
+		},
+		&cli.StringFlag{
+			Name:  "log-format",
+			Usage: "Format of the console logger's output: text or json (the file loggers are not affected)",
+		},
+		&cli.StringFlag{
+			Name:  "color",
+			Usage: "Colorize the console output: auto (only if it is a terminal), always or never, it overrides COLORIZE of the console loggers in the config",
+		},
+		&cli.StringFlag{
+			Name:  "cpuprofile",
+			Usage: "Write a pprof CPU profile of the command to the file",
+		},
+		&cli.StringFlag{
+			Name:  "memprofile",
+			Usage: "Write a pprof memory (heap) profile to the file when the command finishes",
+		},
 	}
 }
 
 func prepareSubcommandWithConfig(command *cli.Command, globalFlags []cli.Flag) {
-	command.Flags = append(append([]cli.Flag{}, globalFlags...), command.Flags...)
+	flags := make([]cli.Flag, 0, len(globalFlags)+len(command.Flags))
+	for _, flag := range globalFlags {
+		// a flag of the command shadows the global flag with the same name, eg: the boolean "--color" of "doctor check"
+		if findFlag(command.Flags, flag.Names()[0]) == nil {
+			flags = append(flags, flag)
+		}
+	}
+	command.Flags = append(flags, command.Flags...)
 	command.Action = prepareWorkPathAndCustomConf(command.Action)
 	command.HideHelp = true
 	if command.Name != "help" {
@@ -94,28 +258,166 @@ func prepareSubcommandWithConfig(command *cli.Command, globalFlags []cli.Flag) {
 	}
 }
 
+// argWorkPathAndCustomConf collects the global path and config flags from the command and its parents
+func argWorkPathAndCustomConf(ctx *cli.Context) (args setting.ArgWorkPathAndCustomConf, err error) {
+	// from children to parent, check the global flags
+	for _, curCtx := range ctx.Lineage() {
+		if curCtx.IsSet("work-path") && args.WorkPath == "" {
+			args.WorkPath = curCtx.String("work-path")
+		}
+		if curCtx.IsSet("custom-path") && args.CustomPath == "" {
+			args.CustomPath = curCtx.String("custom-path")
+		}
+		if curCtx.IsSet("config") && args.CustomConf == "" {
+			args.CustomConf = curCtx.String("config")
+		}
+		if curCtx.IsSet("config-dir") && args.ConfigDir == "" {
+			args.ConfigDir = curCtx.String("config-dir")
+		}
+	}
+	if args.CustomConf != "" && args.ConfigDir != "" {
+		return args, errors.New("--config and --config-dir can't be used together")
+	}
+	if enabled, _ := strconv.ParseBool(os.Getenv(setting.EnvConfigEnabled)); enabled {
+		args.EnvConfig = os.Environ()
+	}
+	return args, nil
+}
+
+// globalLogLevel returns the level of the "--log-level" flag from the command or its parents, "set" is false if the flag is not used
+func globalLogLevel(ctx *cli.Context) (level log.Level, set bool, err error) {
+	for _, curCtx := range ctx.Lineage() {
+		if !curCtx.IsSet("log-level") {
+			continue
+		}
+		s := strings.ToLower(strings.TrimSpace(curCtx.String("log-level")))
+		switch s {
+		case "trace", "debug", "info", "warn", "error", "fatal":
+			return log.LevelFromString(s), true, nil
+		}
+		return log.UNDEFINED, false, fmt.Errorf("invalid log level %q, it should be one of: trace, debug, info, warn, error, fatal", curCtx.String("log-level"))
+	}
+	return log.UNDEFINED, false, nil
+}
+
+// globalLogFormatJSON returns whether the "--log-format" flag from the command or its parents is "json", "set" is false if the flag is not used
+func globalLogFormatJSON(ctx *cli.Context) (isJSON, set bool, err error) {
+	for _, curCtx := range ctx.Lineage() {
+		if !curCtx.IsSet("log-format") {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(curCtx.String("log-format"))) {
+		case "text":
+			return false, true, nil
+		case "json":
+			return true, true, nil
+		}
+		return false, false, fmt.Errorf("invalid log format %q, it should be one of: text, json", curCtx.String("log-format"))
+	}
+	return false, false, nil
+}
+
+// findFlag returns the flag with the name (or alias) from the flags, or nil
+func findFlag(flags []cli.Flag, name string) cli.Flag {
+	for _, flag := range flags {
+		for _, n := range flag.Names() {
+			if n == name {
+				return flag
+			}
+		}
+	}
+	return nil
+}
+
+// globalColor returns the "--color" flag from the command or its parents, OptionalBoolNone means "auto" or that the flag is not used
+func globalColor(ctx *cli.Context) (util.OptionalBool, error) {
+	for _, curCtx := range ctx.Lineage() {
+		if !curCtx.IsSet("color") {
+			continue
+		}
+		// the commands with their own boolean "--color" flag (eg: "doctor check") don't have the global one
+		if curCtx.Command != nil {
+			if _, isOwn := findFlag(curCtx.Command.Flags, "color").(*cli.BoolFlag); isOwn {
+				continue
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(curCtx.String("color"))) {
+		case "auto":
+			return util.OptionalBoolNone, nil
+		case "always":
+			return util.OptionalBoolTrue, nil
+		case "never":
+			return util.OptionalBoolFalse, nil
+		}
+		return util.OptionalBoolNone, fmt.Errorf("invalid color mode %q, it should be one of: auto, always, never", curCtx.String("color"))
+	}
+	return util.OptionalBoolNone, nil
+}
+
+// setConsoleColor makes the console loggers created after it (including the configured ones) colorize their output or not,
+// OptionalBoolNone keeps the detection of the terminal
+func setConsoleColor(colorize util.OptionalBool) {
+	if colorize.IsNone() {
+		return
+	}
+	log.CanColorStdout = colorize.IsTrue()
+	log.CanColorStderr = colorize.IsTrue()
+	setting.SetLogColorOverride(colorize)
+}
+
 // prepareWorkPathAndCustomConf wraps the Action to prepare the work path and custom config
+// The command line flags take precedence over the environment variables (GITEA_WORK_DIR, GITEA_CUSTOM), which take precedence over the defaults,
+// see setting.InitWorkPathAndCfgProvider for details, "--log-level debug" shows where each path comes from.
 // It can't use "Before", because each level's sub-command's Before will be called one by one, so the "init" would be done multiple times
 func prepareWorkPathAndCustomConf(action cli.ActionFunc) func(ctx *cli.Context) error {
-	return func(ctx *cli.Context) error {
-		var args setting.ArgWorkPathAndCustomConf
-		// from children to parent, check the global flags
-		for _, curCtx := range ctx.Lineage() {
-			if curCtx.IsSet("work-path") && args.WorkPath == "" {
-				args.WorkPath = curCtx.String("work-path")
-			}
-			if curCtx.IsSet("custom-path") && args.CustomPath == "" {
-				args.CustomPath = curCtx.String("custom-path")
-			}
-			if curCtx.IsSet("config") && args.CustomConf == "" {
-				args.CustomConf = curCtx.String("config")
+	return func(ctx *cli.Context) (err error) {
+		args, err := argWorkPathAndCustomConf(ctx)
+		if err != nil {
+			return err
+		}
+		logLevel, logLevelSet, err := globalLogLevel(ctx)
+		if err != nil {
+			return err
+		}
+		logFormatJSON, logFormatSet, err := globalLogFormatJSON(ctx)
+		if err != nil {
+			return err
+		}
+		colorize, err := globalColor(ctx)
+		if err != nil {
+			return err
+		}
+		if logFormatSet {
+			log.SetConsoleFormatJSON(logFormatJSON)
+		}
+		setConsoleColor(colorize)
+		if logLevelSet || logFormatSet || !colorize.IsNone() {
+			// the sub-command flags are not parsed yet when the app's "Before" prepares the console logger, so set it again
+			if !logLevelSet {
+				logLevel = log.GetLevel()
 			}
+			log.SetConsoleLogger(log.DEFAULT, "console-default", logLevel)
+		}
+		if logLevelSet {
+			setting.SetLogLevelOverride(logLevel)
 		}
 		setting.InitWorkPathAndCommonConfig(os.Getenv, args)
 		if ctx.Bool("help") || action == nil {
 			// the default behavior of "urfave/cli": "nil action" means "show help"
 			return cmdHelp().Action(ctx)
 		}
+
+		stopProfiling, err := startProfiling(globalProfilePaths(ctx))
+		if err != nil {
+			return err
+		}
+		defer func() {
+			// the profiles are also written if the action fails, it is when they are needed the most.
+			// The action's error is only wrapped if stopping fails, otherwise the exit code of a cli.ExitCoder would be lost
+			if stopErr := stopProfiling(); stopErr != nil {
+				err = errors.Join(err, stopErr)
+			}
+		}()
 		return action(ctx)
 	}
 }
@@ -125,16 +427,39 @@ func reflectGet(v any, fieldName string) any {
 	return e.FieldByName(fieldName).Interface()
 }
 
+// checkDuplicateFlagNames checks that no name or alias is used by two flags of the same command,
+// otherwise urfave/cli resolves the flag unpredictably
+func checkDuplicateFlagNames(cmdName string, flags []cli.Flag) bool {
+	ok := true
+	usedBy := map[string]int{} // name or alias => the index of the flag using it, two flags can have the same Name
+	for i, flag := range flags {
+		names := flag.Names()
+		if len(names) == 0 {
+			continue
+		}
+		for _, name := range names {
+			if other, has := usedBy[name]; has && other != i {
+				ok = false
+				log.Error("cli.Flag %q and %q of command %q both use the name %q", flags[other].Names()[0], names[0], cmdName, name)
+				continue
+			}
+			usedBy[name] = i
+		}
+	}
+	return ok
+}
+
 // https://cli.urfave.org/migrate-v1-to-v2/#flag-aliases-are-done-differently
 // Sadly v2 doesn't warn you if a comma is in the name. (https://github.com/urfave/cli/issues/1103)
 func checkCommandFlags(c any) bool {
 	var cmds []*cli.Command
-	if app, ok := c.(*cli.App); ok {
+	ok := true
+	if app, isApp := c.(*cli.App); isApp {
 		cmds = app.Commands
+		ok = checkDuplicateFlagNames(app.Name, app.Flags)
 	} else {
 		cmds = c.(*cli.Command).Subcommands
 	}
-	ok := true
 	for _, cmd := range cmds {
 		for _, flag := range cmd.Flags {
 			flagName := reflectGet(flag, "Name").(string)
@@ -143,6 +468,9 @@ func checkCommandFlags(c any) bool {
 				log.Error("cli.Flag can't have comma in its Name: %q, use Aliases instead", flagName)
 			}
 		}
+		if !checkDuplicateFlagNames(cmd.Name, cmd.Flags) {
+			ok = false
+		}
 		if !checkCommandFlags(cmd) {
 			ok = false
 		}
@@ -170,6 +498,7 @@ func NewMainApp() *cli.App {
 		CmdDumpRepository,
 		CmdRestoreRepository,
 		CmdActions,
+		CmdConfig,
 		cmdHelp(), // the "help" sub-command was used to show the more information for "work path" and "custom config"
 	}
 
@@ -0,0 +1,9 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !noglobalshortflags
+
+package cmd
+
+// globalShortFlags is true when the global flags have their short aliases: "-C", "-c" and "-w"
+var globalShortFlags = true
@@ -0,0 +1,10 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build noglobalshortflags
+
+package cmd
+
+// globalShortFlags is false when built with the "noglobalshortflags" tag,
+// then the sub-commands can use "-C", "-c" and "-w" for their own flags
+var globalShortFlags = false
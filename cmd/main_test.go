@@ -4,6 +4,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,7 +12,9 @@ import (
 	"testing"
 
 	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/urfave/cli/v2"
@@ -61,6 +64,14 @@ func TestCliCmd(t *testing.T) {
 			cmd: "./gitea help",
 			exp: "DEFAULT CONFIGURATION:",
 		},
+		{
+			cmd: "./gitea help --json",
+			exp: `"default_configuration": {`,
+		},
+		{
+			cmd: "./gitea admin help --json",
+			exp: `"name": "admin"`,
+		},
 
 		// parse paths
 		{
@@ -129,3 +140,133 @@ func TestCliCmd(t *testing.T) {
 		assert.Contains(t, outStr, c.exp, c.cmd)
 	}
 }
+
+func TestCliCmdProfile(t *testing.T) {
+	app := newTestApp()
+	app.Writer = new(strings.Builder)
+	failCmd := &cli.Command{
+		Name: "test-fail",
+		Action: func(ctx *cli.Context) error {
+			return errors.New("the action failed")
+		},
+	}
+	prepareSubcommandWithConfig(failCmd, appGlobalFlags())
+	app.Commands = append(app.Commands, failCmd)
+
+	dir := t.TempDir()
+	for _, name := range []string{"test-cmd", "test-fail"} {
+		cpuProfile := filepath.Join(dir, name+".cpu.pprof")
+		memProfile := filepath.Join(dir, name+".mem.pprof")
+		err := app.Run([]string{"./gitea", "--cpuprofile", cpuProfile, name, "--memprofile", memProfile})
+		if name == "test-fail" {
+			assert.ErrorContains(t, err, "the action failed")
+		} else {
+			assert.NoError(t, err)
+		}
+		for _, profile := range []string{cpuProfile, memProfile} {
+			fi, err := os.Stat(profile)
+			if assert.NoError(t, err, profile) {
+				assert.NotZero(t, fi.Size(), profile)
+			}
+		}
+	}
+}
+
+func TestCliCmdColor(t *testing.T) {
+	canColorStdout, canColorStderr := log.CanColorStdout, log.CanColorStderr
+	defer func() {
+		log.CanColorStdout, log.CanColorStderr = canColorStdout, canColorStderr
+		setting.SetLogColorOverride(util.OptionalBoolNone)
+	}()
+
+	app := newTestApp()
+	app.Writer = new(strings.Builder)
+	assert.NoError(t, app.Run([]string{"./gitea", "--color", "never", "test-cmd"}))
+	assert.False(t, log.CanColorStdout)
+	assert.False(t, log.CanColorStderr)
+
+	assert.NoError(t, app.Run([]string{"./gitea", "test-cmd", "--color", "always"}))
+	assert.True(t, log.CanColorStdout)
+	assert.True(t, log.CanColorStderr)
+
+	// "auto" doesn't override the detected (here the previously set) value
+	assert.NoError(t, app.Run([]string{"./gitea", "--color", "auto", "test-cmd"}))
+	assert.True(t, log.CanColorStdout)
+
+	assert.ErrorContains(t, app.Run([]string{"./gitea", "--color", "sometimes", "test-cmd"}), "invalid color mode")
+
+	// the boolean flag of a command shadows the global one
+	ownColorCmd := &cli.Command{
+		Name:   "test-own-color",
+		Flags:  []cli.Flag{&cli.BoolFlag{Name: "color"}},
+		Action: func(ctx *cli.Context) error { return nil },
+	}
+	prepareSubcommandWithConfig(ownColorCmd, appGlobalFlags())
+	app.Commands = append(app.Commands, ownColorCmd)
+	assert.True(t, checkCommandFlags(app))
+	assert.NoError(t, app.Run([]string{"./gitea", "--color", "never", "test-own-color", "--color"}))
+	assert.False(t, log.CanColorStdout)
+}
+
+func TestCliCmdExitCode(t *testing.T) {
+	osExiter := cli.OsExiter
+	defer func() { cli.OsExiter = osExiter }()
+	exitCode := 0
+	cli.OsExiter = func(code int) { exitCode = code }
+
+	app := newTestApp()
+	app.Writer = new(strings.Builder)
+	exitCmd := &cli.Command{
+		Name: "test-exit",
+		Action: func(ctx *cli.Context) error {
+			return cli.Exit("", 2)
+		},
+	}
+	prepareSubcommandWithConfig(exitCmd, appGlobalFlags())
+	app.Commands = append(app.Commands, exitCmd)
+
+	// the hooks rely on the exit code to reject the pushes
+	err := app.Run([]string{"./gitea", "test-exit"})
+	assert.Error(t, err)
+	assert.Equal(t, 2, exitCode)
+
+	// the profiling doesn't hide the exit code
+	exitCode = 0
+	dir := t.TempDir()
+	err = app.Run([]string{"./gitea", "--cpuprofile", filepath.Join(dir, "cpu.pprof"), "test-exit", "--memprofile", filepath.Join(dir, "mem.pprof")})
+	assert.Error(t, err)
+	assert.Equal(t, 2, exitCode)
+}
+
+func TestCheckCommandFlagsDuplicate(t *testing.T) {
+	app := &cli.App{
+		Commands: []*cli.Command{
+			{
+				Name: "ok",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "password", Aliases: []string{"p"}},
+					&cli.IntFlag{Name: "port"},
+				},
+			},
+		},
+	}
+	assert.True(t, checkCommandFlags(app))
+
+	app.Commands[0].Subcommands = []*cli.Command{
+		{
+			Name: "dup",
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "password", Aliases: []string{"p"}},
+				&cli.IntFlag{Name: "port", Aliases: []string{"p"}},
+			},
+		},
+	}
+	assert.False(t, checkCommandFlags(app))
+
+	// two distinct flags with the same name
+	app.Commands[0].Subcommands[0].Flags = []cli.Flag{
+		&cli.StringFlag{Name: "password"},
+		&cli.BoolFlag{Name: "password"},
+	}
+	assert.False(t, checkCommandFlags(app))
+}
@@ -4,10 +4,16 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"strings"
 	"time"
 
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/private"
+	"code.gitea.io/gitea/modules/setting"
 
 	"github.com/urfave/cli/v2"
 )
@@ -18,6 +24,18 @@ var (
 		Name:        "manager",
 		Usage:       "Manage the running gitea process",
 		Description: "This is a command for managing the running gitea process",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "manager-addr",
+				Usage:   "Address (host:port) of the manager listener to send the requests to, instead of the local Gitea server",
+				EnvVars: []string{"GITEA_MANAGER_ADDR"},
+			},
+			&cli.StringFlag{
+				Name:    "manager-token",
+				Usage:   "Manager token of the manager listener, prefer the environment variable to keep it out of the process list",
+				EnvVars: []string{"GITEA_MANAGER_TOKEN"},
+			},
+		},
 		Subcommands: []*cli.Command{
 			subcmdShutdown,
 			subcmdRestart,
@@ -25,6 +43,12 @@ var (
 			subcmdFlushQueues,
 			subcmdLogging,
 			subCmdProcesses,
+			subcmdCancelProcess,
+			subcmdSetSetting,
+			subcmdMaintenance,
+			subcmdSSHReadOnly,
+			subcmdFsckRepos,
+			subcmdStats,
 		},
 	}
 	subcmdShutdown = &cli.Command{
@@ -48,8 +72,9 @@ var (
 		Action: runRestart,
 	}
 	subcmdReloadTemplates = &cli.Command{
-		Name:  "reload-templates",
-		Usage: "Reload template files in the running process",
+		Name:        "reload-templates",
+		Usage:       "Reload template files in the running process",
+		Description: "Re-read and recompile the HTML templates, if the new templates fail to compile the running ones are kept and the error is printed",
 		Flags: []cli.Flag{
 			&cli.BoolFlag{
 				Name: "debug",
@@ -76,6 +101,98 @@ var (
 			},
 		},
 	}
+	subcmdSetSetting = &cli.Command{
+		Name:      "set-setting",
+		Usage:     "Change a setting of the running process",
+		ArgsUsage: "section.KEY value",
+		Description: fmt.Sprintf(`Change a setting of the running process without restarting it, the config file isn't changed
+so the setting is reverted when the process restarts. Only these settings can be changed: %s`, strings.Join(setting.RuntimeSettingNames(), ", ")),
+		Action: runSetSetting,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name: "debug",
+			},
+		},
+	}
+	subcmdMaintenance = &cli.Command{
+		Name:      "maintenance",
+		Usage:     "Turn the maintenance mode of the running process on or off",
+		ArgsUsage: "on|off",
+		Description: `In the maintenance mode, the web frontend responds 503 with a message to all requests except the ones of the admins
+and the sign-in pages. The mode is saved in the database, so it stays on after restarts until it is turned off.`,
+		Action: runMaintenance,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name: "debug",
+			},
+			&cli.StringFlag{
+				Name:  "message",
+				Usage: "The message shown to the users when turning the maintenance mode on, a default message is shown if it is empty",
+			},
+		},
+	}
+	subcmdSSHReadOnly = &cli.Command{
+		Name:      "ssh-read-only",
+		Usage:     "Turn the read-only mode of the pushes over SSH on or off in the running process",
+		ArgsUsage: "on|off",
+		Description: `In the read-only mode, "gitea serv" rejects all pushes with a message while fetching and cloning still work.
+The config file isn't changed, so [server].SSH_READ_ONLY is used again when the process restarts.`,
+		Action: runSSHReadOnly,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name: "debug",
+			},
+			&cli.StringFlag{
+				Name:  "message",
+				Usage: "The message shown to the users whose pushes are rejected, the current message is kept if it is empty",
+			},
+			&cli.StringFlag{
+				Name:  "until",
+				Usage: "When the write access is expected to resume (eg: \"2024-05-01 18:00 UTC\"), it is added to the message, the previous time is cleared if it is empty",
+			},
+		},
+	}
+	subcmdFsckRepos = &cli.Command{
+		Name:  "fsck-repos",
+		Usage: "Run \"git fsck\" on repositories in the running process",
+		Description: `Check the health of the given repositories, or of all the repositories with the health check enabled, like the
+repo_health_check cron task does. The repositories with problems are printed and also reported as system notices.`,
+		Action: runFsckRepos,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name: "debug",
+			},
+			&cli.StringSliceFlag{
+				Name:  "repo",
+				Usage: "The repository to check as owner/name, can be repeated",
+			},
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Check all the repositories with the health check enabled",
+			},
+			&cli.IntFlag{
+				Name:  "concurrency",
+				Value: 1,
+				Usage: "The number of the repositories checked at the same time",
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Value: 60 * time.Second,
+				Usage: "Timeout for checking each repository",
+			},
+		},
+	}
+	subcmdCancelProcess = &cli.Command{
+		Name:      "cancel",
+		Usage:     "Cancel a process, like a stuck mirror sync, by its PID (as listed by the processes command)",
+		ArgsUsage: "<pid>",
+		Action:    runCancelProcess,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name: "debug",
+			},
+		},
+	}
 	subCmdProcesses = &cli.Command{
 		Name:   "processes",
 		Usage:  "Display running processes within the current process",
@@ -88,6 +205,10 @@ var (
 				Name:  "flat",
 				Usage: "Show processes as flat table rather than as tree",
 			},
+			&cli.BoolFlag{
+				Name:  "tree",
+				Usage: "Show processes as a tree of the parents and their children (the default)",
+			},
 			&cli.BoolFlag{
 				Name:  "no-system",
 				Usage: "Do not show system processes",
@@ -96,9 +217,14 @@ var (
 				Name:  "stacktraces",
 				Usage: "Show stacktraces",
 			},
+			&cli.StringFlag{
+				Name:  "format",
+				Value: "text",
+				Usage: "Output format: text or json",
+			},
 			&cli.BoolFlag{
 				Name:  "json",
-				Usage: "Output as json",
+				Usage: "Output as json. DEPRECATED: use --format json instead",
 			},
 			&cli.StringFlag{
 				Name:  "cancel",
@@ -108,11 +234,33 @@ var (
 	}
 )
 
+// setupManager prepares the manager commands to send their requests to the local Gitea server,
+// or to the manager listener of the --manager-addr, which doesn't need the local configuration
+func setupManager(ctx context.Context, c *cli.Context) error {
+	addr := c.String("manager-addr")
+	if addr == "" {
+		setup(ctx, c.Bool("debug"))
+		return nil
+	}
+	if c.String("manager-token") == "" {
+		return errors.New("--manager-token (or GITEA_MANAGER_TOKEN) is required with --manager-addr")
+	}
+	if c.Bool("debug") {
+		setupConsoleLogger(log.TRACE, false, os.Stderr)
+	} else {
+		setupConsoleLogger(log.FATAL, false, os.Stderr)
+	}
+	private.UseRemoteManager(addr, c.String("manager-token"))
+	return nil
+}
+
 func runShutdown(c *cli.Context) error {
 	ctx, cancel := installSignals()
 	defer cancel()
 
-	setup(ctx, c.Bool("debug"))
+	if err := setupManager(ctx, c); err != nil {
+		return err
+	}
 	extra := private.Shutdown(ctx)
 	return handleCliResponseExtra(extra)
 }
@@ -121,7 +269,9 @@ func runRestart(c *cli.Context) error {
 	ctx, cancel := installSignals()
 	defer cancel()
 
-	setup(ctx, c.Bool("debug"))
+	if err := setupManager(ctx, c); err != nil {
+		return err
+	}
 	extra := private.Restart(ctx)
 	return handleCliResponseExtra(extra)
 }
@@ -130,7 +280,9 @@ func runReloadTemplates(c *cli.Context) error {
 	ctx, cancel := installSignals()
 	defer cancel()
 
-	setup(ctx, c.Bool("debug"))
+	if err := setupManager(ctx, c); err != nil {
+		return err
+	}
 	extra := private.ReloadTemplates(ctx)
 	return handleCliResponseExtra(extra)
 }
@@ -139,7 +291,9 @@ func runFlushQueues(c *cli.Context) error {
 	ctx, cancel := installSignals()
 	defer cancel()
 
-	setup(ctx, c.Bool("debug"))
+	if err := setupManager(ctx, c); err != nil {
+		return err
+	}
 	extra := private.FlushQueues(ctx, c.Duration("timeout"), c.Bool("non-blocking"))
 	return handleCliResponseExtra(extra)
 }
@@ -148,7 +302,144 @@ func runProcesses(c *cli.Context) error {
 	ctx, cancel := installSignals()
 	defer cancel()
 
-	setup(ctx, c.Bool("debug"))
-	extra := private.Processes(ctx, os.Stdout, c.Bool("flat"), c.Bool("no-system"), c.Bool("stacktraces"), c.Bool("json"), c.String("cancel"))
+	if c.Bool("flat") && c.Bool("tree") {
+		return errors.New("--flat and --tree can't be used together")
+	}
+	format := c.String("format")
+	if c.Bool("json") {
+		if _, err := fmt.Fprintln(c.App.ErrWriter, "--json flag is deprecated. Use --format json instead."); err != nil {
+			return err
+		}
+		if c.IsSet("format") && format != "json" {
+			return fmt.Errorf("--json contradicts --format %s", format)
+		}
+		format = "json"
+	}
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown output format %q, it should be one of: text, json", format)
+	}
+
+	if err := setupManager(ctx, c); err != nil {
+		return err
+	}
+	extra := private.Processes(ctx, os.Stdout, c.Bool("flat"), c.Bool("no-system"), c.Bool("stacktraces"), format == "json", c.String("cancel"))
+	return handleCliResponseExtra(extra)
+}
+
+func runCancelProcess(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("the PID of the process is required")
+	}
+
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := setupManager(ctx, c); err != nil {
+		return err
+	}
+	extra := private.CancelProcess(ctx, c.Args().First())
 	return handleCliResponseExtra(extra)
 }
+
+func runSetSetting(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return errors.New("the setting name (section.KEY) and the value are required")
+	}
+
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := setupManager(ctx, c); err != nil {
+		return err
+	}
+	extra := private.SetSetting(ctx, c.Args().Get(0), c.Args().Get(1))
+	return handleCliResponseExtra(extra)
+}
+
+func runMaintenance(c *cli.Context) error {
+	var enabled bool
+	switch c.Args().First() {
+	case "on":
+		enabled = true
+	case "off":
+		if c.IsSet("message") {
+			return errors.New("--message can only be used when turning the maintenance mode on")
+		}
+	default:
+		return errors.New("the argument should be either \"on\" or \"off\"")
+	}
+	if c.NArg() > 1 {
+		return errors.New("only one argument (on or off) is expected")
+	}
+
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := setupManager(ctx, c); err != nil {
+		return err
+	}
+	extra := private.SetMaintenance(ctx, enabled, c.String("message"))
+	return handleCliResponseExtra(extra)
+}
+
+func runSSHReadOnly(c *cli.Context) error {
+	var enabled bool
+	switch c.Args().First() {
+	case "on":
+		enabled = true
+	case "off":
+		if c.IsSet("message") || c.IsSet("until") {
+			return errors.New("--message and --until can only be used when turning the SSH read-only mode on")
+		}
+	default:
+		return errors.New("the argument should be either \"on\" or \"off\"")
+	}
+	if c.NArg() > 1 {
+		return errors.New("only one argument (on or off) is expected")
+	}
+
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := setupManager(ctx, c); err != nil {
+		return err
+	}
+	extra := private.SetSSHReadOnly(ctx, private.SSHReadOnlyOptions{
+		Enabled: enabled,
+		Message: c.String("message"),
+		Until:   c.String("until"),
+	})
+	return handleCliResponseExtra(extra)
+}
+
+func runFsckRepos(c *cli.Context) error {
+	if c.Bool("all") == (len(c.StringSlice("repo")) > 0) {
+		return errors.New("either --repo or --all is required")
+	}
+	if c.Int("concurrency") < 1 {
+		return fmt.Errorf("invalid --concurrency %d, it should be 1 or more", c.Int("concurrency"))
+	}
+
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := setupManager(ctx, c); err != nil {
+		return err
+	}
+	result, extra := private.FsckRepos(ctx, private.FsckReposOptions{
+		Repos:       c.StringSlice("repo"),
+		Concurrency: c.Int("concurrency"),
+		Timeout:     c.Duration("timeout"),
+	})
+	if extra.HasError() {
+		return handleCliResponseExtra(extra)
+	}
+	for _, problem := range result.Problems {
+		_, _ = fmt.Fprintf(c.App.Writer, "%s: %s\n", problem.Repo, problem.Error)
+	}
+	_, _ = fmt.Fprintf(c.App.Writer, "Checked %d repositories: %d healthy, %d with problems\n", result.Checked, result.Checked-len(result.Problems), len(result.Problems))
+	if len(result.Problems) > 0 {
+		return cli.Exit("", 1)
+	}
+	return nil
+}
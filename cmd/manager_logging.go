@@ -6,6 +6,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"sort"
 
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/private"
@@ -14,6 +15,15 @@ import (
 )
 
 var (
+	loggingPauseLoggerFlag = &cli.StringFlag{
+		Name:  "logger",
+		Usage: `Only pause or resume the named logger, it defaults to "default" if --writer is set`,
+	}
+	loggingPauseWriterFlag = &cli.StringFlag{
+		Name:  "writer",
+		Usage: "Only pause or resume the named writer of the logger",
+	}
+
 	defaultLoggingFlags = []cli.Flag{
 		&cli.StringFlag{
 			Name:  "logger",
@@ -63,10 +73,14 @@ var (
 			{
 				Name:  "pause",
 				Usage: "Pause logging (Gitea will buffer logs up to a certain point and will drop them after that point)",
+				Description: `Without --logger and --writer, all loggers are paused and the logs are buffered.
+With --logger (and --writer), only the named logger (or its writer) is muted, its logs are discarded until it is resumed.`,
 				Flags: []cli.Flag{
 					&cli.BoolFlag{
 						Name: "debug",
 					},
+					loggingPauseLoggerFlag,
+					loggingPauseWriterFlag,
 				},
 				Action: runPauseLogging,
 			}, {
@@ -76,8 +90,19 @@ var (
 					&cli.BoolFlag{
 						Name: "debug",
 					},
+					loggingPauseLoggerFlag,
+					loggingPauseWriterFlag,
 				},
 				Action: runResumeLogging,
+			}, {
+				Name:  "show",
+				Usage: "Show the loggers, their writers and which of them are paused",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name: "debug",
+					},
+				},
+				Action: runShowLogging,
 			}, {
 				Name:  "release-and-reopen",
 				Usage: "Cause Gitea to release and re-open files used for logging",
@@ -198,7 +223,9 @@ func runRemoveLogger(c *cli.Context) error {
 	ctx, cancel := installSignals()
 	defer cancel()
 
-	setup(ctx, c.Bool("debug"))
+	if err := setupManager(ctx, c); err != nil {
+		return err
+	}
 	logger := c.String("logger")
 	if len(logger) == 0 {
 		logger = log.DEFAULT
@@ -213,7 +240,9 @@ func runAddConnLogger(c *cli.Context) error {
 	ctx, cancel := installSignals()
 	defer cancel()
 
-	setup(ctx, c.Bool("debug"))
+	if err := setupManager(ctx, c); err != nil {
+		return err
+	}
 	vals := map[string]any{}
 	mode := "conn"
 	vals["net"] = "tcp"
@@ -243,7 +272,9 @@ func runAddFileLogger(c *cli.Context) error {
 	ctx, cancel := installSignals()
 	defer cancel()
 
-	setup(ctx, c.Bool("debug"))
+	if err := setupManager(ctx, c); err != nil {
+		return err
+	}
 	vals := map[string]any{}
 	mode := "file"
 	if c.IsSet("filename") {
@@ -306,31 +337,91 @@ func commonAddLogger(c *cli.Context, mode string, vals map[string]any) error {
 	return handleCliResponseExtra(extra)
 }
 
+// loggingPauseTarget returns the logger and writer names of the pause/resume commands, empty names mean all loggers
+func loggingPauseTarget(c *cli.Context) (logger, writer string) {
+	logger, writer = c.String("logger"), c.String("writer")
+	if logger == "" && writer != "" {
+		logger = log.DEFAULT
+	}
+	return logger, writer
+}
+
 func runPauseLogging(c *cli.Context) error {
 	ctx, cancel := installSignals()
 	defer cancel()
 
-	setup(ctx, c.Bool("debug"))
-	userMsg := private.PauseLogging(ctx)
-	_, _ = fmt.Fprintln(os.Stdout, userMsg)
-	return nil
+	if err := setupManager(ctx, c); err != nil {
+		return err
+	}
+	logger, writer := loggingPauseTarget(c)
+	extra := private.PauseLogging(ctx, logger, writer)
+	return handleCliResponseExtra(extra)
 }
 
 func runResumeLogging(c *cli.Context) error {
 	ctx, cancel := installSignals()
 	defer cancel()
 
-	setup(ctx, c.Bool("debug"))
-	userMsg := private.ResumeLogging(ctx)
-	_, _ = fmt.Fprintln(os.Stdout, userMsg)
+	if err := setupManager(ctx, c); err != nil {
+		return err
+	}
+	logger, writer := loggingPauseTarget(c)
+	extra := private.ResumeLogging(ctx, logger, writer)
+	return handleCliResponseExtra(extra)
+}
+
+func runShowLogging(c *cli.Context) error {
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := setupManager(ctx, c); err != nil {
+		return err
+	}
+	status, extra := private.ShowLogging(ctx)
+	if extra.HasError() {
+		return handleCliResponseExtra(extra)
+	}
+
+	if status.Paused {
+		_, _ = fmt.Fprintln(c.App.Writer, "All loggers are paused, the logs are buffered")
+	}
+	for _, loggerName := range sortedMapKeys(status.Loggers) {
+		logger, _ := status.Loggers[loggerName].(map[string]any)
+		_, _ = fmt.Fprintf(c.App.Writer, "Logger %q: %s\n", loggerName, loggingStateString(logger["IsEnabled"] == true, logger["IsPaused"] == true))
+		writers, _ := logger["EventWriters"].(map[string]any)
+		for _, writerName := range sortedMapKeys(writers) {
+			writer, _ := writers[writerName].(map[string]any)
+			_, _ = fmt.Fprintf(c.App.Writer, "  Writer %q (%v, level %v): %s\n", writerName, writer["WriterType"], writer["Level"], loggingStateString(true, writer["IsPaused"] == true))
+		}
+	}
 	return nil
 }
 
+func loggingStateString(enabled, paused bool) string {
+	if paused {
+		return "paused"
+	} else if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
+
+func sortedMapKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func runReleaseReopenLogging(c *cli.Context) error {
 	ctx, cancel := installSignals()
 	defer cancel()
 
-	setup(ctx, c.Bool("debug"))
+	if err := setupManager(ctx, c); err != nil {
+		return err
+	}
 	userMsg := private.ReleaseReopenLogging(ctx)
 	_, _ = fmt.Fprintln(os.Stdout, userMsg)
 	return nil
@@ -339,7 +430,9 @@ func runReleaseReopenLogging(c *cli.Context) error {
 func runSetLogSQL(c *cli.Context) error {
 	ctx, cancel := installSignals()
 	defer cancel()
-	setup(ctx, c.Bool("debug"))
+	if err := setupManager(ctx, c); err != nil {
+		return err
+	}
 
 	extra := private.SetLogSQL(ctx, !c.Bool("off"))
 	return handleCliResponseExtra(extra)
@@ -0,0 +1,101 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/private"
+
+	"github.com/urfave/cli/v2"
+)
+
+var subcmdStats = &cli.Command{
+	Name:        "stats",
+	Usage:       "Show the live counters of the running process",
+	Description: "Show a snapshot of the database connections, the queues, the running git processes, the goroutines and the memory of the running process",
+	Action:      runStats,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name: "debug",
+		},
+		&cli.StringFlag{
+			Name:  "format",
+			Value: "text",
+			Usage: "Output format: text or json",
+		},
+	},
+}
+
+func runStats(c *cli.Context) error {
+	format := c.String("format")
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown output format %q, it should be one of: text, json", format)
+	}
+
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := setupManager(ctx, c); err != nil {
+		return err
+	}
+	stats, extra := private.Stats(ctx)
+	if extra.HasError() {
+		return handleCliResponseExtra(extra)
+	}
+
+	if format == "json" {
+		bs, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(c.App.Writer, string(bs))
+		return err
+	}
+	return writeManagerStats(c.App.Writer, stats)
+}
+
+// writeManagerStats writes the stats as a summary followed by the table of the queues
+func writeManagerStats(out io.Writer, stats *private.ManagerStats) error {
+	w := tabwriter.NewWriter(out, 5, 0, 1, ' ', 0)
+	_, _ = fmt.Fprintf(w, "Started:\t%s (uptime %s)\n", stats.StartTime.Format(time.RFC3339), time.Since(stats.StartTime).Round(time.Second))
+	_, _ = fmt.Fprintf(w, "Goroutines:\t%d\n", stats.Goroutines)
+	_, _ = fmt.Fprintf(w, "Git processes:\t%d\n", stats.GitProcesses)
+
+	maxOpen := "unlimited"
+	if stats.Database.MaxOpenConnections > 0 {
+		maxOpen = fmt.Sprint(stats.Database.MaxOpenConnections)
+	}
+	_, _ = fmt.Fprintf(w, "Database connections:\t%d open (%d in use, %d idle), max %s, waited %d times for %s\n",
+		stats.Database.OpenConnections, stats.Database.InUse, stats.Database.Idle, maxOpen,
+		stats.Database.WaitCount, stats.Database.WaitDuration)
+
+	mem := stats.Memory
+	_, _ = fmt.Fprintf(w, "Memory:\t%s allocated, %s heap in use (%d objects), %s stack in use, %s obtained from the system\n",
+		base.FileSize(int64(mem.Alloc)), base.FileSize(int64(mem.HeapInuse)), mem.HeapObjects,
+		base.FileSize(int64(mem.StackInuse)), base.FileSize(int64(mem.Sys)))
+	_, _ = fmt.Fprintf(w, "Garbage collections:\t%d (%s allocated in total)\n", mem.NumGC, base.FileSize(int64(mem.TotalAlloc)))
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintln(out)
+	queues, _ := newListFormatter("text", out)
+	if err := queues.WriteHeader([]listColumn{
+		{Title: "Queue"}, {Title: "Type"}, {Title: "Items"}, {Title: "Workers"}, {Title: "Active"}, {Title: "Max workers"},
+	}); err != nil {
+		return err
+	}
+	for _, q := range stats.Queues {
+		if err := queues.WriteRow(q.Name, q.Type, q.Items, q.Workers, q.ActiveWorkers, q.MaxWorkers); err != nil {
+			return err
+		}
+	}
+	return queues.Flush()
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/private"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteManagerStats(t *testing.T) {
+	stats := &private.ManagerStats{
+		StartTime: time.Now().Add(-time.Hour),
+		Database: private.DatabaseStats{
+			OpenConnections: 3,
+			InUse:           1,
+			Idle:            2,
+			WaitCount:       4,
+			WaitDuration:    time.Second,
+		},
+		Queues: []private.QueueStats{
+			{Name: "issue_indexer", Type: "level", Items: 5, Workers: 1, ActiveWorkers: 1, MaxWorkers: 10},
+		},
+		GitProcesses: 2,
+		Goroutines:   42,
+		Memory: private.MemoryStats{
+			Alloc:       2048,
+			HeapInuse:   4096,
+			HeapObjects: 7,
+			NumGC:       8,
+		},
+	}
+
+	out := &strings.Builder{}
+	assert.NoError(t, writeManagerStats(out, stats))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if assert.Len(t, lines, 9) {
+		assert.Contains(t, lines[0], "(uptime 1h0m0s)")
+		assert.Equal(t, "Goroutines:           42", lines[1])
+		assert.Equal(t, "Git processes:        2", lines[2])
+		assert.Equal(t, "Database connections: 3 open (1 in use, 2 idle), max unlimited, waited 4 times for 1s", lines[3])
+		assert.Equal(t, "Memory:               2.0 KiB allocated, 4.0 KiB heap in use (7 objects), 0 B stack in use, 0 B obtained from the system", lines[4])
+		assert.Equal(t, "Garbage collections:  8 (0 B allocated in total)", lines[5])
+		assert.Empty(t, lines[6])
+		assert.Equal(t, []string{"Queue", "Type", "Items", "Workers", "Active", "Max", "workers"}, strings.Fields(lines[7]))
+		assert.Equal(t, []string{"issue_indexer", "level", "5", "1", "1", "10"}, strings.Fields(lines[8]))
+	}
+
+	stats.Database.MaxOpenConnections = 10
+	out.Reset()
+	assert.NoError(t, writeManagerStats(out, stats))
+	assert.Contains(t, out.String(), "max 10,")
+}
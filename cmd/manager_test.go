@@ -0,0 +1,34 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+func TestProcessesFlags(t *testing.T) {
+	run := func(args ...string) (string, error) {
+		app := cli.NewApp()
+		app.Flags = subCmdProcesses.Flags
+		app.Action = runProcesses
+		errOut := &strings.Builder{}
+		app.ErrWriter = errOut
+		err := app.Run(append([]string{"./gitea"}, args...))
+		return errOut.String(), err
+	}
+
+	_, err := run("--flat", "--tree")
+	assert.EqualError(t, err, "--flat and --tree can't be used together")
+
+	_, err = run("--format", "yaml")
+	assert.EqualError(t, err, `unknown output format "yaml", it should be one of: text, json`)
+
+	errOut, err := run("--json", "--format", "text")
+	assert.EqualError(t, err, "--json contradicts --format text")
+	assert.Equal(t, "--json flag is deprecated. Use --format json instead.\n", errOut)
+}
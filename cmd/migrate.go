@@ -5,6 +5,7 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/migrations"
@@ -12,6 +13,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 
 	"github.com/urfave/cli/v2"
+	"xorm.io/xorm"
 )
 
 // CmdMigrate represents the available migrate sub-command.
@@ -20,8 +22,24 @@ var CmdMigrate = &cli.Command{
 	Usage:       "Migrate the database",
 	Description: "This is a command for migrating the database, so that you can run gitea admin create-user before starting the server.",
 	Action:      runMigrate,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name: "dry-run",
+			Usage: fmt.Sprintf("Only print the pending migrations without modifying the database, exit with code %d if there are pending migrations",
+				migrateDryRunPendingExitCode),
+		},
+		&cli.Int64Flag{
+			Name: "to",
+			Usage: "Only migrate the database up to this version instead of the latest one, the tables are left as the migrations made them. " +
+				"It can't be below the current version, the migrations can't be reverted",
+		},
+	},
 }
 
+// migrateDryRunPendingExitCode is the exit code of "migrate --dry-run" when the database is not up-to-date,
+// it differs from the general error exit code 1 so that scripts could tell them apart
+const migrateDryRunPendingExitCode = 2
+
 func runMigrate(ctx *cli.Context) error {
 	stdCtx, cancel := installSignals()
 	defer cancel()
@@ -36,6 +54,19 @@ func runMigrate(ctx *cli.Context) error {
 	log.Info("Log path: %s", setting.Log.RootPath)
 	log.Info("Configuration file: %s", setting.CustomConf)
 
+	target := migrations.ExpectedVersion()
+	if ctx.IsSet("to") {
+		target = ctx.Int64("to")
+	}
+
+	if ctx.Bool("dry-run") {
+		return runMigrateDryRun(ctx, stdCtx, target)
+	}
+	// migrating to the latest version is the full migration, which also synchronizes the tables
+	if target != migrations.ExpectedVersion() {
+		return runMigrateTo(ctx, stdCtx, target)
+	}
+
 	if err := db.InitEngineWithMigration(context.Background(), migrations.Migrate); err != nil {
 		log.Fatal("Failed to initialize ORM engine: %v", err)
 		return err
@@ -43,3 +74,69 @@ func runMigrate(ctx *cli.Context) error {
 
 	return nil
 }
+
+// runMigrateTo migrates the database up to the target version, unlike a full migration the tables are not synchronized
+// with the models of this release, because they only match the latest version
+func runMigrateTo(ctx *cli.Context, stdCtx context.Context, target int64) error {
+	if err := db.InitEngine(stdCtx); err != nil {
+		return err
+	}
+	x := db.DefaultContext.(*db.Context).Engine().(*xorm.Engine)
+	if err := migrations.MigrateTo(x, target); err != nil {
+		return cli.Exit(fmt.Sprintf("Unable to migrate the database to version %d: %v", target, err), 1)
+	}
+
+	current, err := migrations.GetCurrentDBVersion(x)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(ctx.App.Writer, "Database version is %d\n", current)
+	if current < migrations.ExpectedVersion() {
+		_, _ = fmt.Fprintf(ctx.App.Writer, "This Gitea release needs version %d to run, run \"gitea migrate\" to apply the remaining migrations\n", migrations.ExpectedVersion())
+	}
+	return nil
+}
+
+func runMigrateDryRun(ctx *cli.Context, stdCtx context.Context, target int64) error {
+	if err := db.InitEngine(stdCtx); err != nil {
+		return err
+	}
+	x := db.DefaultContext.(*db.Context).Engine().(*xorm.Engine)
+	current, pending, err := migrations.PendingMigrations(x)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Unable to compute the pending migrations: %v", err), 1)
+	}
+
+	if current < 0 {
+		if target != migrations.ExpectedVersion() {
+			return cli.Exit(fmt.Sprintf("Database has not been initialized, its tables can only be created with version %d", migrations.ExpectedVersion()), 1)
+		}
+		_, _ = fmt.Fprintf(ctx.App.Writer, "Database has not been initialized, the tables will be created with version %d\n", migrations.ExpectedVersion())
+		return cli.Exit("", migrateDryRunPendingExitCode)
+	}
+	if target > migrations.ExpectedVersion() {
+		return cli.Exit(fmt.Sprintf("Target version %d is beyond the version %d of this Gitea release", target, migrations.ExpectedVersion()), 1)
+	} else if target < current {
+		return cli.Exit(fmt.Sprintf("Database version %d is already beyond the target version %d, migrations can't be reverted", current, target), 1)
+	}
+	// the migration of a version upgrades the database to the next version
+	for i, m := range pending {
+		if m.Version >= target {
+			pending = pending[:i]
+			break
+		}
+	}
+	if len(pending) == 0 {
+		if target == migrations.ExpectedVersion() {
+			_, _ = fmt.Fprintf(ctx.App.Writer, "Database version %d is up-to-date, no migration to run\n", current)
+		} else {
+			_, _ = fmt.Fprintf(ctx.App.Writer, "Database version %d is the target version, no migration to run\n", current)
+		}
+		return nil
+	}
+	_, _ = fmt.Fprintf(ctx.App.Writer, "Database version %d, %d migration(s) to run to version %d:\n", current, len(pending), target)
+	for _, m := range pending {
+		_, _ = fmt.Fprintf(ctx.App.Writer, "Migration[%d]: %s\n", m.Version, m.Description())
+	}
+	return cli.Exit("", migrateDryRunPendingExitCode)
+}
@@ -5,8 +5,11 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	actions_model "code.gitea.io/gitea/models/actions"
 	"code.gitea.io/gitea/models/db"
@@ -15,6 +18,7 @@ import (
 	packages_model "code.gitea.io/gitea/models/packages"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/log"
 	packages_module "code.gitea.io/gitea/modules/packages"
 	"code.gitea.io/gitea/modules/setting"
@@ -36,6 +40,30 @@ var CmdMigrateStorage = &cli.Command{
 			Value:   "",
 			Usage:   "Type of stored files to copy.  Allowed types: 'attachments', 'lfs', 'avatars', 'repo-avatars', 'repo-archivers', 'packages', 'actions-log'",
 		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Only count the files of each type (or the given type) and their total size, nothing is copied and the new storage isn't needed",
+		},
+		&cli.BoolFlag{
+			Name:  "skip-existing",
+			Usage: "Skip the files which the new storage already has with the same size, so an interrupted migration can be resumed",
+		},
+		&cli.BoolFlag{
+			Name:  "verify-checksum",
+			Usage: "Also compare the SHA256 checksums of the files with --skip-existing, it reads the existing files of both storages",
+		},
+		&cli.IntFlag{
+			Name:  "retries",
+			Usage: "Retry a file which can't be copied up to N times, with an exponential backoff from 1s, e.g. for the transient errors of a remote storage",
+		},
+		&cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "Timeout of each attempt to copy a file to or from a minio storage, e.g. 5m, 0 means no timeout",
+		},
+		&cli.BoolFlag{
+			Name:  "fail-fast",
+			Usage: "Stop at the first file which can't be copied, the other files are copied and the failed ones are listed at the end by default",
+		},
 		&cli.StringFlag{
 			Name:    "storage",
 			Aliases: []string{"s"},
@@ -94,51 +122,113 @@ var CmdMigrateStorage = &cli.Command{
 	},
 }
 
-func migrateAttachments(ctx context.Context, dstStorage storage.ObjectStorage) error {
+// migrateStorageOptions represents the options of the migration of the files of a type
+type migrateStorageOptions struct {
+	storage.CopyOptions
+	Retries  int           // how many times a failed file is retried
+	Timeout  time.Duration // the timeout of each attempt to copy a file, 0 means no timeout
+	FailFast bool          // stop at the first file which can't be copied instead of continuing with the others
+
+	failed []string // the files which couldn't be copied
+}
+
+// migrateStorageRetryDelay is the delay before the first retry of a file, it's doubled for each next retry
+var migrateStorageRetryDelay = time.Second
+
+const migrateStorageMaxRetryDelay = time.Minute
+
+// copyToStorage copies the file at the path from the source storage to the same path of the new storage,
+// a failed copy is retried with an exponential backoff, and the file is recorded as failed if the last retry fails
+func copyToStorage(ctx context.Context, dstStorage, srcStorage storage.ObjectStorage, p string, opts *migrateStorageOptions) error {
+	delay := migrateStorageRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := copyToStorageOnce(ctx, dstStorage, srcStorage, p, opts)
+		if err == nil {
+			return nil
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// a missing source file won't appear by retrying
+		if attempt > opts.Retries || errors.Is(err, os.ErrNotExist) {
+			if opts.FailFast {
+				return fmt.Errorf("unable to copy %q: %w", p, err)
+			}
+			log.Error("Unable to copy %q, continuing with the other files: %v", p, err)
+			opts.failed = append(opts.failed, p)
+			return nil
+		}
+		log.Warn("Unable to copy %q (attempt %d of %d), retrying in %v: %v", p, attempt, opts.Retries+1, delay, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > migrateStorageMaxRetryDelay {
+			delay = migrateStorageMaxRetryDelay
+		}
+	}
+}
+
+func copyToStorageOnce(ctx context.Context, dstStorage, srcStorage storage.ObjectStorage, p string, opts *migrateStorageOptions) error {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	_, skipped, err := storage.CopyWithOptions(storage.WithContext(ctx, dstStorage), p, storage.WithContext(ctx, srcStorage), p, opts.CopyOptions)
+	if skipped {
+		log.Debug("Skip %q, the new storage already has it", p)
+	}
+	return err
+}
+
+func migrateAttachments(ctx context.Context, dstStorage storage.ObjectStorage, opts *migrateStorageOptions) error {
 	return db.Iterate(ctx, nil, func(ctx context.Context, attach *repo_model.Attachment) error {
-		_, err := storage.Copy(dstStorage, attach.RelativePath(), storage.Attachments, attach.RelativePath())
-		return err
+		return copyToStorage(ctx, dstStorage, storage.Attachments, attach.RelativePath(), opts)
 	})
 }
 
-func migrateLFS(ctx context.Context, dstStorage storage.ObjectStorage) error {
+func migrateLFS(ctx context.Context, dstStorage storage.ObjectStorage, opts *migrateStorageOptions) error {
 	return db.Iterate(ctx, nil, func(ctx context.Context, mo *git_model.LFSMetaObject) error {
-		_, err := storage.Copy(dstStorage, mo.RelativePath(), storage.LFS, mo.RelativePath())
-		return err
+		return copyToStorage(ctx, dstStorage, storage.LFS, mo.RelativePath(), opts)
 	})
 }
 
-func migrateAvatars(ctx context.Context, dstStorage storage.ObjectStorage) error {
+func migrateAvatars(ctx context.Context, dstStorage storage.ObjectStorage, opts *migrateStorageOptions) error {
 	return db.Iterate(ctx, nil, func(ctx context.Context, user *user_model.User) error {
-		_, err := storage.Copy(dstStorage, user.CustomAvatarRelativePath(), storage.Avatars, user.CustomAvatarRelativePath())
-		return err
+		if user.CustomAvatarRelativePath() == "" {
+			// the user doesn't have a custom avatar
+			return nil
+		}
+		return copyToStorage(ctx, dstStorage, storage.Avatars, user.CustomAvatarRelativePath(), opts)
 	})
 }
 
-func migrateRepoAvatars(ctx context.Context, dstStorage storage.ObjectStorage) error {
+func migrateRepoAvatars(ctx context.Context, dstStorage storage.ObjectStorage, opts *migrateStorageOptions) error {
 	return db.Iterate(ctx, nil, func(ctx context.Context, repo *repo_model.Repository) error {
-		_, err := storage.Copy(dstStorage, repo.CustomAvatarRelativePath(), storage.RepoAvatars, repo.CustomAvatarRelativePath())
-		return err
+		if repo.CustomAvatarRelativePath() == "" {
+			// the repository doesn't have a custom avatar
+			return nil
+		}
+		return copyToStorage(ctx, dstStorage, storage.RepoAvatars, repo.CustomAvatarRelativePath(), opts)
 	})
 }
 
-func migrateRepoArchivers(ctx context.Context, dstStorage storage.ObjectStorage) error {
+func migrateRepoArchivers(ctx context.Context, dstStorage storage.ObjectStorage, opts *migrateStorageOptions) error {
 	return db.Iterate(ctx, nil, func(ctx context.Context, archiver *repo_model.RepoArchiver) error {
 		p := archiver.RelativePath()
-		_, err := storage.Copy(dstStorage, p, storage.RepoArchives, p)
-		return err
+		return copyToStorage(ctx, dstStorage, storage.RepoArchives, p, opts)
 	})
 }
 
-func migratePackages(ctx context.Context, dstStorage storage.ObjectStorage) error {
+func migratePackages(ctx context.Context, dstStorage storage.ObjectStorage, opts *migrateStorageOptions) error {
 	return db.Iterate(ctx, nil, func(ctx context.Context, pb *packages_model.PackageBlob) error {
 		p := packages_module.KeyToRelativePath(packages_module.BlobHash256Key(pb.HashSHA256))
-		_, err := storage.Copy(dstStorage, p, storage.Packages, p)
-		return err
+		return copyToStorage(ctx, dstStorage, storage.Packages, p, opts)
 	})
 }
 
-func migrateActionsLog(ctx context.Context, dstStorage storage.ObjectStorage) error {
+func migrateActionsLog(ctx context.Context, dstStorage storage.ObjectStorage, opts *migrateStorageOptions) error {
 	return db.Iterate(ctx, nil, func(ctx context.Context, task *actions_model.ActionTask) error {
 		if task.LogExpired {
 			// the log has been cleared
@@ -149,15 +239,77 @@ func migrateActionsLog(ctx context.Context, dstStorage storage.ObjectStorage) er
 			return nil
 		}
 		p := task.LogFilename
-		_, err := storage.Copy(dstStorage, p, storage.Actions, p)
-		return err
+		return copyToStorage(ctx, dstStorage, storage.Actions, p, opts)
 	})
 }
 
+var migratedMethods = map[string]func(context.Context, storage.ObjectStorage, *migrateStorageOptions) error{
+	"attachments":    migrateAttachments,
+	"lfs":            migrateLFS,
+	"avatars":        migrateAvatars,
+	"repo-avatars":   migrateRepoAvatars,
+	"repo-archivers": migrateRepoArchivers,
+	"packages":       migratePackages,
+	"actions-log":    migrateActionsLog,
+}
+
+// migratedTypes is the order of the types in the dry-run output
+var migratedTypes = []string{"attachments", "lfs", "avatars", "repo-avatars", "repo-archivers", "packages", "actions-log"}
+
+// runMigrateStorageDryRun walks the files of the type (all types if it is empty) like the migration does,
+// but the destination only counts the files, and outputs a table of the counts and sizes
+func runMigrateStorageDryRun(ctx context.Context, tp string) error {
+	types := migratedTypes
+	if tp != "" {
+		if _, ok := migratedMethods[tp]; !ok {
+			return fmt.Errorf("unsupported storage: %s", tp)
+		}
+		types = []string{tp}
+	}
+
+	formatter, err := newListFormatter("text", os.Stdout)
+	if err != nil {
+		return err
+	}
+	if err = formatter.WriteHeader([]listColumn{{Title: "Type"}, {Title: "Files"}, {Title: "Size"}}); err != nil {
+		return err
+	}
+	var totalCount, totalSize int64
+	for _, t := range types {
+		counter := storage.NewCountingStorage()
+		if err := migratedMethods[t](ctx, counter, &migrateStorageOptions{FailFast: true}); err != nil {
+			_ = formatter.Flush() // show the counted types, the file would also fail to be migrated
+			return fmt.Errorf("%s: %w", t, err)
+		}
+		totalCount += counter.Count
+		totalSize += counter.Size
+		if err = formatter.WriteRow(t, counter.Count, base.FileSize(counter.Size)); err != nil {
+			return err
+		}
+	}
+	if err = formatter.WriteRow("Total", totalCount, base.FileSize(totalSize)); err != nil {
+		return err
+	}
+	return formatter.Flush()
+}
+
 func runMigrateStorage(ctx *cli.Context) error {
 	stdCtx, cancel := installSignals()
 	defer cancel()
 
+	if ctx.Bool("verify-checksum") && !ctx.Bool("skip-existing") {
+		return errors.New("--verify-checksum can only be used with --skip-existing")
+	}
+	if ctx.Bool("dry-run") && ctx.Bool("skip-existing") {
+		return errors.New("--skip-existing can't be used with --dry-run, the dry-run doesn't use the new storage")
+	}
+	if ctx.Int("retries") < 0 {
+		return fmt.Errorf("invalid --retries %d, it can't be negative", ctx.Int("retries"))
+	}
+	if ctx.Duration("timeout") < 0 {
+		return fmt.Errorf("invalid --timeout %v, it can't be negative", ctx.Duration("timeout"))
+	}
+
 	if err := initDB(stdCtx); err != nil {
 		return err
 	}
@@ -168,7 +320,12 @@ func runMigrateStorage(ctx *cli.Context) error {
 	log.Info("Log path: %s", setting.Log.RootPath)
 	log.Info("Configuration file: %s", setting.CustomConf)
 
-	if err := db.InitEngineWithMigration(context.Background(), migrations.Migrate); err != nil {
+	// the dry-run must not change anything, so it doesn't migrate the database
+	migrateDB := migrations.Migrate
+	if ctx.Bool("dry-run") {
+		migrateDB = migrations.EnsureUpToDate
+	}
+	if err := db.InitEngineWithMigration(context.Background(), migrateDB); err != nil {
 		log.Fatal("Failed to initialize ORM engine: %v", err)
 		return err
 	}
@@ -177,6 +334,10 @@ func runMigrateStorage(ctx *cli.Context) error {
 		return err
 	}
 
+	if ctx.Bool("dry-run") {
+		return runMigrateStorageDryRun(stdCtx, strings.ToLower(ctx.String("type")))
+	}
+
 	var dstStorage storage.ObjectStorage
 	var err error
 	switch strings.ToLower(ctx.String("storage")) {
@@ -216,21 +377,27 @@ func runMigrateStorage(ctx *cli.Context) error {
 		return err
 	}
 
-	migratedMethods := map[string]func(context.Context, storage.ObjectStorage) error{
-		"attachments":    migrateAttachments,
-		"lfs":            migrateLFS,
-		"avatars":        migrateAvatars,
-		"repo-avatars":   migrateRepoAvatars,
-		"repo-archivers": migrateRepoArchivers,
-		"packages":       migratePackages,
-		"actions-log":    migrateActionsLog,
-	}
-
 	tp := strings.ToLower(ctx.String("type"))
 	if m, ok := migratedMethods[tp]; ok {
-		if err := m(stdCtx, dstStorage); err != nil {
+		opts := &migrateStorageOptions{
+			CopyOptions: storage.CopyOptions{
+				SkipExisting:   ctx.Bool("skip-existing"),
+				VerifyChecksum: ctx.Bool("verify-checksum"),
+			},
+			Retries:  ctx.Int("retries"),
+			Timeout:  ctx.Duration("timeout"),
+			FailFast: ctx.Bool("fail-fast"),
+		}
+		if err := m(stdCtx, dstStorage, opts); err != nil {
 			return err
 		}
+		if len(opts.failed) > 0 {
+			_, _ = fmt.Fprintf(ctx.App.ErrWriter, "%d %s files couldn't be copied to the new storage:\n", len(opts.failed), tp)
+			for _, p := range opts.failed {
+				_, _ = fmt.Fprintf(ctx.App.ErrWriter, "  %s\n", p)
+			}
+			return fmt.Errorf("%d %s files couldn't be copied to the new storage", len(opts.failed), tp)
+		}
 		log.Info("%s files have successfully been copied to the new storage.", tp)
 		return nil
 	}
@@ -5,11 +5,16 @@ package cmd
 
 import (
 	"context"
+	"errors"
+	"io"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/packages"
+	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unittest"
 	user_model "code.gitea.io/gitea/models/user"
 	packages_module "code.gitea.io/gitea/modules/packages"
@@ -63,7 +68,11 @@ func TestMigratePackages(t *testing.T) {
 		})
 	assert.NoError(t, err)
 
-	err = migratePackages(ctx, dstStorage)
+	err = migratePackages(ctx, dstStorage, &migrateStorageOptions{})
+	assert.NoError(t, err)
+
+	// resuming the migration skips the existing blob
+	err = migratePackages(ctx, dstStorage, &migrateStorageOptions{CopyOptions: storage.CopyOptions{SkipExisting: true, VerifyChecksum: true}})
 	assert.NoError(t, err)
 
 	entries, err := os.ReadDir(p)
@@ -72,3 +81,81 @@ func TestMigratePackages(t *testing.T) {
 	assert.EqualValues(t, "01", entries[0].Name())
 	assert.EqualValues(t, "tmp", entries[1].Name())
 }
+
+func TestMigrateAvatars(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	ctx := context.Background()
+
+	// only one user has a custom avatar, the users and repositories without one are skipped
+	_, err := db.GetEngine(ctx).Where("id > 0").Cols("avatar").Update(&user_model.User{Avatar: ""})
+	assert.NoError(t, err)
+	_, err = db.GetEngine(ctx).ID(2).Cols("avatar").Update(&user_model.User{Avatar: "migrate-avatar"})
+	assert.NoError(t, err)
+	_, err = db.GetEngine(ctx).Where("id > 0").Cols("avatar").Update(&repo_model.Repository{Avatar: ""})
+	assert.NoError(t, err)
+	_, err = storage.Avatars.Save("migrate-avatar", strings.NewReader("avatar"), 6)
+	assert.NoError(t, err)
+	defer func() { _ = storage.Avatars.Delete("migrate-avatar") }()
+
+	dstStorage, err := storage.NewLocalStorage(ctx, &setting.Storage{Path: t.TempDir()})
+	assert.NoError(t, err)
+
+	assert.NoError(t, migrateAvatars(ctx, dstStorage, &migrateStorageOptions{}))
+	_, err = dstStorage.Stat("migrate-avatar")
+	assert.NoError(t, err)
+
+	assert.NoError(t, migrateRepoAvatars(ctx, dstStorage, &migrateStorageOptions{}))
+}
+
+// flakyStorage fails the first saves like a remote storage with transient network errors
+type flakyStorage struct {
+	storage.ObjectStorage
+	failures int
+}
+
+func (s *flakyStorage) Save(path string, r io.Reader, size int64) (int64, error) {
+	if s.failures > 0 {
+		s.failures--
+		return 0, errors.New("connection reset by peer")
+	}
+	return s.ObjectStorage.Save(path, r, size)
+}
+
+func TestCopyToStorageRetries(t *testing.T) {
+	defer func(delay time.Duration) { migrateStorageRetryDelay = delay }(migrateStorageRetryDelay)
+	migrateStorageRetryDelay = time.Millisecond
+
+	ctx := context.Background()
+	srcStorage, err := storage.NewLocalStorage(ctx, &setting.Storage{Path: t.TempDir()})
+	assert.NoError(t, err)
+	_, err = srcStorage.Save("a", strings.NewReader("content"), 7)
+	assert.NoError(t, err)
+	localDst, err := storage.NewLocalStorage(ctx, &setting.Storage{Path: t.TempDir()})
+	assert.NoError(t, err)
+
+	// the file is copied by the last retry
+	dstStorage := &flakyStorage{ObjectStorage: localDst, failures: 2}
+	opts := &migrateStorageOptions{Retries: 2}
+	assert.NoError(t, copyToStorage(ctx, dstStorage, srcStorage, "a", opts))
+	assert.Empty(t, opts.failed)
+	_, err = localDst.Stat("a")
+	assert.NoError(t, err)
+
+	// the file is recorded as failed once the retries are exhausted, and the migration continues
+	dstStorage = &flakyStorage{ObjectStorage: localDst, failures: 3}
+	opts = &migrateStorageOptions{Retries: 1}
+	assert.NoError(t, copyToStorage(ctx, dstStorage, srcStorage, "a", opts))
+	assert.Equal(t, []string{"a"}, opts.failed)
+	assert.Equal(t, 1, dstStorage.failures)
+
+	// a missing source file isn't retried
+	opts = &migrateStorageOptions{Retries: 3}
+	assert.NoError(t, copyToStorage(ctx, dstStorage, srcStorage, "missing", opts))
+	assert.Equal(t, []string{"missing"}, opts.failed)
+	assert.Equal(t, 1, dstStorage.failures)
+
+	// --fail-fast stops at the failed file
+	opts = &migrateStorageOptions{FailFast: true}
+	assert.ErrorContains(t, copyToStorage(ctx, dstStorage, srcStorage, "a", opts), "connection reset by peer")
+	assert.Empty(t, opts.failed)
+}
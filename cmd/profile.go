@@ -0,0 +1,71 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+
+	"github.com/urfave/cli/v2"
+)
+
+// globalProfilePaths returns the paths of the "--cpuprofile" and "--memprofile" flags from the command or its parents
+func globalProfilePaths(ctx *cli.Context) (cpuProfile, memProfile string) {
+	for _, curCtx := range ctx.Lineage() {
+		if curCtx.IsSet("cpuprofile") && cpuProfile == "" {
+			cpuProfile = curCtx.String("cpuprofile")
+		}
+		if curCtx.IsSet("memprofile") && memProfile == "" {
+			memProfile = curCtx.String("memprofile")
+		}
+	}
+	return cpuProfile, memProfile
+}
+
+// startProfiling starts the CPU profiling if cpuProfile is set, the returned function must be called when the command finishes,
+// it stops the CPU profiling and writes the heap profile to memProfile if it is set
+func startProfiling(cpuProfile, memProfile string) (stop func() error, err error) {
+	var cpuFile *os.File
+	if cpuProfile != "" {
+		if cpuFile, err = os.Create(cpuProfile); err != nil {
+			return nil, fmt.Errorf("unable to create the CPU profile: %w", err)
+		}
+		if err = pprof.StartCPUProfile(cpuFile); err != nil {
+			_ = cpuFile.Close()
+			return nil, fmt.Errorf("unable to start the CPU profiling: %w", err)
+		}
+	}
+
+	return func() error {
+		var errs []error
+		if cpuFile != nil {
+			pprof.StopCPUProfile()
+			if err := cpuFile.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("unable to write the CPU profile: %w", err))
+			}
+		}
+		if memProfile != "" {
+			if err := writeHeapProfile(memProfile); err != nil {
+				errs = append(errs, fmt.Errorf("unable to write the memory profile: %w", err))
+			}
+		}
+		return errors.Join(errs...)
+	}, nil
+}
+
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	runtime.GC() // get up-to-date statistics
+	if err = pprof.WriteHeapProfile(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -4,20 +4,31 @@
 package cmd
 
 import (
-	"strings"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/private"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/migrations"
 
 	"github.com/urfave/cli/v2"
 )
 
 // CmdRestoreRepository represents the available restore a repository sub-command.
 var CmdRestoreRepository = &cli.Command{
-	Name:        "restore-repo",
-	Usage:       "Restore the repository from disk",
-	Description: "This is a command for restoring the repository data.",
-	Action:      runRestoreRepository,
+	Name:  "restore-repo",
+	Usage: "Restore the repository from disk",
+	Description: `This is a command for restoring the repository data.
+The repository is restored under the owner and name of the dumped repository unless --owner or --rename-to is given,
+the restore fails if the destination repository already exists.`,
+	Action:       runRestoreRepository,
+	BashComplete: completeFlagValues(map[string]completionValuesFunc{"owner_name": completeOwners, "owner": completeOwners}),
 	Flags: []cli.Flag{
 		&cli.StringFlag{
 			Name:    "repo_dir",
@@ -26,25 +37,36 @@ var CmdRestoreRepository = &cli.Command{
 			Usage:   "Repository dir path to restore from",
 		},
 		&cli.StringFlag{
-			Name:  "owner_name",
-			Value: "",
-			Usage: "Restore destination owner name",
+			Name:    "owner_name",
+			Aliases: []string{"owner"},
+			Value:   "",
+			Usage:   "Restore destination owner name, defaults to the owner of the dumped repository",
 		},
 		&cli.StringFlag{
-			Name:  "repo_name",
-			Value: "",
-			Usage: "Restore destination repository name",
+			Name:    "repo_name",
+			Aliases: []string{"rename-to"},
+			Value:   "",
+			Usage:   "Restore destination repository name, defaults to the name of the dumped repository",
 		},
 		&cli.StringFlag{
 			Name:  "units",
 			Value: "",
 			Usage: `Which items will be restored, one or more units should be separated as comma.
-wiki, issues, labels, releases, release_assets, milestones, pull_requests, comments are allowed. Empty means all units.`,
+wiki, issues, labels, releases, release_assets, milestones, pull_requests (or pulls), comments are allowed. Empty means all units.
+Unknown units are rejected, the units missing in the dump are skipped with a warning.`,
 		},
 		&cli.BoolFlag{
 			Name:  "validation",
 			Usage: "Sanity check the content of the files before trying to load them",
 		},
+		&cli.BoolFlag{
+			Name:  "validate-only",
+			Usage: "Only check the integrity of the dump and report the first problem, nothing is restored",
+		},
+		&cli.BoolFlag{
+			Name:  "report-all",
+			Usage: "Report all the problems of the dump instead of the first one, with --validate-only",
+		},
 	},
 }
 
@@ -52,10 +74,24 @@ func runRestoreRepository(c *cli.Context) error {
 	ctx, cancel := installSignals()
 	defer cancel()
 
+	if c.Bool("report-all") && !c.Bool("validate-only") {
+		return cli.Exit("--report-all can only be used with --validate-only", 1)
+	}
+
 	setting.MustInstalled()
-	var units []string
-	if s := c.String("units"); s != "" {
-		units = strings.Split(s, ",")
+	units, err := migrations.ParseRepoDumpUnits(c.String("units"))
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+	if err := warnDumpManifestVersion(c.App.ErrWriter, c.String("repo_dir"), setting.AppVer); err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+	// the units missing in the dump are restored as empty, warn about them so that the user won't expect them to be restored
+	for _, unit := range migrations.MissingRepoDumpUnits(c.String("repo_dir"), units) {
+		_, _ = fmt.Fprintf(c.App.ErrWriter, "Warning: unit %q is not found in the dump %q, skip it\n", unit, c.String("repo_dir"))
+	}
+	if c.Bool("validate-only") {
+		return runValidateRepoDump(ctx, c, units)
 	}
 	extra := private.RestoreRepo(
 		ctx,
@@ -67,3 +103,41 @@ func runRestoreRepository(c *cli.Context) error {
 	)
 	return handleCliResponseExtra(extra)
 }
+
+// runValidateRepoDump checks the dump locally, neither the database nor the running server are used
+func runValidateRepoDump(ctx context.Context, c *cli.Context, units []string) error {
+	if err := git.InitSimple(ctx); err != nil {
+		return err
+	}
+	problems := migrations.ValidateRepoDump(ctx, c.String("repo_dir"), units, c.Bool("report-all"))
+	for _, problem := range problems {
+		_, _ = fmt.Fprintln(c.App.ErrWriter, problem)
+	}
+	if len(problems) > 0 {
+		if c.Bool("report-all") {
+			return cli.Exit(fmt.Sprintf("The dump %q has %d problems", c.String("repo_dir"), len(problems)), 1)
+		}
+		return cli.Exit(fmt.Sprintf("The dump %q is invalid", c.String("repo_dir")), 1)
+	}
+	_, _ = fmt.Fprintf(c.App.Writer, "The dump %q is valid\n", c.String("repo_dir"))
+	return nil
+}
+
+// warnDumpManifestVersion warns if the manifest of the dump in the directory was written by another version of Gitea,
+// a dump without a manifest isn't checked
+func warnDumpManifestVersion(w io.Writer, dir, appVer string) error {
+	content, err := os.ReadFile(filepath.Join(dir, dumpManifestName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to read the manifest of the dump: %w", err)
+	}
+	var manifest dumpManifest
+	if err = json.Unmarshal(content, &manifest); err != nil {
+		return fmt.Errorf("unable to parse the manifest of the dump %s: %w", filepath.Join(dir, dumpManifestName), err)
+	}
+	if manifest.GiteaVersion != appVer {
+		_, _ = fmt.Fprintf(w, "Warning: the dump was created by Gitea %s, it is restored into Gitea %s\n", manifest.GiteaVersion, appVer)
+	}
+	return nil
+}
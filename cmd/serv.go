@@ -53,6 +53,11 @@ var CmdServ = &cli.Command{
 		&cli.BoolFlag{
 			Name: "debug",
 		},
+		&cli.BoolFlag{
+			Name:    "trace",
+			Usage:   "Log each step of the access decision (parsed command, key, user, permission checks and the result) to the server log",
+			EnvVars: []string{"GITEA_SERV_TRACE"},
+		},
 	},
 }
 
@@ -122,6 +127,13 @@ func fail(ctx context.Context, userMessage, logMsgFmt string, args ...any) error
 	return cli.Exit("", 1)
 }
 
+// servTrace sends a trace message to the server log if the tracing of "gitea serv" is enabled
+func servTrace(ctx context.Context, enabled bool, format string, args ...any) {
+	if enabled {
+		_ = private.SSHTrace(ctx, fmt.Sprintf(format, args...))
+	}
+}
+
 // handleCliResponseExtra handles the extra response from the cli sub-commands
 // If there is a user message it will be printed to stdout
 // If the command failed it will return an error (the error will be printed by cli framework)
@@ -142,6 +154,12 @@ func runServ(c *cli.Context) error {
 	// FIXME: This needs to internationalised
 	setup(ctx, c.Bool("debug"))
 
+	trace := c.Bool("trace")
+	failServ := func(userMessage, logMsgFmt string, args ...any) error {
+		servTrace(ctx, trace, "%s: failed: %s (%s)", c.Args().First(), userMessage, fmt.Sprintf(logMsgFmt, args...))
+		return fail(ctx, userMessage, logMsgFmt, args...)
+	}
+
 	if setting.SSH.Disabled {
 		println("Gitea: SSH has been disabled")
 		return nil
@@ -156,18 +174,19 @@ func runServ(c *cli.Context) error {
 
 	keys := strings.Split(c.Args().First(), "-")
 	if len(keys) != 2 || keys[0] != "key" {
-		return fail(ctx, "Key ID format error", "Invalid key argument: %s", c.Args().First())
+		return failServ("Key ID format error", "Invalid key argument: %s", c.Args().First())
 	}
 	keyID, err := strconv.ParseInt(keys[1], 10, 64)
 	if err != nil {
-		return fail(ctx, "Key ID parsing error", "Invalid key argument: %s", c.Args().Get(1))
+		return failServ("Key ID parsing error", "Invalid key argument: %s", c.Args().Get(1))
 	}
 
 	cmd := os.Getenv("SSH_ORIGINAL_COMMAND")
+	servTrace(ctx, trace, "key-%d: SSH_ORIGINAL_COMMAND %q", keyID, cmd)
 	if len(cmd) == 0 {
 		key, user, err := private.ServNoCommand(ctx, keyID)
 		if err != nil {
-			return fail(ctx, "Key check failed", "Failed to check provided key: %v", err)
+			return failServ("Key check failed", "Failed to check provided key: %v", err)
 		}
 		switch key.Type {
 		case asymkey_model.KeyTypeDeploy:
@@ -185,7 +204,7 @@ func runServ(c *cli.Context) error {
 
 	words, err := shellquote.Split(cmd)
 	if err != nil {
-		return fail(ctx, "Error parsing arguments", "Failed to parse arguments: %v", err)
+		return failServ("Error parsing arguments", "Failed to parse arguments: %v", err)
 	}
 
 	if len(words) < 2 {
@@ -196,7 +215,7 @@ func runServ(c *cli.Context) error {
 				return nil
 			}
 		}
-		return fail(ctx, "Too few arguments", "Too few arguments in cmd: %s", cmd)
+		return failServ("Too few arguments", "Too few arguments in cmd: %s", cmd)
 	}
 
 	verb := words[0]
@@ -208,7 +227,7 @@ func runServ(c *cli.Context) error {
 	var lfsVerb string
 	if verb == lfsAuthenticateVerb {
 		if !setting.LFS.StartServer {
-			return fail(ctx, "Unknown git command", "LFS authentication request over SSH denied, LFS support is disabled")
+			return failServ("Unknown git command", "LFS authentication request over SSH denied, LFS support is disabled")
 		}
 
 		if len(words) > 2 {
@@ -221,37 +240,37 @@ func runServ(c *cli.Context) error {
 
 	rr := strings.SplitN(repoPath, "/", 2)
 	if len(rr) != 2 {
-		return fail(ctx, "Invalid repository path", "Invalid repository path: %v", repoPath)
+		return failServ("Invalid repository path", "Invalid repository path: %v", repoPath)
 	}
 
 	username := strings.ToLower(rr[0])
 	reponame := strings.ToLower(strings.TrimSuffix(rr[1], ".git"))
 
 	if alphaDashDotPattern.MatchString(reponame) {
-		return fail(ctx, "Invalid repo name", "Invalid repo name: %s", reponame)
+		return failServ("Invalid repo name", "Invalid repo name: %s", reponame)
 	}
 
 	if c.Bool("enable-pprof") {
 		if err := os.MkdirAll(setting.PprofDataPath, os.ModePerm); err != nil {
-			return fail(ctx, "Error while trying to create PPROF_DATA_PATH", "Error while trying to create PPROF_DATA_PATH: %v", err)
+			return failServ("Error while trying to create PPROF_DATA_PATH", "Error while trying to create PPROF_DATA_PATH: %v", err)
 		}
 
 		stopCPUProfiler, err := pprof.DumpCPUProfileForUsername(setting.PprofDataPath, username)
 		if err != nil {
-			return fail(ctx, "Unable to start CPU profiler", "Unable to start CPU profile: %v", err)
+			return failServ("Unable to start CPU profiler", "Unable to start CPU profile: %v", err)
 		}
 		defer func() {
 			stopCPUProfiler()
 			err := pprof.DumpMemProfileForUsername(setting.PprofDataPath, username)
 			if err != nil {
-				_ = fail(ctx, "Unable to dump Mem profile", "Unable to dump Mem Profile: %v", err)
+				_ = failServ("Unable to dump Mem profile", "Unable to dump Mem Profile: %v", err)
 			}
 		}()
 	}
 
 	requestedMode, has := allowedCommands[verb]
 	if !has {
-		return fail(ctx, "Unknown git command", "Unknown git command %s", verb)
+		return failServ("Unknown git command", "Unknown git command %s", verb)
 	}
 
 	if verb == lfsAuthenticateVerb {
@@ -260,13 +279,14 @@ func runServ(c *cli.Context) error {
 		} else if lfsVerb == "download" {
 			requestedMode = perm.AccessModeRead
 		} else {
-			return fail(ctx, "Unknown LFS verb", "Unknown lfs verb %s", lfsVerb)
+			return failServ("Unknown LFS verb", "Unknown lfs verb %s", lfsVerb)
 		}
 	}
+	servTrace(ctx, trace, "key-%d: parsed verb %q (lfs verb %q) on %s/%s, requesting mode %s", keyID, verb, lfsVerb, username, reponame, requestedMode.String())
 
-	results, extra := private.ServCommand(ctx, keyID, username, reponame, requestedMode, verb, lfsVerb)
+	results, extra := private.ServCommand(ctx, keyID, username, reponame, requestedMode, trace, verb, lfsVerb)
 	if extra.HasError() {
-		return fail(ctx, extra.UserMsg, "ServCommand failed: %s", extra.Error)
+		return failServ(extra.UserMsg, "ServCommand failed: %s", extra.Error)
 	}
 
 	// LFS token authentication
@@ -288,7 +308,7 @@ func runServ(c *cli.Context) error {
 		// Sign and get the complete encoded token as a string using the secret
 		tokenString, err := token.SignedString(setting.LFS.JWTSecretBytes)
 		if err != nil {
-			return fail(ctx, "Failed to sign JWT Token", "Failed to sign JWT token: %v", err)
+			return failServ("Failed to sign JWT Token", "Failed to sign JWT token: %v", err)
 		}
 
 		tokenAuthentication := &git_model.LFSTokenResponse{
@@ -300,7 +320,7 @@ func runServ(c *cli.Context) error {
 		enc := json.NewEncoder(os.Stdout)
 		err = enc.Encode(tokenAuthentication)
 		if err != nil {
-			return fail(ctx, "Failed to encode LFS json response", "Failed to encode LFS json response: %v", err)
+			return failServ("Failed to encode LFS json response", "Failed to encode LFS json response: %v", err)
 		}
 		return nil
 	}
@@ -346,13 +366,13 @@ func runServ(c *cli.Context) error {
 	gitcmd.Env = append(gitcmd.Env, git.CommonCmdServEnvs()...)
 
 	if err = gitcmd.Run(); err != nil {
-		return fail(ctx, "Failed to execute git command", "Failed to execute git command: %v", err)
+		return failServ("Failed to execute git command", "Failed to execute git command: %v", err)
 	}
 
 	// Update user key activity.
 	if results.KeyID > 0 {
 		if err = private.UpdatePublicKeyInRepo(ctx, results.KeyID, results.RepoID); err != nil {
-			return fail(ctx, "Failed to update public key", "UpdatePublicKeyInRepo: %v", err)
+			return failServ("Failed to update public key", "UpdatePublicKeyInRepo: %v", err)
 		}
 	}
 
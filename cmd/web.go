@@ -5,15 +5,16 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"net/http/pprof"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
-
-	_ "net/http/pprof" // Used for debugging if enabled and a web server is running
+	"sync"
 
 	"code.gitea.io/gitea/modules/container"
 	"code.gitea.io/gitea/modules/graceful"
@@ -46,17 +47,44 @@ and it takes care of all the other things for you`,
 			Value:   "3000",
 			Usage:   "Temporary port number to prevent conflict",
 		},
+		&cli.StringSliceFlag{
+			Name:  "listen",
+			Usage: "Listen on the address instead of HTTP_ADDR and HTTP_PORT, it can be used multiple times. The address is 'host:port' or a unix socket path like 'unix:/path/to/gitea.sock'",
+		},
 		&cli.StringFlag{
 			Name:  "install-port",
 			Value: "3000",
 			Usage: "Temporary port number to run the install page on to prevent conflict",
 		},
+		&cli.BoolFlag{
+			Name:    "disable-install-page",
+			Aliases: []string{"install-lock"},
+			Usage:   "Never serve the install page whatever INSTALL_LOCK is, Gitea refuses to start if it isn't installed",
+		},
 		&cli.StringFlag{
 			Name:    "pid",
 			Aliases: []string{"P"},
 			Value:   PIDFile,
 			Usage:   "Custom pid file path",
 		},
+		&cli.DurationFlag{
+			Name:  "shutdown-timeout",
+			Usage: "How long the graceful shutdown waits for the running requests before forcibly closing the connections, it overrides GRACEFUL_HAMMER_TIME",
+		},
+		&cli.BoolFlag{
+			Name:  "enable-pprof",
+			Usage: "Serve the pprof profiling endpoints on the separate --pprof-addr listener, like ENABLE_PPROF",
+		},
+		&cli.StringFlag{
+			Name:  "pprof-addr",
+			Value: "localhost:6060",
+			Usage: "Address ('host:port') of the pprof listener, it shouldn't be reachable from a public network",
+		},
+		&cli.BoolFlag{
+			Name:    "watch-config",
+			Aliases: []string{"graceful-restart-on-config-change"},
+			Usage:   "Restart the server gracefully when the config file changes, for trying out config changes. An invalid config is logged and the server keeps running with the old one",
+		},
 		&cli.BoolFlag{
 			Name:    "quiet",
 			Aliases: []string{"q"},
@@ -91,6 +119,17 @@ func runHTTPRedirector() {
 	}
 }
 
+func runManagerListener() {
+	_, _, finished := process.GetManager().AddTypedContext(graceful.GetManager().HammerContext(), "Web: Manager Listener", process.SystemProcessType, true)
+	defer finished()
+
+	log.Info("Manager API listen: http://%s", setting.ManagerListenAddr)
+	err := runHTTP("tcp", setting.ManagerListenAddr, "Manager", routers.ManagerRoutes(), false)
+	if err != nil {
+		log.Fatal("Failed to start manager listener: %v", err)
+	}
+}
+
 func createPIDFile(pidPath string) {
 	currentPid := os.Getpid()
 	if err := os.MkdirAll(filepath.Dir(pidPath), os.ModePerm); err != nil {
@@ -105,6 +144,21 @@ func createPIDFile(pidPath string) {
 	if _, err := file.WriteString(strconv.FormatInt(int64(currentPid), 10)); err != nil {
 		log.Fatal("Failed to write PID information: %v", err)
 	}
+
+	// remove the PID file on clean shutdown, unless it has been taken over by the child process of a graceful restart
+	graceful.GetManager().RunAtTerminate(func() {
+		content, err := os.ReadFile(pidPath)
+		if err != nil {
+			log.Error("Failed to read PID file: %v", err)
+			return
+		}
+		if strings.TrimSpace(string(content)) != strconv.Itoa(currentPid) {
+			return
+		}
+		if err := os.Remove(pidPath); err != nil {
+			log.Error("Failed to remove PID file: %v", err)
+		}
+	})
 }
 
 func serveInstall(ctx *cli.Context) error {
@@ -129,7 +183,7 @@ func serveInstall(ctx *cli.Context) error {
 		}
 	}
 	c := install.Routes()
-	err := listen(c, false)
+	err := listen(c, false, ctx.StringSlice("listen"))
 	if err != nil {
 		log.Critical("Unable to open listener for installer. Is Gitea already running?")
 		graceful.GetManager().DoGracefulShutdown()
@@ -148,6 +202,9 @@ func serveInstall(ctx *cli.Context) error {
 func serveInstalled(ctx *cli.Context) error {
 	setting.InitCfgProvider(setting.CustomConf)
 	setting.LoadCommonSettings()
+	if ctx.Bool("disable-install-page") {
+		setting.InstallLock = true // the config is loaded again, keep the override of disableInstallPage
+	}
 	setting.MustInstalled()
 
 	log.Info("Gitea version: %s%s", setting.AppVer, setting.AppBuiltWith)
@@ -207,22 +264,52 @@ func serveInstalled(ctx *cli.Context) error {
 		}
 	}
 
+	// Override the graceful hammer time within the configuration, it is only read when shutting down
+	if ctx.IsSet("shutdown-timeout") {
+		setting.GracefulHammerTime = ctx.Duration("shutdown-timeout")
+		log.Info("Graceful shutdown timeout: %v", setting.GracefulHammerTime)
+	}
+
+	// The manager API is also served on a TCP address for the `gitea manager --manager-addr` commands from other hosts
+	if setting.ManagerListenAddr != "" {
+		if setting.ManagerToken == "" {
+			log.Fatal("[server] MANAGER_LISTEN_ADDR is set but [security] MANAGER_TOKEN is missing, the manager listener would reject all the requests")
+		}
+		graceful.GetManager().RegisterServers(1)
+		go runManagerListener()
+	}
+
+	if ctx.Bool("watch-config") {
+		go watchConfig(graceful.GetManager().ShutdownContext())
+	}
+
 	// Set up Chi routes
 	c := routers.NormalRoutes()
-	err := listen(c, true)
+	err := listen(c, true, ctx.StringSlice("listen"))
 	<-graceful.GetManager().Done()
 	log.Info("PID: %d Gitea Web Finished", os.Getpid())
 	log.GetManager().Close()
 	return err
 }
 
-func servePprof() {
-	http.DefaultServeMux.Handle("/debug/fgprof", fgprof.Handler())
-	_, _, finished := process.GetManager().AddTypedContext(context.Background(), "Web: PProf Server", process.SystemProcessType, true)
-	// The pprof server is for debug purpose only, it shouldn't be exposed on public network. At the moment it's not worth to introduce a configurable option for it.
-	log.Info("Starting pprof server on localhost:6060")
-	log.Info("Stopped pprof server: %v", http.ListenAndServe("localhost:6060", nil))
-	finished()
+// servePprof serves the profiling endpoints on their own listener, which is shut down with the web server.
+// The pprof server is for debug purpose only, it shouldn't be exposed on public network.
+func servePprof(addr string) {
+	_, _, finished := process.GetManager().AddTypedContext(graceful.GetManager().HammerContext(), "Web: PProf Server", process.SystemProcessType, true)
+	defer finished()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/fgprof", fgprof.Handler())
+
+	log.Info("PProf server listen: http://%s/debug/pprof/", addr)
+	if err := runHTTP("tcp", addr, "PProf", mux, false); err != nil {
+		log.Error("Failed to start pprof server: %v", err)
+	}
 }
 
 func runWeb(ctx *cli.Context) error {
@@ -247,6 +334,12 @@ func runWeb(ctx *cli.Context) error {
 		createPIDFile(ctx.String("pid"))
 	}
 
+	if ctx.Bool("disable-install-page") {
+		if err := disableInstallPage(); err != nil {
+			return err
+		}
+	}
+
 	if !setting.InstallLock {
 		if err := serveInstall(ctx); err != nil {
 			return err
@@ -255,13 +348,30 @@ func runWeb(ctx *cli.Context) error {
 		NoInstallListener()
 	}
 
-	if setting.EnablePprof {
-		go servePprof()
+	if setting.EnablePprof || ctx.Bool("enable-pprof") {
+		graceful.GetManager().RegisterServers(1)
+		go servePprof(ctx.String("pprof-addr"))
 	}
 
 	return serveInstalled(ctx)
 }
 
+// disableInstallPage makes the server start as installed for --disable-install-page, so the install page is never exposed
+// by a provisioning mistake like a config without INSTALL_LOCK. Only a missing config file, which can't be installed, is an error.
+func disableInstallPage() error {
+	if setting.InstallLock {
+		log.Info("The install page is disabled by --disable-install-page")
+		return nil
+	}
+	if setting.CfgProvider.IsLoadedFromEmpty() {
+		return fmt.Errorf("the install page is disabled by --disable-install-page, but the config file %q doesn't exist", setting.CustomConf)
+	}
+	log.Warn("INSTALL_LOCK isn't true in the config file %q, but the install page is disabled by --disable-install-page: "+
+		"Gitea starts as installed with this config. Set INSTALL_LOCK = true in it to remove this warning", setting.CustomConf)
+	setting.InstallLock = true
+	return nil
+}
+
 func setPort(port string) error {
 	setting.AppURL = strings.Replace(setting.AppURL, setting.HTTPPort, port, 1)
 	setting.HTTPPort = port
@@ -294,14 +404,61 @@ func setPort(port string) error {
 	return nil
 }
 
-func listen(m http.Handler, handleRedirector bool) error {
-	listenAddr := setting.HTTPAddr
-	if setting.Protocol != setting.HTTPUnix && setting.Protocol != setting.FCGIUnix {
-		listenAddr = net.JoinHostPort(listenAddr, setting.HTTPPort)
+// webListenAddr is an address for the web server to listen on
+type webListenAddr struct {
+	network string // "tcp" or "unix"
+	addr    string
+}
+
+// parseWebListenAddrs parses the "--listen" addresses, if there is none, the address is made from HTTP_ADDR and HTTP_PORT
+func parseWebListenAddrs(addrs []string) ([]webListenAddr, error) {
+	if len(addrs) == 0 {
+		if setting.Protocol == setting.HTTPUnix || setting.Protocol == setting.FCGIUnix {
+			return []webListenAddr{{network: "unix", addr: setting.HTTPAddr}}, nil
+		}
+		return []webListenAddr{{network: "tcp", addr: net.JoinHostPort(setting.HTTPAddr, setting.HTTPPort)}}, nil
+	}
+
+	listenAddrs := make([]webListenAddr, 0, len(addrs))
+	seen := make(container.Set[string])
+	for _, addr := range addrs {
+		listenAddr := webListenAddr{network: "tcp", addr: addr}
+		if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+			listenAddr = webListenAddr{network: "unix", addr: path}
+		} else if filepath.IsAbs(addr) {
+			listenAddr = webListenAddr{network: "unix", addr: addr}
+		} else if _, _, err := net.SplitHostPort(addr); err != nil {
+			return nil, fmt.Errorf("invalid listen address %q: %w", addr, err)
+		}
+		if listenAddr.addr == "" {
+			return nil, fmt.Errorf("invalid listen address %q: empty unix socket path", addr)
+		}
+		if !seen.Add(listenAddr.network + ":" + listenAddr.addr) {
+			return nil, fmt.Errorf("duplicate listen address %q", addr)
+		}
+		listenAddrs = append(listenAddrs, listenAddr)
+	}
+	return listenAddrs, nil
+}
+
+func listen(m http.Handler, handleRedirector bool, addrs []string) error {
+	listenAddrs, err := parseWebListenAddrs(addrs)
+	if err != nil {
+		return err
 	}
+	if setting.Protocol == setting.HTTPS && setting.EnableAcme && (len(listenAddrs) > 1 || listenAddrs[0].network != "tcp") {
+		return errors.New("ACME can only be used with a single TCP listen address")
+	}
+
 	_, _, finished := process.GetManager().AddTypedContext(graceful.GetManager().HammerContext(), "Web: Gitea Server", process.SystemProcessType, true)
 	defer finished()
-	log.Info("Listen: %v://%s%s", setting.Protocol, listenAddr, setting.AppSubURL)
+	for _, listenAddr := range listenAddrs {
+		scheme := string(setting.Protocol)
+		if listenAddr.network == "unix" && !strings.HasSuffix(scheme, "+unix") {
+			scheme += "+unix"
+		}
+		log.Info("Listen: %s://%s%s", scheme, listenAddr.addr, setting.AppSubURL)
+	}
 	// This can be useful for users, many users do wrong to their config and get strange behaviors behind a reverse-proxy.
 	// A user may fix the configuration mistake when he sees this log.
 	// And this is also very helpful to maintainers to provide help to users to resolve their configuration problems.
@@ -311,47 +468,51 @@ func listen(m http.Handler, handleRedirector bool) error {
 		log.Info("LFS server enabled")
 	}
 
-	var err error
-	switch setting.Protocol {
-	case setting.HTTP:
-		if handleRedirector {
+	// the ACME server handles the HTTP redirector by itself
+	if handleRedirector && !(setting.Protocol == setting.HTTPS && setting.EnableAcme) {
+		if setting.Protocol == setting.HTTPS && setting.RedirectOtherPort {
+			go runHTTPRedirector()
+		} else {
 			NoHTTPRedirector()
 		}
-		err = runHTTP("tcp", listenAddr, "Web", m, setting.UseProxyProtocol)
+	}
+
+	// the graceful manager only expects one main listener, tell it about the others
+	graceful.GetManager().RegisterServers(len(listenAddrs) - 1)
+
+	// all the servers share the same handler and the graceful manager, so they are shut down together
+	errs := make([]error, len(listenAddrs))
+	var wg sync.WaitGroup
+	for i, listenAddr := range listenAddrs {
+		wg.Add(1)
+		go func(i int, listenAddr webListenAddr) {
+			defer wg.Done()
+			errs[i] = serveWebListenAddr(m, listenAddr)
+		}(i, listenAddr)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func serveWebListenAddr(m http.Handler, listenAddr webListenAddr) error {
+	var err error
+	switch setting.Protocol {
+	case setting.HTTP, setting.HTTPUnix:
+		err = runHTTP(listenAddr.network, listenAddr.addr, "Web", m, setting.UseProxyProtocol)
 	case setting.HTTPS:
 		if setting.EnableAcme {
-			err = runACME(listenAddr, m)
+			err = runACME(listenAddr.addr, m)
 			break
 		}
-		if handleRedirector {
-			if setting.RedirectOtherPort {
-				go runHTTPRedirector()
-			} else {
-				NoHTTPRedirector()
-			}
-		}
-		err = runHTTPS("tcp", listenAddr, "Web", setting.CertFile, setting.KeyFile, m, setting.UseProxyProtocol, setting.ProxyProtocolTLSBridging)
-	case setting.FCGI:
-		if handleRedirector {
-			NoHTTPRedirector()
-		}
-		err = runFCGI("tcp", listenAddr, "FCGI Web", m, setting.UseProxyProtocol)
-	case setting.HTTPUnix:
-		if handleRedirector {
-			NoHTTPRedirector()
-		}
-		err = runHTTP("unix", listenAddr, "Web", m, setting.UseProxyProtocol)
-	case setting.FCGIUnix:
-		if handleRedirector {
-			NoHTTPRedirector()
-		}
-		err = runFCGI("unix", listenAddr, "Web", m, setting.UseProxyProtocol)
+		err = runHTTPS(listenAddr.network, listenAddr.addr, "Web", setting.CertFile, setting.KeyFile, m, setting.UseProxyProtocol, setting.ProxyProtocolTLSBridging)
+	case setting.FCGI, setting.FCGIUnix:
+		err = runFCGI(listenAddr.network, listenAddr.addr, "FCGI Web", m, setting.UseProxyProtocol)
 	default:
 		log.Fatal("Invalid protocol: %s", setting.Protocol)
 	}
 	if err != nil {
 		log.Critical("Failed to start server: %v", err)
 	}
-	log.Info("HTTP Listener: %s Closed", listenAddr)
+	log.Info("HTTP Listener: %s Closed", listenAddr.addr)
 	return err
 }
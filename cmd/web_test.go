@@ -0,0 +1,75 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWebListenAddrs(t *testing.T) {
+	defer func(protocol setting.Scheme, addr, port string) {
+		setting.Protocol, setting.HTTPAddr, setting.HTTPPort = protocol, addr, port
+	}(setting.Protocol, setting.HTTPAddr, setting.HTTPPort)
+
+	// without "--listen", HTTP_ADDR and HTTP_PORT are used
+	setting.Protocol, setting.HTTPAddr, setting.HTTPPort = setting.HTTP, "0.0.0.0", "3000"
+	addrs, err := parseWebListenAddrs(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []webListenAddr{{network: "tcp", addr: "0.0.0.0:3000"}}, addrs)
+
+	setting.Protocol, setting.HTTPAddr = setting.HTTPUnix, "/run/gitea/gitea.sock"
+	addrs, err = parseWebListenAddrs(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []webListenAddr{{network: "unix", addr: "/run/gitea/gitea.sock"}}, addrs)
+
+	addrs, err = parseWebListenAddrs([]string{"127.0.0.1:3000", "[::1]:3000", "unix:/run/gitea/a.sock", "/run/gitea/b.sock"})
+	assert.NoError(t, err)
+	assert.Equal(t, []webListenAddr{
+		{network: "tcp", addr: "127.0.0.1:3000"},
+		{network: "tcp", addr: "[::1]:3000"},
+		{network: "unix", addr: "/run/gitea/a.sock"},
+		{network: "unix", addr: "/run/gitea/b.sock"},
+	}, addrs)
+
+	for _, invalid := range [][]string{
+		{"127.0.0.1"},
+		{"unix:"},
+		{"127.0.0.1:3000", "127.0.0.1:3000"},
+		// the same socket with and without the "unix:" prefix
+		{"unix:/run/gitea/a.sock", "/run/gitea/a.sock"},
+	} {
+		_, err = parseWebListenAddrs(invalid)
+		assert.Error(t, err, "%v", invalid)
+	}
+}
+
+func TestDisableInstallPage(t *testing.T) {
+	defer func(cfg setting.ConfigProvider, customConf string, installLock bool) {
+		setting.CfgProvider, setting.CustomConf, setting.InstallLock = cfg, customConf, installLock
+	}(setting.CfgProvider, setting.CustomConf, setting.InstallLock)
+
+	// a config without INSTALL_LOCK is started as installed
+	dir := t.TempDir()
+	setting.CustomConf = filepath.Join(dir, "app.ini")
+	assert.NoError(t, os.WriteFile(setting.CustomConf, []byte("APP_NAME = Gitea\n"), 0o644))
+	cfg, err := setting.NewConfigProviderFromFile(setting.CustomConf)
+	assert.NoError(t, err)
+	setting.CfgProvider, setting.InstallLock = cfg, false
+	assert.NoError(t, disableInstallPage())
+	assert.True(t, setting.InstallLock)
+
+	// a missing config file can't be used
+	setting.CustomConf = filepath.Join(dir, "missing.ini")
+	cfg, err = setting.NewConfigProviderFromFile(setting.CustomConf)
+	assert.NoError(t, err)
+	setting.CfgProvider, setting.InstallLock = cfg, false
+	assert.ErrorContains(t, disableInstallPage(), "doesn't exist")
+	assert.False(t, setting.InstallLock)
+}
@@ -0,0 +1,167 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchConfigDebounce is how long the config watcher waits after the last change before restarting,
+// editors and config management tools often write a file in several steps
+const watchConfigDebounce = time.Second
+
+// watchConfig restarts the web server gracefully when the config changes. The changed config is validated by
+// "gitea config validate" in a separate process first, if it can't be loaded the server keeps running with the old config.
+func watchConfig(ctx context.Context) {
+	if !setting.GracefulRestartable {
+		log.Error("The config is not watched: --watch-config needs ALLOW_GRACEFUL_RESTARTS to be enabled")
+		return
+	}
+
+	ctx, _, finished := process.GetManager().AddTypedContext(ctx, "Web: Config Watcher", process.SystemProcessType, true)
+	defer finished()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Error("Unable to create the config watcher: %v", err)
+		return
+	}
+	defer watcher.Close()
+
+	// editors often replace the file instead of writing it, so the directory is watched rather than the file
+	isConfigDir, _ := util.IsDir(setting.CustomConf)
+	watchDir := setting.CustomConf
+	if !isConfigDir {
+		watchDir = filepath.Dir(setting.CustomConf)
+	}
+	if err = watcher.Add(watchDir); err != nil {
+		log.Error("Unable to watch the config directory %q: %v", watchDir, err)
+		return
+	}
+
+	// the fingerprint of the config the server is running with, so changing the config back after a rejected change doesn't restart
+	runningFingerprint, err := configFingerprint(setting.CustomConf)
+	if err != nil {
+		log.Error("Unable to read the config %q: %v", setting.CustomConf, err)
+		return
+	}
+	log.Info("Watching the config %q, the server restarts gracefully when it changes", setting.CustomConf)
+
+	// the debounced callbacks never run at the same time, so they don't need more locking
+	restarting := false
+	debounce := util.Debounce(watchConfigDebounce)
+	onChange := func() {
+		if restarting {
+			return
+		}
+		fingerprint, err := configFingerprint(setting.CustomConf)
+		if err != nil {
+			log.Error("Unable to read the changed config %q, keep running with the old config: %v", setting.CustomConf, err)
+			return
+		}
+		if fingerprint == runningFingerprint {
+			return
+		}
+
+		output, err := validateChangedConfig(ctx)
+		usable, hasIssues := changedConfigUsable(err)
+		if !usable {
+			log.Error("The changed config %q is invalid, keep running with the old config: %v\n%s", setting.CustomConf, err, output)
+			return
+		} else if hasIssues {
+			log.Warn("The changed config %q has issues:\n%s", setting.CustomConf, output)
+		}
+		log.Info("The config %q has changed, restarting gracefully", setting.CustomConf)
+		restarting = true
+		graceful.GetManager().DoGracefulRestart()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if isConfigDir && strings.HasSuffix(event.Name, ".ini") || !isConfigDir && filepath.Clean(event.Name) == setting.CustomConf {
+				log.Trace("Watched config had event: %v", event)
+				debounce(onChange)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Error("Watched config had error: %v", err)
+		}
+	}
+}
+
+// configFingerprint returns the hash of the config file, or of all the "*.ini" files in the config directory,
+// so a write which doesn't change the content doesn't restart the server
+func configFingerprint(path string) (string, error) {
+	files := []string{path}
+	if isDir, _ := util.IsDir(path); isDir {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return "", err
+		}
+		files = files[:0]
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".ini") {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+
+	h := sha256.New()
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		_, _ = h.Write([]byte(file))
+		_, _ = h.Write(content)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// changedConfigUsable tells from the error of validateChangedConfig whether the server can restart with the changed config,
+// the issues like unknown keys don't prevent the server from starting, only a config which can't be loaded does
+func changedConfigUsable(err error) (usable, hasIssues bool) {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == setting.ConfigValidateIssuesExitCode {
+		return true, true
+	}
+	return err == nil, false
+}
+
+// validateChangedConfig runs "gitea config validate" in a separate process, loading the settings changes
+// the global settings and fails fatally on some invalid values, so it can't be done by the running server
+func validateChangedConfig(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, setting.AppPath, setting.ConfigValidateArgs()...)
+	output, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
@@ -0,0 +1,77 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.ini")
+	assert.NoError(t, os.WriteFile(file, []byte("APP_NAME = a\n"), 0o644))
+
+	fingerprint, err := configFingerprint(file)
+	assert.NoError(t, err)
+
+	// rewriting the same content doesn't change the fingerprint
+	assert.NoError(t, os.WriteFile(file, []byte("APP_NAME = a\n"), 0o644))
+	same, err := configFingerprint(file)
+	assert.NoError(t, err)
+	assert.Equal(t, fingerprint, same)
+
+	assert.NoError(t, os.WriteFile(file, []byte("APP_NAME = b\n"), 0o644))
+	changed, err := configFingerprint(file)
+	assert.NoError(t, err)
+	assert.NotEqual(t, fingerprint, changed)
+
+	// only the "*.ini" files of a config directory are used
+	dirFingerprint, err := configFingerprint(dir)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a config"), 0o644))
+	same, err = configFingerprint(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, dirFingerprint, same)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "z.ini"), []byte("[server]\n"), 0o644))
+	changed, err = configFingerprint(dir)
+	assert.NoError(t, err)
+	assert.NotEqual(t, dirFingerprint, changed)
+
+	_, err = configFingerprint(filepath.Join(dir, "missing.ini"))
+	assert.Error(t, err)
+}
+
+func TestChangedConfigUsable(t *testing.T) {
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "app.ini")
+	usable := func(content string) (bool, bool) {
+		assert.NoError(t, os.WriteFile(conf, []byte(content), 0o644))
+		_, err := runConfigValidateProcess(conf)
+		return changedConfigUsable(err)
+	}
+
+	ok, hasIssues := usable("APP_NAME = Gitea\n")
+	assert.True(t, ok)
+	assert.False(t, hasIssues)
+
+	// the unknown keys don't prevent the restart
+	ok, hasIssues = usable("APP_NAME = Gitea\nNO_SUCH_KEY = 1\n")
+	assert.True(t, ok)
+	assert.True(t, hasIssues)
+
+	// a config which can't be loaded does
+	ok, _ = usable("[server\n")
+	assert.False(t, ok)
+
+	// so does a validation which can't run
+	ok, _ = changedConfigUsable(errors.New("exec: not found"))
+	assert.False(t, ok)
+}
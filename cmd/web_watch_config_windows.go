@@ -0,0 +1,17 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build windows
+
+package cmd
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// watchConfig is not supported on Windows, because the server can't restart itself gracefully
+func watchConfig(_ context.Context) {
+	log.Error("The config is not watched: --watch-config is not supported on Windows")
+}
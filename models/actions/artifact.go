@@ -22,6 +22,8 @@ const (
 	ArtifactStatusUploadConfirmed = 2
 	// ArtifactStatusUploadError is the status of an artifact upload that is errored
 	ArtifactStatusUploadError = 3
+	// ArtifactStatusExpired is the status of an artifact whose file has been removed after the retention days
+	ArtifactStatusExpired = 4
 )
 
 func init() {
@@ -149,3 +151,20 @@ func ListArtifactsByRunIDAndName(ctx context.Context, runID int64, name string)
 	arts := make([]*ActionArtifact, 0, 10)
 	return arts, db.GetEngine(ctx).Where("run_id=? AND artifact_name=?", runID, name).Find(&arts)
 }
+
+// FindArtifactsToExpire returns at most limit artifacts created before the time which haven't expired yet,
+// ordered by id from the id after afterID so that they can be iterated in batches even when they aren't changed
+func FindArtifactsToExpire(ctx context.Context, olderThan timeutil.TimeStamp, afterID int64, limit int) ([]*ActionArtifact, error) {
+	arts := make([]*ActionArtifact, 0, limit)
+	return arts, db.GetEngine(ctx).
+		Where("id > ? AND created_unix < ? AND status <> ?", afterID, olderThan, ArtifactStatusExpired).
+		OrderBy("id").
+		Limit(limit).
+		Find(&arts)
+}
+
+// SetArtifactExpired marks the artifact as expired, its file must have been removed from the storage
+func SetArtifactExpired(ctx context.Context, id int64) error {
+	_, err := db.GetEngine(ctx).ID(id).Cols("status").Update(&ActionArtifact{Status: ArtifactStatusExpired})
+	return err
+}
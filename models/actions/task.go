@@ -334,6 +334,17 @@ func CreateTaskForRunner(ctx context.Context, runner *ActionRunner) (*ActionTask
 	return task, true, nil
 }
 
+// FindOldTasksToExpire returns at most limit tasks stopped before the time whose logs haven't expired yet,
+// ordered by id from the id after afterID so that they can be iterated in batches even when they aren't changed
+func FindOldTasksToExpire(ctx context.Context, olderThan timeutil.TimeStamp, afterID int64, limit int) ([]*ActionTask, error) {
+	tasks := make([]*ActionTask, 0, limit)
+	return tasks, db.GetEngine(ctx).
+		Where("id > ? AND stopped > 0 AND stopped < ? AND log_expired = ?", afterID, olderThan, false).
+		OrderBy("id").
+		Limit(limit).
+		Find(&tasks)
+}
+
 func UpdateTask(ctx context.Context, task *ActionTask, cols ...string) error {
 	sess := db.GetEngine(ctx).ID(task.ID)
 	if len(cols) > 0 {
@@ -50,6 +50,8 @@ const (
 	NotificationSourceCommit
 	// NotificationSourceRepository is a notification for a repository
 	NotificationSourceRepository
+	// NotificationSourceSystem is a notification sent by a site administrator, it has no repository
+	NotificationSourceSystem
 )
 
 // Notification represents a notification
@@ -67,6 +69,9 @@ type Notification struct {
 
 	UpdatedBy int64 `xorm:"INDEX NOT NULL"`
 
+	// Message is the text of a system notification
+	Message string `xorm:"TEXT"`
+
 	Issue      *issues_model.Issue    `xorm:"-"`
 	Repository *repo_model.Repository `xorm:"-"`
 	Comment    *issues_model.Comment  `xorm:"-"`
@@ -172,6 +177,60 @@ func CreateRepoTransferNotification(ctx context.Context, doer, newOwner *user_mo
 	})
 }
 
+// SystemNotificationOptions represents the filters for the receivers of a system notification
+type SystemNotificationOptions struct {
+	OnlyAdmins bool
+	SourceID   int64
+}
+
+// CreateSystemNotifications creates a system notification with the given message for each active individual user
+// matching the options, and returns how many notifications were created
+func CreateSystemNotifications(ctx context.Context, doer *user_model.User, message string, opts SystemNotificationOptions) (int, error) {
+	cond := builder.Eq{
+		"type":           user_model.UserTypeIndividual,
+		"is_active":      true,
+		"prohibit_login": false,
+	}
+	if opts.OnlyAdmins {
+		cond["is_admin"] = true
+	}
+	if opts.SourceID > 0 {
+		cond["login_source"] = opts.SourceID
+	}
+
+	var count int
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		var userIDs []int64
+		if err := db.GetEngine(ctx).Table(new(user_model.User)).Where(cond).Cols("id").Find(&userIDs); err != nil {
+			return err
+		}
+
+		for len(userIDs) > 0 {
+			limit := db.DefaultMaxInSize
+			if len(userIDs) < limit {
+				limit = len(userIDs)
+			}
+			notify := make([]*Notification, 0, limit)
+			for _, userID := range userIDs[:limit] {
+				notify = append(notify, &Notification{
+					UserID:    userID,
+					Status:    NotificationStatusUnread,
+					Source:    NotificationSourceSystem,
+					UpdatedBy: doer.ID,
+					Message:   message,
+				})
+			}
+			if err := db.Insert(ctx, notify); err != nil {
+				return err
+			}
+			count += limit
+			userIDs = userIDs[limit:]
+		}
+		return nil
+	})
+	return count, err
+}
+
 // CreateOrUpdateIssueNotifications creates an issue notification
 // for each watcher, or updates it if already exists
 // receiverID > 0 just send to receiver, else send to all watcher
@@ -387,7 +446,7 @@ func (n *Notification) LoadAttributes(ctx context.Context) (err error) {
 }
 
 func (n *Notification) loadRepo(ctx context.Context) (err error) {
-	if n.Repository == nil {
+	if n.Repository == nil && n.RepoID != 0 {
 		n.Repository, err = repo_model.GetRepositoryByID(ctx, n.RepoID)
 		if err != nil {
 			return fmt.Errorf("getRepositoryByID [%d]: %w", n.RepoID, err)
@@ -455,6 +514,8 @@ func (n *Notification) HTMLURL() string {
 		return n.Repository.HTMLURL() + "/commit/" + url.PathEscape(n.CommitID)
 	case NotificationSourceRepository:
 		return n.Repository.HTMLURL()
+	case NotificationSourceSystem:
+		return setting.AppURL + "notifications"
 	}
 	return ""
 }
@@ -471,6 +532,8 @@ func (n *Notification) Link() string {
 		return n.Repository.Link() + "/commit/" + url.PathEscape(n.CommitID)
 	case NotificationSourceRepository:
 		return n.Repository.Link()
+	case NotificationSourceSystem:
+		return setting.AppSubURL + "/notifications"
 	}
 	return ""
 }
@@ -498,7 +561,7 @@ func (nl NotificationList) LoadAttributes(ctx context.Context) error {
 func (nl NotificationList) getPendingRepoIDs() []int64 {
 	ids := make(container.Set[int64], len(nl))
 	for _, notification := range nl {
-		if notification.Repository != nil {
+		if notification.Repository != nil || notification.RepoID == 0 {
 			continue
 		}
 		ids.Add(notification.RepoID)
@@ -547,6 +610,10 @@ func (nl NotificationList) LoadRepos(ctx context.Context) (repo_model.Repository
 
 	reposList := make(repo_model.RepositoryList, 0, len(repoIDs))
 	for i, notification := range nl {
+		if notification.RepoID == 0 {
+			// system notifications have no repository
+			continue
+		}
 		if notification.Repository == nil {
 			notification.Repository = repos[notification.RepoID]
 		}
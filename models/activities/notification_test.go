@@ -13,6 +13,7 @@ import (
 	user_model "code.gitea.io/gitea/models/user"
 
 	"github.com/stretchr/testify/assert"
+	"xorm.io/builder"
 )
 
 func TestCreateOrUpdateIssueNotifications(t *testing.T) {
@@ -109,3 +110,30 @@ func TestUpdateNotificationStatuses(t *testing.T) {
 	unittest.AssertExistsAndLoadBean(t,
 		&activities_model.Notification{ID: notfPinned.ID, Status: activities_model.NotificationStatusPinned})
 }
+
+func TestCreateSystemNotifications(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1})
+
+	count, err := activities_model.CreateSystemNotifications(db.DefaultContext, doer, "maintenance tonight", activities_model.SystemNotificationOptions{OnlyAdmins: true})
+	assert.NoError(t, err)
+	receivers := builder.Eq{"type": user_model.UserTypeIndividual, "is_active": true, "prohibit_login": false}
+	admins := unittest.GetCountByCond(t, "`user`", receivers.And(builder.Eq{"is_admin": true}))
+	assert.EqualValues(t, admins, count)
+
+	notf := unittest.AssertExistsAndLoadBean(t, &activities_model.Notification{UserID: 1, Source: activities_model.NotificationSourceSystem})
+	assert.Equal(t, "maintenance tonight", notf.Message)
+	assert.EqualValues(t, 0, notf.RepoID)
+	assert.NoError(t, notf.LoadAttributes(db.DefaultContext))
+	assert.Nil(t, notf.Repository)
+
+	// the system notifications are not removed from the list for having no repository
+	_, failures, err := activities_model.NotificationList{notf}.LoadRepos(db.DefaultContext)
+	assert.NoError(t, err)
+	assert.Empty(t, failures)
+
+	count, err = activities_model.CreateSystemNotifications(db.DefaultContext, doer, "hello", activities_model.SystemNotificationOptions{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, unittest.GetCountByCond(t, "`user`", receivers), count)
+	unittest.AssertNotExistsBean(t, &activities_model.Notification{UserID: 9, Message: "hello"})
+}
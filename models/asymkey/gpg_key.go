@@ -6,6 +6,7 @@ package asymkey
 import (
 	"context"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"code.gitea.io/gitea/modules/timeutil"
 
 	"github.com/keybase/go-crypto/openpgp"
+	"github.com/keybase/go-crypto/openpgp/armor"
 	"github.com/keybase/go-crypto/openpgp/packet"
 	"xorm.io/xorm"
 )
@@ -123,6 +125,43 @@ func GPGKeyToEntity(k *GPGKey) (*openpgp.Entity, error) {
 	return keys[0], err
 }
 
+// WriteArmoredGPGKeys writes the public keys as an armored "PGP PUBLIC KEY BLOCK" with the given headers,
+// the keys imported without a backup of their armored content can't be exported and are listed in the "Note" header
+func WriteArmoredGPGKeys(w io.Writer, keys []*GPGKey, headers map[string]string) error {
+	entities := make([]*openpgp.Entity, 0)
+	failedEntitiesID := make([]string, 0)
+	for _, k := range keys {
+		e, err := GPGKeyToEntity(k)
+		if err != nil {
+			if IsErrGPGKeyImportNotExist(err) {
+				failedEntitiesID = append(failedEntitiesID, k.KeyID)
+				continue // Skip previous import without backup of imported armored key
+			}
+			return err
+		}
+		entities = append(entities, e)
+	}
+
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	if len(failedEntitiesID) > 0 { // If some key need re-import to be exported
+		headers["Note"] = fmt.Sprintf("The keys with the following IDs couldn't be exported and need to be reuploaded %s", strings.Join(failedEntitiesID, ", "))
+	} else if len(entities) == 0 {
+		headers["Note"] = "This user hasn't uploaded any GPG keys."
+	}
+	writer, err := armor.Encode(w, "PGP PUBLIC KEY BLOCK", headers)
+	if err != nil {
+		return err
+	}
+	for _, e := range entities {
+		if err := e.Serialize(writer); err != nil { // TODO find why key are exported with a different cipherTypeByte as original (should not be blocking but strange)
+			return err
+		}
+	}
+	return writer.Close()
+}
+
 // parseSubGPGKey parse a sub Key
 func parseSubGPGKey(ownerID int64, primaryID string, pubkey *packet.PublicKey, expiry time.Time) (*GPGKey, error) {
 	content, err := base64EncPubKey(pubkey)
@@ -4,6 +4,7 @@
 package asymkey
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -390,3 +391,18 @@ epiDVQ==
 		assert.Equal(t, time.Unix(1586105389, 0), expire)
 	}
 }
+
+func TestWriteArmoredGPGKeys(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	var buf strings.Builder
+	assert.NoError(t, WriteArmoredGPGKeys(&buf, nil, map[string]string{"Comment": "user2"}))
+	assert.True(t, strings.HasPrefix(buf.String(), "-----BEGIN PGP PUBLIC KEY BLOCK-----\n"))
+	assert.Contains(t, buf.String(), "Comment: user2\n")
+	assert.Contains(t, buf.String(), "Note: This user hasn't uploaded any GPG keys.\n")
+
+	// a key imported without the backup of its armored content can't be exported
+	buf.Reset()
+	assert.NoError(t, WriteArmoredGPGKeys(&buf, []*GPGKey{{KeyID: "0123456789ABCDEF"}}, nil))
+	assert.Contains(t, buf.String(), "Note: The keys with the following IDs couldn't be exported and need to be reuploaded 0123456789ABCDEF\n")
+}
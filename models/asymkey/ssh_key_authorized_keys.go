@@ -118,6 +118,11 @@ func appendAuthorizedKeysToFile(keys ...*PublicKey) error {
 // Note: db.GetEngine(db.DefaultContext).Iterate does not get latest data after insert/delete, so we have to call this function
 // outside any session scope independently.
 func RewriteAllPublicKeys() error {
+	return RewriteAllPublicKeysConcurrently(1)
+}
+
+// RewriteAllPublicKeysConcurrently is like RewriteAllPublicKeys but the lines of at most concurrency keys are generated at the same time
+func RewriteAllPublicKeysConcurrently(concurrency int) error {
 	// Don't rewrite key if internal server
 	if setting.SSH.StartBuiltinServer || !setting.SSH.CreateAuthorizedKeysFile {
 		return nil
@@ -165,7 +170,7 @@ func RewriteAllPublicKeys() error {
 		}
 	}
 
-	if err := RegeneratePublicKeys(db.DefaultContext, t); err != nil {
+	if err := RegeneratePublicKeysConcurrently(db.DefaultContext, t, concurrency); err != nil {
 		return err
 	}
 
@@ -175,11 +180,53 @@ func RewriteAllPublicKeys() error {
 
 // RegeneratePublicKeys regenerates the authorized_keys file
 func RegeneratePublicKeys(ctx context.Context, t io.StringWriter) error {
-	if err := db.GetEngine(ctx).Where("type != ?", KeyTypePrincipal).Iterate(new(PublicKey), func(idx int, bean any) (err error) {
-		_, err = t.WriteString((bean.(*PublicKey)).AuthorizedString())
-		return err
-	}); err != nil {
-		return err
+	return RegeneratePublicKeysConcurrently(ctx, t, 1)
+}
+
+// RegeneratePublicKeysConcurrently is like RegeneratePublicKeys but the lines of at most concurrency keys are generated
+// at the same time. The keys are loaded by batches and written in the order of their IDs.
+func RegeneratePublicKeysConcurrently(ctx context.Context, t io.StringWriter, concurrency int) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	batchSize := setting.Database.IterateBufferSize
+	var lastID int64
+	for {
+		keys := make([]*PublicKey, 0, batchSize)
+		if err := db.GetEngine(ctx).Where("type != ? AND id > ?", KeyTypePrincipal, lastID).OrderBy("id").Limit(batchSize).Find(&keys); err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			break
+		}
+
+		lines := make([]string, len(keys))
+		var wg sync.WaitGroup
+		idxCh := make(chan int)
+		for i := 0; i < concurrency; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for idx := range idxCh {
+					lines[idx] = keys[idx].AuthorizedString()
+				}
+			}()
+		}
+		for idx := range keys {
+			idxCh <- idx
+		}
+		close(idxCh)
+		wg.Wait()
+
+		for _, line := range lines {
+			if _, err := t.WriteString(line); err != nil {
+				return err
+			}
+		}
+		if len(keys) < batchSize {
+			break
+		}
+		lastID = keys[len(keys)-1].ID
 	}
 
 	fPath := filepath.Join(setting.SSH.RootPath, "authorized_keys")
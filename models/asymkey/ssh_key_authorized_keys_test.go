@@ -0,0 +1,53 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package asymkey
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegeneratePublicKeysConcurrently(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	defer func(bufferSize int, rootPath string) {
+		setting.Database.IterateBufferSize, setting.SSH.RootPath = bufferSize, rootPath
+	}(setting.Database.IterateBufferSize, setting.SSH.RootPath)
+	// the keys are loaded by several batches
+	setting.Database.IterateBufferSize = 2
+	setting.SSH.RootPath = t.TempDir()
+
+	fixture := unittest.AssertExistsAndLoadBean(t, &PublicKey{ID: 1})
+	var principalID int64
+	for i := 0; i < 5; i++ {
+		key := &PublicKey{OwnerID: 2, Name: fmt.Sprintf("key-%d", i), Content: fixture.Content, Type: KeyTypeUser, Mode: fixture.Mode}
+		if i == 2 {
+			// the principals are written to authorized_principals
+			key.Type = KeyTypePrincipal
+		}
+		assert.NoError(t, db.Insert(db.DefaultContext, key))
+		if key.Type == KeyTypePrincipal {
+			principalID = key.ID
+		}
+	}
+
+	expected := &strings.Builder{}
+	assert.NoError(t, RegeneratePublicKeys(db.DefaultContext, expected))
+	assert.Equal(t, 5, strings.Count(expected.String(), fixture.Content))
+	// the lines are in the order of the key IDs
+	assert.Less(t, strings.Index(expected.String(), " serv key-3\""), strings.Index(expected.String(), " serv key-5\""))
+	assert.NotContains(t, expected.String(), fmt.Sprintf(" serv key-%d\"", principalID))
+
+	for _, concurrency := range []int{0, 3, 10} {
+		regenerated := &strings.Builder{}
+		assert.NoError(t, RegeneratePublicKeysConcurrently(db.DefaultContext, regenerated, concurrency))
+		assert.Equal(t, expected.String(), regenerated.String(), "concurrency %d", concurrency)
+	}
+}
@@ -143,6 +143,11 @@ var allAccessTokenScopes = []AccessTokenScope{
 	AccessTokenScopeWriteUser, AccessTokenScopeReadUser,
 }
 
+// AllAccessTokenScopes returns all the valid access token scopes, including "all"
+func AllAccessTokenScopes() []AccessTokenScope {
+	return append([]AccessTokenScope{AccessTokenScopeAll}, allAccessTokenScopes...)
+}
+
 // allAccessTokenScopeBits contains all access token scopes.
 var allAccessTokenScopeBits = map[AccessTokenScope]accessTokenScopeBitmap{
 	AccessTokenScopeAll:               accessTokenScopeAllBits,
@@ -88,3 +88,16 @@ func TestAccessTokenScope_HasScope(t *testing.T) {
 		})
 	}
 }
+
+func TestAllAccessTokenScopes(t *testing.T) {
+	scopes := AllAccessTokenScopes()
+	assert.Contains(t, scopes, AccessTokenScopeAll)
+	assert.Contains(t, scopes, AccessTokenScopePublicOnly)
+	for _, scope := range scopes {
+		_, err := scope.Normalize()
+		assert.NoError(t, err, scope)
+	}
+
+	_, err := AccessTokenScope("read:repo").Normalize()
+	assert.Error(t, err)
+}
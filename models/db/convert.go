@@ -4,42 +4,124 @@
 package db
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 
+	"github.com/go-sql-driver/mysql"
 	"xorm.io/xorm"
 	"xorm.io/xorm/schemas"
 )
 
-// ConvertUtf8ToUtf8mb4 converts database and tables from utf8 to utf8mb4 if it's mysql and set ROW_FORMAT=dynamic
-func ConvertUtf8ToUtf8mb4() error {
+// mysqlCharsetCollations are the character sets which a MySQL database can be converted to, and the collations used for them.
+// "utf8" is used for utf8mb3, it is understood by all MySQL and MariaDB versions.
+var mysqlCharsetCollations = map[string][2]string{
+	"utf8mb4": {"utf8mb4", "utf8mb4_general_ci"},
+	"utf8mb3": {"utf8", "utf8_general_ci"},
+}
+
+// MySQLCharsets returns the character sets which ConvertMySQLCharset accepts
+func MySQLCharsets() []string {
+	return []string{"utf8mb4", "utf8mb3"}
+}
+
+// MySQLCharsetOfCollation returns the character set of a MySQL collation, the legacy "utf8" is reported as "utf8mb3"
+func MySQLCharsetOfCollation(collation string) string {
+	charset, _, _ := strings.Cut(collation, "_")
+	if charset == "utf8" {
+		return "utf8mb3"
+	}
+	return charset
+}
+
+// MySQLTableCollation is the collation of a MySQL table
+type MySQLTableCollation struct {
+	Name      string
+	Collation string
+}
+
+// GetMySQLCollations returns the default collation of the MySQL database and the collations of its tables ordered by name
+func GetMySQLCollations(ctx context.Context) (dbCollation string, tables []*MySQLTableCollation, err error) {
 	if x.Dialect().URI().DBType != schemas.MYSQL {
-		return nil
+		return "", nil, errors.New("the collations can only be checked for a MySQL database")
 	}
+	if _, err = GetEngine(ctx).SQL("SELECT DEFAULT_COLLATION_NAME FROM information_schema.SCHEMATA WHERE SCHEMA_NAME = ?", setting.Database.Name).Get(&dbCollation); err != nil {
+		return "", nil, err
+	}
+	err = GetEngine(ctx).SQL("SELECT TABLE_NAME AS name, TABLE_COLLATION AS collation FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE' ORDER BY TABLE_NAME", setting.Database.Name).Find(&tables)
+	return dbCollation, tables, err
+}
 
-	_, err := x.Exec(fmt.Sprintf("ALTER DATABASE `%s` CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci", setting.Database.Name))
-	if err != nil {
-		return err
+// MySQLSkippedTable is a table which ConvertMySQLCharset has left unchanged
+type MySQLSkippedTable struct {
+	Name   string
+	Reason string
+}
+
+// mysqlConvertSkipReason returns why a table can't be converted if the error only affects this table, otherwise it returns ""
+func mysqlConvertSkipReason(err error) string {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return ""
+	}
+	switch mysqlErr.Number {
+	case 1071, 1709: // ER_TOO_LONG_KEY, ER_INDEX_COLUMN_TOO_LONG
+		return "an index would exceed the key length limit: " + mysqlErr.Message
+	case 1366: // ER_TRUNCATED_WRONG_VALUE_FOR_FIELD
+		return "it contains characters which can't be stored in the character set: " + mysqlErr.Message
+	}
+	return ""
+}
+
+// ConvertMySQLCharset converts the MySQL database and its tables to the character set ("utf8mb4" or "utf8mb3") and sets ROW_FORMAT=dynamic.
+// A table which can't be converted because of the index length limit, or because it contains characters which can't be stored
+// in the character set, is left unchanged and returned as skipped instead of failing the whole conversion.
+func ConvertMySQLCharset(ctx context.Context, charset string) (skipped []*MySQLSkippedTable, err error) {
+	if x.Dialect().URI().DBType != schemas.MYSQL {
+		return nil, errors.New("the character set can only be converted for a MySQL database")
+	}
+	target, ok := mysqlCharsetCollations[charset]
+	if !ok {
+		return nil, fmt.Errorf("unsupported character set %q, it should be one of: %s", charset, strings.Join(MySQLCharsets(), ", "))
 	}
 
 	tables, err := x.DBMetas()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// use one connection, so the session's sql_mode applies to all the statements
+	conn, err := x.DB().Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// in strict mode the conversion of a table fails instead of replacing the characters which can't be stored by "?"
+	if _, err = conn.ExecContext(ctx, "SET SESSION sql_mode = CONCAT_WS(',', @@SESSION.sql_mode, 'STRICT_ALL_TABLES')"); err != nil {
+		return nil, err
+	}
+	if _, err = conn.ExecContext(ctx, fmt.Sprintf("ALTER DATABASE `%s` CHARACTER SET %s COLLATE %s", setting.Database.Name, target[0], target[1])); err != nil {
+		return nil, err
 	}
 	for _, table := range tables {
-		if _, err := x.Exec(fmt.Sprintf("ALTER TABLE `%s` ROW_FORMAT=dynamic;", table.Name)); err != nil {
-			return err
+		if _, err = conn.ExecContext(ctx, fmt.Sprintf("ALTER TABLE `%s` ROW_FORMAT=dynamic", table.Name)); err != nil {
+			return skipped, err
 		}
-
-		if _, err := x.Exec(fmt.Sprintf("ALTER TABLE `%s` CONVERT TO CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci;", table.Name)); err != nil {
-			return err
+		if _, err = conn.ExecContext(ctx, fmt.Sprintf("ALTER TABLE `%s` CONVERT TO CHARACTER SET %s COLLATE %s", table.Name, target[0], target[1])); err != nil {
+			if reason := mysqlConvertSkipReason(err); reason != "" {
+				skipped = append(skipped, &MySQLSkippedTable{Name: table.Name, Reason: reason})
+				continue
+			}
+			return skipped, err
 		}
 	}
-
-	return nil
+	return skipped, nil
 }
 
 // ConvertVarcharToNVarchar converts database and tables from varchar to nvarchar if it's mssql
@@ -0,0 +1,38 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package db // it's not db_test, because this file is for testing the private function mysqlConvertSkipReason
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMySQLCharsetOfCollation(t *testing.T) {
+	assert.Equal(t, "utf8mb4", MySQLCharsetOfCollation("utf8mb4_general_ci"))
+	assert.Equal(t, "utf8mb4", MySQLCharsetOfCollation("utf8mb4_0900_ai_ci"))
+	assert.Equal(t, "utf8mb3", MySQLCharsetOfCollation("utf8_general_ci"))
+	assert.Equal(t, "utf8mb3", MySQLCharsetOfCollation("utf8mb3_unicode_ci"))
+	assert.Equal(t, "latin1", MySQLCharsetOfCollation("latin1_swedish_ci"))
+
+	// all the supported character sets can be checked by the collations they are converted to
+	for _, charset := range MySQLCharsets() {
+		assert.Equal(t, charset, MySQLCharsetOfCollation(mysqlCharsetCollations[charset][1]))
+	}
+}
+
+func TestMySQLConvertSkipReason(t *testing.T) {
+	tooLong := &mysql.MySQLError{Number: 1071, Message: "Specified key was too long; max key length is 767 bytes"}
+	assert.Equal(t, "an index would exceed the key length limit: Specified key was too long; max key length is 767 bytes", mysqlConvertSkipReason(tooLong))
+	assert.Contains(t, mysqlConvertSkipReason(fmt.Errorf("alter: %w", tooLong)), "key length limit")
+	assert.Contains(t, mysqlConvertSkipReason(&mysql.MySQLError{Number: 1709, Message: "Index column size too large"}), "key length limit")
+	assert.Contains(t, mysqlConvertSkipReason(&mysql.MySQLError{Number: 1366, Message: "Incorrect string value"}), "can't be stored in the character set")
+
+	// the other errors fail the whole conversion
+	assert.Empty(t, mysqlConvertSkipReason(&mysql.MySQLError{Number: 1045, Message: "Access denied"}))
+	assert.Empty(t, mysqlConvertSkipReason(errors.New("connection refused")))
+}
@@ -0,0 +1,250 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm/caches"
+	"xorm.io/xorm/dialects"
+	"xorm.io/xorm/schemas"
+	"xorm.io/xorm/tags"
+)
+
+// DumpDatabaseSince dumps the database like DumpDatabase, but only the rows created since the given time of the tables
+// which have a created time column (with the "created" xorm tag), the other tables are dumped fully and their names are returned.
+// It is a best-effort incremental dump: the rows updated or deleted since then are not in it.
+func DumpDatabaseSince(ctx context.Context, filePath, dbType string, since timeutil.TimeStamp) (fullTables []string, err error) {
+	tbs, err := dumpTableInfos()
+	if err != nil {
+		return nil, err
+	}
+
+	var fullTbs, sinceTbs []*schemas.Table
+	var sinceCols []string
+	for _, t := range tbs {
+		if col := createdColumn(t); col != "" {
+			sinceTbs = append(sinceTbs, t)
+			sinceCols = append(sinceCols, col)
+		} else {
+			fullTbs = append(fullTbs, t)
+			fullTables = append(fullTables, t.Name)
+		}
+	}
+
+	dstDialect := x.Dialect()
+	var dstTypes []schemas.DBType
+	if len(dbType) > 0 {
+		dstTypes = append(dstTypes, schemas.DBType(dbType))
+		if dstDialect = dialects.QueryDialect(schemas.DBType(dbType)); dstDialect == nil {
+			return nil, fmt.Errorf("unsupported database type %v", dbType)
+		}
+		if err := dstDialect.Init(&dialects.URI{DBType: schemas.DBType(dbType), DBName: x.Dialect().URI().DBName}); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := os.Create(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintf(f, "/*Incremental dump (best-effort): only the rows created since %s of the tables with a created time, the updated and deleted rows are missing*/\n",
+		since.FormatLong()); err != nil {
+		return nil, err
+	}
+	// the tables without a created time are dumped by xorm, it also writes the header (and the SQL mode of MySQL) once
+	if err := x.DumpTables(fullTbs, f, dstTypes...); err != nil {
+		return nil, err
+	}
+	for i, t := range sinceTbs {
+		if err := dumpTableSince(ctx, f, t, dstDialect, sinceCols[i], since); err != nil {
+			return nil, fmt.Errorf("unable to dump table %s: %w", t.Name, err)
+		}
+	}
+	return fullTables, nil
+}
+
+// createdColumn returns the name of the column of the table filled with the creation time as a unix timestamp, or an empty string
+func createdColumn(t *schemas.Table) string {
+	for _, col := range t.Columns() {
+		if col.IsCreated && col.SQLType.IsNumeric() {
+			return col.Name
+		}
+	}
+	return ""
+}
+
+// dumpTableSince writes the table like xorm's DumpTables does, but only the rows whose "sinceCol" is not before "since"
+func dumpTableSince(ctx context.Context, w io.Writer, table *schemas.Table, dstDialect dialects.Dialect, sinceCol string, since timeutil.TimeStamp) error {
+	dstTable := table
+	if table.Type != nil && dstDialect != x.Dialect() {
+		parser := tags.NewParser("xorm", dstDialect, x.GetTableMapper(), x.GetColumnMapper(), caches.NewManager())
+		var err error
+		if dstTable, err = parser.Parse(reflect.New(table.Type).Elem()); err != nil {
+			return err
+		}
+	}
+	dstType := dstDialect.URI().DBType
+
+	sqlStr, _, err := dstDialect.CreateTableSQL(ctx, x.DB(), dstTable, dstTable.Name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, "\n"+sqlStr+";\n"); err != nil {
+		return err
+	}
+	if len(dstTable.PKColumns()) > 0 && dstType == schemas.MSSQL {
+		if _, err := fmt.Fprintf(w, "SET IDENTITY_INSERT [%s] ON;\n", dstTable.Name); err != nil {
+			return err
+		}
+	}
+	for _, index := range dstTable.Indexes {
+		if _, err := io.WriteString(w, dstDialect.CreateIndexSQL(dstTable.Name, index)+";\n"); err != nil {
+			return err
+		}
+	}
+
+	// the time is an integer, so it can be formatted into the query regardless of the placeholder style of the database
+	rows, err := x.DB().QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s WHERE %s >= %d",
+		x.Dialect().Quoter().Join(table.ColumnsSeq(), ", "), x.Quote(table.Name), x.Quote(sinceCol), int64(since)))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	insertPrefix := "INSERT INTO " + dstDialect.Quoter().Quote(dstTable.Name) + " (" + dstDialect.Quoter().Join(dstTable.ColumnsSeq(), ", ") + ") VALUES ("
+	dstCols := dstTable.Columns()
+	values := make([]sql.NullString, len(dstCols))
+	scanArgs := make([]any, len(dstCols))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return err
+		}
+		formatted := make([]string, len(values))
+		for i, v := range values {
+			if formatted[i], err = formatDumpValue(v, dstCols[i], dstType); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, insertPrefix+strings.Join(formatted, ",")+");\n"); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// like xorm, move the sequence of Postgres after the inserted ids
+	if dstType == schemas.POSTGRES && table.AutoIncrColumn() != nil {
+		if _, err := fmt.Fprintf(w, "SELECT setval('%s_id_seq', COALESCE((SELECT MAX(%s) + 1 FROM %s), 1), false);\n",
+			dstTable.Name, table.AutoIncrColumn().Name, dstDialect.Quoter().Quote(dstTable.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// controlCharactersRe matches the characters which can't be written as-is in an SQL string literal, like xorm does
+var controlCharactersRe = regexp.MustCompile(`[\x00-\x1f\x7f]+`)
+
+// formatDumpValue formats the value of the column as an SQL literal of the database type,
+// it follows the formatting of xorm's DumpTables so both parts of the dump can be restored the same way
+func formatDumpValue(v sql.NullString, col *schemas.Column, dstType schemas.DBType) (string, error) {
+	switch {
+	case !v.Valid:
+		return "NULL", nil
+	case col.SQLType.IsBool():
+		b, err := strconv.ParseBool(v.String)
+		if err != nil {
+			return "", err
+		}
+		if dstType == schemas.POSTGRES {
+			return strconv.FormatBool(b), nil
+		} else if b {
+			return "1", nil
+		}
+		return "0", nil
+	case col.SQLType.IsNumeric():
+		return v.String, nil
+	case len(v.String) == 0:
+		return "''", nil
+	}
+
+	isBlob := col.SQLType.IsBlob()
+	switch dstType {
+	case schemas.POSTGRES:
+		if isBlob {
+			return fmt.Sprintf("'\\x%x'", v.String), nil
+		}
+		// the control characters are concatenated as escape strings, a NUL byte can't be stored in a text column anyway
+		return concatDumpString(v.String, " || ", func(chars string) string {
+			var sb strings.Builder
+			sb.WriteString("e'")
+			for i := 0; i < len(chars); i++ {
+				fmt.Fprintf(&sb, "\\x%02x", chars[i])
+			}
+			sb.WriteString("'")
+			return sb.String()
+		}), nil
+	case schemas.MYSQL:
+		// the dump sets NO_BACKSLASH_ESCAPES, so only the quotes and the control characters need to be escaped
+		if !controlCharactersRe.MatchString(v.String) {
+			return "'" + strings.ReplaceAll(v.String, "'", "''") + "'", nil
+		}
+		return "CONCAT(" + concatDumpString(v.String, ", ", func(chars string) string {
+			formatted := make([]string, len(chars))
+			for i := 0; i < len(chars); i++ {
+				formatted[i] = "CHAR(" + strconv.Itoa(int(chars[i])) + ")"
+			}
+			return strings.Join(formatted, ", ")
+		}) + ")", nil
+	case schemas.SQLITE:
+		if isBlob {
+			return fmt.Sprintf("X'%x'", v.String), nil
+		}
+		return concatDumpString(v.String, " || ", func(chars string) string {
+			return fmt.Sprintf("X'%x'", chars)
+		}), nil
+	case schemas.MSSQL:
+		if isBlob {
+			return fmt.Sprintf("CONVERT(VARBINARY(MAX), '0x%x', 1)", v.String), nil
+		}
+		return "N'" + strings.ReplaceAll(v.String, "'", "''") + "'", nil
+	}
+	return "'" + strings.ReplaceAll(v.String, "'", "''") + "'", nil
+}
+
+// concatDumpString quotes the string and joins its runs of control characters, formatted by formatControl, with sep
+func concatDumpString(s, sep string, formatControl func(chars string) string) string {
+	s = strings.ReplaceAll(s, "'", "''")
+	var parts []string
+	for len(s) > 0 {
+		loc := controlCharactersRe.FindStringIndex(s)
+		if loc == nil {
+			parts = append(parts, "'"+s+"'")
+			break
+		}
+		if loc[0] > 0 {
+			parts = append(parts, "'"+s[:loc[0]]+"'")
+		}
+		parts = append(parts, formatControl(s[loc[0]:loc[1]]))
+		s = s[loc[1]:]
+	}
+	return strings.Join(parts, sep)
+}
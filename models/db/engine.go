@@ -238,13 +238,13 @@ func NamesToBean(names ...string) ([]any, error) {
 	return beans, nil
 }
 
-// DumpDatabase dumps all data from database according the special database SQL syntax to file system.
-func DumpDatabase(filePath, dbType string) error {
+// dumpTableInfos returns the tables to dump, including the version table of the migrations
+func dumpTableInfos() ([]*schemas.Table, error) {
 	var tbs []*schemas.Table
 	for _, t := range tables {
 		t, err := x.TableInfo(t)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		tbs = append(tbs, t)
 	}
@@ -254,10 +254,18 @@ func DumpDatabase(filePath, dbType string) error {
 		Version int64
 	}
 	t, err := x.TableInfo(&Version{})
+	if err != nil {
+		return nil, err
+	}
+	return append(tbs, t), nil
+}
+
+// DumpDatabase dumps all data from database according the special database SQL syntax to file system.
+func DumpDatabase(filePath, dbType string) error {
+	tbs, err := dumpTableInfos()
 	if err != nil {
 		return err
 	}
-	tbs = append(tbs, t)
 
 	if len(dbType) > 0 {
 		return x.DumpTablesToFile(tbs, filePath, schemas.DBType(dbType))
@@ -291,6 +299,11 @@ func GetMaxID(beanOrTableName any) (maxID int64, err error) {
 	return maxID, err
 }
 
+// ConnectionStats returns the statistics of the database connection pool
+func ConnectionStats() sql.DBStats {
+	return x.DB().Stats()
+}
+
 func SetLogSQL(ctx context.Context, on bool) {
 	e := GetEngine(ctx)
 	if x, ok := e.(*xorm.Engine); ok {
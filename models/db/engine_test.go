@@ -4,17 +4,24 @@
 package db_test
 
 import (
+	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
+	auth_model "code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
 	issues_model "code.gitea.io/gitea/models/issues"
 	"code.gitea.io/gitea/models/unittest"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
 
 	_ "code.gitea.io/gitea/cmd" // for TestPrimaryKeys
 
 	"github.com/stretchr/testify/assert"
+	"xorm.io/xorm"
+	"xorm.io/xorm/names"
 )
 
 func TestDumpDatabase(t *testing.T) {
@@ -33,6 +40,102 @@ func TestDumpDatabase(t *testing.T) {
 	}
 }
 
+func TestDumpDatabaseSince(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	dir := t.TempDir()
+
+	type Version struct {
+		ID      int64 `xorm:"pk autoincr"`
+		Version int64
+	}
+	assert.NoError(t, db.GetEngine(db.DefaultContext).Sync2(new(Version)))
+
+	since := timeutil.TimeStamp(1579194806)
+	expected, err := db.GetEngine(db.DefaultContext).Where("created_unix >= ?", since).Count(&issues_model.Issue{})
+	assert.NoError(t, err)
+	assert.NotZero(t, expected)
+
+	insertIssueRe := regexp.MustCompile("(?m)^INSERT INTO [`\"\\[]?issue[`\"\\]]? ")
+	for _, dbType := range setting.SupportedDatabaseTypes {
+		filePath := filepath.Join(dir, dbType+".sql")
+		fullTables, err := db.DumpDatabaseSince(db.DefaultContext, filePath, dbType, since)
+		assert.NoError(t, err)
+		assert.Contains(t, fullTables, "version")
+		assert.NotContains(t, fullTables, "issue")
+
+		content, err := os.ReadFile(filePath)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(content), "/*Incremental dump (best-effort)"))
+		assert.Len(t, insertIssueRe.FindAllString(string(content), -1), int(expected), dbType)
+	}
+}
+
+func TestDumpDatabaseSinceValues(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	dir := t.TempDir()
+
+	cred := &auth_model.WebAuthnCredential{
+		Name:         "new\nline back\\slash nul\x00 'quote'",
+		LowerName:    "dump-since-values",
+		UserID:       1,
+		CredentialID: []byte{0x00, 0x01, '\n', '\'', '\\', 0xff},
+		PublicKey:    []byte{0xde, 0xad, 0x00, 0xbe, 0xef},
+		AAGUID:       []byte{0x7f, 'a', 0x0d},
+	}
+	assert.NoError(t, db.Insert(db.DefaultContext, cred))
+
+	insertCredRe := regexp.MustCompile("^INSERT INTO [`\"\\[]?webauthn_credential[`\"\\]]? ")
+	insertLines := func(content []byte) []string {
+		var lines []string
+		for _, line := range strings.Split(string(content), "\n") {
+			if insertCredRe.MatchString(line) {
+				lines = append(lines, line)
+			}
+		}
+		return lines
+	}
+
+	for _, dbType := range setting.SupportedDatabaseTypes {
+		fullPath := filepath.Join(dir, dbType+"-full.sql")
+		assert.NoError(t, db.DumpDatabase(fullPath, dbType))
+		sincePath := filepath.Join(dir, dbType+"-since.sql")
+		_, err := db.DumpDatabaseSince(db.DefaultContext, sincePath, dbType, cred.CreatedUnix)
+		assert.NoError(t, err)
+
+		// the rows dumped since a time are formatted like the rows of the full dump of xorm
+		full, err := os.ReadFile(fullPath)
+		assert.NoError(t, err)
+		since, err := os.ReadFile(sincePath)
+		assert.NoError(t, err)
+		sinceLines := insertLines(since)
+		assert.Len(t, sinceLines, 1, dbType)
+		assert.Subset(t, insertLines(full), sinceLines, dbType)
+	}
+
+	if !setting.EnableSQLite3 {
+		return
+	}
+
+	// the SQLite dump can be restored with the same binary values and special characters
+	restored, err := xorm.NewEngine("sqlite3", filepath.Join(dir, "restored.db"))
+	assert.NoError(t, err)
+	defer restored.Close()
+	restored.SetMapper(names.GonicMapper{})
+	_, err = restored.ImportFile(filepath.Join(dir, "sqlite3-since.sql"))
+	assert.NoError(t, err)
+
+	got := &auth_model.WebAuthnCredential{}
+	has, err := restored.ID(cred.ID).Get(got)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.Equal(t, cred.Name, got.Name)
+	assert.Equal(t, cred.CredentialID, got.CredentialID)
+	assert.Equal(t, cred.PublicKey, got.PublicKey)
+	assert.Equal(t, cred.AAGUID, got.AAGUID)
+}
+
 func TestDeleteOrphanedObjects(t *testing.T) {
 	assert.NoError(t, unittest.PrepareTestDatabase())
 
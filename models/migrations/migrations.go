@@ -21,6 +21,7 @@ import (
 	"code.gitea.io/gitea/models/migrations/v1_19"
 	"code.gitea.io/gitea/models/migrations/v1_20"
 	"code.gitea.io/gitea/models/migrations/v1_21"
+	"code.gitea.io/gitea/models/migrations/v1_22"
 	"code.gitea.io/gitea/models/migrations/v1_6"
 	"code.gitea.io/gitea/models/migrations/v1_7"
 	"code.gitea.io/gitea/models/migrations/v1_8"
@@ -521,6 +522,13 @@ var migrations = []Migration{
 	NewMigration("Update Action Ref", v1_21.UpdateActionsRefIndex),
 	// v269 -> v270
 	NewMigration("Drop deleted branch table", v1_21.DropDeletedBranchTable),
+
+	// Gitea 1.21.0 ends at 270
+
+	// v270 -> v271
+	NewMigration("Add max_repo_size column to user table", v1_22.AddMaxRepoSizeToUser),
+	// v271 -> v272
+	NewMigration("Add message column to notification table", v1_22.AddMessageToNotification),
 }
 
 // GetCurrentDBVersion returns the current db version
@@ -569,8 +577,62 @@ func EnsureUpToDate(x *xorm.Engine) error {
 	return nil
 }
 
+// PendingMigration is a migration which hasn't been applied to the database
+type PendingMigration struct {
+	Migration
+	Version int64 // the database version before the migration
+}
+
+func pendingMigrationsFrom(v int64) []*PendingMigration {
+	pending := make([]*PendingMigration, 0, len(migrations)-int(v-minDBVersion))
+	for i, m := range migrations[v-minDBVersion:] {
+		pending = append(pending, &PendingMigration{Migration: m, Version: v + int64(i)})
+	}
+	return pending
+}
+
+// PendingMigrations returns the current database version and the migrations which would be applied by Migrate,
+// the database is not modified. The version is -1 if the database has not been initialized,
+// then no migration would be applied because all tables would be created from scratch.
+func PendingMigrations(x *xorm.Engine) (int64, []*PendingMigration, error) {
+	exist, err := x.IsTableExist(new(Version))
+	if err != nil {
+		return -1, nil, fmt.Errorf("check version table: %w", err)
+	} else if !exist {
+		return -1, nil, nil
+	}
+
+	currentVersion := &Version{ID: 1}
+	has, err := x.Get(currentVersion)
+	if err != nil {
+		return -1, nil, fmt.Errorf("get: %w", err)
+	} else if !has {
+		return -1, nil, nil
+	}
+
+	v := currentVersion.Version
+	if minDBVersion > v {
+		return v, nil, fmt.Errorf("DB version %d (<= %d) is too old for auto-migration. Upgrade to Gitea 1.6.4 first then upgrade to this version", v, minDBVersion)
+	}
+	if int(v-minDBVersion) > len(migrations) {
+		return v, nil, fmt.Errorf("DB version %d is for a newer Gitea, this Gitea release expects version %d", v, ExpectedVersion())
+	}
+	return v, pendingMigrationsFrom(v), nil
+}
+
 // Migrate database to current version
 func Migrate(x *xorm.Engine) error {
+	return MigrateTo(x, ExpectedVersion())
+}
+
+// MigrateTo migrates the database up to the target version, the migrations of the later versions are not applied.
+// The target can't be below the current version because the migrations can't be reverted.
+// A database which hasn't been initialized can only be migrated to the current version.
+func MigrateTo(x *xorm.Engine, target int64) error {
+	if target > ExpectedVersion() {
+		return fmt.Errorf("target version %d is beyond the version %d of this Gitea release", target, ExpectedVersion())
+	}
+
 	// Set a new clean the default mapper to GonicMapper as that is the default for Gitea.
 	x.SetMapper(names.GonicMapper{})
 	if err := x.Sync(new(Version)); err != nil {
@@ -584,6 +646,9 @@ func Migrate(x *xorm.Engine) error {
 	} else if !has {
 		// If the version record does not exist we think
 		// it is a fresh installation and we can skip all migrations.
+		if target != ExpectedVersion() {
+			return fmt.Errorf("database has not been initialized, its tables can only be created with the version %d", ExpectedVersion())
+		}
 		currentVersion.ID = 0
 		currentVersion.Version = int64(minDBVersion + len(migrations))
 
@@ -611,6 +676,10 @@ Please try upgrading to a lower version first (suggested v1.6.4), then upgrade t
 		return nil
 	}
 
+	if target < v {
+		return fmt.Errorf("database version %d is already beyond the target version %d, migrations can't be reverted", v, target)
+	}
+
 	// Some migration tasks depend on the git command
 	if git.DefaultContext == nil {
 		if err = git.InitSimple(context.Background()); err != nil {
@@ -619,14 +688,17 @@ Please try upgrading to a lower version first (suggested v1.6.4), then upgrade t
 	}
 
 	// Migrate
-	for i, m := range migrations[v-minDBVersion:] {
-		log.Info("Migration[%d]: %s", v+int64(i), m.Description())
+	for _, m := range pendingMigrationsFrom(v) {
+		if m.Version >= target {
+			break
+		}
+		log.Info("Migration[%d]: %s", m.Version, m.Description())
 		// Reset the mapper between each migration - migrations are not supposed to depend on each other
 		x.SetMapper(names.GonicMapper{})
 		if err = m.Migrate(x); err != nil {
-			return fmt.Errorf("migration[%d]: %s failed: %w", v+int64(i), m.Description(), err)
+			return fmt.Errorf("migration[%d]: %s failed: %w", m.Version, m.Description(), err)
 		}
-		currentVersion.Version = v + int64(i) + 1
+		currentVersion.Version = m.Version + 1
 		if _, err = x.ID(1).Update(currentVersion); err != nil {
 			return err
 		}
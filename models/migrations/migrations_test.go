@@ -0,0 +1,107 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package migrations
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/migrations/base"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/xorm"
+)
+
+func TestMigrateTo(t *testing.T) {
+	x, deferable := base.PrepareTestEnv(t, 0, new(Version))
+	defer deferable()
+	if x == nil || t.Failed() {
+		return
+	}
+
+	// the last two migrations are replaced to record which ones are applied, the expected version doesn't change
+	defer func(old []Migration) { migrations = old }(migrations)
+	var applied []string
+	recordMigration := func(desc string) Migration {
+		return NewMigration(desc, func(*xorm.Engine) error {
+			applied = append(applied, desc)
+			return nil
+		})
+	}
+	migrations = append(migrations[:len(migrations)-2:len(migrations)-2], recordMigration("before last"), recordMigration("last"))
+
+	expected := ExpectedVersion()
+	assert.ErrorContains(t, MigrateTo(x, expected+1), "is beyond the version")
+	// a database which hasn't been initialized can only get the latest version
+	assert.ErrorContains(t, MigrateTo(x, expected-1), "database has not been initialized")
+
+	// the database is two versions behind, it is migrated by one version
+	_, err := x.Insert(&Version{ID: 1, Version: expected - 2})
+	assert.NoError(t, err)
+	assert.NoError(t, MigrateTo(x, expected-1))
+	version, err := GetCurrentDBVersion(x)
+	assert.NoError(t, err)
+	assert.Equal(t, expected-1, version)
+	assert.Equal(t, []string{"before last"}, applied)
+
+	assert.ErrorContains(t, MigrateTo(x, expected-2), "migrations can't be reverted")
+
+	assert.NoError(t, MigrateTo(x, expected))
+	version, err = GetCurrentDBVersion(x)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, version)
+	assert.Equal(t, []string{"before last", "last"}, applied)
+}
+
+func TestPendingMigrations(t *testing.T) {
+	x, deferable := base.PrepareTestEnv(t, 0)
+	defer deferable()
+	if x == nil || t.Failed() {
+		return
+	}
+
+	// without the version table, the tables would be created from scratch
+	current, pending, err := PendingMigrations(x)
+	assert.NoError(t, err)
+	assert.EqualValues(t, -1, current)
+	assert.Empty(t, pending)
+
+	assert.NoError(t, x.Sync(new(Version)))
+	current, pending, err = PendingMigrations(x)
+	assert.NoError(t, err)
+	assert.EqualValues(t, -1, current)
+	assert.Empty(t, pending)
+
+	expected := ExpectedVersion()
+	_, err = x.Insert(&Version{ID: 1, Version: expected - 2})
+	assert.NoError(t, err)
+	current, pending, err = PendingMigrations(x)
+	assert.NoError(t, err)
+	assert.Equal(t, expected-2, current)
+	if assert.Len(t, pending, 2) {
+		assert.Equal(t, expected-2, pending[0].Version)
+		assert.Equal(t, expected-1, pending[1].Version)
+		assert.Equal(t, migrations[len(migrations)-1].Description(), pending[1].Description())
+	}
+	// the database is not modified
+	version, err := GetCurrentDBVersion(x)
+	assert.NoError(t, err)
+	assert.Equal(t, expected-2, version)
+
+	_, err = x.ID(1).Cols("version").Update(&Version{Version: expected})
+	assert.NoError(t, err)
+	current, pending, err = PendingMigrations(x)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, current)
+	assert.Empty(t, pending)
+
+	_, err = x.ID(1).Cols("version").Update(&Version{Version: expected + 1})
+	assert.NoError(t, err)
+	_, _, err = PendingMigrations(x)
+	assert.ErrorContains(t, err, "is for a newer Gitea")
+
+	_, err = x.ID(1).Cols("version").Update(&Version{Version: minDBVersion - 1})
+	assert.NoError(t, err)
+	_, _, err = PendingMigrations(x)
+	assert.ErrorContains(t, err, "is too old for auto-migration")
+}
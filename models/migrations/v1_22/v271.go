@@ -0,0 +1,16 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddMessageToNotification(x *xorm.Engine) error {
+	type Notification struct {
+		Message string `xorm:"TEXT"`
+	}
+
+	return x.Sync(new(Notification))
+}
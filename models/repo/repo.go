@@ -838,6 +838,15 @@ func CountRepositories(ctx context.Context, opts CountRepositoryOptions) (int64,
 	return count, nil
 }
 
+// GetOwnerRepositoriesSize returns the total size in bytes (git and LFS) of the repositories of the owner
+func GetOwnerRepositoriesSize(ctx context.Context, ownerID int64) (int64, error) {
+	size, err := db.GetEngine(ctx).Where("owner_id = ?", ownerID).SumInt(new(Repository), "size")
+	if err != nil {
+		return 0, fmt.Errorf("getOwnerRepositoriesSize: %w", err)
+	}
+	return size, nil
+}
+
 // UpdateRepoIssueNumbers updates one of a repositories amount of (open|closed) (issues|PRs) with the current count
 func UpdateRepoIssueNumbers(ctx context.Context, repoID int64, isPull, isClosed bool) error {
 	field := "num_"
@@ -112,3 +112,9 @@ func UpdateIndexerStatus(ctx context.Context, repo *Repository, indexerType Repo
 	}
 	return nil
 }
+
+// DeleteIndexerStatus deletes the indexer status of the repository, so it's indexed from scratch the next time
+func DeleteIndexerStatus(ctx context.Context, repoID int64, indexerType RepoIndexerType) error {
+	_, err := db.GetEngine(ctx).Where("`repo_id` = ? AND `indexer_type` = ?", repoID, indexerType).Delete(new(RepoIndexerStatus))
+	return err
+}
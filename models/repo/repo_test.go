@@ -186,3 +186,20 @@ func TestGetRepositoryByURL(t *testing.T) {
 		test(t, "try.gitea.io:user2/repo2.git")
 	})
 }
+
+func TestCheckRepoSizeQuota(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	size, err := repo_model.GetOwnerRepositoriesSize(db.DefaultContext, 2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 7320, size)
+
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	assert.NoError(t, repo_model.CheckRepoSizeQuota(db.DefaultContext, user, 1<<30), "no limit")
+
+	user.MaxRepoSize = size + 100
+	assert.NoError(t, repo_model.CheckRepoSizeQuota(db.DefaultContext, user, 100))
+	err = repo_model.CheckRepoSizeQuota(db.DefaultContext, user, 101)
+	assert.True(t, repo_model.IsErrRepoSizeQuotaExceeded(err))
+	assert.EqualValues(t, size+101, err.(repo_model.ErrRepoSizeQuotaExceeded).Size)
+}
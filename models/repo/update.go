@@ -66,6 +66,41 @@ func (err ErrReachLimitOfRepo) Unwrap() error {
 	return util.ErrPermissionDenied
 }
 
+// ErrRepoSizeQuotaExceeded represents a "RepoSizeQuotaExceeded" kind of error.
+type ErrRepoSizeQuotaExceeded struct {
+	Limit int64
+	Size  int64
+}
+
+// IsErrRepoSizeQuotaExceeded checks if an error is a ErrRepoSizeQuotaExceeded.
+func IsErrRepoSizeQuotaExceeded(err error) bool {
+	_, ok := err.(ErrRepoSizeQuotaExceeded)
+	return ok
+}
+
+func (err ErrRepoSizeQuotaExceeded) Error() string {
+	return fmt.Sprintf("user has exceeded the size quota of repositories [limit: %d, size: %d]", err.Limit, err.Size)
+}
+
+func (err ErrRepoSizeQuotaExceeded) Unwrap() error {
+	return util.ErrPermissionDenied
+}
+
+// CheckRepoSizeQuota checks that the total size of the repositories of the owner with the additional size fits in the owner's quota
+func CheckRepoSizeQuota(ctx context.Context, u *user_model.User, additional int64) error {
+	if u.MaxRepoSize <= 0 {
+		return nil
+	}
+	size, err := GetOwnerRepositoriesSize(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	if size+additional > u.MaxRepoSize {
+		return ErrRepoSizeQuotaExceeded{Limit: u.MaxRepoSize, Size: size + additional}
+	}
+	return nil
+}
+
 // ErrRepoAlreadyExist represents a "RepoAlreadyExist" kind of error.
 type ErrRepoAlreadyExist struct {
 	Uname string
@@ -7,6 +7,8 @@ package system
 const (
 	KeyPictureDisableGravatar       = "picture.disable_gravatar"
 	KeyPictureEnableFederatedAvatar = "picture.enable_federated_avatar"
+	KeyMaintenanceEnabled           = "maintenance.enabled"
+	KeyMaintenanceMessage           = "maintenance.message"
 )
 
 // genSettingCacheKey returns the cache key for some configuration
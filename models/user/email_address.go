@@ -9,10 +9,12 @@ import (
 	"fmt"
 	"net/mail"
 	"regexp"
+	"sort"
 	"strings"
 
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/container"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
@@ -505,6 +507,87 @@ func SearchEmails(opts *SearchEmailOptions) ([]*SearchEmailResult, int64, error)
 	return emails, count, err
 }
 
+// DuplicateEmailResult is an e-mail address which is used by more than one user or organization, case-insensitively
+type DuplicateEmailResult struct {
+	LowerEmail  string
+	Email       string
+	IsActivated bool
+	IsPrimary   bool
+	// From User
+	UID  int64
+	Name string
+	Type UserType
+}
+
+// FindDuplicateEmails returns the e-mail addresses which are used by more than one user or organization, case-insensitively,
+// sorted by the lowercased e-mail address. Besides the email_address table, the emails of the user table are checked too,
+// the organizations and the users whose e-mail address is missing in the email_address table have only them.
+func FindDuplicateEmails(ctx context.Context) ([]*DuplicateEmailResult, error) {
+	// UNION removes the same e-mail address of a user in both tables. The builder's UNION isn't used, SQLite doesn't support its parentheses
+	lowerEmails := make([]string, 0, 10)
+	if err := db.GetEngine(ctx).SQL("SELECT email FROM (" +
+		"SELECT lower_email AS email, uid FROM email_address UNION SELECT LOWER(email) AS email, id AS uid FROM `user` WHERE email <> ''" +
+		") emails_of_users GROUP BY email HAVING COUNT(*) > 1").Find(&lowerEmails); err != nil {
+		return nil, err
+	}
+	if len(lowerEmails) == 0 {
+		return nil, nil
+	}
+
+	emails := make([]*DuplicateEmailResult, 0, len(lowerEmails)*2)
+	if err := db.GetEngine(ctx).Table("email_address").
+		Select("email_address.lower_email, email_address.email, email_address.is_activated, email_address.is_primary, `user`.id AS uid, `user`.name, `user`.type").
+		Join("INNER", "`user`", "`user`.id = email_address.uid").
+		In("email_address.lower_email", lowerEmails).
+		Find(&emails); err != nil {
+		return nil, err
+	}
+	inEmailAddresses := make(container.Set[string], len(emails))
+	for _, email := range emails {
+		inEmailAddresses.Add(fmt.Sprintf("%d:%s", email.UID, email.LowerEmail))
+	}
+
+	users := make([]*User, 0, len(lowerEmails))
+	if err := db.GetEngine(ctx).Where(builder.In("LOWER(email)", lowerEmails)).Find(&users); err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		lowerEmail := strings.ToLower(u.Email)
+		if inEmailAddresses.Contains(fmt.Sprintf("%d:%s", u.ID, lowerEmail)) {
+			continue
+		}
+		emails = append(emails, &DuplicateEmailResult{
+			LowerEmail:  lowerEmail,
+			Email:       u.Email,
+			IsActivated: u.IsActive,
+			IsPrimary:   true,
+			UID:         u.ID,
+			Name:        u.Name,
+			Type:        u.Type,
+		})
+	}
+
+	// the email_address rows of the deleted users are counted by the first query, but they can't be listed
+	accounts := make(map[string]int, len(lowerEmails))
+	for _, email := range emails {
+		accounts[email.LowerEmail]++
+	}
+	duplicates := make([]*DuplicateEmailResult, 0, len(emails))
+	for _, email := range emails {
+		if accounts[email.LowerEmail] > 1 {
+			duplicates = append(duplicates, email)
+		}
+	}
+
+	sort.Slice(duplicates, func(i, j int) bool {
+		if duplicates[i].LowerEmail != duplicates[j].LowerEmail {
+			return duplicates[i].LowerEmail < duplicates[j].LowerEmail
+		}
+		return duplicates[i].UID < duplicates[j].UID
+	})
+	return duplicates, nil
+}
+
 // ActivateUserEmail will change the activated state of an email address,
 // either primary or secondary (all in the email_address table)
 func ActivateUserEmail(userID int64, email string, activate bool) (err error) {
@@ -253,6 +253,36 @@ func TestListEmails(t *testing.T) {
 	assert.Greater(t, count, int64(len(emails)))
 }
 
+func TestFindDuplicateEmails(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	emails, err := user_model.FindDuplicateEmails(db.DefaultContext)
+	assert.NoError(t, err)
+	assert.Empty(t, emails)
+
+	// the organization 'user3' shares the primary email of 'user2' (with another case, like the old data), it only has the email in the user table
+	_, err = db.GetEngine(db.DefaultContext).Exec("UPDATE `user` SET email = ? WHERE id = ?", "User2@Example.com", 3)
+	assert.NoError(t, err)
+
+	emails, err = user_model.FindDuplicateEmails(db.DefaultContext)
+	assert.NoError(t, err)
+	if assert.Len(t, emails, 2) {
+		assert.Equal(t, &user_model.DuplicateEmailResult{
+			LowerEmail:  "user2@example.com",
+			Email:       "user2@example.com",
+			IsActivated: true,
+			IsPrimary:   true,
+			UID:         2,
+			Name:        "user2",
+			Type:        user_model.UserTypeIndividual,
+		}, emails[0])
+		assert.EqualValues(t, 3, emails[1].UID)
+		assert.Equal(t, "user2@example.com", emails[1].LowerEmail)
+		assert.Equal(t, "User2@Example.com", emails[1].Email)
+		assert.Equal(t, user_model.UserTypeOrganization, emails[1].Type)
+	}
+}
+
 func TestEmailAddressValidate(t *testing.T) {
 	kases := map[string]error{
 		"abc@gmail.com":                  nil,
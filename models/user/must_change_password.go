@@ -13,7 +13,16 @@ import (
 	"xorm.io/builder"
 )
 
-func SetMustChangePassword(ctx context.Context, all, mustChangePassword bool, include, exclude []string) (int64, error) {
+// SetMustChangePasswordOptions selects the users of SetMustChangePassword
+type SetMustChangePasswordOptions struct {
+	All       bool     // all users, Include and AllAdmins are ignored
+	AllAdmins bool     // all admins and the users in Include
+	Include   []string // the names of the users
+	Exclude   []string // the names of the users to skip
+}
+
+// SetMustChangePassword sets the MustChangePassword flag of the selected users and returns the number of the changed users
+func SetMustChangePassword(ctx context.Context, mustChangePassword bool, opts SetMustChangePasswordOptions) (int64, error) {
 	sliceTrimSpaceDropEmpty := func(input []string) []string {
 		output := make([]string, 0, len(input))
 		for _, in := range input {
@@ -31,16 +40,23 @@ func SetMustChangePassword(ctx context.Context, all, mustChangePassword bool, in
 	// Only include the users where something changes to get an accurate count
 	cond = builder.Neq{"must_change_password": mustChangePassword}
 
-	if !all {
-		include = sliceTrimSpaceDropEmpty(include)
-		if len(include) == 0 {
+	if !opts.All {
+		include := sliceTrimSpaceDropEmpty(opts.Include)
+		if len(include) == 0 && !opts.AllAdmins {
 			return 0, util.NewSilentWrapErrorf(util.ErrInvalidArgument, "no users to include provided")
 		}
 
-		cond = cond.And(builder.In("lower_name", include))
+		selected := builder.NewCond()
+		if opts.AllAdmins {
+			selected = selected.Or(builder.Eq{"is_admin": true})
+		}
+		if len(include) > 0 {
+			selected = selected.Or(builder.In("lower_name", include))
+		}
+		cond = cond.And(selected)
 	}
 
-	exclude = sliceTrimSpaceDropEmpty(exclude)
+	exclude := sliceTrimSpaceDropEmpty(opts.Exclude)
 	if len(exclude) > 0 {
 		cond = cond.And(builder.NotIn("lower_name", exclude))
 	}
@@ -0,0 +1,39 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package user_test
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetMustChangePassword(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	// user1 is the only admin
+	n, err := user_model.SetMustChangePassword(db.DefaultContext, true, user_model.SetMustChangePasswordOptions{AllAdmins: true, Include: []string{"User2"}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, n)
+	unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1, MustChangePassword: true})
+	unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2, MustChangePassword: true})
+	assert.False(t, unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 4}).MustChangePassword)
+
+	// only the changed users are counted
+	n, err = user_model.SetMustChangePassword(db.DefaultContext, false, user_model.SetMustChangePasswordOptions{AllAdmins: true, Exclude: []string{"user1"}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, n)
+	n, err = user_model.SetMustChangePassword(db.DefaultContext, false, user_model.SetMustChangePasswordOptions{All: true, Exclude: []string{"user2"}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, n)
+	assert.False(t, unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1}).MustChangePassword)
+	unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2, MustChangePassword: true})
+
+	_, err = user_model.SetMustChangePassword(db.DefaultContext, true, user_model.SetMustChangePasswordOptions{})
+	assert.Error(t, err)
+}
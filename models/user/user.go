@@ -102,6 +102,8 @@ type User struct {
 	LastRepoVisibility bool
 	// Maximum repository creation limit, -1 means use global default
 	MaxRepoCreation int `xorm:"NOT NULL DEFAULT -1"`
+	// Maximum total size in bytes of the owned repositories, 0 means no limit
+	MaxRepoSize int64 `xorm:"NOT NULL DEFAULT 0"`
 
 	// IsActive true: primary email is activated, user can access Web UI and Git SSH.
 	// false: an inactive user can only log in Web UI for account operations (ex: activate the account by email), no other access.
@@ -87,10 +87,11 @@ func checkAuthorizedKeys(ctx context.Context, logger log.Logger, autofix bool) e
 
 func init() {
 	Register(&Check{
-		Title:     "Check if OpenSSH authorized_keys file is up-to-date",
-		Name:      "authorized-keys",
-		IsDefault: true,
-		Run:       checkAuthorizedKeys,
-		Priority:  4,
+		Title:          "Check if OpenSSH authorized_keys file is up-to-date",
+		Name:           "authorized-keys",
+		IsDefault:      true,
+		Run:            checkAuthorizedKeys,
+		Priority:       4,
+		DestructiveFix: "rewrite the authorized_keys file with the public keys of the database",
 	})
 }
@@ -50,10 +50,11 @@ func checkOldArchives(ctx context.Context, logger log.Logger, autofix bool) erro
 
 func init() {
 	Register(&Check{
-		Title:     "Check old archives",
-		Name:      "check-old-archives",
-		IsDefault: false,
-		Run:       checkOldArchives,
-		Priority:  7,
+		Title:          "Check old archives",
+		Name:           "check-old-archives",
+		IsDefault:      false,
+		Run:            checkOldArchives,
+		Priority:       7,
+		DestructiveFix: "delete the old archives directories of the repositories",
 	})
 }
@@ -0,0 +1,53 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// checkConfigKeys reports the keys of the config file which are unknown to this Gitea version or deprecated,
+// like "gitea config validate" does, but it is runnable together with the other checks
+func checkConfigKeys(ctx context.Context, logger log.Logger, autofix bool) error {
+	// the validation loads all the settings again, which would change the settings used by the other checks,
+	// so "gitea config validate" does it in its own process
+	cmd := exec.CommandContext(ctx, setting.AppPath, setting.ConfigValidateArgs()...)
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if err != nil && (!errors.As(err, &exitErr) || exitErr.ExitCode() != setting.ConfigValidateIssuesExitCode) {
+		logger.Critical("Unable to validate config %q: %v\n%s", setting.CustomConf, err, strings.TrimSpace(stderr.String()))
+		return fmt.Errorf("unable to validate config %q: %w", setting.CustomConf, err)
+	}
+	if err == nil {
+		logger.Info("No unknown or deprecated keys in config %q", setting.CustomConf)
+		return nil
+	}
+
+	// each issue is printed on its own line
+	for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+		logger.Warn("%s", line)
+	}
+	return nil
+}
+
+func init() {
+	Register(&Check{
+		Title:                      "Check the config file for unknown and deprecated keys",
+		Name:                       "check-config-keys",
+		IsDefault:                  false,
+		Run:                        checkConfigKeys,
+		SkipDatabaseInitialization: true,
+		Priority:                   2, // after the "paths" check which checks the config file exists
+	})
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckConfigKeys(t *testing.T) {
+	defer func(appPath, workPath, customPath, customConf string) {
+		setting.AppPath, setting.AppWorkPath, setting.CustomPath, setting.CustomConf = appPath, workPath, customPath, customConf
+	}(setting.AppPath, setting.AppWorkPath, setting.CustomPath, setting.CustomConf)
+
+	dir := t.TempDir()
+	setting.AppWorkPath = filepath.Join(dir, "work")
+	setting.CustomPath = filepath.Join(dir, "custom")
+	setting.CustomConf = filepath.Join(dir, "conf.d")
+	assert.NoError(t, os.Mkdir(setting.CustomConf, 0o755))
+
+	// a fake "gitea" which prints its arguments and exits with the given code
+	run := func(exitCode int) (CheckStatus, string, error) {
+		setting.AppPath = filepath.Join(dir, fmt.Sprintf("gitea-%d", exitCode))
+		assert.NoError(t, os.WriteFile(setting.AppPath, []byte(fmt.Sprintf("#!/bin/sh\necho \"$@\"\nexit %d\n", exitCode)), 0o755))
+		stepLogger := &doctorCheckStepLogger{out: &strings.Builder{}}
+		err := runCheck(context.Background(), &Check{Run: checkConfigKeys}, stepLogger, false, 0)
+		status, message := stepLogger.result(err)
+		return status, message, err
+	}
+
+	// the validation gets the same paths and config directory as this process
+	expectedArgs := fmt.Sprintf("--work-path %s --custom-path %s --config-dir %s config validate", setting.AppWorkPath, setting.CustomPath, setting.CustomConf)
+
+	status, _, err := run(0)
+	assert.NoError(t, err)
+	assert.Equal(t, CheckStatusOK, status)
+
+	status, message, err := run(setting.ConfigValidateIssuesExitCode)
+	assert.NoError(t, err)
+	assert.Equal(t, CheckStatusWarn, status)
+	assert.Contains(t, message, expectedArgs)
+
+	status, message, err = run(1)
+	assert.ErrorContains(t, err, "unable to validate config")
+	assert.Equal(t, CheckStatusError, status)
+	assert.Contains(t, message, "unable to validate config")
+}
@@ -220,10 +220,11 @@ func checkDBConsistency(ctx context.Context, logger log.Logger, autofix bool) er
 
 func init() {
 	Register(&Check{
-		Title:     "Check consistency of database",
-		Name:      "check-db-consistency",
-		IsDefault: false,
-		Run:       checkDBConsistency,
-		Priority:  3,
+		Title:          "Check consistency of database",
+		Name:           "check-db-consistency",
+		IsDefault:      false,
+		Run:            checkDBConsistency,
+		Priority:       3,
+		DestructiveFix: "delete the orphaned rows and rewrite the inconsistent columns of the database",
 	})
 }
@@ -32,11 +32,12 @@ func checkDBVersion(ctx context.Context, logger log.Logger, autofix bool) error
 
 func init() {
 	Register(&Check{
-		Title:         "Check Database Version",
-		Name:          "check-db-version",
-		IsDefault:     true,
-		Run:           checkDBVersion,
-		AbortIfFailed: false,
-		Priority:      2,
+		Title:          "Check Database Version",
+		Name:           "check-db-version",
+		IsDefault:      true,
+		Run:            checkDBVersion,
+		AbortIfFailed:  false,
+		Priority:       2,
+		DestructiveFix: "migrate the database to the current version, the migrated database can't be used by the older Gitea versions",
 	})
 }
@@ -5,10 +5,13 @@ package doctor
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"io"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/modules/git"
@@ -25,6 +28,8 @@ type Check struct {
 	AbortIfFailed              bool
 	SkipDatabaseInitialization bool
 	Priority                   int
+	// DestructiveFix tells what the fix deletes or rewrites, the fix is only applied once confirmed if it is set
+	DestructiveFix string
 }
 
 func initDBSkipLogger(ctx context.Context) error {
@@ -40,14 +45,32 @@ func initDBSkipLogger(ctx context.Context) error {
 	return nil
 }
 
+// CheckStatus is the status of a check result
+type CheckStatus string
+
+const (
+	CheckStatusOK    CheckStatus = "ok"
+	CheckStatusWarn  CheckStatus = "warn"
+	CheckStatusError CheckStatus = "error"
+)
+
+// CheckResult is the result of running a check, the message contains the error or the logged warnings
+type CheckResult struct {
+	Name    string      `json:"name"`
+	Title   string      `json:"title"`
+	Status  CheckStatus `json:"status"`
+	Message string      `json:"message"`
+}
+
 type doctorCheckLogger struct {
+	out      io.Writer
 	colorize bool
 }
 
 var _ log.BaseLogger = (*doctorCheckLogger)(nil)
 
 func (d *doctorCheckLogger) Log(skip int, level log.Level, format string, v ...any) {
-	_, _ = fmt.Fprintf(os.Stdout, format+"\n", v...)
+	_, _ = fmt.Fprintf(d.out, format+"\n", v...)
 }
 
 func (d *doctorCheckLogger) GetLevel() log.Level {
@@ -55,33 +78,104 @@ func (d *doctorCheckLogger) GetLevel() log.Level {
 }
 
 type doctorCheckStepLogger struct {
+	out      io.Writer
 	colorize bool
+
+	mu        sync.Mutex // a timed out check keeps running in its goroutine and may still log
+	abandoned bool       // the check has timed out, its logs are dropped
+	maxLevel  log.Level  // the highest level of the logged messages
+	messages  []string   // the messages logged at WARN level or above
 }
 
 var _ log.BaseLogger = (*doctorCheckStepLogger)(nil)
 
 func (d *doctorCheckStepLogger) Log(skip int, level log.Level, format string, v ...any) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.abandoned {
+		return
+	}
+	if level > d.maxLevel {
+		d.maxLevel = level
+	}
+	if level >= log.WARN {
+		d.messages = append(d.messages, fmt.Sprintf(format, v...))
+	}
+
 	levelChar := fmt.Sprintf("[%s]", strings.ToUpper(level.String()[0:1]))
 	var levelArg any = levelChar
 	if d.colorize {
 		levelArg = log.NewColoredValue(levelChar, level.ColorAttributes()...)
 	}
 	args := append([]any{levelArg}, v...)
-	_, _ = fmt.Fprintf(os.Stdout, " - %s "+format+"\n", args...)
+	_, _ = fmt.Fprintf(d.out, " - %s "+format+"\n", args...)
 }
 
 func (d *doctorCheckStepLogger) GetLevel() log.Level {
 	return log.TRACE
 }
 
+func (d *doctorCheckStepLogger) abandon() {
+	d.mu.Lock()
+	d.abandoned = true
+	d.mu.Unlock()
+}
+
+// result returns the status and message of the check by its returned error and the logged messages
+func (d *doctorCheckStepLogger) result(err error) (CheckStatus, string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err != nil {
+		return CheckStatusError, err.Error()
+	}
+	message := strings.Join(d.messages, "\n")
+	if d.maxLevel >= log.ERROR {
+		return CheckStatusError, message
+	} else if d.maxLevel >= log.WARN {
+		return CheckStatusWarn, message
+	}
+	return CheckStatusOK, message
+}
+
 // Checks is the list of available commands
 var Checks []*Check
 
-// RunChecks runs the doctor checks for the provided list
-func RunChecks(ctx context.Context, colorize, autofix bool, checks []*Check) error {
+// runCheck runs the check, if it doesn't finish within the timeout (0 means no timeout) it fails with a "timed out" error.
+// A check which doesn't respect the context can't be stopped, it is left running in the background and its logs are dropped.
+func runCheck(ctx context.Context, check *Check, stepLogger *doctorCheckStepLogger, autofix bool, timeout time.Duration) error {
+	logger := log.BaseLoggerToGeneralLogger(stepLogger)
+	if timeout <= 0 {
+		return check.Run(ctx, logger, autofix)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- check.Run(ctx, logger, autofix)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		stepLogger.abandon()
+		err = ctx.Err()
+	}
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %v", timeout)
+	}
+	return err
+}
+
+// RunChecks runs the doctor checks for the provided list, the human-readable output is written to "out".
+// Each check is bounded by the timeout, 0 means no timeout.
+// The destructive fixes are only applied if confirmFix confirms them (all of them if it is nil), otherwise the check only reports the problems.
+// The results of the run checks are returned, including the failed one if a check aborts the run.
+func RunChecks(ctx context.Context, out io.Writer, colorize, autofix bool, timeout time.Duration, confirmFix func(*Check) bool, checks []*Check) ([]*CheckResult, error) {
 	// the checks output logs by a special logger, they do not use the default logger
-	logger := log.BaseLoggerToGeneralLogger(&doctorCheckLogger{colorize: colorize})
-	loggerStep := log.BaseLoggerToGeneralLogger(&doctorCheckStepLogger{colorize: colorize})
+	logger := log.BaseLoggerToGeneralLogger(&doctorCheckLogger{out: out, colorize: colorize})
+	results := make([]*CheckResult, 0, len(checks))
 	dbIsInit := false
 	for i, check := range checks {
 		if !dbIsInit && !check.SkipDatabaseInitialization {
@@ -89,15 +183,26 @@ func RunChecks(ctx context.Context, colorize, autofix bool, checks []*Check) err
 			if err := initDBSkipLogger(ctx); err != nil {
 				logger.Error("Error whilst initializing the database: %v", err)
 				logger.Error("Check if you are using the right config file. You can use a --config directive to specify one.")
-				return nil
+				results = append(results, &CheckResult{Name: "database", Title: "Initialize the database", Status: CheckStatusError, Message: err.Error()})
+				return results, nil
 			}
 			dbIsInit = true
 		}
 		logger.Info("\n[%d] %s", i+1, check.Title)
-		if err := check.Run(ctx, loggerStep, autofix); err != nil {
+		stepLogger := &doctorCheckStepLogger{out: out, colorize: colorize}
+		checkAutofix := autofix
+		if autofix && check.DestructiveFix != "" && confirmFix != nil && !confirmFix(check) {
+			logger.Info(" - The fix is not confirmed, the problems are only reported")
+			checkAutofix = false
+		}
+		err := runCheck(ctx, check, stepLogger, checkAutofix, timeout)
+		result := &CheckResult{Name: check.Name, Title: check.Title}
+		result.Status, result.Message = stepLogger.result(err)
+		results = append(results, result)
+		if err != nil {
 			if check.AbortIfFailed {
 				logger.Critical("FAIL")
-				return err
+				return results, err
 			}
 			logger.Error("ERROR")
 		} else {
@@ -105,7 +210,36 @@ func RunChecks(ctx context.Context, colorize, autofix bool, checks []*Check) err
 		}
 	}
 	logger.Info("\nAll done.")
-	return nil
+	return results, nil
+}
+
+// ChecksByNames returns the registered checks with the given names in the registered order,
+// it returns an error listing the valid names if any name is unknown
+func ChecksByNames(names []string) ([]*Check, error) {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			wanted[name] = true
+		}
+	}
+	checks := make([]*Check, 0, len(wanted))
+	validNames := make([]string, 0, len(Checks))
+	for _, check := range Checks {
+		validNames = append(validNames, check.Name)
+		if wanted[check.Name] {
+			checks = append(checks, check)
+			delete(wanted, check.Name)
+		}
+	}
+	if len(wanted) > 0 {
+		unknown := make([]string, 0, len(wanted))
+		for name := range wanted {
+			unknown = append(unknown, name)
+		}
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown check(s): %s, valid checks are: %s", strings.Join(unknown, ", "), strings.Join(validNames, ", "))
+	}
+	return checks, nil
 }
 
 // Register registers a command with the list
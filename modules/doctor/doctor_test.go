@@ -0,0 +1,111 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package doctor
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecksByNames(t *testing.T) {
+	checks, err := ChecksByNames([]string{"paths", " Hooks", ""})
+	assert.NoError(t, err)
+	if assert.Len(t, checks, 2) {
+		// the checks are returned in the registered order
+		assert.Equal(t, "paths", checks[0].Name)
+		assert.Equal(t, "hooks", checks[1].Name)
+	}
+
+	_, err = ChecksByNames([]string{"hooks", "no-such-check"})
+	assert.ErrorContains(t, err, "unknown check(s): no-such-check, valid checks are: ")
+}
+
+func TestRunChecksTimeout(t *testing.T) {
+	unblock := make(chan struct{})
+	defer close(unblock)
+	checks := []*Check{
+		{
+			Name:                       "hang",
+			SkipDatabaseInitialization: true,
+			Run: func(ctx context.Context, logger log.Logger, autofix bool) error {
+				<-unblock // ignores the context like a check wedged in a call without context
+				logger.Error("dropped")
+				return nil
+			},
+		},
+		{
+			Name:                       "cancelled",
+			SkipDatabaseInitialization: true,
+			Run: func(ctx context.Context, logger log.Logger, autofix bool) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		},
+		{
+			Name:                       "fast",
+			SkipDatabaseInitialization: true,
+			Run: func(ctx context.Context, logger log.Logger, autofix bool) error {
+				logger.Warn("a warning")
+				return nil
+			},
+		},
+	}
+
+	results, err := RunChecks(context.Background(), io.Discard, false, false, 50*time.Millisecond, nil, checks)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 3) {
+		assert.Equal(t, CheckStatusError, results[0].Status)
+		assert.Equal(t, "timed out after 50ms", results[0].Message)
+		assert.Equal(t, CheckStatusError, results[1].Status)
+		assert.Equal(t, "timed out after 50ms", results[1].Message)
+		assert.Equal(t, CheckStatusWarn, results[2].Status)
+		assert.Equal(t, "a warning", results[2].Message)
+	}
+
+	// no timeout
+	results, err = RunChecks(context.Background(), io.Discard, false, false, 0, nil, checks[2:])
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, CheckStatusWarn, results[0].Status)
+	}
+}
+
+func TestRunChecksConfirmFix(t *testing.T) {
+	fixed := map[string]bool{}
+	newCheck := func(name, destructiveFix string) *Check {
+		return &Check{
+			Name:                       name,
+			SkipDatabaseInitialization: true,
+			DestructiveFix:             destructiveFix,
+			Run: func(ctx context.Context, logger log.Logger, autofix bool) error {
+				fixed[name] = autofix
+				return nil
+			},
+		}
+	}
+	checks := []*Check{newCheck("safe", ""), newCheck("confirmed", "delete a"), newCheck("refused", "delete b")}
+
+	var asked []string
+	confirmFix := func(check *Check) bool {
+		asked = append(asked, check.Name)
+		return check.Name == "confirmed"
+	}
+	_, err := RunChecks(context.Background(), io.Discard, false, true, 0, confirmFix, checks)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"confirmed", "refused"}, asked)
+	assert.Equal(t, map[string]bool{"safe": true, "confirmed": true, "refused": false}, fixed)
+
+	// nothing is asked without --fix
+	asked = nil
+	_, err = RunChecks(context.Background(), io.Discard, false, false, 0, confirmFix, checks)
+	assert.NoError(t, err)
+	assert.Empty(t, asked)
+	assert.Equal(t, map[string]bool{"safe": false, "confirmed": false, "refused": false}, fixed)
+}
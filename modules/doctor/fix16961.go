@@ -313,10 +313,11 @@ func fixBrokenRepoUnits16961(ctx context.Context, logger log.Logger, autofix boo
 
 func init() {
 	Register(&Check{
-		Title:     "Check for incorrectly dumped repo_units (See #16961)",
-		Name:      "fix-broken-repo-units",
-		IsDefault: false,
-		Run:       fixBrokenRepoUnits16961,
-		Priority:  7,
+		Title:          "Check for incorrectly dumped repo_units (See #16961)",
+		Name:           "fix-broken-repo-units",
+		IsDefault:      false,
+		Run:            fixBrokenRepoUnits16961,
+		Priority:       7,
+		DestructiveFix: "rewrite the broken configurations of the repository units",
 	})
 }
@@ -79,10 +79,11 @@ func synchronizeRepoHeads(ctx context.Context, logger log.Logger, autofix bool)
 
 func init() {
 	Register(&Check{
-		Title:     "Synchronize repo HEADs",
-		Name:      "synchronize-repo-heads",
-		IsDefault: true,
-		Run:       synchronizeRepoHeads,
-		Priority:  7,
+		Title:          "Synchronize repo HEADs",
+		Name:           "synchronize-repo-heads",
+		IsDefault:      true,
+		Run:            synchronizeRepoHeads,
+		Priority:       7,
+		DestructiveFix: "point the HEAD of the repositories to their default branch",
 	})
 }
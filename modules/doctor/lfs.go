@@ -5,11 +5,16 @@ package doctor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"time"
 
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/services/repository"
 )
 
@@ -22,6 +27,18 @@ func init() {
 		AbortIfFailed:              false,
 		SkipDatabaseInitialization: false,
 		Priority:                   1,
+		DestructiveFix:             "delete the LFS meta objects older than a week which are not referenced by the repositories",
+	})
+
+	Register(&Check{
+		Title:                      "Check for orphaned LFS files in storage and LFS meta objects missing in storage",
+		Name:                       "lfs-orphaned-objects",
+		IsDefault:                  false,
+		Run:                        checkLFSOrphanedObjects,
+		AbortIfFailed:              false,
+		SkipDatabaseInitialization: false,
+		Priority:                   1,
+		DestructiveFix:             "delete the LFS files in storage which are not referenced by any LFS meta object",
 	})
 }
 
@@ -49,3 +66,41 @@ func garbageCollectLFSCheck(ctx context.Context, logger log.Logger, autofix bool
 
 	return checkStorage(&checkStorageOptions{LFS: true})(ctx, logger, autofix)
 }
+
+// checkLFSOrphanedObjects reports the LFS files in storage which are not referenced by any LFS meta object (they are deleted with autofix),
+// and the LFS meta objects whose files are missing in storage. Both the storage and the database are scanned in batches.
+func checkLFSOrphanedObjects(ctx context.Context, logger log.Logger, autofix bool) error {
+	if !setting.LFS.StartServer {
+		logger.Info("LFS isn't enabled (skipped)")
+		return nil
+	}
+
+	// it also initializes the storage
+	if err := checkStorage(&checkStorageOptions{LFS: true})(ctx, logger, autofix); err != nil {
+		return err
+	}
+
+	totalCount, missingCount := 0, 0
+	if err := db.Iterate(ctx, nil, func(ctx context.Context, meta *git_model.LFSMetaObject) error {
+		totalCount++
+		_, err := storage.LFS.Stat(meta.RelativePath())
+		if err == nil {
+			return nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("unable to stat LFS file %s: %w", meta.RelativePath(), err)
+		}
+		missingCount++
+		logger.Warn("LFS meta object %s of repository %d is missing in storage", meta.Oid, meta.RepositoryID)
+		return nil
+	}); err != nil {
+		logger.Error("Error whilst iterating LFS meta objects: %v", err)
+		return err
+	}
+
+	if missingCount > 0 {
+		logger.Warn("Found %d/%d LFS meta object(s) missing in storage, they can't be fixed automatically, please restore the files or re-push them", missingCount, totalCount)
+	} else {
+		logger.Info("Found %d LFS meta object(s), all of them exist in storage", totalCount)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckLFSOrphanedObjects(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	defer func(startServer bool) {
+		setting.LFS.StartServer = startServer
+	}(setting.LFS.StartServer)
+	setting.LFS.StartServer = true
+
+	check := func(autofix bool) (CheckStatus, string) {
+		stepLogger := &doctorCheckStepLogger{out: &strings.Builder{}}
+		err := runCheck(context.Background(), &Check{Run: checkLFSOrphanedObjects}, stepLogger, autofix, 0)
+		return stepLogger.result(err)
+	}
+
+	// the LFS storage of the tests is empty, all the meta objects are missing
+	total := unittest.GetCount(t, &git_model.LFSMetaObject{})
+	status, message := check(false)
+	assert.Equal(t, CheckStatusWarn, status)
+	assert.Contains(t, message, fmt.Sprintf("Found %d/%d LFS meta object(s) missing in storage", total, total))
+
+	meta := unittest.AssertExistsAndLoadBean(t, &git_model.LFSMetaObject{ID: 1})
+	_, err := storage.LFS.Save(meta.RelativePath(), strings.NewReader("content"), -1)
+	assert.NoError(t, err)
+	orphaned := lfs.Pointer{Oid: strings.Repeat("a", 64)}
+	_, err = storage.LFS.Save(orphaned.RelativePath(), strings.NewReader("orphaned"), -1)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, storage.Clean(storage.LFS))
+	}()
+
+	status, message = check(false)
+	assert.Equal(t, CheckStatusWarn, status)
+	assert.Contains(t, message, "Found 1/2 (8 B/15 B) orphaned LFS file(s)")
+	assert.Contains(t, message, fmt.Sprintf("Found %d/%d LFS meta object(s) missing in storage", total-1, total))
+	_, err = storage.LFS.Stat(orphaned.RelativePath())
+	assert.NoError(t, err)
+
+	// the orphaned file is deleted, the missing ones can't be fixed
+	_, message = check(true)
+	assert.NotContains(t, message, "orphaned")
+	assert.Contains(t, message, fmt.Sprintf("Found %d/%d LFS meta object(s) missing in storage", total-1, total))
+	_, err = storage.LFS.Stat(orphaned.RelativePath())
+	assert.Error(t, err)
+	_, err = storage.LFS.Stat(meta.RelativePath())
+	assert.NoError(t, err)
+}
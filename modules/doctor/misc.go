@@ -261,11 +261,12 @@ func init() {
 		Priority:  5,
 	})
 	Register(&Check{
-		Title:     "Check if hook files are up-to-date and executable",
-		Name:      "hooks",
-		IsDefault: false,
-		Run:       checkHooks,
-		Priority:  6,
+		Title:          "Check if hook files are up-to-date and executable",
+		Name:           "hooks",
+		IsDefault:      false,
+		Run:            checkHooks,
+		Priority:       6,
+		DestructiveFix: "rewrite the hook files of the repositories which are outdated or not executable",
 	})
 	Register(&Check{
 		Title:     "Recalculate Stars number for all user",
@@ -58,7 +58,8 @@ func checkConfigurationFile(logger log.Logger, autofix bool, fileOpts configurat
 }
 
 func checkConfigurationFiles(ctx context.Context, logger log.Logger, autofix bool) error {
-	if fi, err := os.Stat(setting.CustomConf); err != nil || !fi.Mode().IsRegular() {
+	fi, err := os.Stat(setting.CustomConf)
+	if err != nil || !(fi.Mode().IsRegular() || fi.IsDir()) {
 		logger.Error("Failed to find configuration file at '%s'.", setting.CustomConf)
 		logger.Error("If you've never ran Gitea yet, this is normal and '%s' will be created for you on first run.", setting.CustomConf)
 		logger.Error("Otherwise check that you are running this command from the correct path and/or provide a `--config` parameter.")
@@ -69,7 +70,7 @@ func checkConfigurationFiles(ctx context.Context, logger log.Logger, autofix boo
 	setting.MustInstalled()
 
 	configurationFiles := []configurationFile{
-		{"Configuration File Path", setting.CustomConf, false, true, false},
+		{"Configuration File Path", setting.CustomConf, fi.IsDir(), true, false},
 		{"Repository Root Path", setting.RepoRootPath, true, true, true},
 		{"Data Root Path", setting.AppDataPath, true, true, true},
 		{"Custom File Root Path", setting.CustomPath, true, false, false},
@@ -216,6 +216,7 @@ func init() {
 		AbortIfFailed:              false,
 		SkipDatabaseInitialization: false,
 		Priority:                   1,
+		DestructiveFix:             "delete the orphaned attachments, LFS files, avatars, archives and package blobs in storage",
 	})
 
 	Register(&Check{
@@ -226,6 +227,7 @@ func init() {
 		AbortIfFailed:              false,
 		SkipDatabaseInitialization: false,
 		Priority:                   1,
+		DestructiveFix:             "delete the orphaned attachments in storage",
 	})
 
 	Register(&Check{
@@ -236,6 +238,7 @@ func init() {
 		AbortIfFailed:              false,
 		SkipDatabaseInitialization: false,
 		Priority:                   1,
+		DestructiveFix:             "delete the orphaned LFS files in storage",
 	})
 
 	Register(&Check{
@@ -246,6 +249,7 @@ func init() {
 		AbortIfFailed:              false,
 		SkipDatabaseInitialization: false,
 		Priority:                   1,
+		DestructiveFix:             "delete the orphaned user and repository avatars in storage",
 	})
 
 	Register(&Check{
@@ -256,6 +260,7 @@ func init() {
 		AbortIfFailed:              false,
 		SkipDatabaseInitialization: false,
 		Priority:                   1,
+		DestructiveFix:             "delete the orphaned repository archives in storage",
 	})
 
 	Register(&Check{
@@ -266,5 +271,6 @@ func init() {
 		AbortIfFailed:              false,
 		SkipDatabaseInitialization: false,
 		Priority:                   1,
+		DestructiveFix:             "delete the orphaned package blobs in storage",
 	})
 }
@@ -0,0 +1,135 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// storageBackend is a configured storage which is probed by the "storage" check
+type storageBackend struct {
+	name    string
+	enabled bool
+	cfg     *setting.Storage
+}
+
+func configuredStorageBackends() []storageBackend {
+	return []storageBackend{
+		{"attachments", setting.Attachment.Enabled, setting.Attachment.Storage},
+		{"lfs", setting.LFS.StartServer, setting.LFS.Storage},
+		{"avatars", true, setting.Avatar.Storage},
+		{"repo-avatars", true, setting.RepoAvatar.Storage},
+		{"repo-archives", true, setting.RepoArchive.Storage},
+		{"packages", setting.Packages.Enabled, setting.Packages.Storage},
+		{"actions-logs", setting.Actions.Enabled, setting.Actions.LogStorage},
+		{"actions-artifacts", setting.Actions.Enabled, setting.Actions.ArtifactStorage},
+	}
+}
+
+// storageLocation describes where the objects of the storage are, without the credentials
+func storageLocation(cfg *setting.Storage) string {
+	if cfg.Type == setting.MinioStorageType {
+		return fmt.Sprintf("%s %s/%s", cfg.MinioConfig.Endpoint, cfg.MinioConfig.Bucket, cfg.MinioConfig.BasePath)
+	}
+	return cfg.Path
+}
+
+// probeStorage writes a small object to the storage, reads it back and deletes it, and returns the latencies of these steps.
+// The probe object is deleted even if reading it back fails.
+func probeStorage(st storage.ObjectStorage) (latencies [3]time.Duration, err error) {
+	suffix, err := util.CryptoRandomString(16)
+	if err != nil {
+		return latencies, err
+	}
+	probePath := "gitea-doctor-probe-" + suffix
+	content := []byte("gitea doctor storage probe " + probePath)
+
+	start := time.Now()
+	if _, err = st.Save(probePath, bytes.NewReader(content), int64(len(content))); err != nil {
+		// a partially written object might still exist
+		_ = st.Delete(probePath)
+		return latencies, fmt.Errorf("write: %w", err)
+	}
+	latencies[0] = time.Since(start)
+	deleted := false
+	defer func() {
+		if !deleted {
+			_ = st.Delete(probePath)
+		}
+	}()
+
+	start = time.Now()
+	obj, err := st.Open(probePath)
+	if err != nil {
+		return latencies, fmt.Errorf("read: %w", err)
+	}
+	read, err := io.ReadAll(obj)
+	_ = obj.Close()
+	if err != nil {
+		return latencies, fmt.Errorf("read: %w", err)
+	} else if !bytes.Equal(read, content) {
+		return latencies, fmt.Errorf("read: the content read back differs from the content written")
+	}
+	latencies[1] = time.Since(start)
+
+	start = time.Now()
+	deleted = true
+	if err = st.Delete(probePath); err != nil {
+		return latencies, fmt.Errorf("delete: %w", err)
+	}
+	latencies[2] = time.Since(start)
+	return latencies, nil
+}
+
+// checkStorageBackends probes each configured storage backend independently, so a misconfigured one doesn't hide the others
+func checkStorageBackends(_ context.Context, logger log.Logger, _ bool) error {
+	var checked, failed int
+	for _, backend := range configuredStorageBackends() {
+		if !backend.enabled {
+			logger.Info("%s: not enabled (skipped)", backend.name)
+			continue
+		}
+		checked++
+		location := fmt.Sprintf("%s: %s", backend.cfg.Type, storageLocation(backend.cfg))
+		st, err := storage.NewStorage(backend.cfg.Type, backend.cfg)
+		if err != nil {
+			failed++
+			logger.Error("%s (%s): unable to initialize the storage: %v", backend.name, location, err)
+			continue
+		}
+		latencies, err := probeStorage(st)
+		if err != nil {
+			failed++
+			logger.Error("%s (%s): %v", backend.name, location, err)
+			continue
+		}
+		logger.Info("%s (%s): OK, write %v, read %v, delete %v", backend.name, location,
+			latencies[0].Round(time.Microsecond), latencies[1].Round(time.Microsecond), latencies[2].Round(time.Microsecond))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d storage backends failed", failed, checked)
+	}
+	return nil
+}
+
+func init() {
+	Register(&Check{
+		Title:                      "Check that the storage backends can write, read and delete objects",
+		Name:                       "storage",
+		IsDefault:                  false,
+		Run:                        checkStorageBackends,
+		AbortIfFailed:              false,
+		SkipDatabaseInitialization: true,
+		Priority:                   1,
+	})
+}
@@ -0,0 +1,68 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package doctor
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingStorage fails one of the steps of the probe
+type failingStorage struct {
+	storage.ObjectStorage
+	failOn  string
+	deletes int
+}
+
+func (s *failingStorage) Save(path string, r io.Reader, size int64) (int64, error) {
+	if s.failOn == "write" {
+		return 0, errors.New("injected failure")
+	}
+	return s.ObjectStorage.Save(path, r, size)
+}
+
+func (s *failingStorage) Open(path string) (storage.Object, error) {
+	if s.failOn == "read" {
+		return nil, errors.New("injected failure")
+	}
+	return s.ObjectStorage.Open(path)
+}
+
+func (s *failingStorage) Delete(path string) error {
+	s.deletes++
+	if s.failOn == "delete" {
+		return errors.New("injected failure")
+	}
+	return s.ObjectStorage.Delete(path)
+}
+
+func TestProbeStorage(t *testing.T) {
+	dir := t.TempDir()
+	st, err := storage.NewStorage(setting.LocalStorageType, &setting.Storage{Path: dir})
+	assert.NoError(t, err)
+
+	_, err = probeStorage(st)
+	assert.NoError(t, err)
+	// the probe object is deleted
+	assert.NoError(t, st.IterateObjects("", func(path string, obj storage.Object) error {
+		t.Errorf("unexpected object %s", path)
+		return nil
+	}))
+
+	// the failing step is reported, and the probe object is deleted if it was written
+	for _, failOn := range []string{"write", "read", "delete"} {
+		fs := &failingStorage{ObjectStorage: st, failOn: failOn}
+		_, err = probeStorage(fs)
+		assert.ErrorContains(t, err, failOn+": injected failure")
+		if failOn == "read" {
+			assert.Equal(t, 1, fs.deletes)
+		}
+	}
+}
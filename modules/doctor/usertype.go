@@ -32,10 +32,11 @@ func checkUserType(ctx context.Context, logger log.Logger, autofix bool) error {
 
 func init() {
 	Register(&Check{
-		Title:     "Check if user with wrong type exist",
-		Name:      "check-user-type",
-		IsDefault: true,
-		Run:       checkUserType,
-		Priority:  3,
+		Title:          "Check if user with wrong type exist",
+		Name:           "check-user-type",
+		IsDefault:      true,
+		Run:            checkUserType,
+		Priority:       3,
+		DestructiveFix: "change the type of the users who are members of teams to organization",
 	})
 }
@@ -0,0 +1,171 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	webhook_model "code.gitea.io/gitea/models/webhook"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// WebhookDeliveryWindow is how far back the "webhooks" check looks at the deliveries
+var WebhookDeliveryWindow = 7 * 24 * time.Hour
+
+// deadWebhook is a webhook whose deliveries in the window all failed
+type deadWebhook struct {
+	HookID        int64
+	Deliveries    int64
+	LastDelivered timeutil.TimeStampNano
+}
+
+// findDeadWebhooks returns the webhooks which have deliveries since the given time and none of them succeeded, ordered by the webhook ID
+func findDeadWebhooks(ctx context.Context, since time.Time) (dead []*deadWebhook, checked int, err error) {
+	cond := builder.Eq{"is_delivered": true}.And(builder.Gte{"delivered": since.UnixNano()})
+
+	var delivered []*deadWebhook
+	if err := db.GetEngine(ctx).Table("hook_task").
+		Select("hook_id, COUNT(*) AS deliveries, MAX(delivered) AS last_delivered").
+		Where(cond).GroupBy("hook_id").Find(&delivered); err != nil {
+		return nil, 0, err
+	}
+
+	var succeeded []int64
+	if err := db.GetEngine(ctx).Table("hook_task").Distinct("hook_id").
+		Where(cond.And(builder.Eq{"is_succeed": true})).Find(&succeeded); err != nil {
+		return nil, 0, err
+	}
+	succeededSet := make(map[int64]bool, len(succeeded))
+	for _, id := range succeeded {
+		succeededSet[id] = true
+	}
+
+	for _, hook := range delivered {
+		if !succeededSet[hook.HookID] {
+			dead = append(dead, hook)
+		}
+	}
+	sort.Slice(dead, func(i, j int) bool { return dead[i].HookID < dead[j].HookID })
+	return dead, len(delivered), nil
+}
+
+// webhookOwnerNames returns a readable description of where each webhook is configured
+func webhookOwnerNames(ctx context.Context, hooks []*webhook_model.Webhook) (map[int64]string, error) {
+	var repoIDs, ownerIDs []int64
+	for _, hook := range hooks {
+		if hook.RepoID > 0 {
+			repoIDs = append(repoIDs, hook.RepoID)
+		} else if hook.OwnerID > 0 {
+			ownerIDs = append(ownerIDs, hook.OwnerID)
+		}
+	}
+	repos, err := repo_model.GetRepositoriesMapByIDs(repoIDs)
+	if err != nil {
+		return nil, err
+	}
+	owners, err := user_model.GetPossibleUserByIDs(ctx, ownerIDs)
+	if err != nil {
+		return nil, err
+	}
+	ownerNames := make(map[int64]string, len(owners))
+	for _, owner := range owners {
+		ownerNames[owner.ID] = owner.Name
+	}
+
+	names := make(map[int64]string, len(hooks))
+	for _, hook := range hooks {
+		switch {
+		case hook.RepoID > 0:
+			if repo, ok := repos[hook.RepoID]; ok {
+				names[hook.ID] = "repository " + repo.FullName()
+			} else {
+				names[hook.ID] = fmt.Sprintf("repository #%d", hook.RepoID)
+			}
+		case hook.OwnerID > 0:
+			if name, ok := ownerNames[hook.OwnerID]; ok {
+				names[hook.ID] = "owner " + name
+			} else {
+				names[hook.ID] = fmt.Sprintf("owner #%d", hook.OwnerID)
+			}
+		case hook.IsSystemWebhook:
+			names[hook.ID] = "system webhook"
+		default:
+			names[hook.ID] = "default webhook"
+		}
+	}
+	return names, nil
+}
+
+// checkWebhookDeliveries reports the webhooks whose recent deliveries all failed, their endpoints are likely dead
+func checkWebhookDeliveries(ctx context.Context, logger log.Logger, _ bool) error {
+	since := time.Now().Add(-WebhookDeliveryWindow)
+	dead, checked, err := findDeadWebhooks(ctx, since)
+	if err != nil {
+		return fmt.Errorf("unable to count the webhook deliveries: %w", err)
+	}
+	if len(dead) == 0 {
+		logger.Info("None of the %d webhook(s) with deliveries since %s failed all its deliveries", checked, since.Format(time.RFC3339))
+		return nil
+	}
+
+	hookIDs := make([]int64, 0, len(dead))
+	for _, d := range dead {
+		hookIDs = append(hookIDs, d.HookID)
+	}
+	hooks := make(map[int64]*webhook_model.Webhook, len(hookIDs))
+	if err := db.GetEngine(ctx).In("id", hookIDs).Find(&hooks); err != nil {
+		return fmt.Errorf("unable to load the webhooks: %w", err)
+	}
+	hookList := make([]*webhook_model.Webhook, 0, len(hooks))
+	for _, hook := range hooks {
+		hookList = append(hookList, hook)
+	}
+	names, err := webhookOwnerNames(ctx, hookList)
+	if err != nil {
+		return fmt.Errorf("unable to load the webhook owners: %w", err)
+	}
+
+	reported := 0
+	for _, d := range dead {
+		hook, ok := hooks[d.HookID]
+		if !ok {
+			// the deliveries of a deleted webhook are not a problem of any endpoint
+			continue
+		}
+		reported++
+		inactive := ""
+		if !hook.IsActive {
+			inactive = " (inactive)"
+		}
+		logger.Warn("%s: webhook %d%s to %s has %d failed deliveries and none succeeded, the last one at %s",
+			names[hook.ID], hook.ID, inactive, util.SanitizeCredentialURLs(hook.URL), d.Deliveries,
+			d.LastDelivered.AsTime().Format(time.RFC3339))
+	}
+	if reported > 0 {
+		logger.Warn("%d of %d webhook(s) with deliveries since %s failed all their deliveries", reported, checked, since.Format(time.RFC3339))
+	} else {
+		logger.Info("None of the %d webhook(s) with deliveries since %s failed all its deliveries", checked, since.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func init() {
+	Register(&Check{
+		Title:     "Check for webhooks whose recent deliveries all failed",
+		Name:      "webhooks",
+		IsDefault: false,
+		Run:       checkWebhookDeliveries,
+		Priority:  8,
+	})
+}
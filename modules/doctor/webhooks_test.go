@@ -0,0 +1,73 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	webhook_model "code.gitea.io/gitea/models/webhook"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindDeadWebhooks(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	now := time.Now()
+	since := now.Add(-24 * time.Hour)
+	var tasks []*webhook_model.HookTask
+	addTask := func(hookID int64, delivered time.Time, succeed bool) {
+		tasks = append(tasks, &webhook_model.HookTask{
+			HookID:      hookID,
+			UUID:        fmt.Sprintf("dead-webhooks-%d", len(tasks)),
+			IsDelivered: true,
+			Delivered:   timeutil.TimeStampNano(delivered.UnixNano()),
+			IsSucceed:   succeed,
+		})
+	}
+	// all the deliveries of the webhook 1 failed
+	addTask(1, now.Add(-2*time.Hour), false)
+	addTask(1, now.Add(-time.Hour), false)
+	// one delivery of the webhook 2 succeeded
+	addTask(2, now.Add(-2*time.Hour), false)
+	addTask(2, now.Add(-time.Hour), true)
+	// the deliveries of the webhook 3 before the window are ignored, its only delivery in the window succeeded
+	addTask(3, now.Add(-48*time.Hour), false)
+	addTask(3, now.Add(-time.Hour), true)
+	// the webhook 4 only has failed deliveries before the window
+	addTask(4, now.Add(-48*time.Hour), false)
+	// the webhook 1000 has been deleted
+	addTask(1000, now.Add(-time.Hour), false)
+	assert.NoError(t, db.Insert(db.DefaultContext, tasks))
+
+	dead, checked, err := findDeadWebhooks(db.DefaultContext, since)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, checked)
+	if assert.Len(t, dead, 2) {
+		assert.EqualValues(t, 1, dead[0].HookID)
+		assert.EqualValues(t, 2, dead[0].Deliveries)
+		assert.EqualValues(t, now.Add(-time.Hour).UnixNano(), dead[0].LastDelivered)
+		assert.EqualValues(t, 1000, dead[1].HookID)
+	}
+
+	// the deleted webhook isn't reported by the check
+	defer func(window time.Duration) {
+		WebhookDeliveryWindow = window
+	}(WebhookDeliveryWindow)
+	WebhookDeliveryWindow = 24 * time.Hour
+	stepLogger := &doctorCheckStepLogger{out: &strings.Builder{}}
+	assert.NoError(t, runCheck(context.Background(), &Check{Run: checkWebhookDeliveries}, stepLogger, false, 0))
+	status, message := stepLogger.result(nil)
+	assert.Equal(t, CheckStatusWarn, status)
+	assert.Contains(t, message, "repository user2/repo1: webhook 1 to www.example.com/url1 has 2 failed deliveries and none succeeded")
+	assert.NotContains(t, message, "webhook 1000")
+	assert.Contains(t, message, "1 of 4 webhook(s) with deliveries since")
+}
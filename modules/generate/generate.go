@@ -7,8 +7,12 @@ package generate
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
+	"fmt"
 	"io"
+	"strings"
 	"time"
+	"unicode"
 
 	"code.gitea.io/gitea/modules/util"
 
@@ -66,3 +70,74 @@ func NewSecretKey() (string, error) {
 
 	return secretKey, nil
 }
+
+// minSecretLength is the minimum length of a secret which is not generated with a known structure
+const minSecretLength = 32
+
+// CheckInternalToken checks whether the value looks like a valid INTERNAL_TOKEN. A token in the form of a JWT (like the ones
+// generated by NewInternalToken) must be complete, but its signature can't be verified because the signing key is not kept.
+func CheckInternalToken(token string) error {
+	if err := checkSecretChars(token); err != nil {
+		return err
+	}
+	if !strings.Contains(token, ".") {
+		if len(token) < minSecretLength {
+			return fmt.Errorf("it has %d characters, at least %d are expected", len(token), minSecretLength)
+		}
+		return nil
+	}
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		return fmt.Errorf("it is a malformed JWT: %w", err)
+	}
+	method, ok := parsed.Method.(*jwt.SigningMethodHMAC)
+	if !ok {
+		return fmt.Errorf("it is a JWT signed with %s, but HMAC is expected", parsed.Method.Alg())
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(token[strings.LastIndexByte(token, '.')+1:])
+	if err != nil {
+		return fmt.Errorf("it is a JWT with a malformed signature: %w", err)
+	}
+	if len(signature) != method.Hash.Size() {
+		return fmt.Errorf("it is a JWT with a signature of %d bytes, %d bytes are expected for %s, it may be truncated", len(signature), method.Hash.Size(), method.Alg())
+	}
+	return nil
+}
+
+// CheckJwtSecretBase64 checks whether the value is a base64 encoded 32 bytes secret, like the ones generated by NewJwtSecretBase64
+func CheckJwtSecretBase64(secret string) error {
+	if err := checkSecretChars(secret); err != nil {
+		return err
+	}
+	bytes, err := base64.RawURLEncoding.DecodeString(secret)
+	if err != nil {
+		return fmt.Errorf("it is not base64 encoded (URL encoding without padding): %w", err)
+	}
+	if len(bytes) != 32 {
+		return fmt.Errorf("it decodes to %d bytes, 32 bytes are expected", len(bytes))
+	}
+	return nil
+}
+
+// CheckSecretKey checks whether the value looks like a valid SECRET_KEY
+func CheckSecretKey(secret string) error {
+	if err := checkSecretChars(secret); err != nil {
+		return err
+	}
+	if len(secret) < minSecretLength {
+		return fmt.Errorf("it has %d characters, at least %d are expected", len(secret), minSecretLength)
+	}
+	return nil
+}
+
+// checkSecretChars checks that the secret is not empty and has no whitespace, which is usually left by copying and pasting
+func checkSecretChars(secret string) error {
+	if secret == "" {
+		return errors.New("it is empty")
+	}
+	if i := strings.IndexFunc(secret, unicode.IsSpace); i >= 0 {
+		return fmt.Errorf("it contains a whitespace character at position %d", i+1)
+	}
+	return nil
+}
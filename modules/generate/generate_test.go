@@ -0,0 +1,42 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package generate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckInternalToken(t *testing.T) {
+	token, err := NewInternalToken()
+	assert.NoError(t, err)
+	assert.NoError(t, CheckInternalToken(token))
+	assert.NoError(t, CheckInternalToken(strings.Repeat("a", 32)))
+
+	assert.ErrorContains(t, CheckInternalToken(""), "empty")
+	assert.ErrorContains(t, CheckInternalToken(token+"\n"), "whitespace")
+	assert.ErrorContains(t, CheckInternalToken(token[:len(token)-5]), "may be truncated")
+	assert.ErrorContains(t, CheckInternalToken(token[:strings.LastIndexByte(token, '.')]), "malformed JWT")
+	assert.ErrorContains(t, CheckInternalToken("short"), "at least 32")
+}
+
+func TestCheckJwtSecretBase64(t *testing.T) {
+	secret, err := NewJwtSecretBase64()
+	assert.NoError(t, err)
+	assert.NoError(t, CheckJwtSecretBase64(secret))
+
+	assert.ErrorContains(t, CheckJwtSecretBase64(secret[:40]), "decodes to 30 bytes")
+	assert.ErrorContains(t, CheckJwtSecretBase64(secret+"="), "not base64 encoded")
+}
+
+func TestCheckSecretKey(t *testing.T) {
+	secret, err := NewSecretKey()
+	assert.NoError(t, err)
+	assert.NoError(t, CheckSecretKey(secret))
+
+	assert.ErrorContains(t, CheckSecretKey("!#@FDEWREWR&*("), "at least 32")
+	assert.ErrorContains(t, CheckSecretKey(" "+secret), "position 1")
+}
@@ -7,6 +7,8 @@ import (
 	"context"
 	"testing"
 
+	"code.gitea.io/gitea/modules/process"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -42,6 +44,21 @@ func TestRunWithContextStd(t *testing.T) {
 	assert.Contains(t, stdout, "git version")
 }
 
+func TestCommandProcessType(t *testing.T) {
+	count := func() int {
+		return process.GetManager().CountByType(process.GitProcessType)
+	}
+	before := count()
+	err := NewCommand(context.Background(), "--version").Run(&RunOpts{
+		PipelineFunc: func(ctx context.Context, cancel context.CancelFunc) error {
+			assert.Equal(t, before+1, count())
+			return nil
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, before, count())
+}
+
 func TestGitArgument(t *testing.T) {
 	assert.True(t, isValidArgumentOption("-x"))
 	assert.True(t, isValidArgumentOption("--xx"))
@@ -389,7 +389,9 @@ func configUnsetAll(key, value string) error {
 	return fmt.Errorf("failed to get git config %s, err: %w", key, err)
 }
 
-// Fsck verifies the connectivity and validity of the objects in the database
+// Fsck verifies the connectivity and validity of the objects in the database,
+// the returned error contains the problems reported by git
 func Fsck(ctx context.Context, repoPath string, timeout time.Duration, args TrustedCmdArgs) error {
-	return NewCommand(ctx, "fsck").AddArguments(args...).Run(&RunOpts{Timeout: timeout, Dir: repoPath})
+	_, _, err := NewCommand(ctx, "fsck").AddArguments(args...).RunStdBytes(&RunOpts{Timeout: timeout, Dir: repoPath})
+	return err
 }
@@ -272,6 +272,12 @@ func (g *Manager) InformCleanup() {
 	g.createServerWaitGroup.Done()
 }
 
+// RegisterServers tells the cleanup wait group that n more servers will be created besides the pre-defined ones (numberOfServersToCreate),
+// eg: the main HTTP/HTTPS service listens on more than one address. It must be called before these servers get their listeners.
+func (g *Manager) RegisterServers(n int) {
+	g.createServerWaitGroup.Add(n)
+}
+
 // Done allows the manager to be viewed as a context.Context, it returns a channel that is closed when the server is finished terminating
 func (g *Manager) Done() <-chan struct{} {
 	return g.managerCtx.Done()
@@ -23,6 +23,7 @@ import (
 
 const (
 	listenFDsEnv = "LISTEN_FDS"
+	listenPIDEnv = "LISTEN_PID"
 	startFD      = 3
 	unlinkFDsEnv = "GITEA_UNLINK_FDS"
 
@@ -97,6 +98,14 @@ func getProvidedFDs() (savedErr error) {
 			return
 		}
 
+		// systemd socket activation sets LISTEN_PID to the process which should use the provided fds.
+		// If it doesn't match, the variables are inherited from a parent process, so the fds are not for us.
+		// (The graceful restart doesn't pass LISTEN_PID to the new process)
+		if pidStr := os.Getenv(listenPIDEnv); pidStr != "" && pidStr != strconv.Itoa(os.Getpid()) {
+			log.Warn("Ignoring %s=%s: %s=%s doesn't match the current PID %d", listenFDsEnv, numFDs, listenPIDEnv, pidStr, os.Getpid())
+			return
+		}
+
 		fdsToUnlinkStr := strings.Split(os.Getenv(unlinkFDsEnv), ",")
 		providedListenersToUnlink = make([]bool, n)
 		for _, fdStr := range fdsToUnlinkStr {
@@ -125,6 +134,7 @@ func getProvidedFDs() (savedErr error) {
 			savedErr = fmt.Errorf("Error getting provided socket fd %d: %w", i, err)
 			return
 		}
+		log.Info("Using %d provided listener(s) from the parent process or systemd socket activation", len(providedListeners))
 	})
 	return savedErr
 }
@@ -135,6 +145,7 @@ func CloseProvidedListeners() error {
 	defer mutex.Unlock()
 	var returnableError error
 	for _, l := range providedListeners {
+		log.Warn("Closing unused provided listener %s, there is no server listening on this address", l.Addr())
 		err := l.Close()
 		if err != nil {
 			log.Error("Error in closing unused provided listener: %v", err)
@@ -190,7 +201,7 @@ func GetListenerTCP(network string, address *net.TCPAddr) (*net.TCPListener, err
 
 	// look for a provided listener
 	for i, l := range providedListeners {
-		if isSameAddr(l.Addr(), address) {
+		if isSameAddr(l.Addr(), address) || isSamePortOnAnyIP(l.Addr(), address) {
 			providedListeners = append(providedListeners[:i], providedListeners[i+1:]...)
 			needsUnlink := providedListenersToUnlink[i]
 			providedListenersToUnlink = append(providedListenersToUnlink[:i], providedListenersToUnlink[i+1:]...)
@@ -283,6 +294,17 @@ func isSameAddr(a1, a2 net.Addr) bool {
 	return a1s == a2s
 }
 
+// isSamePortOnAnyIP checks whether the wanted address listens on all IPs (eg: the default "0.0.0.0"),
+// and the provided listener (eg: from systemd "ListenStream=127.0.0.1:3000") listens on the same port.
+// Then the provided listener is used instead of failing to bind the port again.
+func isSamePortOnAnyIP(provided net.Addr, wanted *net.TCPAddr) bool {
+	providedTCP, ok := provided.(*net.TCPAddr)
+	if !ok || (wanted.IP != nil && !wanted.IP.IsUnspecified()) {
+		return false
+	}
+	return providedTCP.Port == wanted.Port
+}
+
 func getActiveListeners() []net.Listener {
 	mutex.Lock()
 	defer mutex.Unlock()
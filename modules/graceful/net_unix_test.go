@@ -0,0 +1,27 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package graceful
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSamePortOnAnyIP(t *testing.T) {
+	provided := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 3000}
+
+	// the wanted address listens on all IPs
+	assert.True(t, isSamePortOnAnyIP(provided, &net.TCPAddr{Port: 3000}))
+	assert.True(t, isSamePortOnAnyIP(provided, &net.TCPAddr{IP: net.IPv4zero, Port: 3000}))
+	assert.True(t, isSamePortOnAnyIP(provided, &net.TCPAddr{IP: net.IPv6unspecified, Port: 3000}))
+
+	assert.False(t, isSamePortOnAnyIP(provided, &net.TCPAddr{Port: 3001}))
+	// a specific IP only matches the same address, which isSameAddr checks
+	assert.False(t, isSamePortOnAnyIP(provided, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 3000}))
+	assert.False(t, isSamePortOnAnyIP(&net.UnixAddr{Name: "/run/gitea.sock", Net: "unix"}, &net.TCPAddr{Port: 3000}))
+}
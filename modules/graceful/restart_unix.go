@@ -69,9 +69,10 @@ func RestartProcess() (int, error) {
 	}
 
 	// Pass on the environment and replace the old count key with the new one.
+	// The LISTEN_PID from systemd is dropped, it doesn't match the new process.
 	var env []string
 	for _, v := range os.Environ() {
-		if !strings.HasPrefix(v, listenFDsEnv+"=") {
+		if !strings.HasPrefix(v, listenFDsEnv+"=") && !strings.HasPrefix(v, listenPIDEnv+"=") {
 			env = append(env, v)
 		}
 	}
@@ -54,6 +54,7 @@ type Server struct {
 	address              string
 	listener             net.Listener
 	wg                   sync.WaitGroup
+	activeConns          atomic.Int64 // the number of the accepted connections which haven't been closed
 	state                state
 	lock                 *sync.RWMutex
 	BeforeBegin          func(network, address string)
@@ -245,6 +246,7 @@ func (wl *wrappedListener) Accept() (net.Conn, error) {
 	}
 
 	wl.server.wg.Add(1)
+	wl.server.activeConns.Add(1)
 	return c, nil
 }
 
@@ -298,6 +300,7 @@ func (w *wrappedConn) Close() error {
 				}
 			}
 		}()
+		w.server.activeConns.Add(-1)
 		w.server.wg.Done()
 	}
 	return w.Conn.Close()
@@ -60,7 +60,7 @@ func (srv *Server) doHammer() {
 	if srv.getState() != stateShuttingDown {
 		return
 	}
-	log.Warn("Forcefully shutting down parent")
+	log.Warn("Forcefully shutting down parent, %d connections of %s are still active", srv.activeConns.Load(), srv.address)
 	for {
 		if srv.getState() == stateTerminate {
 			break
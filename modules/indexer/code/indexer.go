@@ -264,6 +264,17 @@ func UpdateRepoIndexer(repo *repo_model.Repository) {
 	}
 }
 
+// ReindexRepo removes the repository from the code indexer and queues it to be indexed from scratch
+func ReindexRepo(ctx context.Context, repo *repo_model.Repository) error {
+	if err := (*globalIndexer.Load()).Delete(ctx, repo.ID); err != nil {
+		return err
+	}
+	if err := repo_model.DeleteIndexerStatus(ctx, repo.ID, repo_model.RepoIndexerTypeCode); err != nil {
+		return err
+	}
+	return indexerQueue.Push(&internal.IndexerData{RepoID: repo.ID})
+}
+
 // IsAvailable checks if issue indexer is available
 func IsAvailable(ctx context.Context) bool {
 	return (*globalIndexer.Load()).Ping(ctx) == nil
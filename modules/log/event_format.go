@@ -6,8 +6,11 @@ package log
 import (
 	"bytes"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
+
+	"code.gitea.io/gitea/modules/json"
 )
 
 type Event struct {
@@ -244,3 +247,44 @@ func EventFormatTextMessage(mode *WriterMode, event *Event, msgFormat string, ms
 	buf = append(buf, '\n')
 	return buf
 }
+
+type eventJSON struct {
+	Level   string         `json:"level"`
+	Time    string         `json:"time"`
+	Caller  string         `json:"caller"`
+	Message string         `json:"message"`
+	Fields  map[string]any `json:"fields,omitempty"`
+}
+
+// EventFormatJSONMessage makes the log message as a JSON object in one line, the mode's flags and colorizing are not used
+func EventFormatJSONMessage(mode *WriterMode, event *Event, msgFormat string, msgArgs ...any) []byte {
+	msg := event.MsgSimpleText
+	if msg == "" {
+		msg = colorSprintf(false, msgFormat, msgArgs...)
+	}
+	ev := eventJSON{
+		Level:   event.Level.String(),
+		Time:    event.Time.Format(time.RFC3339Nano),
+		Caller:  event.Filename + ":" + strconv.Itoa(event.Line),
+		Message: strings.TrimSuffix(msg, "\n"),
+		Fields:  map[string]any{},
+	}
+	if event.Caller != "" {
+		ev.Fields["func"] = event.Caller
+	}
+	if event.GoroutinePid != "" {
+		ev.Fields["pid"] = event.GoroutinePid
+	}
+	if mode.Prefix != "" {
+		ev.Fields["prefix"] = mode.Prefix
+	}
+	if event.Stacktrace != "" && mode.StacktraceLevel <= event.Level {
+		ev.Fields["stacktrace"] = event.Stacktrace
+	}
+	buf, err := json.Marshal(ev)
+	if err != nil {
+		// it should never happen, all the fields are strings
+		buf = []byte(fmt.Sprintf(`{"level":"error","message":%q}`, "unable to marshal log event: "+err.Error()))
+	}
+	return append(buf, '\n')
+}
@@ -55,3 +55,32 @@ func TestEventFormatTextMessage(t *testing.T) {
 
 	assert.Equal(t, "[PREFIX] \x1b[36m2020/01/02 03:04:05.000000 \x1b[0m\x1b[32mfilename:123:\x1b[32mcaller\x1b[0m \x1b[1;31m[E]\x1b[0m [\x1b[93mpid\x1b[0m] msg format: arg0 \x1b[34marg1\x1b[0m\n\tstacktrace\n\n", string(res))
 }
+
+func TestEventFormatJSONMessage(t *testing.T) {
+	res := EventFormatJSONMessage(&WriterMode{Prefix: "[PREFIX] ", Colorize: true, StacktraceLevel: ERROR},
+		&Event{
+			Time:         time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC),
+			Caller:       "caller",
+			Filename:     "filename",
+			Line:         123,
+			GoroutinePid: "pid",
+			Level:        ERROR,
+			Stacktrace:   "stacktrace",
+		},
+		"msg format: %v %v\n", "arg0", NewColoredValue("arg1", FgBlue),
+	)
+	assert.JSONEq(t, `{
+	"level": "error",
+	"time": "2020-01-02T03:04:05.000000006Z",
+	"caller": "filename:123",
+	"message": "msg format: arg0 arg1",
+	"fields": {"func": "caller", "pid": "pid", "prefix": "[PREFIX] ", "stacktrace": "stacktrace"}
+}`, string(res))
+	assert.Equal(t, byte('\n'), res[len(res)-1])
+
+	res = EventFormatJSONMessage(&WriterMode{StacktraceLevel: NONE},
+		&Event{Time: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), Filename: "filename", Line: 1, Level: INFO, MsgSimpleText: "simple"},
+		"ignored",
+	)
+	assert.Equal(t, `{"level":"info","time":"2020-01-02T03:04:05Z","caller":"filename:1","message":"simple"}`+"\n", string(res))
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"regexp"
 	"runtime/pprof"
+	"sync/atomic"
 	"time"
 )
 
@@ -36,6 +37,7 @@ type EventWriterBaseImpl struct {
 
 	shared  bool
 	stopped chan struct{}
+	paused  atomic.Bool // a paused writer discards the events, unlike the global pause which blocks the writers
 }
 
 var _ EventWriterBase = (*EventWriterBaseImpl)(nil)
@@ -6,12 +6,22 @@ package log
 import (
 	"io"
 	"os"
+	"sync/atomic"
 )
 
 type WriterConsoleOption struct {
 	Stderr bool
 }
 
+// consoleFormatJSON makes the console writers output JSON objects instead of the text messages
+var consoleFormatJSON atomic.Bool
+
+// SetConsoleFormatJSON sets whether the console writers created after it output JSON objects (EventFormatJSONMessage),
+// the existing writers are not changed, and the other writers (eg: file) always use their own formats
+func SetConsoleFormatJSON(b bool) {
+	consoleFormatJSON.Store(b)
+}
+
 type eventWriterConsole struct {
 	*EventWriterBaseImpl
 }
@@ -32,6 +42,9 @@ func NewEventWriterConsole(name string, mode WriterMode) EventWriter {
 	} else {
 		w.OutputWriteCloser = nopCloser{os.Stdout}
 	}
+	if consoleFormatJSON.Load() {
+		w.FormatMessage = EventFormatJSONMessage
+	}
 	return w
 }
 
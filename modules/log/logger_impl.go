@@ -23,6 +23,7 @@ type LoggerImpl struct {
 
 	level           atomic.Int32
 	stacktraceLevel atomic.Int32
+	paused          atomic.Bool // a paused logger discards the events
 
 	eventWriterMu sync.RWMutex
 	eventWriters  map[string]EventWriter
@@ -49,7 +50,7 @@ func (l *LoggerImpl) SendLogEvent(event *Event) {
 	event.msgFormat, event.msgArgs = "(already processed by formatters)", nil
 
 	for _, w := range l.eventWriters {
-		if event.Level < w.GetLevel() {
+		if event.Level < w.GetLevel() || w.Base().paused.Load() {
 			continue
 		}
 		formatted := &EventFormatted{
@@ -156,11 +157,35 @@ func (l *LoggerImpl) DumpWriters() map[string]any {
 		m := map[string]any{}
 		_ = json.Unmarshal(bs, &m)
 		m["WriterType"] = w.GetWriterType()
+		m["IsPaused"] = w.Base().paused.Load()
 		writers[k] = m
 	}
 	return writers
 }
 
+// SetPaused pauses or resumes the logger, or only its writer if the writer name is not empty.
+// The events are discarded while paused.
+func (l *LoggerImpl) SetPaused(writerName string, paused bool) error {
+	if writerName == "" {
+		l.paused.Store(paused)
+		return nil
+	}
+
+	l.eventWriterMu.RLock()
+	defer l.eventWriterMu.RUnlock()
+	w, ok := l.eventWriters[writerName]
+	if !ok {
+		return util.ErrNotExist
+	}
+	w.Base().paused.Store(paused)
+	return nil
+}
+
+// IsPaused returns true if the logger is paused by SetPaused
+func (l *LoggerImpl) IsPaused() bool {
+	return l.paused.Load()
+}
+
 // Close closes the logger, non-shared writers are closed and flushed
 func (l *LoggerImpl) Close() {
 	l.ReplaceAllWriters()
@@ -177,7 +202,7 @@ func (l *LoggerImpl) IsEnabled() bool {
 
 // Log prepares the log event, if the level matches, the event will be sent to the writers
 func (l *LoggerImpl) Log(skip int, level Level, format string, logArgs ...any) {
-	if Level(l.level.Load()) > level {
+	if Level(l.level.Load()) > level || l.paused.Load() {
 		return
 	}
 
@@ -9,6 +9,8 @@ import (
 	"testing"
 	"time"
 
+	"code.gitea.io/gitea/modules/util"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -108,6 +110,32 @@ func TestLoggerPause(t *testing.T) {
 	logger.Close()
 }
 
+func TestLoggerSetPaused(t *testing.T) {
+	logger := NewLoggerWithWriters(context.Background(), "test")
+
+	w1 := newDummyWriter("dummy-1", DEBUG, 0)
+	w2 := newDummyWriter("dummy-2", DEBUG, 0)
+	logger.AddWriters(w1, w2)
+
+	assert.NoError(t, logger.SetPaused("dummy-1", true))
+	assert.ErrorIs(t, logger.SetPaused("no-such-writer", true), util.ErrNotExist)
+	logger.Info("writer-paused")
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, []string{}, w1.GetLogs())
+	assert.Equal(t, []string{"writer-paused\n"}, w2.GetLogs())
+
+	assert.NoError(t, logger.SetPaused("", true))
+	logger.Info("logger-paused")
+	assert.NoError(t, logger.SetPaused("", false))
+	assert.NoError(t, logger.SetPaused("dummy-1", false))
+	logger.Info("resumed")
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, []string{"resumed\n"}, w1.GetLogs())
+	assert.Equal(t, []string{"writer-paused\n", "resumed\n"}, w2.GetLogs())
+
+	logger.Close()
+}
+
 type testLogString struct {
 	Field string
 }
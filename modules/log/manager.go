@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"sync"
 	"sync/atomic"
+
+	"code.gitea.io/gitea/modules/util"
 )
 
 const DEFAULT = "default"
@@ -59,11 +61,24 @@ func (m *LoggerManager) PauseAll() {
 // ResumeAll resumes all event writers
 func (m *LoggerManager) ResumeAll() {
 	m.pauseMu.Lock()
-	close(m.pauseChan)
-	m.pauseChan = nil
+	if m.pauseChan != nil {
+		close(m.pauseChan)
+		m.pauseChan = nil
+	}
 	m.pauseMu.Unlock()
 }
 
+// SetLoggerPaused pauses or resumes an existing logger, or only its writer if the writer name is not empty
+func (m *LoggerManager) SetLoggerPaused(loggerName, writerName string, paused bool) error {
+	m.mu.Lock()
+	logger := m.loggers[loggerName]
+	m.mu.Unlock()
+	if logger == nil {
+		return util.ErrNotExist
+	}
+	return logger.SetPaused(writerName, paused)
+}
+
 // GetPauseChan returns a channel for writer pausing
 func (m *LoggerManager) GetPauseChan() chan struct{} {
 	m.pauseMu.RLock()
@@ -98,6 +113,7 @@ func (m *LoggerManager) DumpLoggers() map[string]any {
 	for name, logger := range m.loggers {
 		loggerDump := map[string]any{
 			"IsEnabled":    logger.IsEnabled(),
+			"IsPaused":     logger.IsPaused(),
 			"EventWriters": logger.DumpWriters(),
 		}
 		dump[name] = loggerDump
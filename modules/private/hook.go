@@ -61,6 +61,7 @@ type HookOptions struct {
 // SSHLogOption ssh log options
 type SSHLogOption struct {
 	IsError bool
+	IsTrace bool
 	Message string
 }
 
@@ -141,3 +142,11 @@ func SSHLog(ctx context.Context, isErr bool, msg string) error {
 	_, extra := requestJSONResp(req, &responseText{})
 	return extra.Error
 }
+
+// SSHTrace sends a trace message of "gitea serv --trace", it is logged even if the ssh log is disabled
+func SSHTrace(ctx context.Context, msg string) error {
+	reqURL := setting.LocalURL + "api/internal/ssh/log"
+	req := newInternalRequest(ctx, reqURL, "POST", &SSHLogOption{IsTrace: true, Message: msg})
+	_, extra := requestJSONResp(req, &responseText{})
+	return extra.Error
+}
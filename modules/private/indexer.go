@@ -0,0 +1,35 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package private
+
+import (
+	"context"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// ReindexOptions represents the options for the reindex call
+type ReindexOptions struct {
+	Repos     []string // "owner/name" of the repositories, a batch of all the repositories after AfterID if empty
+	Types     []string // "code" and/or "issues"
+	AfterID   int64
+	BatchSize int
+	Wait      bool // wait until the indexer queues are flushed
+}
+
+// ReindexResult is the result of the reindex call
+type ReindexResult struct {
+	Repos  []string // the reindexed repositories, the ones with nothing to index (e.g. the code of an empty repository) are not listed
+	LastID int64    // the ID of the last repository of the batch, the AfterID of the next batch, 0 if the batch is empty
+	Total  int64    // the number of all the repositories, only set when reindexing all of them
+}
+
+// Reindex rebuilds the indexes of the repositories in the running process
+func Reindex(ctx context.Context, opts ReindexOptions) (*ReindexResult, ResponseExtra) {
+	reqURL := setting.LocalURL + "api/internal/indexer/reindex"
+	req := newInternalRequest(ctx, reqURL, "POST", opts)
+	req.SetTimeout(10*time.Second, 0) // waiting for the indexers may take very long, the command can be interrupted
+	return requestJSONResp(req, &ReindexResult{})
+}
@@ -34,8 +34,37 @@ func getClientIP() string {
 	return strings.Fields(sshConnEnv)[0]
 }
 
+// remoteManager is the manager listener ("[server] MANAGER_LISTEN_ADDR") of a Gitea server, which receives the manager requests
+// instead of the local Gitea server if it is set
+var remoteManager struct {
+	baseURL string
+	token   string
+}
+
+// UseRemoteManager sends the manager requests to the manager listener at addr ("host:port" or a URL), authenticated by the token.
+// The local configuration is not needed to send them then.
+func UseRemoteManager(addr, token string) {
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	remoteManager.baseURL = strings.TrimRight(addr, "/") + "/"
+	remoteManager.token = token
+}
+
+// remoteManagerURL returns the URL of the request on the manager listener, or false if the request should be sent to the local server
+func remoteManagerURL(reqURL string) (string, bool) {
+	if remoteManager.baseURL == "" || !strings.HasPrefix(reqURL, setting.LocalURL+"api/internal/manager/") {
+		return "", false
+	}
+	return remoteManager.baseURL + strings.TrimPrefix(reqURL, setting.LocalURL), true
+}
+
 func newInternalRequest(ctx context.Context, url, method string, body ...any) *httplib.Request {
-	if setting.InternalToken == "" {
+	token := setting.InternalToken
+	remoteURL, isRemote := remoteManagerURL(url)
+	if isRemote {
+		url, token = remoteURL, remoteManager.token
+	} else if setting.InternalToken == "" {
 		log.Fatal(`The INTERNAL_TOKEN setting is missing from the configuration file: %q.
 Ensure you are running in the correct environment or set the correct configuration file with -c.`, setting.CustomConf)
 	}
@@ -43,13 +72,14 @@ Ensure you are running in the correct environment or set the correct configurati
 	req := httplib.NewRequest(url, method).
 		SetContext(ctx).
 		Header("X-Real-IP", getClientIP()).
-		Header("Authorization", fmt.Sprintf("Bearer %s", setting.InternalToken)).
+		Header("Authorization", fmt.Sprintf("Bearer %s", token)).
 		SetTLSClientConfig(&tls.Config{
 			InsecureSkipVerify: true,
 			ServerName:         setting.Domain,
 		})
 
-	if setting.Protocol == setting.HTTPUnix {
+	// the manager listener is a plain TCP listener, the local server's unix socket and PROXY protocol don't apply to it
+	if !isRemote && setting.Protocol == setting.HTTPUnix {
 		req.SetTransport(&http.Transport{
 			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
 				var d net.Dialer
@@ -66,7 +96,7 @@ Ensure you are running in the correct environment or set the correct configurati
 				return conn, err
 			},
 		})
-	} else if setting.LocalUseProxyProtocol {
+	} else if !isRemote && setting.LocalUseProxyProtocol {
 		req.SetTransport(&http.Transport{
 			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
 				var d net.Dialer
@@ -29,3 +29,14 @@ func AuthorizedPublicKeyByContent(ctx context.Context, content string) (string,
 	resp, extra := requestJSONResp(req, &responseText{})
 	return resp.Text, extra
 }
+
+// CheckPublicKey checks whether the key found by its content or by its fingerprint is usable,
+// the error of the ResponseExtra is nil only if it is.
+func CheckPublicKey(ctx context.Context, content, fingerprint string) ResponseExtra {
+	reqURL := setting.LocalURL + "api/internal/ssh/check_key"
+	req := newInternalRequest(ctx, reqURL, "POST")
+	req.Param("content", content)
+	req.Param("fingerprint", fingerprint)
+	_, extra := requestJSONResp(req, &responseText{})
+	return extra
+}
@@ -52,20 +52,33 @@ func FlushQueues(ctx context.Context, timeout time.Duration, nonBlocking bool) R
 	return requestJSONClientMsg(req, "Flushed")
 }
 
-// PauseLogging pauses logging
-func PauseLogging(ctx context.Context) ResponseExtra {
-	reqURL := setting.LocalURL + "api/internal/manager/pause-logging"
+// PauseLogging pauses logging, only the logger (or its writer) is paused if the logger name is not empty
+func PauseLogging(ctx context.Context, logger, writer string) ResponseExtra {
+	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/manager/pause-logging?logger=%s&writer=%s", url.QueryEscape(logger), url.QueryEscape(writer))
 	req := newInternalRequest(ctx, reqURL, "POST")
 	return requestJSONClientMsg(req, "Logging Paused")
 }
 
-// ResumeLogging resumes logging
-func ResumeLogging(ctx context.Context) ResponseExtra {
-	reqURL := setting.LocalURL + "api/internal/manager/resume-logging"
+// ResumeLogging resumes logging, only the logger (or its writer) is resumed if the logger name is not empty
+func ResumeLogging(ctx context.Context, logger, writer string) ResponseExtra {
+	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/manager/resume-logging?logger=%s&writer=%s", url.QueryEscape(logger), url.QueryEscape(writer))
 	req := newInternalRequest(ctx, reqURL, "POST")
 	return requestJSONClientMsg(req, "Logging Restarted")
 }
 
+// LoggingStatus represents the loggers and their paused state returned by the show-logging call
+type LoggingStatus struct {
+	Paused  bool           // all writers are paused (the events are buffered)
+	Loggers map[string]any // the loggers dumped by log.LoggerManager.DumpLoggers
+}
+
+// ShowLogging returns the loggers and their paused state
+func ShowLogging(ctx context.Context) (*LoggingStatus, ResponseExtra) {
+	reqURL := setting.LocalURL + "api/internal/manager/show-logging"
+	req := newInternalRequest(ctx, reqURL, "GET")
+	return requestJSONResp(req, &LoggingStatus{})
+}
+
 // ReleaseReopenLogging releases and reopens logging files
 func ReleaseReopenLogging(ctx context.Context) ResponseExtra {
 	reqURL := setting.LocalURL + "api/internal/manager/release-and-reopen-logging"
@@ -80,6 +93,79 @@ func SetLogSQL(ctx context.Context, on bool) ResponseExtra {
 	return requestJSONClientMsg(req, "Log SQL setting set")
 }
 
+// SetSettingOptions represents the options for the set-setting call
+type SetSettingOptions struct {
+	Name  string // "section.KEY"
+	Value string
+}
+
+// SetSetting changes an allowed setting of the running process
+func SetSetting(ctx context.Context, name, value string) ResponseExtra {
+	reqURL := setting.LocalURL + "api/internal/manager/set-setting"
+	req := newInternalRequest(ctx, reqURL, "POST", SetSettingOptions{Name: name, Value: value})
+	return requestJSONClientMsg(req, fmt.Sprintf("Set %s to %s", name, value))
+}
+
+// MaintenanceOptions represents the options for the maintenance call
+type MaintenanceOptions struct {
+	Enabled bool
+	Message string
+}
+
+// SetMaintenance turns the maintenance mode of the web frontend on or off
+func SetMaintenance(ctx context.Context, enabled bool, message string) ResponseExtra {
+	reqURL := setting.LocalURL + "api/internal/manager/maintenance"
+	req := newInternalRequest(ctx, reqURL, "POST", MaintenanceOptions{Enabled: enabled, Message: message})
+	if enabled {
+		return requestJSONClientMsg(req, "Maintenance mode is on")
+	}
+	return requestJSONClientMsg(req, "Maintenance mode is off")
+}
+
+// SSHReadOnlyOptions represents the options for the ssh-read-only call
+type SSHReadOnlyOptions struct {
+	Enabled bool
+	Message string // keep the current message if empty
+	Until   string
+}
+
+// SetSSHReadOnly turns the read-only mode of the pushes over SSH on or off
+func SetSSHReadOnly(ctx context.Context, opts SSHReadOnlyOptions) ResponseExtra {
+	reqURL := setting.LocalURL + "api/internal/manager/ssh-read-only"
+	req := newInternalRequest(ctx, reqURL, "POST", opts)
+	if opts.Enabled {
+		return requestJSONClientMsg(req, "SSH read-only mode is on")
+	}
+	return requestJSONClientMsg(req, "SSH read-only mode is off")
+}
+
+// FsckReposOptions represents the options for the fsck-repos call
+type FsckReposOptions struct {
+	Repos       []string // "owner/name" of the repositories, all the repositories with the health check enabled if empty
+	Concurrency int
+	Timeout     time.Duration // the timeout of each repository
+}
+
+// FsckRepoProblem is a repository which failed "git fsck"
+type FsckRepoProblem struct {
+	Repo  string
+	Error string
+}
+
+// FsckReposResult is the result of the fsck-repos call
+type FsckReposResult struct {
+	Checked  int
+	Problems []FsckRepoProblem
+}
+
+// FsckRepos runs "git fsck" on the repositories in the running process
+func FsckRepos(ctx context.Context, opts FsckReposOptions) (*FsckReposResult, ResponseExtra) {
+	reqURL := setting.LocalURL + "api/internal/manager/fsck-repos"
+	req := newInternalRequest(ctx, reqURL, "POST", opts)
+	req.SetTimeout(10*time.Second, 0) // checking all the repositories may take very long, the command can be interrupted
+	return requestJSONResp(req, &FsckReposResult{})
+}
+
 // LoggerOptions represents the options for the add logger call
 type LoggerOptions struct {
 	Logger string
@@ -107,6 +193,14 @@ func RemoveLogger(ctx context.Context, logger, writer string) ResponseExtra {
 	return requestJSONClientMsg(req, "Removed")
 }
 
+// CancelProcess cancels the process of the PID, the ResponseExtra.UserMsg tells whether it has been found and cancelled
+func CancelProcess(ctx context.Context, pid string) ResponseExtra {
+	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/manager/processes/%s/cancel", url.PathEscape(pid))
+	req := newInternalRequest(ctx, reqURL, "POST")
+	_, extra := requestJSONResp(req, &Response{})
+	return extra
+}
+
 // Processes return the current processes from this gitea instance
 func Processes(ctx context.Context, out io.Writer, flat, noSystem, stacktraces, json bool, cancel string) ResponseExtra {
 	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/manager/processes?flat=%t&no-system=%t&stacktraces=%t&json=%t&cancel-pid=%s", flat, noSystem, stacktraces, json, url.QueryEscape(cancel))
@@ -118,3 +212,52 @@ func Processes(ctx context.Context, out io.Writer, flat, noSystem, stacktraces,
 	_, extra := requestJSONResp(req, &responseCallback{callback})
 	return extra
 }
+
+// DatabaseStats are the statistics of the database connection pool
+type DatabaseStats struct {
+	MaxOpenConnections int
+	OpenConnections    int
+	InUse              int
+	Idle               int
+	WaitCount          int64
+	WaitDuration       time.Duration
+}
+
+// QueueStats are the statistics of a queue
+type QueueStats struct {
+	Name          string
+	Type          string
+	Items         int
+	Workers       int
+	ActiveWorkers int
+	MaxWorkers    int
+}
+
+// MemoryStats are the memory statistics of the Go runtime, in bytes
+type MemoryStats struct {
+	Alloc       uint64
+	TotalAlloc  uint64
+	Sys         uint64
+	HeapAlloc   uint64
+	HeapInuse   uint64
+	HeapObjects uint64
+	StackInuse  uint64
+	NumGC       uint32
+}
+
+// ManagerStats is the snapshot of the live counters of the running process returned by the stats call
+type ManagerStats struct {
+	StartTime    time.Time
+	Database     DatabaseStats
+	Queues       []QueueStats
+	GitProcesses int64
+	Goroutines   int
+	Memory       MemoryStats
+}
+
+// Stats returns the live counters of the running process
+func Stats(ctx context.Context) (*ManagerStats, ResponseExtra) {
+	reqURL := setting.LocalURL + "api/internal/manager/stats"
+	req := newInternalRequest(ctx, reqURL, "GET")
+	return requestJSONResp(req, &ManagerStats{})
+}
@@ -5,7 +5,6 @@ package private
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"code.gitea.io/gitea/modules/setting"
@@ -20,7 +19,7 @@ type RestoreParams struct {
 	Validation bool
 }
 
-// RestoreRepo calls the internal RestoreRepo function
+// RestoreRepo calls the internal RestoreRepo function, an empty owner or repository name defaults to the one of the dump
 func RestoreRepo(ctx context.Context, repoDir, ownerName, repoName string, units []string, validation bool) ResponseExtra {
 	reqURL := setting.LocalURL + "api/internal/restore_repo"
 
@@ -32,5 +31,7 @@ func RestoreRepo(ctx context.Context, repoDir, ownerName, repoName string, units
 		Validation: validation,
 	})
 	req.SetTimeout(3*time.Second, 0) // since the request will spend much time, don't timeout
-	return requestJSONClientMsg(req, fmt.Sprintf("Restore repo %s/%s successfully", ownerName, repoName))
+	// the message has the final owner and name of the restored repository
+	_, extra := requestJSONResp(req, &Response{})
+	return extra
 }
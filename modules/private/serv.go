@@ -45,8 +45,8 @@ type ServCommandResults struct {
 	RepoID      int64
 }
 
-// ServCommand preps for a serv call
-func ServCommand(ctx context.Context, keyID int64, ownerName, repoName string, mode perm.AccessMode, verbs ...string) (*ServCommandResults, ResponseExtra) {
+// ServCommand preps for a serv call, with trace the server logs each step of the access decision
+func ServCommand(ctx context.Context, keyID int64, ownerName, repoName string, mode perm.AccessMode, trace bool, verbs ...string) (*ServCommandResults, ResponseExtra) {
 	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/serv/command/%d/%s/%s?mode=%d",
 		keyID,
 		url.PathEscape(ownerName),
@@ -58,6 +58,9 @@ func ServCommand(ctx context.Context, keyID int64, ownerName, repoName string, m
 			reqURL += fmt.Sprintf("&verb=%s", url.QueryEscape(verb))
 		}
 	}
+	if trace {
+		reqURL += "&trace=true"
+	}
 	req := newInternalRequest(ctx, reqURL, "GET")
 	return requestJSONResp(req, &ServCommandResults{})
 }
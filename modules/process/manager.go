@@ -11,6 +11,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"code.gitea.io/gitea/modules/util"
 )
 
 // TODO: This packages still uses a singleton for the Manager.
@@ -135,6 +137,11 @@ func (pm *Manager) AddTypedContext(parent context.Context, description, processT
 // Most processes will not need to use the cancel function but there will be cases whereby you want to cancel the process but not immediately remove it from the
 // process table.
 func (pm *Manager) AddContextTimeout(parent context.Context, timeout time.Duration, description string) (ctx context.Context, cancel context.CancelFunc, finshed FinishedFunc) {
+	return pm.AddTypedContextTimeout(parent, timeout, description, NormalProcessType)
+}
+
+// AddTypedContextTimeout is like AddContextTimeout but the process has the given type
+func (pm *Manager) AddTypedContextTimeout(parent context.Context, timeout time.Duration, description, processType string) (ctx context.Context, cancel context.CancelFunc, finshed FinishedFunc) {
 	if timeout <= 0 {
 		// it's meaningless to use timeout <= 0, and it must be a bug! so we must panic here to tell developers to make the timeout correct
 		panic("the timeout must be greater than zero, otherwise the context will be cancelled immediately")
@@ -142,7 +149,7 @@ func (pm *Manager) AddContextTimeout(parent context.Context, timeout time.Durati
 
 	ctx, cancel = context.WithTimeout(parent, timeout)
 
-	ctx, _, finshed = pm.Add(ctx, description, cancel, NormalProcessType, true)
+	ctx, _, finshed = pm.Add(ctx, description, cancel, processType, true)
 
 	return ctx, cancel, finshed
 }
@@ -232,12 +239,44 @@ func (pm *Manager) remove(process *process) {
 	}
 }
 
+// IsRunning returns whether the process hasn't finished yet
+func (pm *Manager) IsRunning(pid IDType) bool {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	_, ok := pm.processMap[pid]
+	return ok
+}
+
+// CountByType returns the number of the running processes of the type
+func (pm *Manager) CountByType(processType string) int {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	count := 0
+	for _, process := range pm.processMap {
+		if process.Type == processType {
+			count++
+		}
+	}
+	return count
+}
+
 // Cancel a process in the ProcessManager.
 func (pm *Manager) Cancel(pid IDType) {
+	_, _ = pm.TryCancel(pid)
+}
+
+// TryCancel cancels a process in the ProcessManager and returns it,
+// the error is util.ErrNotExist if there is no such process and util.ErrPermissionDenied if it is a system process.
+func (pm *Manager) TryCancel(pid IDType) (*Process, error) {
 	pm.mutex.Lock()
 	process, ok := pm.processMap[pid]
 	pm.mutex.Unlock()
-	if ok && process.Type != SystemProcessType {
-		process.Cancel()
+	if !ok {
+		return nil, util.NewNotExistErrorf("process %s doesn't exist", pid)
+	}
+	if process.Type == SystemProcessType {
+		return nil, util.NewPermissionDeniedErrorf("process %s is a system process", pid)
 	}
+	process.Cancel()
+	return process.toProcess(), nil
 }
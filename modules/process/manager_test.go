@@ -8,6 +8,8 @@ import (
 	"testing"
 	"time"
 
+	"code.gitea.io/gitea/modules/util"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -67,6 +69,30 @@ func TestManager_Cancel(t *testing.T) {
 	finished()
 }
 
+func TestManager_TryCancel(t *testing.T) {
+	pm := Manager{processMap: make(map[IDType]*process), next: 1}
+
+	ctx, _, finished := pm.AddContext(context.Background(), "foo")
+	defer finished()
+
+	process, err := pm.TryCancel(GetPID(ctx))
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", process.Description)
+	assert.Error(t, ctx.Err(), "TryCancel should cancel the provided context")
+	assert.True(t, pm.IsRunning(GetPID(ctx)), "the process is running until it is finished")
+	finished()
+	assert.False(t, pm.IsRunning(GetPID(ctx)))
+
+	_, err = pm.TryCancel("no-such-pid")
+	assert.ErrorIs(t, err, util.ErrNotExist)
+
+	systemCtx, _, finished := pm.AddTypedContext(context.Background(), "system", SystemProcessType, true)
+	defer finished()
+	_, err = pm.TryCancel(GetPID(systemCtx))
+	assert.ErrorIs(t, err, util.ErrPermissionDenied)
+	assert.NoError(t, systemCtx.Err(), "system processes can't be cancelled")
+}
+
 func TestManager_Remove(t *testing.T) {
 	pm := Manager{processMap: make(map[IDType]*process), next: 1}
 
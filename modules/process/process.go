@@ -12,6 +12,7 @@ var (
 	SystemProcessType  = "system"
 	RequestProcessType = "request"
 	NormalProcessType  = "normal"
+	GitProcessType     = "git"
 	NoneProcessType    = "none"
 )
 
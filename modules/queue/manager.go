@@ -76,11 +76,17 @@ func (m *Manager) ManagedQueues() map[int64]ManagedWorkerPoolQueue {
 
 // FlushAll tries to make all managed queues process all items synchronously, until timeout or the queue is empty.
 // It is for testing purpose only. It's not designed to be used in a cluster.
+// The timeout is for flushing all queues in total, 0 means no timeout.
 func (m *Manager) FlushAll(ctx context.Context, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 	var finalErr error
 	qs := m.ManagedQueues()
 	for _, q := range qs {
-		if err := q.FlushWithContext(ctx, timeout); err != nil {
+		if err := q.FlushWithContext(ctx, 0); err != nil {
 			finalErr = err // TODO: in Go 1.20: errors.Join
 		}
 	}
@@ -182,6 +182,11 @@ func CreateRepository(doer, u *user_model.User, opts CreateRepoOptions) (*repo_m
 			Limit: u.MaxRepoCreation,
 		}
 	}
+	if !doer.IsAdmin {
+		if err := repo_model.CheckRepoSizeQuota(db.DefaultContext, u, 0); err != nil {
+			return nil, err
+		}
+	}
 
 	if len(opts.DefaultBranch) == 0 {
 		opts.DefaultBranch = setting.Repository.DefaultBranch
@@ -293,8 +298,8 @@ func CreateRepository(doer, u *user_model.User, opts CreateRepoOptions) (*repo_m
 
 const notRegularFileMode = os.ModeSymlink | os.ModeNamedPipe | os.ModeSocket | os.ModeDevice | os.ModeCharDevice | os.ModeIrregular
 
-// getDirectorySize returns the disk consumption for a given path
-func getDirectorySize(path string) (int64, error) {
+// GetDirectorySize returns the disk consumption for a given path
+func GetDirectorySize(path string) (int64, error) {
 	var size int64
 	err := filepath.WalkDir(path, func(_ string, info os.DirEntry, err error) error {
 		if err != nil {
@@ -318,9 +323,9 @@ func getDirectorySize(path string) (int64, error) {
 	return size, err
 }
 
-// UpdateRepoSize updates the repository size, calculating it using getDirectorySize
+// UpdateRepoSize updates the repository size, calculating it using GetDirectorySize
 func UpdateRepoSize(ctx context.Context, repo *repo_model.Repository) error {
-	size, err := getDirectorySize(repo.RepoPath())
+	size, err := GetDirectorySize(repo.RepoPath())
 	if err != nil {
 		return fmt.Errorf("updateSize: %w", err)
 	}
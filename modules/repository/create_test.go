@@ -174,7 +174,7 @@ func TestGetDirectorySize(t *testing.T) {
 	repo, err := repo_model.GetRepositoryByID(db.DefaultContext, 1)
 	assert.NoError(t, err)
 
-	size, err := getDirectorySize(repo.RepoPath())
+	size, err := GetDirectorySize(repo.RepoPath())
 	assert.NoError(t, err)
 	assert.EqualValues(t, size, repo.Size)
 }
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/setting"
@@ -107,6 +108,18 @@ done
 	return hookNames, hookTpls, giteaHookTpls
 }
 
+// HookScripts returns the content of the hook scripts "hooks/<name>" and "hooks/<name>.d/gitea" which CreateDelegateHooks writes,
+// the latter is empty if the hook calls Gitea directly (eg: proc-receive)
+func HookScripts(name string) (hookTpl, giteaHookTpl string, err error) {
+	hookNames, hookTpls, giteaHookTpls := getHookTemplates()
+	for i, hookName := range hookNames {
+		if hookName == name {
+			return hookTpls[i], giteaHookTpls[i], nil
+		}
+	}
+	return "", "", fmt.Errorf("unknown hook %q, it should be one of: %s", name, strings.Join(hookNames, ", "))
+}
+
 // CreateDelegateHooks creates all the hooks scripts for the repo
 func CreateDelegateHooks(repoPath string) error {
 	return createDelegateHooks(repoPath)
@@ -0,0 +1,34 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHookScripts(t *testing.T) {
+	repoPath := t.TempDir()
+	assert.NoError(t, CreateDelegateHooks(repoPath))
+
+	hookNames, _, _ := getHookTemplates()
+	for _, name := range hookNames {
+		hookTpl, giteaHookTpl, err := HookScripts(name)
+		assert.NoError(t, err)
+
+		// the scripts must be the same as the ones written into the repositories
+		content, err := os.ReadFile(filepath.Join(repoPath, "hooks", name))
+		assert.NoError(t, err)
+		assert.Equal(t, string(content), hookTpl, name)
+		content, err = os.ReadFile(filepath.Join(repoPath, "hooks", name+".d", "gitea"))
+		assert.NoError(t, err)
+		assert.Equal(t, string(content), giteaHookTpl, name)
+	}
+
+	_, _, err := HookScripts("no-such-hook")
+	assert.ErrorContains(t, err, `unknown hook "no-such-hook", it should be one of: pre-receive, update, post-receive`)
+}
@@ -281,60 +281,124 @@ func CleanUpMigrateInfo(ctx context.Context, repo *repo_model.Repository) (*repo
 
 // SyncReleasesWithTags synchronizes release table with repository tags
 func SyncReleasesWithTags(repo *repo_model.Repository, gitRepo *git.Repository) error {
-	log.Debug("SyncReleasesWithTags: in Repo[%d:%s/%s]", repo.ID, repo.OwnerName, repo.Name)
-
 	// optimized procedure for pull-mirrors which saves a lot of time (in
 	// particular for repos with many tags).
 	if repo.IsMirror {
+		log.Debug("SyncReleasesWithTags: in Repo[%d:%s/%s]", repo.ID, repo.OwnerName, repo.Name)
 		return pullMirrorReleaseSync(repo, gitRepo)
 	}
+	_, err := SyncReleasesWithTagsResult(repo, gitRepo, false)
+	return err
+}
+
+// ReleasesSyncResult represents the changes of the releases made by SyncReleasesWithTagsResult, by tag name
+type ReleasesSyncResult struct {
+	Added   []string // the tags without a release, a release of the tag is created for each
+	Updated []string // the releases whose tag points to another commit, or the drafts whose tag exists, they are bound to the tag
+	Deleted []string // the releases of the tags only whose tag is gone, they are deleted
+	Drafted []string // the releases whose tag is gone, they are marked as draft
+}
+
+// IsEmpty returns whether nothing is changed
+func (r *ReleasesSyncResult) IsEmpty() bool {
+	return len(r.Added) == 0 && len(r.Updated) == 0 && len(r.Deleted) == 0 && len(r.Drafted) == 0
+}
 
+// SyncReleasesWithTagsResult synchronizes release table with repository tags like SyncReleasesWithTags and returns the changes,
+// nothing is changed in the dry-run mode but the result is the same
+func SyncReleasesWithTagsResult(repo *repo_model.Repository, gitRepo *git.Repository, dryRun bool) (*ReleasesSyncResult, error) {
+	log.Debug("SyncReleasesWithTags: in Repo[%d:%s/%s]", repo.ID, repo.OwnerName, repo.Name)
+
+	result := &ReleasesSyncResult{}
 	existingRelTags := make(container.Set[string])
+	changedRelTags := make(container.Set[string]) // the releases to bind to their tags again, the tag moved or the release is a draft
 	opts := repo_model.FindReleasesOptions{
 		IncludeDrafts: true,
 		IncludeTags:   true,
 		ListOptions:   db.ListOptions{PageSize: 50},
 	}
+	// only collect the changes while paginating, the releases deleted by the changes would shift the pages
+	var goneRels []*repo_model.Release
 	for page := 1; ; page++ {
 		opts.Page = page
 		rels, err := repo_model.GetReleasesByRepoID(gitRepo.Ctx, repo.ID, opts)
 		if err != nil {
-			return fmt.Errorf("unable to GetReleasesByRepoID in Repo[%d:%s/%s]: %w", repo.ID, repo.OwnerName, repo.Name, err)
+			return nil, fmt.Errorf("unable to GetReleasesByRepoID in Repo[%d:%s/%s]: %w", repo.ID, repo.OwnerName, repo.Name, err)
 		}
 		if len(rels) == 0 {
 			break
 		}
 		for _, rel := range rels {
 			if rel.IsDraft {
+				changedRelTags.Add(strings.ToLower(rel.TagName))
 				continue
 			}
 			commitID, err := gitRepo.GetTagCommitID(rel.TagName)
 			if err != nil && !git.IsErrNotExist(err) {
-				return fmt.Errorf("unable to GetTagCommitID for %q in Repo[%d:%s/%s]: %w", rel.TagName, repo.ID, repo.OwnerName, repo.Name, err)
+				return nil, fmt.Errorf("unable to GetTagCommitID for %q in Repo[%d:%s/%s]: %w", rel.TagName, repo.ID, repo.OwnerName, repo.Name, err)
 			}
-			if git.IsErrNotExist(err) || commitID != rel.Sha1 {
-				if err := repo_model.PushUpdateDeleteTag(repo, rel.TagName); err != nil {
-					return fmt.Errorf("unable to PushUpdateDeleteTag: %q in Repo[%d:%s/%s]: %w", rel.TagName, repo.ID, repo.OwnerName, repo.Name, err)
-				}
+			if git.IsErrNotExist(err) {
+				goneRels = append(goneRels, rel)
+			} else if commitID != rel.Sha1 {
+				goneRels = append(goneRels, rel)
+				changedRelTags.Add(strings.ToLower(rel.TagName))
 			} else {
 				existingRelTags.Add(strings.ToLower(rel.TagName))
 			}
 		}
 	}
 
+	type newTag struct{ name, sha1, refname string }
+	var newTags []newTag
 	_, err := gitRepo.WalkReferences(git.ObjectTag, 0, 0, func(sha1, refname string) error {
 		tagName := strings.TrimPrefix(refname, git.TagPrefix)
 		if existingRelTags.Contains(strings.ToLower(tagName)) {
 			return nil
 		}
-
-		if err := PushUpdateAddTag(db.DefaultContext, repo, gitRepo, tagName, sha1, refname); err != nil {
-			return fmt.Errorf("unable to PushUpdateAddTag: %q to Repo[%d:%s/%s]: %w", tagName, repo.ID, repo.OwnerName, repo.Name, err)
+		newTags = append(newTags, newTag{name: tagName, sha1: sha1, refname: refname})
+		if changedRelTags.Contains(strings.ToLower(tagName)) {
+			result.Updated = append(result.Updated, tagName)
+		} else {
+			result.Added = append(result.Added, tagName)
 		}
-
 		return nil
 	})
-	return err
+	if err != nil {
+		return nil, err
+	}
+	updatedTags := make(container.Set[string])
+	for _, tagName := range result.Updated {
+		updatedTags.Add(strings.ToLower(tagName))
+	}
+	for _, rel := range goneRels {
+		if updatedTags.Contains(strings.ToLower(rel.TagName)) {
+			continue
+		} else if rel.IsTag {
+			result.Deleted = append(result.Deleted, rel.TagName)
+		} else {
+			result.Drafted = append(result.Drafted, rel.TagName)
+		}
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	// the releases of pull-mirrors are rebuilt from the tags, the result is the same
+	if repo.IsMirror {
+		return result, pullMirrorReleaseSync(repo, gitRepo)
+	}
+
+	for _, rel := range goneRels {
+		if err := repo_model.PushUpdateDeleteTag(repo, rel.TagName); err != nil {
+			return nil, fmt.Errorf("unable to PushUpdateDeleteTag: %q in Repo[%d:%s/%s]: %w", rel.TagName, repo.ID, repo.OwnerName, repo.Name, err)
+		}
+	}
+	for _, tag := range newTags {
+		if err := PushUpdateAddTag(db.DefaultContext, repo, gitRepo, tag.name, tag.sha1, tag.refname); err != nil {
+			return nil, fmt.Errorf("unable to PushUpdateAddTag: %q to Repo[%d:%s/%s]: %w", tag.name, repo.ID, repo.OwnerName, repo.Name, err)
+		}
+	}
+	return result, nil
 }
 
 // PushUpdateAddTag must be called for any push actions to add tag
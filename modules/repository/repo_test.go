@@ -0,0 +1,43 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repository
+
+import (
+	"testing"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncReleasesWithTagsResult(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	gitRepo, err := git.OpenRepository(git.DefaultContext, repo.RepoPath())
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+
+	// only the tag "v1.1" exists, the tag of "delete-tag" and of the release "v1.0" are gone
+	expected := &ReleasesSyncResult{
+		Deleted: []string{"delete-tag"},
+		Drafted: []string{"v1.0"},
+	}
+	result, err := SyncReleasesWithTagsResult(repo, gitRepo, true)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Release{ID: 3})
+
+	result, err = SyncReleasesWithTagsResult(repo, gitRepo, false)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	unittest.AssertNotExistsBean(t, &repo_model.Release{ID: 3})
+	assert.True(t, unittest.AssertExistsAndLoadBean(t, &repo_model.Release{ID: 5}).IsDraft)
+
+	result, err = SyncReleasesWithTagsResult(repo, gitRepo, false)
+	assert.NoError(t, err)
+	assert.True(t, result.IsEmpty())
+}
@@ -13,13 +13,17 @@ import (
 // Actions settings
 var (
 	Actions = struct {
-		LogStorage        *Storage // how the created logs should be stored
-		ArtifactStorage   *Storage // how the created artifacts should be stored
-		Enabled           bool
-		DefaultActionsURL defaultActionsURL `ini:"DEFAULT_ACTIONS_URL"`
+		LogStorage            *Storage // how the created logs should be stored
+		ArtifactStorage       *Storage // how the created artifacts should be stored
+		Enabled               bool
+		DefaultActionsURL     defaultActionsURL `ini:"DEFAULT_ACTIONS_URL"`
+		ArtifactRetentionDays int64             `ini:"ARTIFACT_RETENTION_DAYS"` // 0 keeps the artifacts forever
+		LogRetentionDays      int64             `ini:"LOG_RETENTION_DAYS"`      // 0 keeps the logs forever
 	}{
-		Enabled:           false,
-		DefaultActionsURL: defaultActionsURLGitHub,
+		Enabled:               false,
+		DefaultActionsURL:     defaultActionsURLGitHub,
+		ArtifactRetentionDays: 90,
+		LogRetentionDays:      365,
 	}
 )
 
@@ -0,0 +1,123 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"gopkg.in/ini.v1" //nolint:depguard
+)
+
+// ErrConfigKeyHasValue is returned by UpdateConfigFileKey if the key already has a value and it is not allowed to overwrite it
+var ErrConfigKeyHasValue = errors.New("config key already has a value")
+
+// UpdateConfigFileKey sets the value of the key in the section of the config file.
+// Unlike ConfigProvider.Save, it only edits the lines of the key (or adds the key/section),
+// all other lines including comments are kept as-is. The file is replaced atomically.
+// If the key already has a non-empty value, ErrConfigKeyHasValue is returned unless "overwrite" is true.
+func UpdateConfigFileKey(file, section, key, value string, overwrite bool) error {
+	if section == ini.DefaultSection {
+		section = ""
+	}
+	content, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	fileMode := os.FileMode(0o600)
+	if fi, err := os.Stat(file); err == nil {
+		if fi.IsDir() {
+			return fmt.Errorf("%q is a directory, can't update a key in it", file)
+		}
+		fileMode = fi.Mode().Perm()
+	}
+
+	newline := "\n"
+	if strings.Contains(string(content), "\r\n") {
+		newline = "\r\n"
+	}
+	var lines []string
+	if len(content) > 0 {
+		lines = strings.Split(strings.TrimSuffix(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n"), "\n")
+	}
+
+	curSection, found := "", false
+	sectionEnd := -1 // the line index to insert the key if the section exists but the key doesn't
+	if section == "" {
+		sectionEnd = 0
+	}
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") && strings.Contains(trimmed, "]") {
+			curSection = strings.TrimSpace(trimmed[1:strings.Index(trimmed, "]")])
+			if curSection == ini.DefaultSection {
+				curSection = ""
+			}
+			if curSection == section {
+				sectionEnd = i + 1
+			}
+			continue
+		}
+		if curSection != section || trimmed == "" || trimmed[0] == ';' || trimmed[0] == '#' {
+			continue
+		}
+		sectionEnd = i + 1
+		delimPos := strings.IndexAny(line, "=:")
+		if delimPos == -1 || strings.TrimSpace(line[:delimPos]) != key {
+			continue
+		}
+		rest := line[delimPos+1:]
+		if strings.TrimSpace(rest) != "" && !overwrite {
+			return ErrConfigKeyHasValue
+		}
+		spaces := rest[:len(rest)-len(strings.TrimLeft(rest, " \t"))]
+		if spaces == "" && delimPos > 0 && line[delimPos-1] == ' ' {
+			spaces = " "
+		}
+		lines[i] = line[:delimPos+1] + spaces + value
+		found = true
+	}
+
+	if !found {
+		keyLine := key + " = " + value
+		if sectionEnd != -1 {
+			lines = append(lines[:sectionEnd], append([]string{keyLine}, lines[sectionEnd:]...)...)
+		} else {
+			if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+				lines = append(lines, "")
+			}
+			lines = append(lines, "["+section+"]", keyLine)
+		}
+	}
+
+	if err = os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tmpFile.Close()
+		_ = util.Remove(tmpFile.Name())
+	}()
+	if _, err = tmpFile.WriteString(strings.Join(lines, newline) + newline); err != nil {
+		return err
+	}
+	if err = tmpFile.Chmod(fileMode); err != nil {
+		return err
+	}
+	if err = tmpFile.Sync(); err != nil {
+		return err
+	}
+	if err = tmpFile.Close(); err != nil {
+		return err
+	}
+	return util.Rename(tmpFile.Name(), file)
+}
@@ -0,0 +1,57 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateConfigFileKey(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.ini")
+	_ = os.WriteFile(file, []byte(`; comment
+APP_NAME = Test
+
+[security]
+INSTALL_LOCK=true
+SECRET_KEY =
+; trailing comment
+
+[server]
+HTTP_PORT = 3000
+`), 0o640)
+
+	assert.NoError(t, UpdateConfigFileKey(file, "security", "SECRET_KEY", "s1", false))
+	assert.ErrorIs(t, UpdateConfigFileKey(file, "security", "SECRET_KEY", "s2", false), ErrConfigKeyHasValue)
+	assert.NoError(t, UpdateConfigFileKey(file, "security", "INTERNAL_TOKEN", "t1", false))
+	assert.NoError(t, UpdateConfigFileKey(file, "security", "INSTALL_LOCK", "false", true))
+	assert.NoError(t, UpdateConfigFileKey(file, "oauth2", "JWT_SECRET", "j1", false))
+	assert.NoError(t, UpdateConfigFileKey(file, "", "RUN_MODE", "prod", false))
+
+	bs, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, `; comment
+APP_NAME = Test
+RUN_MODE = prod
+
+[security]
+INSTALL_LOCK=false
+SECRET_KEY = s1
+INTERNAL_TOKEN = t1
+; trailing comment
+
+[server]
+HTTP_PORT = 3000
+
+[oauth2]
+JWT_SECRET = j1
+`, string(bs))
+
+	fi, err := os.Stat(file)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0o640, fi.Mode().Perm())
+}
@@ -15,6 +15,10 @@ import (
 const (
 	EnvConfigKeyPrefixGitea = "GITEA__"
 	EnvConfigKeySuffixFile  = "__FILE"
+
+	// EnvConfigEnabled is the environment variable to opt in to applying the "GITEA__SECTION__KEY" variables
+	// to the loaded config directly, without running environment-to-ini first
+	EnvConfigEnabled = "GITEA_ENV_CONFIG"
 )
 
 const escapeRegexpString = "_0[xX](([0-9a-fA-F][0-9a-fA-F])+)_"
@@ -109,6 +113,11 @@ func decodeEnvironmentKey(prefixGitea, suffixFile, envKey string) (ok bool, sect
 }
 
 func EnvironmentToConfig(cfg ConfigProvider, envs []string) (changed bool) {
+	return EnvironmentToConfigWithPrefix(cfg, EnvConfigKeyPrefixGitea, envs)
+}
+
+// EnvironmentToConfigWithPrefix is like EnvironmentToConfig, but it uses the environment variables of the form "<prefix>SECTION__KEY"
+func EnvironmentToConfigWithPrefix(cfg ConfigProvider, prefix string, envs []string) (changed bool) {
 	for _, kv := range envs {
 		idx := strings.IndexByte(kv, '=')
 		if idx < 0 {
@@ -118,7 +127,7 @@ func EnvironmentToConfig(cfg ConfigProvider, envs []string) (changed bool) {
 		// parse the environment variable to config section name and key name
 		envKey := kv[:idx]
 		envValue := kv[idx+1:]
-		ok, sectionName, keyName, useFileValue := decodeEnvironmentKey(EnvConfigKeyPrefixGitea, EnvConfigKeySuffixFile, envKey)
+		ok, sectionName, keyName, useFileValue := decodeEnvironmentKey(prefix, EnvConfigKeySuffixFile, envKey)
 		if !ok {
 			continue
 		}
@@ -156,6 +165,8 @@ func EnvironmentToConfig(cfg ConfigProvider, envs []string) (changed bool) {
 			changed = true
 		}
 		key.SetValue(keyValue)
+		// the value may be a secret, so it is not logged
+		log.Debug("Config key %s in section [%s] is set by the environment variable %s", keyName, sectionName, envKey)
 	}
 	return changed
 }
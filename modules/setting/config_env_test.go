@@ -5,6 +5,7 @@ package setting
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -100,3 +101,22 @@ key = old
 	assert.True(t, changed)
 	assert.Equal(t, "value-from-file", cfg.Section("sec").Key("key").String())
 }
+
+func TestEnvironmentToConfigWithPrefix(t *testing.T) {
+	cfg, err := NewConfigProviderFromFile("")
+	assert.NoError(t, err)
+
+	changed := EnvironmentToConfigWithPrefix(cfg, "MYGITEA__", []string{
+		"GITEA__sec__key=ignored",
+		"MYGITEA__sec__key=value",
+		"MYGITEA__LOG_0x2E_CONSOLE__COLORIZE=false",
+	})
+	assert.True(t, changed)
+	assert.Equal(t, "value", cfg.Section("sec").Key("key").String())
+	assert.Equal(t, "false", cfg.Section("log.console").Key("COLORIZE").String())
+
+	var buf strings.Builder
+	_, err = cfg.WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "[sec]\nkey = value\n\n[log.console]\nCOLORIZE = false\n", buf.String())
+}
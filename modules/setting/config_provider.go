@@ -6,6 +6,7 @@ package setting
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -52,6 +53,7 @@ type ConfigProvider interface {
 	GetSection(name string) (ConfigSection, error)
 	Save() error
 	SaveTo(filename string) error
+	WriteTo(w io.Writer) (int64, error)
 
 	DisableSaving()
 	PrepareSaving() (ConfigProvider, error)
@@ -62,18 +64,29 @@ type iniConfigProvider struct {
 	file string
 	ini  *ini.File
 
+	recorder *configAccessRecorder // only used when validating the config, it records the accessed keys and the invalid values
+
 	disableSaving   bool // disable the "Save" method because the config options could be polluted
 	loadedFromEmpty bool // whether the file has not existed previously
 }
 
 type iniConfigSection struct {
-	sec *ini.Section
+	sec      *ini.Section
+	recorder *configAccessRecorder
+}
+
+// iniConfigKey checks whether the value can be parsed as the expected type when the config is being validated
+type iniConfigKey struct {
+	*ini.Key
+	sec      string
+	recorder *configAccessRecorder
 }
 
 var (
 	_ ConfigProvider = (*iniConfigProvider)(nil)
 	_ ConfigSection  = (*iniConfigSection)(nil)
 	_ ConfigKey      = (*ini.Key)(nil)
+	_ ConfigKey      = (*iniConfigKey)(nil)
 )
 
 // ConfigSectionKey only searches the keys in the given section, but it is O(n).
@@ -84,7 +97,13 @@ func ConfigSectionKey(sec ConfigSection, key string) ConfigKey {
 	if sec == nil {
 		return nil
 	}
-	for _, k := range sec.Keys() {
+	keys := sec.Keys
+	if s, ok := sec.(*iniConfigSection); ok {
+		// only the given key is accessed, the other keys in the section should still be checked
+		s.recorder.recordKey(s.sec.Name(), key)
+		keys = s.rawKeys
+	}
+	for _, k := range keys() {
 		if k.Name() == key {
 			return k
 		}
@@ -145,22 +164,35 @@ func (s *iniConfigSection) Name() string {
 }
 
 func (s *iniConfigSection) MapTo(v any) error {
+	s.recorder.recordStruct(s.sec, v)
 	return s.sec.MapTo(v)
 }
 
 func (s *iniConfigSection) HasKey(key string) bool {
+	s.recorder.recordKey(s.sec.Name(), key)
 	return s.sec.HasKey(key)
 }
 
 func (s *iniConfigSection) NewKey(name, value string) (ConfigKey, error) {
+	s.recorder.recordKey(s.sec.Name(), name)
 	return s.sec.NewKey(name, value)
 }
 
 func (s *iniConfigSection) Key(key string) ConfigKey {
+	s.recorder.recordKey(s.sec.Name(), key)
+	if s.recorder != nil {
+		return &iniConfigKey{Key: s.sec.Key(key), sec: s.sec.Name(), recorder: s.recorder}
+	}
 	return s.sec.Key(key)
 }
 
+// Keys returns all keys in the section, the callers handle all of them, so the section is treated as fully accessed
 func (s *iniConfigSection) Keys() (keys []ConfigKey) {
+	s.recorder.recordSection(s.sec.Name())
+	return s.rawKeys()
+}
+
+func (s *iniConfigSection) rawKeys() (keys []ConfigKey) {
 	for _, k := range s.sec.Keys() {
 		keys = append(keys, k)
 	}
@@ -168,12 +200,48 @@ func (s *iniConfigSection) Keys() (keys []ConfigKey) {
 }
 
 func (s *iniConfigSection) ChildSections() (sections []ConfigSection) {
-	for _, s := range s.sec.ChildSections() {
-		sections = append(sections, &iniConfigSection{s})
+	for _, sec := range s.sec.ChildSections() {
+		sections = append(sections, &iniConfigSection{sec: sec, recorder: s.recorder})
 	}
 	return sections
 }
 
+func (k *iniConfigKey) MustBool(defaultVal ...bool) bool {
+	if k.Key.String() != "" {
+		if _, err := k.Key.Bool(); err != nil {
+			k.recorder.recordInvalidValue(k.sec, k.Name(), "bool", err)
+		}
+	}
+	return k.Key.MustBool(defaultVal...)
+}
+
+func (k *iniConfigKey) MustInt(defaultVal ...int) int {
+	if k.Key.String() != "" {
+		if _, err := k.Key.Int(); err != nil {
+			k.recorder.recordInvalidValue(k.sec, k.Name(), "int", err)
+		}
+	}
+	return k.Key.MustInt(defaultVal...)
+}
+
+func (k *iniConfigKey) MustInt64(defaultVal ...int64) int64 {
+	if k.Key.String() != "" {
+		if _, err := k.Key.Int64(); err != nil {
+			k.recorder.recordInvalidValue(k.sec, k.Name(), "int64", err)
+		}
+	}
+	return k.Key.MustInt64(defaultVal...)
+}
+
+func (k *iniConfigKey) MustDuration(defaultVal ...time.Duration) time.Duration {
+	if k.Key.String() != "" {
+		if _, err := k.Key.Duration(); err != nil {
+			k.recorder.recordInvalidValue(k.sec, k.Name(), "duration", err)
+		}
+	}
+	return k.Key.MustDuration(defaultVal...)
+}
+
 // NewConfigProviderFromData this function is mainly for testing purpose
 func NewConfigProviderFromData(configContent string) (ConfigProvider, error) {
 	cfg, err := ini.Load(strings.NewReader(configContent))
@@ -188,17 +256,28 @@ func NewConfigProviderFromData(configContent string) (ConfigProvider, error) {
 }
 
 // NewConfigProviderFromFile load configuration from file.
+// If the "file" is a directory, all the "*.ini" files in it are merged, and the result can't be saved.
 // NOTE: do not print any log except error.
 func NewConfigProviderFromFile(file string, extraConfigs ...string) (ConfigProvider, error) {
 	cfg := ini.Empty(ini.LoadOptions{KeyValueDelimiterOnWrite: " = "})
 	loadedFromEmpty := true
 
 	if file != "" {
+		isDir, err := util.IsDir(file)
+		if err != nil {
+			return nil, fmt.Errorf("unable to check if %q is a directory. Error: %v", file, err)
+		}
 		isFile, err := util.IsFile(file)
 		if err != nil {
 			return nil, fmt.Errorf("unable to check if %q is a file. Error: %v", file, err)
 		}
-		if isFile {
+		if isDir {
+			if err = appendConfigDir(cfg, file); err != nil {
+				return nil, err
+			}
+			loadedFromEmpty = false
+			file = "" // the merged config fragments can't be saved back into one file
+		} else if isFile {
 			if err = cfg.Append(file); err != nil {
 				return nil, fmt.Errorf("failed to load config file %q: %v", file, err)
 			}
@@ -222,13 +301,36 @@ func NewConfigProviderFromFile(file string, extraConfigs ...string) (ConfigProvi
 	}, nil
 }
 
+// appendConfigDir loads all "*.ini" files in the directory in lexical order, the later ones override the earlier ones
+func appendConfigDir(cfg *ini.File, dir string) error {
+	entries, err := os.ReadDir(dir) // the entries are sorted by filename
+	if err != nil {
+		return fmt.Errorf("unable to read config directory %q: %v", dir, err)
+	}
+	loaded := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".ini") {
+			continue
+		}
+		file := filepath.Join(dir, entry.Name())
+		if err = cfg.Append(file); err != nil {
+			return fmt.Errorf("failed to load config file %q: %v", file, err)
+		}
+		loaded++
+	}
+	if loaded == 0 {
+		return fmt.Errorf("no *.ini config file found in directory %q", dir)
+	}
+	return nil
+}
+
 func (p *iniConfigProvider) Section(section string) ConfigSection {
-	return &iniConfigSection{sec: p.ini.Section(section)}
+	return &iniConfigSection{sec: p.ini.Section(section), recorder: p.recorder}
 }
 
 func (p *iniConfigProvider) Sections() (sections []ConfigSection) {
 	for _, s := range p.ini.Sections() {
-		sections = append(sections, &iniConfigSection{s})
+		sections = append(sections, &iniConfigSection{sec: s, recorder: p.recorder})
 	}
 	return sections
 }
@@ -238,7 +340,7 @@ func (p *iniConfigProvider) NewSection(name string) (ConfigSection, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &iniConfigSection{sec: sec}, nil
+	return &iniConfigSection{sec: sec, recorder: p.recorder}, nil
 }
 
 func (p *iniConfigProvider) GetSection(name string) (ConfigSection, error) {
@@ -246,7 +348,7 @@ func (p *iniConfigProvider) GetSection(name string) (ConfigSection, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &iniConfigSection{sec: sec}, nil
+	return &iniConfigSection{sec: sec, recorder: p.recorder}, nil
 }
 
 var errDisableSaving = errors.New("this config can't be saved, developers should prepare a new config to save")
@@ -290,6 +392,11 @@ func (p *iniConfigProvider) SaveTo(filename string) error {
 	return p.ini.SaveTo(filename)
 }
 
+// WriteTo writes the config in the ini format
+func (p *iniConfigProvider) WriteTo(w io.Writer) (int64, error) {
+	return p.ini.WriteTo(w)
+}
+
 // DisableSaving disables the saving function, use PrepareSaving to get clear config options.
 func (p *iniConfigProvider) DisableSaving() {
 	p.disableSaving = true
@@ -324,6 +431,7 @@ func deprecatedSetting(rootCfg ConfigProvider, oldSection, oldKey, newSection, n
 		msg := fmt.Sprintf("Deprecated config option `[%s]` `%s` present. Use `[%s]` `%s` instead. This fallback will be/has been removed in %s", oldSection, oldKey, newSection, newKey, version)
 		log.Error("%v", msg)
 		DeprecatedWarnings = append(DeprecatedWarnings, msg)
+		recordConfigIssue(rootCfg, oldSection, oldKey, fmt.Sprintf("deprecated, use [%s] %s instead", newSection, newKey))
 	}
 }
 
@@ -331,6 +439,7 @@ func deprecatedSetting(rootCfg ConfigProvider, oldSection, oldKey, newSection, n
 func deprecatedSettingDB(rootCfg ConfigProvider, oldSection, oldKey string) {
 	if rootCfg.Section(oldSection).HasKey(oldKey) {
 		log.Error("Deprecated `[%s]` `%s` present which has been copied to database table sys_setting", oldSection, oldKey)
+		recordConfigIssue(rootCfg, oldSection, oldKey, "deprecated, it has been moved to database table sys_setting")
 	}
 }
 
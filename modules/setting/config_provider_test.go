@@ -103,6 +103,29 @@ func TestNewConfigProviderFromFile(t *testing.T) {
 	assert.Equal(t, "[foo]\nk1 = a\n\n[bar]\nk1 = b\n", string(bs))
 }
 
+func TestNewConfigProviderFromDir(t *testing.T) {
+	_, err := NewConfigProviderFromFile(t.TempDir())
+	assert.ErrorContains(t, err, "no *.ini config file found")
+
+	testDir := t.TempDir()
+	_ = os.WriteFile(testDir+"/10-base.ini", []byte("[foo]\nk1 = a\nk2 = b\n"), 0o644)
+	_ = os.WriteFile(testDir+"/20-override.ini", []byte("[foo]\nk2 = c\n[bar]\nk1 = d\n"), 0o644)
+	_ = os.WriteFile(testDir+"/30-ignored.ini.bak", []byte("[foo]\nk1 = x\n"), 0o644)
+	_ = os.Mkdir(testDir+"/40-dir.ini", 0o755)
+
+	cfg, err := NewConfigProviderFromFile(testDir)
+	assert.NoError(t, err)
+	assert.False(t, cfg.IsLoadedFromEmpty())
+	assert.Equal(t, "a", cfg.Section("foo").Key("k1").String())
+	assert.Equal(t, "c", cfg.Section("foo").Key("k2").String())
+	assert.Equal(t, "d", cfg.Section("bar").Key("k1").String())
+
+	// the merged fragments can't be saved
+	assert.Error(t, cfg.Save())
+	_, err = cfg.PrepareSaving()
+	assert.Error(t, err)
+}
+
 func TestNewConfigProviderForLocale(t *testing.T) {
 	// load locale from file
 	localeFile := t.TempDir() + "/locale.ini"
@@ -0,0 +1,222 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+
+	"gopkg.in/ini.v1" //nolint:depguard
+)
+
+// ConfigValidateIssuesExitCode is the exit code of "gitea config validate" when the config can be loaded but has issues,
+// it is 1 when the config can't be loaded at all
+const ConfigValidateIssuesExitCode = 2
+
+// ConfigValidateArgs returns the arguments to run "gitea config validate" in another process
+// for the work path, custom path and config (file or directory) of this one
+func ConfigValidateArgs() []string {
+	configFlag := "--config"
+	if isDir, _ := util.IsDir(CustomConf); isDir {
+		configFlag = "--config-dir"
+	}
+	return []string{"--work-path", AppWorkPath, "--custom-path", CustomPath, configFlag, CustomConf, "config", "validate"}
+}
+
+// ConfigIssue is a problem of a config option found by ValidateConfig
+type ConfigIssue struct {
+	Section string
+	Key     string
+	Message string
+}
+
+func (issue *ConfigIssue) String() string {
+	return fmt.Sprintf("[%s] %s: %s", issue.Section, issue.Key, issue.Message)
+}
+
+// configAccessRecorder records which config keys are read by the setting loaders,
+// then the keys which are never read can be reported as unknown keys.
+// All methods are no-op for a nil recorder, so the config provider only records when validating.
+type configAccessRecorder struct {
+	mu               sync.Mutex
+	accessedKeys     container.Set[string] // "section" + "\x00" + "key"
+	accessedSections container.Set[string] // the sections which have at least one key read
+	consumedSections container.Set[string] // the sections whose keys are all handled, eg: iterated by "Keys()"
+	issues           []*ConfigIssue
+	issueSet         container.Set[string]
+}
+
+func newConfigAccessRecorder() *configAccessRecorder {
+	return &configAccessRecorder{
+		accessedKeys:     make(container.Set[string]),
+		accessedSections: make(container.Set[string]),
+		consumedSections: make(container.Set[string]),
+		issueSet:         make(container.Set[string]),
+	}
+}
+
+func (r *configAccessRecorder) recordKey(sec, key string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.accessedSections.Add(sec)
+	r.accessedKeys.Add(sec + "\x00" + key)
+}
+
+func (r *configAccessRecorder) recordSection(sec string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.accessedSections.Add(sec)
+	r.consumedSections.Add(sec)
+}
+
+func (r *configAccessRecorder) addIssue(sec, key, msg string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	issue := &ConfigIssue{Section: sec, Key: key, Message: msg}
+	// the same key could be read by more than one loader, only report it once
+	if r.issueSet.Add(issue.String()) {
+		r.issues = append(r.issues, issue)
+	}
+}
+
+func (r *configAccessRecorder) recordInvalidValue(sec, key, typ string, err error) {
+	r.addIssue(sec, key, fmt.Sprintf("invalid %s value: %v", typ, err))
+}
+
+// recordStruct records the keys which will be mapped to the struct fields by "MapTo", and checks their value types
+func (r *configAccessRecorder) recordStruct(sec *ini.Section, v any) {
+	if r == nil {
+		return
+	}
+	val := reflect.Indirect(reflect.ValueOf(v))
+	if val.Kind() != reflect.Struct {
+		return
+	}
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("ini"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+			if field.Anonymous {
+				r.recordStruct(sec, val.Field(i).Addr().Interface())
+			}
+			continue
+		}
+		if name == "" {
+			name = ini.SnackCase(field.Name)
+		}
+		r.recordKey(sec.Name(), name)
+
+		key, err := sec.GetKey(name)
+		if err != nil || key.String() == "" {
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.Bool:
+			_, err = key.Bool()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if field.Type == reflect.TypeOf(time.Duration(0)) {
+				_, err = key.Duration()
+			} else {
+				_, err = key.Int64()
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			_, err = key.Uint64()
+		case reflect.Float32, reflect.Float64:
+			_, err = key.Float64()
+		}
+		if err != nil {
+			r.recordInvalidValue(sec.Name(), name, field.Type.String(), err)
+		}
+	}
+}
+
+func recordConfigIssue(rootCfg ConfigProvider, sec, key, msg string) {
+	if p, ok := rootCfg.(*iniConfigProvider); ok {
+		p.recorder.addIssue(p.ini.Section(sec).Name(), key, msg)
+	}
+}
+
+// ValidateConfig loads all the settings from the config provider like the startup does without initializing anything,
+// and reports the issues: unknown keys, deprecated keys and the values which can't be parsed as the expected types.
+// Only the sections read by the loaders are checked for unknown keys, because some sections (eg: "cron.*", "queue.*")
+// are only read on demand when the related module starts.
+// It changes the global settings, so it should only be used by a command which doesn't run the server.
+func ValidateConfig(cfg ConfigProvider) ([]*ConfigIssue, error) {
+	p, ok := cfg.(*iniConfigProvider)
+	if !ok {
+		return nil, errors.New("the config provider doesn't support validation")
+	}
+	p.recorder = newConfigAccessRecorder()
+	CfgProvider = cfg
+
+	if err := loadCommonSettingsFrom(cfg); err != nil {
+		return nil, err
+	}
+	validateLoggersFrom(cfg)
+	loadSettingsFrom(cfg)
+
+	r := p.recorder
+	// it is read by InitWorkPathAndCfgProvider before the settings are loaded
+	r.recordKey(p.ini.Section("").Name(), "WORK_PATH")
+
+	var unknownIssues []*ConfigIssue
+	for _, sec := range p.ini.Sections() {
+		if !r.accessedSections.Contains(sec.Name()) || r.consumedSections.Contains(sec.Name()) {
+			continue
+		}
+		for _, key := range sec.Keys() {
+			if !r.accessedKeys.Contains(sec.Name() + "\x00" + key.Name()) {
+				unknownIssues = append(unknownIssues, &ConfigIssue{Section: sec.Name(), Key: key.Name(), Message: "unknown key"})
+			}
+		}
+	}
+	return append(unknownIssues, r.issues...), nil
+}
+
+// validateLoggersFrom loads the loggers' config options like initManagedLoggers does, but it doesn't create any writer
+func validateLoggersFrom(rootCfg ConfigProvider) {
+	loadLogGlobalFrom(rootCfg)
+	prepareLoggerConfig(rootCfg)
+
+	sec := rootCfg.Section("log")
+	for _, loggerName := range []string{log.DEFAULT, "access", "router", "xorm"} {
+		modeVal := sec.Key("logger." + loggerName + ".MODE").String()
+		if modeVal == "," {
+			modeVal = Log.Mode
+		}
+		for _, modeName := range strings.Split(modeVal, ",") {
+			modeName = strings.TrimSpace(modeName)
+			if modeName == "" {
+				continue
+			}
+			if _, _, _, err := loadLogModeByName(rootCfg, loggerName, modeName); err != nil {
+				recordConfigIssue(rootCfg, "log."+modeName, "MODE", err.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateConfig(t *testing.T) {
+	defer func(old ConfigProvider) { CfgProvider = old }(CfgProvider)
+
+	cfg, err := NewConfigProviderFromData(`
+UNKNOWN_ROOT_KEY = 1
+WORK_PATH = /tmp/gitea
+[server]
+OFFLINE_MODE = maybe
+[repository]
+ROOT = /tmp/repos
+ENABLE_PUSH_CREATE_USER = yes
+NO_SUCH_KEY = a
+[mailer]
+ENABLED = false
+[highlight.mapping]
+.foo = go
+[cron.update_mirrors]
+SCHEDULE = @every 10m
+`)
+	assert.NoError(t, err)
+
+	issues, err := ValidateConfig(cfg)
+	assert.NoError(t, err)
+	var lines []string
+	for _, issue := range issues {
+		lines = append(lines, issue.String())
+	}
+	assert.Equal(t, []string{
+		"[DEFAULT] UNKNOWN_ROOT_KEY: unknown key",
+		"[repository] NO_SUCH_KEY: unknown key",
+		`[server] OFFLINE_MODE: invalid bool value: parsing "maybe": invalid syntax`,
+	}, lines)
+}
+
+func TestConfigValidateArgs(t *testing.T) {
+	defer func(workPath, customPath, customConf string) {
+		AppWorkPath, CustomPath, CustomConf = workPath, customPath, customConf
+	}(AppWorkPath, CustomPath, CustomConf)
+
+	dir := t.TempDir()
+	AppWorkPath = filepath.Join(dir, "work")
+	CustomPath = filepath.Join(dir, "custom")
+	CustomConf = filepath.Join(dir, "app.ini")
+	assert.Equal(t, []string{"--work-path", AppWorkPath, "--custom-path", CustomPath, "--config", CustomConf, "config", "validate"}, ConfigValidateArgs())
+
+	// a config directory is passed as such
+	CustomConf = dir
+	assert.Equal(t, []string{"--work-path", AppWorkPath, "--custom-path", CustomPath, "--config-dir", dir, "config", "validate"}, ConfigValidateArgs())
+}
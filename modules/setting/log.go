@@ -31,12 +31,31 @@ type LogGlobalConfig struct {
 
 var Log LogGlobalConfig
 
+// logLevelOverride is set by the command line (eg: "--log-level"), it takes precedence over all the levels in the config
+var logLevelOverride = log.UNDEFINED
+
+// SetLogLevelOverride makes the level override the configured levels of all the loggers, log.UNDEFINED removes the override
+func SetLogLevelOverride(level log.Level) {
+	logLevelOverride = level
+}
+
+// logColorOverride is set by the command line (eg: "--color"), it takes precedence over COLORIZE of the console loggers in the config
+var logColorOverride = util.OptionalBoolNone
+
+// SetLogColorOverride makes the console loggers colorize their output or not regardless of the config, OptionalBoolNone removes the override
+func SetLogColorOverride(colorize util.OptionalBool) {
+	logColorOverride = colorize
+}
+
 const accessLogTemplateDefault = `{{.Ctx.RemoteHost}} - {{.Identity}} {{.Start.Format "[02/Jan/2006:15:04:05 -0700]" }} "{{.Ctx.Req.Method}} {{.Ctx.Req.URL.RequestURI}} {{.Ctx.Req.Proto}}" {{.ResponseWriter.Status}} {{.ResponseWriter.Size}} "{{.Ctx.Req.Referer}}" "{{.Ctx.Req.UserAgent}}"`
 
 func loadLogGlobalFrom(rootCfg ConfigProvider) {
 	sec := rootCfg.Section("log")
 
 	Log.Level = log.LevelFromString(sec.Key("LEVEL").MustString(log.INFO.String()))
+	if logLevelOverride != log.UNDEFINED {
+		Log.Level = logLevelOverride
+	}
 	Log.StacktraceLogLevel = log.LevelFromString(sec.Key("STACKTRACE_LEVEL").MustString(log.NONE.String()))
 	Log.BufferLen = sec.Key("BUFFER_LEN").MustInt(10000)
 	Log.Mode = sec.Key("MODE").MustString("console")
@@ -130,6 +149,9 @@ func loadLogModeByName(rootCfg ConfigProvider, loggerName, modeName string) (wri
 	}
 
 	writerMode.Level = log.LevelFromString(ConfigInheritedKeyString(sec, "LEVEL", Log.Level.String()))
+	if logLevelOverride != log.UNDEFINED {
+		writerMode.Level = logLevelOverride
+	}
 	writerMode.StacktraceLevel = log.LevelFromString(ConfigInheritedKeyString(sec, "STACKTRACE_LEVEL", Log.StacktraceLogLevel.String()))
 	writerMode.Prefix = ConfigInheritedKeyString(sec, "PREFIX")
 	writerMode.Expression = ConfigInheritedKeyString(sec, "EXPRESSION")
@@ -144,6 +166,9 @@ func loadLogModeByName(rootCfg ConfigProvider, loggerName, modeName string) (wri
 		}
 		writerOption := log.WriterConsoleOption{Stderr: useStderr}
 		writerMode.Colorize = ConfigInheritedKey(sec, "COLORIZE").MustBool(defaultCanColor)
+		if !logColorOverride.IsNone() {
+			writerMode.Colorize = logColorOverride.IsTrue()
+		}
 		writerMode.WriterOption = writerOption
 	case "file":
 		fileName := LogPrepareFilenameForWriter(ConfigInheritedKey(sec, "FILE_NAME").String(), defaultFilaName)
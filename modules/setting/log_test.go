@@ -46,6 +46,7 @@ func TestLogConfigDefault(t *testing.T) {
 		"Colorize": false,
 		"Expression": "",
 		"Flags": "stdflags",
+		"IsPaused": false,
 		"Level": "info",
 		"Prefix": "",
 		"StacktraceLevel": "none",
@@ -85,6 +86,7 @@ logger.xorm.MODE =
 		"Colorize": false,
 		"Expression": "",
 		"Flags": "stdflags",
+		"IsPaused": false,
 		"Level": "info",
 		"Prefix": "",
 		"StacktraceLevel": "none",
@@ -123,6 +125,7 @@ MODE = console
 		"Colorize": false,
 		"Expression": "",
 		"Flags": "stdflags",
+		"IsPaused": false,
 		"Level": "info",
 		"Prefix": "",
 		"StacktraceLevel": "none",
@@ -170,6 +173,7 @@ ACCESS = file
 		"Colorize": false,
 		"Expression": "",
 		"Flags": "stdflags",
+		"IsPaused": false,
 		"Level": "info",
 		"Prefix": "",
 		"StacktraceLevel": "none",
@@ -193,6 +197,7 @@ ACCESS = file
 		"Colorize": false,
 		"Expression": "",
 		"Flags": "none",
+		"IsPaused": false,
 		"Level": "info",
 		"Prefix": "",
 		"StacktraceLevel": "none",
@@ -259,6 +264,7 @@ STDERR = true
 		"Colorize": false,
 		"Expression": "",
 		"Flags": "stdflags",
+		"IsPaused": false,
 		"Level": "warn",
 		"Prefix": "",
 		"StacktraceLevel": "none",
@@ -272,6 +278,7 @@ STDERR = true
 		"Colorize": false,
 		"Expression": "",
 		"Flags": "stdflags",
+		"IsPaused": false,
 		"Level": "error",
 		"Prefix": "",
 		"StacktraceLevel": "none",
@@ -289,6 +296,7 @@ STDERR = true
 		"Colorize": false,
 		"Expression": "",
 		"Flags": "none",
+		"IsPaused": false,
 		"Level": "warn",
 		"Prefix": "",
 		"StacktraceLevel": "none",
@@ -343,6 +351,7 @@ COMPRESSION_LEVEL = 4
 		"Colorize": false,
 		"Expression": "",
 		"Flags": "stdflags",
+		"IsPaused": false,
 		"Level": "info",
 		"Prefix": "",
 		"StacktraceLevel": "none",
@@ -362,6 +371,7 @@ COMPRESSION_LEVEL = 4
 		"Colorize": false,
 		"Expression": "filter",
 		"Flags": "medfile",
+		"IsPaused": false,
 		"Level": "error",
 		"Prefix": "[Prefix] ",
 		"StacktraceLevel": "fatal",
@@ -385,3 +395,23 @@ COMPRESSION_LEVEL = 4
 	expected = strings.ReplaceAll(expected, "$FILENAME-1", tempPath("file-xxx.log"))
 	require.JSONEq(t, expected, toJSON(dump))
 }
+
+func TestLogConfigLevelOverride(t *testing.T) {
+	SetLogLevelOverride(log.DEBUG)
+	defer SetLogLevelOverride(log.UNDEFINED)
+
+	manager, managerClose := initLoggersByConfig(t, `
+[log]
+LEVEL = error
+MODE = console, file
+[log.file]
+LEVEL = warn
+`)
+	defer managerClose()
+
+	dump := manager.GetLogger(log.DEFAULT).DumpWriters()
+	require.Len(t, dump, 2)
+	for name, writer := range dump {
+		assert.EqualValues(t, "debug", writer.(map[string]any)["Level"], name)
+	}
+}
@@ -50,6 +50,11 @@ type Mailer struct {
 // MailService the global mailer
 var MailService *Mailer
 
+// LoadMailerSetting loads the mailer settings, it is only needed when the full settings are not loaded (eg: for the command line)
+func LoadMailerSetting() {
+	loadMailerFrom(CfgProvider)
+}
+
 func loadMailsFrom(rootCfg ConfigProvider) {
 	loadMailerFrom(rootCfg)
 	loadRegisterMailFrom(rootCfg)
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
 )
 
 var (
@@ -75,16 +76,20 @@ type ArgWorkPathAndCustomConf struct {
 	WorkPath   string
 	CustomPath string
 	CustomConf string
+	ConfigDir  string   // a directory of "*.ini" config fragments, it is used as CustomConf, so it can't be used together with CustomConf
+	EnvConfig  []string // the "GITEA__SECTION__KEY=value" environment variables to apply to the config after it is loaded
 }
 
 type stringWithDefault struct {
-	Value string
-	IsSet bool
+	Value  string
+	IsSet  bool
+	Source string // where the value comes from, it is only used for logging
 }
 
-func (s *stringWithDefault) Set(v string) {
+func (s *stringWithDefault) Set(v, source string) {
 	s.Value = v
 	s.IsSet = true
+	s.Source = source
 }
 
 // InitWorkPathAndCommonConfig will set AppWorkPath, CustomPath and CustomConf, init default config provider by CustomConf and load common settings,
@@ -94,6 +99,8 @@ func InitWorkPathAndCommonConfig(getEnvFn func(name string) string, args ArgWork
 }
 
 // InitWorkPathAndCfgProvider will set AppWorkPath, CustomPath and CustomConf, init default config provider by CustomConf
+// The precedence (from high to low) is: WORK_PATH in the config file (only for the work path), command line arguments,
+// environment variables (GITEA_WORK_DIR and GITEA_CUSTOM), builtin defaults. The source of each value is logged at debug level.
 func InitWorkPathAndCfgProvider(getEnvFn func(name string) string, args ArgWorkPathAndCustomConf) {
 	tryAbsPath := func(paths ...string) string {
 		s := paths[len(paths)-1]
@@ -107,31 +114,35 @@ func InitWorkPathAndCfgProvider(getEnvFn func(name string) string, args ArgWorkP
 	}
 
 	var err error
-	tmpWorkPath := stringWithDefault{Value: appWorkPathBuiltin}
+	tmpWorkPath := stringWithDefault{Value: appWorkPathBuiltin, Source: "builtin default"}
 	if tmpWorkPath.Value == "" {
 		tmpWorkPath.Value = filepath.Dir(AppPath)
 	}
-	tmpCustomPath := stringWithDefault{Value: customPathBuiltin}
+	if tmpWorkPath.Value == filepath.Dir(AppPath) {
+		tmpWorkPath.Source = "directory of the binary"
+	}
+	tmpCustomPath := stringWithDefault{Value: customPathBuiltin, Source: "builtin default"}
 	if tmpCustomPath.Value == "" {
 		tmpCustomPath.Value = "custom"
 	}
-	tmpCustomConf := stringWithDefault{Value: customConfBuiltin}
+	tmpCustomConf := stringWithDefault{Value: customConfBuiltin, Source: "builtin default"}
 	if tmpCustomConf.Value == "" {
 		tmpCustomConf.Value = "conf/app.ini"
 	}
 
+	envWorkPath := getEnvFn("GITEA_WORK_DIR")
+	envCustomPath := getEnvFn("GITEA_CUSTOM")
+
 	readFromEnv := func() {
-		envWorkPath := getEnvFn("GITEA_WORK_DIR")
 		if envWorkPath != "" {
-			tmpWorkPath.Set(envWorkPath)
+			tmpWorkPath.Set(envWorkPath, "environment variable GITEA_WORK_DIR")
 			if !filepath.IsAbs(tmpWorkPath.Value) {
 				log.Fatal("GITEA_WORK_DIR (work path) must be absolute path")
 			}
 		}
 
-		envCustomPath := getEnvFn("GITEA_CUSTOM")
 		if envCustomPath != "" {
-			tmpCustomPath.Set(envCustomPath)
+			tmpCustomPath.Set(envCustomPath, "environment variable GITEA_CUSTOM")
 			if !filepath.IsAbs(tmpCustomPath.Value) {
 				log.Fatal("GITEA_CUSTOM (custom path) must be absolute path")
 			}
@@ -140,19 +151,25 @@ func InitWorkPathAndCfgProvider(getEnvFn func(name string) string, args ArgWorkP
 
 	readFromArgs := func() {
 		if args.WorkPath != "" {
-			tmpWorkPath.Set(args.WorkPath)
+			if envWorkPath != "" && filepath.Clean(envWorkPath) != filepath.Clean(args.WorkPath) {
+				log.Info("Work path %q from --work-path overrides %q from GITEA_WORK_DIR", args.WorkPath, envWorkPath)
+			}
+			tmpWorkPath.Set(args.WorkPath, "argument --work-path")
 			if !filepath.IsAbs(tmpWorkPath.Value) {
 				log.Fatal("--work-path must be absolute path")
 			}
 		}
 		if args.CustomPath != "" {
-			tmpCustomPath.Set(args.CustomPath) // if it is not abs, it will be based on work-path, it shouldn't happen
+			if envCustomPath != "" && filepath.Clean(envCustomPath) != filepath.Clean(args.CustomPath) {
+				log.Info("Custom path %q from --custom-path overrides %q from GITEA_CUSTOM", args.CustomPath, envCustomPath)
+			}
+			tmpCustomPath.Set(args.CustomPath, "argument --custom-path") // if it is not abs, it will be based on work-path, it shouldn't happen
 			if !filepath.IsAbs(tmpCustomPath.Value) {
 				log.Error("--custom-path must be absolute path")
 			}
 		}
 		if args.CustomConf != "" {
-			tmpCustomConf.Set(args.CustomConf)
+			tmpCustomConf.Set(args.CustomConf, "argument --config")
 			if !filepath.IsAbs(tmpCustomConf.Value) {
 				// the config path can be relative to the real current working path
 				if tmpCustomConf.Value, err = filepath.Abs(tmpCustomConf.Value); err != nil {
@@ -160,17 +177,33 @@ func InitWorkPathAndCfgProvider(getEnvFn func(name string) string, args ArgWorkP
 				}
 			}
 		}
+		if args.ConfigDir != "" {
+			if args.CustomConf != "" {
+				log.Fatal("--config and --config-dir can't be used together")
+			}
+			tmpCustomConf.Set(args.ConfigDir, "argument --config-dir")
+			if tmpCustomConf.Value, err = filepath.Abs(tmpCustomConf.Value); err != nil {
+				log.Fatal("Failed to get absolute path of config directory %q: %v", tmpCustomConf.Value, err)
+			}
+			if isDir, _ := util.IsDir(tmpCustomConf.Value); !isDir {
+				log.Fatal("--config-dir %q is not a directory", tmpCustomConf.Value)
+			}
+		}
 	}
 
 	readFromEnv()
 	readFromArgs()
 
 	if !tmpCustomConf.IsSet {
-		tmpCustomConf.Set(tryAbsPath(tmpWorkPath.Value, tmpCustomPath.Value, tmpCustomConf.Value))
+		tmpCustomConf.Set(tryAbsPath(tmpWorkPath.Value, tmpCustomPath.Value, tmpCustomConf.Value), tmpCustomConf.Source+" relative to the custom path")
 	}
 
 	// only read the config but do not load/init anything more, because the AppWorkPath and CustomPath are not ready
 	InitCfgProvider(tmpCustomConf.Value)
+	if len(args.EnvConfig) > 0 {
+		// apply them before the install lock check, it clears them from the environment
+		EnvironmentToConfig(CfgProvider, args.EnvConfig)
+	}
 	if HasInstallLock(CfgProvider) {
 		ClearEnvConfigKeys() // if the instance has been installed, do not pass the environment variables to sub-processes
 	}
@@ -187,10 +220,14 @@ func InitWorkPathAndCfgProvider(getEnvFn func(name string) string, args ArgWorkP
 				AppWorkPathMismatch = true
 			}
 		}
-		tmpWorkPath.Set(configWorkPath)
+		tmpWorkPath.Set(configWorkPath, "WORK_PATH in the config file")
 	}
 
-	tmpCustomPath.Set(tryAbsPath(tmpWorkPath.Value, tmpCustomPath.Value))
+	tmpCustomPath.Value = tryAbsPath(tmpWorkPath.Value, tmpCustomPath.Value)
+
+	log.Debug("Work path %q is from %s", tmpWorkPath.Value, tmpWorkPath.Source)
+	log.Debug("Custom path %q is from %s", tmpCustomPath.Value, tmpCustomPath.Source)
+	log.Debug("Config %q is from %s", tmpCustomConf.Value, tmpCustomConf.Source)
 
 	AppWorkPath = tmpWorkPath.Value
 	CustomPath = tmpCustomPath.Value
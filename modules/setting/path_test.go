@@ -6,8 +6,12 @@ package setting
 import (
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
+	"code.gitea.io/gitea/modules/log"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -115,6 +119,20 @@ func TestInitWorkPathAndCommonConfig(t *testing.T) {
 		assert.True(t, AppWorkPathMismatch)
 	})
 
+	t.Run("EnvConfig", func(t *testing.T) {
+		iniWorkPath := fp(tmpDir, "app-workpath.ini")
+		_ = os.WriteFile(iniWorkPath, []byte("WORK_PATH="+dirXxx), 0o644)
+
+		testInit(dirFoo, "", "")
+		InitWorkPathAndCommonConfig(envVars{}.Getenv, ArgWorkPathAndCustomConf{
+			CustomConf: iniWorkPath,
+			EnvConfig:  []string{"GITEA__SERVER__APP_DATA_PATH=" + fp(dirYyy, "data"), "OTHER=1"},
+		})
+		assert.Equal(t, dirXxx, AppWorkPath)
+		assert.Equal(t, fp(dirYyy, "data"), AppDataPath)
+		assert.Equal(t, fp(dirYyy, "data"), CfgProvider.Section("server").Key("APP_DATA_PATH").String())
+	})
+
 	t.Run("Builtin", func(t *testing.T) {
 		testInit(dirFoo, dirBar, dirXxx)
 		InitWorkPathAndCommonConfig(envVars{}.Getenv, ArgWorkPathAndCustomConf{})
@@ -149,3 +167,73 @@ func TestInitWorkPathAndCommonConfig(t *testing.T) {
 		assert.Equal(t, iniWorkPath, CustomConf)
 	})
 }
+
+// logBuffer collects the logged messages
+type logBuffer struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) Close() error {
+	return nil
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestInitWorkPathSources(t *testing.T) {
+	fp := filepath.Join
+	tmpDir := t.TempDir()
+	dirFoo := fp(tmpDir, "foo")
+	dirBar := fp(tmpDir, "bar")
+	dirXxx := fp(tmpDir, "xxx")
+
+	initWithLogs := func(env envVars, args ArgWorkPathAndCustomConf) string {
+		AppWorkPathMismatch = false
+		appWorkPathBuiltin, customPathBuiltin, customConfBuiltin = dirFoo, "", ""
+
+		buf := &logBuffer{}
+		w := log.NewEventWriterBase("path-sources", "dummy", log.WriterMode{Level: log.DEBUG, Flags: log.FlagsFromBits(0)})
+		w.OutputWriteCloser = buf
+		logger := log.GetManager().GetLogger(log.DEFAULT)
+		logger.AddWriters(w)
+		InitWorkPathAndCfgProvider(env.Getenv, args)
+		// the writer is flushed when it is removed
+		assert.NoError(t, logger.RemoveWriter("path-sources"))
+		return buf.String()
+	}
+
+	logs := initWithLogs(envVars{}, ArgWorkPathAndCustomConf{})
+	assert.Contains(t, logs, `Work path "`+dirFoo+`" is from builtin default`)
+	assert.Contains(t, logs, `Custom path "`+fp(dirFoo, "custom")+`" is from builtin default`)
+	assert.Contains(t, logs, `Config "`+fp(dirFoo, "custom/conf/app.ini")+`" is from builtin default relative to the custom path`)
+
+	logs = initWithLogs(envVars{"GITEA_WORK_DIR": dirBar, "GITEA_CUSTOM": fp(dirBar, "custom1")}, ArgWorkPathAndCustomConf{})
+	assert.Contains(t, logs, `Work path "`+dirBar+`" is from environment variable GITEA_WORK_DIR`)
+	assert.Contains(t, logs, `Custom path "`+fp(dirBar, "custom1")+`" is from environment variable GITEA_CUSTOM`)
+	assert.Contains(t, logs, `Config "`+fp(dirBar, "custom1/conf/app.ini")+`" is from builtin default relative to the custom path`)
+
+	// the arguments override the environment variables
+	logs = initWithLogs(envVars{"GITEA_WORK_DIR": dirBar}, ArgWorkPathAndCustomConf{WorkPath: dirXxx, CustomConf: fp(dirBar, "app.ini")})
+	assert.Contains(t, logs, `Work path "`+dirXxx+`" from --work-path overrides "`+dirBar+`" from GITEA_WORK_DIR`)
+	assert.Contains(t, logs, `Work path "`+dirXxx+`" is from argument --work-path`)
+	assert.Contains(t, logs, `Config "`+fp(dirBar, "app.ini")+`" is from argument --config`)
+
+	// the same path from the argument and the environment variable isn't an override
+	logs = initWithLogs(envVars{"GITEA_WORK_DIR": dirBar}, ArgWorkPathAndCustomConf{WorkPath: dirBar + "/"})
+	assert.NotContains(t, logs, "overrides")
+
+	iniWorkPath := fp(tmpDir, "app-workpath.ini")
+	assert.NoError(t, os.WriteFile(iniWorkPath, []byte("WORK_PATH="+dirXxx), 0o644))
+	logs = initWithLogs(envVars{}, ArgWorkPathAndCustomConf{CustomConf: iniWorkPath})
+	assert.Contains(t, logs, `Work path "`+dirXxx+`" is from WORK_PATH in the config file`)
+}
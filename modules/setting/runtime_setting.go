@@ -0,0 +1,63 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// runtimeSettings are the settings which can be changed in the running process by "gitea manager set-setting",
+// only the settings which are read every time they are used (not copied when loading the settings) are safe to add.
+// The keys are "section.KEY" like the config file.
+var runtimeSettings = map[string]func(value string) error{
+	"admin.DISABLE_REGULAR_ORG_CREATION": runtimeBoolSetter(&Admin.DisableRegularOrgCreation),
+	"repository.DISABLE_MIGRATIONS":      runtimeBoolSetter(&Repository.DisableMigrations),
+	"repository.MAX_CREATION_LIMIT":      runtimeIntSetter(&Repository.MaxCreationLimit),
+}
+
+func runtimeBoolSetter(p *bool) func(string) error {
+	return func(value string) error {
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid bool value %q", value)
+		}
+		*p = v
+		return nil
+	}
+}
+
+func runtimeIntSetter(p *int) func(string) error {
+	return func(value string) error {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid int value %q", value)
+		}
+		*p = v
+		return nil
+	}
+}
+
+// RuntimeSettingNames returns the sorted names of the settings which can be changed by SetRuntimeSetting
+func RuntimeSettingNames() []string {
+	names := make([]string, 0, len(runtimeSettings))
+	for name := range runtimeSettings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetRuntimeSetting changes the setting "section.KEY" of the running process, the config file isn't changed.
+// Only the settings of RuntimeSettingNames are accepted.
+func SetRuntimeSetting(name, value string) error {
+	for settingName, set := range runtimeSettings {
+		if strings.EqualFold(settingName, name) {
+			return set(strings.TrimSpace(value))
+		}
+	}
+	return fmt.Errorf("setting %q can't be changed at runtime, the allowed settings are: %s", name, strings.Join(RuntimeSettingNames(), ", "))
+}
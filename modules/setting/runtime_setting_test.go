@@ -0,0 +1,25 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetRuntimeSetting(t *testing.T) {
+	defer func(old int) { Repository.MaxCreationLimit = old }(Repository.MaxCreationLimit)
+	defer func(old bool) { Repository.DisableMigrations = old }(Repository.DisableMigrations)
+
+	assert.NoError(t, SetRuntimeSetting("repository.MAX_CREATION_LIMIT", "0"))
+	assert.Equal(t, 0, Repository.MaxCreationLimit)
+	assert.NoError(t, SetRuntimeSetting("Repository.disable_migrations", "true"))
+	assert.True(t, Repository.DisableMigrations)
+
+	assert.ErrorContains(t, SetRuntimeSetting("repository.MAX_CREATION_LIMIT", "many"), "invalid int value")
+	assert.Equal(t, 0, Repository.MaxCreationLimit)
+	assert.ErrorContains(t, SetRuntimeSetting("repository.DISABLE_MIGRATIONS", "maybe"), "invalid bool value")
+	assert.ErrorContains(t, SetRuntimeSetting("server.ROOT_URL", "http://localhost/"), "can't be changed at runtime")
+}
@@ -4,6 +4,7 @@
 package setting
 
 import (
+	"fmt"
 	"net/url"
 	"os"
 	"strings"
@@ -18,6 +19,7 @@ var (
 	InstallLock                        bool
 	SecretKey                          string
 	InternalToken                      string // internal access token
+	ManagerToken                       string // access token of the manager API on MANAGER_LISTEN_ADDR
 	LogInRememberDays                  int
 	CookieUserName                     string
 	CookieRememberName                 string
@@ -42,27 +44,37 @@ var (
 // loadSecret load the secret from ini by uriKey or verbatimKey, only one of them could be set
 // If the secret is loaded from uriKey (file), the file should be non-empty, to guarantee the behavior stable and clear.
 func loadSecret(sec ConfigSection, uriKey, verbatimKey string) string {
+	secret, _, err := ReadSecret(sec, uriKey, verbatimKey)
+	if err != nil {
+		log.Fatal("%v", err)
+	}
+	return secret
+}
+
+// ReadSecret reads the secret of the config section from the file of the uriKey option if it is set, otherwise from the verbatimKey option.
+// It also returns the path of the file the secret is read from, which is empty if the secret is in the config.
+func ReadSecret(sec ConfigSection, uriKey, verbatimKey string) (secret, file string, err error) {
 	// don't allow setting both URI and verbatim string
 	uri := sec.Key(uriKey).String()
 	verbatim := sec.Key(verbatimKey).String()
 	if uri != "" && verbatim != "" {
-		log.Fatal("Cannot specify both %s and %s", uriKey, verbatimKey)
+		return "", "", fmt.Errorf("cannot specify both %s and %s", uriKey, verbatimKey)
 	}
 
 	// if we have no URI, use verbatim
 	if uri == "" {
-		return verbatim
+		return verbatim, "", nil
 	}
 
 	tempURI, err := url.Parse(uri)
 	if err != nil {
-		log.Fatal("Failed to parse %s (%s): %v", uriKey, uri, err)
+		return "", "", fmt.Errorf("failed to parse %s (%s): %w", uriKey, uri, err)
 	}
 	switch tempURI.Scheme {
 	case "file":
 		buf, err := os.ReadFile(tempURI.RequestURI())
 		if err != nil {
-			log.Fatal("Failed to read %s (%s): %v", uriKey, tempURI.RequestURI(), err)
+			return "", "", fmt.Errorf("failed to read %s (%s): %w", uriKey, tempURI.RequestURI(), err)
 		}
 		val := strings.TrimSpace(string(buf))
 		if val == "" {
@@ -70,14 +82,13 @@ func loadSecret(sec ConfigSection, uriKey, verbatimKey string) string {
 			// For example: if INTERNAL_TOKEN_URI=file:///empty-file,
 			// Then if the token is re-generated during installation and saved to INTERNAL_TOKEN
 			// Then INTERNAL_TOKEN and INTERNAL_TOKEN_URI both exist, that's a fatal error (they shouldn't)
-			log.Fatal("Failed to read %s (%s): the file is empty", uriKey, tempURI.RequestURI())
+			return "", "", fmt.Errorf("failed to read %s (%s): the file is empty", uriKey, tempURI.RequestURI())
 		}
-		return val
+		return val, tempURI.RequestURI(), nil
 
 	// only file URIs are allowed
 	default:
-		log.Fatal("Unsupported URI-Scheme %q (%q = %q)", tempURI.Scheme, uriKey, uri)
-		return ""
+		return "", "", fmt.Errorf("unsupported URI-Scheme %q (%q = %q)", tempURI.Scheme, uriKey, uri)
 	}
 }
 
@@ -147,6 +158,7 @@ func loadSecurityFrom(rootCfg ConfigProvider) {
 		// some users do cluster deployment, they still depend on this auto-generating behavior.
 		generateSaveInternalToken(rootCfg)
 	}
+	ManagerToken = loadSecret(sec, "MANAGER_TOKEN_URI", "MANAGER_TOKEN")
 
 	cfgdata := sec.Key("PASSWORD_COMPLEXITY").Strings(",")
 	if len(cfgdata) == 0 {
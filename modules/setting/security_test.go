@@ -0,0 +1,43 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadSecret(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "secret")
+	assert.NoError(t, os.WriteFile(file, []byte("from-file\n"), 0o600))
+	empty := filepath.Join(dir, "empty")
+	assert.NoError(t, os.WriteFile(empty, nil, 0o600))
+
+	read := func(ini string) (string, string, error) {
+		cfg, err := NewConfigProviderFromData(ini)
+		assert.NoError(t, err)
+		return ReadSecret(cfg.Section("security"), "SECRET_KEY_URI", "SECRET_KEY")
+	}
+
+	secret, source, err := read("[security]\nSECRET_KEY = verbatim\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "verbatim", secret)
+	assert.Empty(t, source)
+
+	secret, source, err = read("[security]\nSECRET_KEY_URI = file://" + file + "\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "from-file", secret)
+	assert.Equal(t, file, source)
+
+	_, _, err = read("[security]\nSECRET_KEY = verbatim\nSECRET_KEY_URI = file://" + file + "\n")
+	assert.ErrorContains(t, err, "cannot specify both SECRET_KEY_URI and SECRET_KEY")
+	_, _, err = read("[security]\nSECRET_KEY_URI = file://" + empty + "\n")
+	assert.ErrorContains(t, err, "the file is empty")
+	_, _, err = read("[security]\nSECRET_KEY_URI = https://example.com/secret\n")
+	assert.ErrorContains(t, err, `unsupported URI-Scheme "https"`)
+}
@@ -74,6 +74,7 @@ var (
 	RedirectOtherPort          bool
 	RedirectorUseProxyProtocol bool
 	PortToRedirect             string
+	ManagerListenAddr          string
 	OfflineMode                bool
 	CertFile                   string
 	KeyFile                    string
@@ -317,6 +318,7 @@ func loadServerFrom(rootCfg ConfigProvider) {
 	RedirectOtherPort = sec.Key("REDIRECT_OTHER_PORT").MustBool(false)
 	PortToRedirect = sec.Key("PORT_TO_REDIRECT").MustString("80")
 	RedirectorUseProxyProtocol = sec.Key("REDIRECTOR_USE_PROXY_PROTOCOL").MustBool(UseProxyProtocol)
+	ManagerListenAddr = sec.Key("MANAGER_LISTEN_ADDR").MustString("")
 	OfflineMode = sec.Key("OFFLINE_MODE").MustBool()
 	if len(StaticRootPath) == 0 {
 		StaticRootPath = AppWorkPath
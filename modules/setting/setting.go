@@ -201,23 +201,27 @@ func mustCurrentRunUserMatch(rootCfg ConfigProvider) {
 // LoadSettings initializes the settings for normal start up
 func LoadSettings() {
 	initAllLoggers()
+	loadSettingsFrom(CfgProvider)
+}
 
-	loadDBSetting(CfgProvider)
-	loadServiceFrom(CfgProvider)
-	loadOAuth2ClientFrom(CfgProvider)
-	loadCacheFrom(CfgProvider)
-	loadSessionFrom(CfgProvider)
-	loadCorsFrom(CfgProvider)
-	loadMailsFrom(CfgProvider)
-	loadProxyFrom(CfgProvider)
-	loadWebhookFrom(CfgProvider)
-	loadMigrationsFrom(CfgProvider)
-	loadIndexerFrom(CfgProvider)
-	loadTaskFrom(CfgProvider)
-	LoadQueueSettings()
-	loadProjectFrom(CfgProvider)
-	loadMimeTypeMapFrom(CfgProvider)
-	loadFederationFrom(CfgProvider)
+// loadSettingsFrom loads the settings which are not loaded by loadCommonSettingsFrom, except the loggers
+func loadSettingsFrom(rootCfg ConfigProvider) {
+	loadDBSetting(rootCfg)
+	loadServiceFrom(rootCfg)
+	loadOAuth2ClientFrom(rootCfg)
+	loadCacheFrom(rootCfg)
+	loadSessionFrom(rootCfg)
+	loadCorsFrom(rootCfg)
+	loadMailsFrom(rootCfg)
+	loadProxyFrom(rootCfg)
+	loadWebhookFrom(rootCfg)
+	loadMigrationsFrom(rootCfg)
+	loadIndexerFrom(rootCfg)
+	loadTaskFrom(rootCfg)
+	loadQueueFrom(rootCfg)
+	loadProjectFrom(rootCfg)
+	loadMimeTypeMapFrom(rootCfg)
+	loadFederationFrom(rootCfg)
 }
 
 // LoadSettingsForInstall initializes the settings for install
@@ -4,6 +4,7 @@
 package setting
 
 import (
+	"fmt"
 	"os"
 	"path"
 	"path/filepath"
@@ -17,6 +18,8 @@ import (
 	gossh "golang.org/x/crypto/ssh"
 )
 
+const sshReadOnlyDefaultMessage = "The server is in read-only mode for maintenance, pushing is disabled."
+
 var SSH = struct {
 	Disabled                              bool               `ini:"DISABLE_SSH"`
 	StartBuiltinServer                    bool               `ini:"START_SSH_SERVER"`
@@ -50,6 +53,9 @@ var SSH = struct {
 	TrustedUserCAKeysParsed               []gossh.PublicKey  `ini:"-"`
 	PerWriteTimeout                       time.Duration      `ini:"SSH_PER_WRITE_TIMEOUT"`
 	PerWritePerKbTimeout                  time.Duration      `ini:"SSH_PER_WRITE_PER_KB_TIMEOUT"`
+	ReadOnly                              bool               `ini:"SSH_READ_ONLY"`
+	ReadOnlyMessage                       string             `ini:"SSH_READ_ONLY_MESSAGE"`
+	ReadOnlyUntil                         string             `ini:"SSH_READ_ONLY_UNTIL"`
 }{
 	Disabled:                      false,
 	StartBuiltinServer:            false,
@@ -65,6 +71,7 @@ var SSH = struct {
 	AuthorizedKeysCommandTemplate: "{{.AppPath}} --config={{.CustomConf}} serv key-{{.Key.ID}}",
 	PerWriteTimeout:               PerWriteTimeout,
 	PerWritePerKbTimeout:          PerWritePerKbTimeout,
+	ReadOnlyMessage:               sshReadOnlyDefaultMessage,
 }
 
 func parseAuthorizedPrincipalsAllow(values []string) ([]string, bool) {
@@ -191,7 +198,18 @@ func loadSSHFrom(rootCfg ConfigProvider) {
 	SSH.PerWriteTimeout = sec.Key("SSH_PER_WRITE_TIMEOUT").MustDuration(PerWriteTimeout)
 	SSH.PerWritePerKbTimeout = sec.Key("SSH_PER_WRITE_PER_KB_TIMEOUT").MustDuration(PerWritePerKbTimeout)
 
+	SSH.ReadOnlyMessage = sec.Key("SSH_READ_ONLY_MESSAGE").MustString(sshReadOnlyDefaultMessage)
+
 	// ensure parseRunModeSetting has been executed before this
 	SSH.BuiltinServerUser = rootCfg.Section("server").Key("BUILTIN_SSH_SERVER_USER").MustString(RunUser)
 	SSH.User = rootCfg.Section("server").Key("SSH_USER").MustString(SSH.BuiltinServerUser)
 }
+
+// SSHReadOnlyRejectMessage returns the message to reject the pushes over SSH in the read-only mode,
+// it tells when the write access is expected to resume if it is known.
+func SSHReadOnlyRejectMessage() string {
+	if SSH.ReadOnlyUntil == "" {
+		return SSH.ReadOnlyMessage
+	}
+	return fmt.Sprintf("%s Write access is expected to resume at %s.", SSH.ReadOnlyMessage, SSH.ReadOnlyUntil)
+}
@@ -0,0 +1,35 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSHReadOnly(t *testing.T) {
+	oldSSH := SSH
+	defer func() { SSH = oldSSH }()
+
+	cfg, err := NewConfigProviderFromData(`
+[server]
+SSH_READ_ONLY = true
+SSH_READ_ONLY_MESSAGE =
+`)
+	assert.NoError(t, err)
+	loadSSHFrom(cfg)
+	assert.True(t, SSH.ReadOnly)
+	assert.Equal(t, sshReadOnlyDefaultMessage, SSHReadOnlyRejectMessage())
+
+	cfg, err = NewConfigProviderFromData(`
+[server]
+SSH_READ_ONLY = true
+SSH_READ_ONLY_MESSAGE = Moving to a new server.
+SSH_READ_ONLY_UNTIL = 2024-05-01 18:00 UTC
+`)
+	assert.NoError(t, err)
+	loadSSHFrom(cfg)
+	assert.Equal(t, "Moving to a new server. Write access is expected to resume at 2024-05-01 18:00 UTC.", SSHReadOnlyRejectMessage())
+}
@@ -37,3 +37,29 @@ func (s discardStorage) URL(_, _ string) (*url.URL, error) {
 func (s discardStorage) IterateObjects(_ string, _ func(string, Object) error) error {
 	return fmt.Errorf("%s", s)
 }
+
+// CountingStorage doesn't store anything, it only counts the objects saved to it and their total size,
+// so it can be used as the destination to know what would be copied without copying anything
+type CountingStorage struct {
+	discardStorage
+	Count int64
+	Size  int64
+}
+
+// NewCountingStorage returns a new CountingStorage, all methods except Save return errors
+func NewCountingStorage() *CountingStorage {
+	return &CountingStorage{discardStorage: "counting storage"}
+}
+
+// Save counts the object, the reader is only read if the size is unknown
+func (s *CountingStorage) Save(_ string, r io.Reader, size int64) (int64, error) {
+	if size < 0 {
+		var err error
+		if size, err = io.Copy(io.Discard, r); err != nil {
+			return 0, err
+		}
+	}
+	s.Count++
+	s.Size += size
+	return size, nil
+}
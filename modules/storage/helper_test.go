@@ -48,3 +48,22 @@ func Test_discardStorage(t *testing.T) {
 		})
 	}
 }
+
+func TestCountingStorage(t *testing.T) {
+	s := NewCountingStorage()
+
+	n, err := s.Save("a", bytes.NewReader([]byte("abc")), 3)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, n)
+
+	// the size is unknown, it is read from the reader
+	n, err = s.Save("b", bytes.NewReader([]byte("hello")), -1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, n)
+
+	assert.EqualValues(t, 2, s.Count)
+	assert.EqualValues(t, 8, s.Size)
+
+	_, err = s.Open("a")
+	assert.Error(t, err)
+}
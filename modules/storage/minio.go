@@ -108,6 +108,13 @@ func NewMinioStorage(ctx context.Context, cfg *setting.Storage) (ObjectStorage,
 	}, nil
 }
 
+// withContext returns a copy of the storage which uses ctx for its operations, it shares the client
+func (m *MinioStorage) withContext(ctx context.Context) ObjectStorage {
+	cp := *m
+	cp.ctx = ctx
+	return &cp
+}
+
 func (m *MinioStorage) buildMinioPath(p string) string {
 	p = util.PathJoinRelX(m.basePath, p)
 	if p == "." {
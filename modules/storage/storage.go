@@ -4,7 +4,9 @@
 package storage
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
@@ -67,11 +69,40 @@ type ObjectStorage interface {
 	IterateObjects(path string, iterator func(path string, obj Object) error) error
 }
 
+// contextStorage is an ObjectStorage whose operations can be bound to another context
+type contextStorage interface {
+	withContext(ctx context.Context) ObjectStorage
+}
+
+// WithContext returns the storage with its operations bound to ctx, e.g. to give them a timeout,
+// the storages which don't use a context (like the local one) are returned as is
+func WithContext(ctx context.Context, s ObjectStorage) ObjectStorage {
+	if cs, ok := s.(contextStorage); ok {
+		return cs.withContext(ctx)
+	}
+	return s
+}
+
 // Copy copies a file from source ObjectStorage to dest ObjectStorage
 func Copy(dstStorage ObjectStorage, dstPath string, srcStorage ObjectStorage, srcPath string) (int64, error) {
+	written, _, err := CopyWithOptions(dstStorage, dstPath, srcStorage, srcPath, CopyOptions{})
+	return written, err
+}
+
+// CopyOptions represents the options of CopyWithOptions
+type CopyOptions struct {
+	// SkipExisting doesn't copy the file if the dest already has a file of the same size at the path
+	SkipExisting bool
+	// VerifyChecksum makes SkipExisting also compare the SHA256 checksums of the files
+	VerifyChecksum bool
+}
+
+// CopyWithOptions copies a file from source ObjectStorage to dest ObjectStorage like Copy,
+// skipped is true if the file isn't copied because the dest already has an identical one
+func CopyWithOptions(dstStorage ObjectStorage, dstPath string, srcStorage ObjectStorage, srcPath string, opts CopyOptions) (written int64, skipped bool, err error) {
 	f, err := srcStorage.Open(srcPath)
 	if err != nil {
-		return 0, err
+		return 0, false, err
 	}
 	defer f.Close()
 
@@ -81,7 +112,61 @@ func Copy(dstStorage ObjectStorage, dstPath string, srcStorage ObjectStorage, sr
 		size = fsinfo.Size()
 	}
 
-	return dstStorage.Save(dstPath, f, size)
+	if opts.SkipExisting && size >= 0 {
+		identical, err := isIdenticalInDest(dstStorage, dstPath, f, size, opts.VerifyChecksum)
+		if err != nil {
+			return 0, false, err
+		}
+		if identical {
+			return 0, true, nil
+		}
+	}
+
+	written, err = dstStorage.Save(dstPath, f, size)
+	return written, false, err
+}
+
+// isIdenticalInDest checks whether the dest has a file at the path with the same size (and checksum) as the source file,
+// the source file is rewound to the start after its checksum is computed
+func isIdenticalInDest(dstStorage ObjectStorage, dstPath string, src Object, size int64, verifyChecksum bool) (bool, error) {
+	dstInfo, err := dstStorage.Stat(dstPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if dstInfo.IsDir() || dstInfo.Size() != size {
+		return false, nil
+	}
+	if !verifyChecksum {
+		return true, nil
+	}
+
+	srcSum, err := sha256Sum(src)
+	if err != nil {
+		return false, err
+	}
+	if _, err = src.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	dst, err := dstStorage.Open(dstPath)
+	if err != nil {
+		return false, err
+	}
+	defer dst.Close()
+	dstSum, err := sha256Sum(dst)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(srcSum, dstSum), nil
+}
+
+func sha256Sum(r io.Reader) ([]byte, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
 }
 
 // Clean delete all the objects in this storage
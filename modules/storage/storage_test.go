@@ -5,6 +5,7 @@ package storage
 
 import (
 	"bytes"
+	"io"
 	"testing"
 
 	"code.gitea.io/gitea/modules/setting"
@@ -49,3 +50,47 @@ func testStorageIterator(t *testing.T, typStr Type, cfg *setting.Storage) {
 		assert.Len(t, expected, count)
 	}
 }
+
+func TestCopyWithOptions(t *testing.T) {
+	src, err := NewStorage(setting.LocalStorageType, &setting.Storage{Path: t.TempDir()})
+	assert.NoError(t, err)
+	dst, err := NewStorage(setting.LocalStorageType, &setting.Storage{Path: t.TempDir()})
+	assert.NoError(t, err)
+
+	_, err = src.Save("a.txt", bytes.NewBufferString("aaa"), -1)
+	assert.NoError(t, err)
+
+	// the dest doesn't have the file
+	written, skipped, err := CopyWithOptions(dst, "a.txt", src, "a.txt", CopyOptions{SkipExisting: true})
+	assert.NoError(t, err)
+	assert.False(t, skipped)
+	assert.EqualValues(t, 3, written)
+
+	// the dest has the same file
+	_, skipped, err = CopyWithOptions(dst, "a.txt", src, "a.txt", CopyOptions{SkipExisting: true, VerifyChecksum: true})
+	assert.NoError(t, err)
+	assert.True(t, skipped)
+
+	// the dest has a different file of the same size, only the checksum can tell
+	_, err = dst.Save("a.txt", bytes.NewBufferString("bbb"), -1)
+	assert.NoError(t, err)
+	_, skipped, err = CopyWithOptions(dst, "a.txt", src, "a.txt", CopyOptions{SkipExisting: true})
+	assert.NoError(t, err)
+	assert.True(t, skipped)
+	written, skipped, err = CopyWithOptions(dst, "a.txt", src, "a.txt", CopyOptions{SkipExisting: true, VerifyChecksum: true})
+	assert.NoError(t, err)
+	assert.False(t, skipped)
+	assert.EqualValues(t, 3, written)
+
+	f, err := dst.Open("a.txt")
+	assert.NoError(t, err)
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	assert.NoError(t, err)
+	assert.Equal(t, "aaa", string(content))
+
+	// without the options, the file is always copied
+	_, skipped, err = CopyWithOptions(dst, "a.txt", src, "a.txt", CopyOptions{})
+	assert.NoError(t, err)
+	assert.False(t, skipped)
+}
@@ -25,7 +25,7 @@ type NotificationSubject struct {
 	LatestCommentURL     string            `json:"latest_comment_url"`
 	HTMLURL              string            `json:"html_url"`
 	LatestCommentHTMLURL string            `json:"latest_comment_html_url"`
-	Type                 NotifySubjectType `json:"type" binding:"In(Issue,Pull,Commit,Repository)"`
+	Type                 NotifySubjectType `json:"type" binding:"In(Issue,Pull,Commit,Repository,System)"`
 	State                StateType         `json:"state"`
 }
 
@@ -46,4 +46,6 @@ const (
 	NotifySubjectCommit NotifySubjectType = "Commit"
 	// NotifySubjectRepository an repository is subject of an notification
 	NotifySubjectRepository NotifySubjectType = "Repository"
+	// NotifySubjectSystem a message of a site administrator is subject of an notification
+	NotifySubjectSystem NotifySubjectType = "System"
 )
@@ -69,7 +69,7 @@ func (h *HTMLRender) CompileTemplates() error {
 	tmpls.Funcs(NewFuncMap())
 	files, err := ListWebTemplateAssetNames(assets)
 	if err != nil {
-		return nil
+		return err
 	}
 	for _, file := range files {
 		if !strings.HasSuffix(file, extSuffix) {
@@ -96,6 +96,7 @@ func HTMLRenderer() *HTMLRender {
 	return htmlRender
 }
 
+// ReloadHTMLTemplates recompiles the templates, the current ones are kept if the new ones fail to compile
 func ReloadHTMLTemplates() error {
 	log.Trace("Reloading HTML templates")
 	if err := htmlRender.CompileTemplates(); err != nil {
@@ -213,6 +214,27 @@ func (p *templateErrorPrettier) handleTemplateRenderingError(err error) string {
 	}
 }
 
+// handleCompileError returns the detailed message of the first handler which recognizes the compilation error
+func (p *templateErrorPrettier) handleCompileError(err error) string {
+	for _, handle := range []func(error) string{
+		p.handleFuncNotDefinedError,
+		p.handleUnexpectedOperandError,
+		p.handleExpectedEndError,
+		p.handleGenericTemplateError,
+	} {
+		if msg := handle(err); msg != "" {
+			return msg
+		}
+	}
+	return fmt.Sprintf("CompileTemplates error: %v", err)
+}
+
+// HandleTemplateCompileError returns the detailed message of a template compilation error, with the erroneous line
+func HandleTemplateCompileError(err error) string {
+	p := &templateErrorPrettier{assets: AssetFS()}
+	return p.handleCompileError(err)
+}
+
 func HandleTemplateRenderingError(err error) string {
 	p := &templateErrorPrettier{assets: AssetFS()}
 	return p.handleTemplateRenderingError(err)
@@ -81,6 +81,15 @@ template error: tmp:test:1 : function "Func" not defined
 {{Func}}
   ^^^^
 ----------------------------------------------------------------------
+`)
+
+	// the compile error handler uses the most specific handler
+	test("{{Func}}", p.handleCompileError, `
+template error: tmp:test:1 : function "Func" not defined
+----------------------------------------------------------------------
+{{Func}}
+  ^^^^
+----------------------------------------------------------------------
 `)
 
 	test("{{'x'3}}", p.handleUnexpectedOperandError, `
@@ -103,4 +112,6 @@ god knows XXX
 `
 	actualMsg := p.handleExpectedEndError(errors.New("template: test:1: expected end; found XXX"))
 	assert.EqualValues(t, strings.TrimSpace(expectedMsg), strings.TrimSpace(actualMsg))
+
+	assert.Equal(t, "CompileTemplates error: unknown", p.handleCompileError(errors.New("unknown")))
 }
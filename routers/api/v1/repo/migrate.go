@@ -227,6 +227,8 @@ func handleMigrateError(ctx *context.APIContext, repoOwner *user_model.User, rem
 		ctx.Error(http.StatusUnprocessableEntity, "", "Remote visit required two factors authentication.")
 	case repo_model.IsErrReachLimitOfRepo(err):
 		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("You have already reached your limit of %d repositories.", repoOwner.MaxCreationLimit()))
+	case repo_model.IsErrRepoSizeQuotaExceeded(err):
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("Your repositories have exceeded the size quota of %d bytes.", repoOwner.MaxRepoSize))
 	case db.IsErrNameReserved(err):
 		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("The username '%s' is reserved.", err.(db.ErrNameReserved).Name))
 	case db.IsErrNameCharsNotAllowed(err):
@@ -42,6 +42,7 @@ import (
 	"code.gitea.io/gitea/services/cron"
 	"code.gitea.io/gitea/services/mailer"
 	mailer_incoming "code.gitea.io/gitea/services/mailer/incoming"
+	"code.gitea.io/gitea/services/maintenance"
 	markup_service "code.gitea.io/gitea/services/markup"
 	repo_migrations "code.gitea.io/gitea/services/migrations"
 	mirror_service "code.gitea.io/gitea/services/mirror"
@@ -135,6 +136,7 @@ func InitWebInstalled(ctx context.Context) {
 	mustInitCtx(ctx, common.InitDBEngine)
 	log.Info("ORM engine initialization successful!")
 	mustInit(system.Init)
+	mustInitCtx(ctx, maintenance.Init)
 	mustInit(oauth2.Init)
 
 	mustInitCtx(ctx, models.Init)
@@ -167,6 +169,14 @@ func InitWebInstalled(ctx context.Context) {
 	cron.NewContext(ctx)
 }
 
+// ManagerRoutes represents the routes of the "[server] MANAGER_LISTEN_ADDR" listener
+func ManagerRoutes() *web.Route {
+	r := web.NewRoute()
+	r.Use(common.ProtocolMiddlewares()...)
+	r.Mount("/api/internal", private.ManagerRoutes())
+	return r
+}
+
 // NormalRoutes represents non install routes
 func NormalRoutes() *web.Route {
 	_ = templates.HTMLRenderer()
@@ -39,6 +39,7 @@ func GenerateActionsRunnerToken(ctx *context.PrivateContext) {
 		ctx.JSON(http.StatusInternalServerError, private.Response{
 			Err: err.Error(),
 		})
+		return
 	}
 
 	token, err := actions_model.GetUnactivatedRunnerToken(ctx, owner, repo)
@@ -64,19 +65,41 @@ func GenerateActionsRunnerToken(ctx *context.PrivateContext) {
 	ctx.PlainText(http.StatusOK, token.Token)
 }
 
+// parseScope parses the runner scope: "" or "global" for a global runner, "org:{org}" or "user:{user}" for an owner,
+// "repo:{owner}/{repo}" for a repository. The scope without a prefix is "{owner}[/{repo}]".
 func parseScope(ctx *context.PrivateContext, scope string) (ownerID, repoID int64, err error) {
 	ownerID = 0
 	repoID = 0
-	if scope == "" {
+	if scope == "" || scope == "global" {
 		return ownerID, repoID, nil
 	}
 
-	ownerName, repoName, found := strings.Cut(scope, "/")
+	kind, name, hasKind := strings.Cut(scope, ":")
+	if !hasKind {
+		kind, name = "", scope
+	}
+	ownerName, repoName, found := strings.Cut(name, "/")
+	switch kind {
+	case "":
+	case "org", "user":
+		if found {
+			return ownerID, repoID, fmt.Errorf("invalid scope %q, it should be %s:{name}", scope, kind)
+		}
+	case "repo":
+		if !found {
+			return ownerID, repoID, fmt.Errorf("invalid scope %q, it should be repo:{owner}/{repo}", scope)
+		}
+	default:
+		return ownerID, repoID, fmt.Errorf("invalid scope %q, the prefix should be org, user or repo", scope)
+	}
 
 	u, err := user_model.GetUserByName(ctx, ownerName)
 	if err != nil {
 		return ownerID, repoID, err
 	}
+	if kind == "org" && !u.IsOrganization() {
+		return ownerID, repoID, fmt.Errorf("%q is not an organization", ownerName)
+	}
 	ownerID = u.ID
 
 	if !found {
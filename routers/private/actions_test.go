@@ -0,0 +1,48 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package private
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScope(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+	ctx, _ := test.MockPrivateContext(t, "POST /api/internal/actions/generate_actions_runner_token")
+
+	for scope, ids := range map[string][2]int64{
+		"":                 {0, 0},
+		"global":           {0, 0},
+		"user3":            {3, 0},
+		"org:user3":        {3, 0},
+		"user:user2":       {2, 0},
+		"user:user3":       {3, 0}, // an organization is also a user
+		"user2/repo1":      {2, 1},
+		"repo:user2/repo1": {2, 1},
+		"repo:user3/repo3": {3, 3},
+	} {
+		ownerID, repoID, err := parseScope(ctx, scope)
+		if assert.NoError(t, err, scope) {
+			assert.Equal(t, ids, [2]int64{ownerID, repoID}, scope)
+		}
+	}
+
+	for _, scope := range []string{
+		"org:user2",        // not an organization
+		"org:user3/repo3",  // a repository in an org scope
+		"user:user2/repo1", // a repository in a user scope
+		"repo:user2",       // no repository
+		"team:user3",       // unknown prefix
+		"no-such-user",
+		"user2/no-such-repo",
+	} {
+		_, _, err := parseScope(ctx, scope)
+		assert.Error(t, err, scope)
+	}
+}
@@ -14,12 +14,15 @@ import (
 	issues_model "code.gitea.io/gitea/models/issues"
 	perm_model "code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
 	gitea_context "code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/private"
+	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/web"
 	pull_service "code.gitea.io/gitea/services/pull"
 )
@@ -101,6 +104,51 @@ func (ctx *preReceiveContext) AssertCreatePullRequest() bool {
 	return true
 }
 
+// assertRepoSizeQuota refuses the push if the pushed objects don't fit in the repository size quota of the owner.
+// The pushed objects are the ones in the quarantine directory, a push without new objects (like a deletion) is always accepted.
+func (ctx *preReceiveContext) assertRepoSizeQuota() bool {
+	if ctx.opts.GitQuarantinePath == "" {
+		return true
+	}
+	repo := ctx.Repo.Repository
+	if err := repo.LoadOwner(ctx); err != nil {
+		log.Error("Unable to load owner of repository %-v: %v", repo, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: fmt.Sprintf("Unable to load owner of repository: %v", err),
+		})
+		return false
+	}
+	if repo.Owner.MaxRepoSize <= 0 {
+		return true
+	}
+
+	pushedSize, err := repo_module.GetDirectorySize(ctx.opts.GitQuarantinePath)
+	if err != nil {
+		log.Error("Unable to get the size of the pushed objects in %s: %v", ctx.opts.GitQuarantinePath, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: fmt.Sprintf("Unable to get the size of the pushed objects: %v", err),
+		})
+		return false
+	}
+	if pushedSize == 0 {
+		return true
+	}
+	if err := repo_model.CheckRepoSizeQuota(ctx, repo.Owner, pushedSize); err != nil {
+		if repo_model.IsErrRepoSizeQuotaExceeded(err) {
+			ctx.JSON(http.StatusForbidden, private.Response{
+				UserMsg: fmt.Sprintf("The repositories of %s would exceed their size quota of %s with this push", repo.Owner.Name, base.FileSize(repo.Owner.MaxRepoSize)),
+			})
+			return false
+		}
+		log.Error("Unable to check the repository size quota of %s: %v", repo.Owner.Name, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: fmt.Sprintf("Unable to check the repository size quota: %v", err),
+		})
+		return false
+	}
+	return true
+}
+
 // HookPreReceive checks whether a individual commit is acceptable
 func HookPreReceive(ctx *gitea_context.PrivateContext) {
 	opts := web.GetForm(ctx).(*private.HookOptions)
@@ -111,6 +159,10 @@ func HookPreReceive(ctx *gitea_context.PrivateContext) {
 		opts:           opts,
 	}
 
+	if !ourCtx.assertRepoSizeQuota() {
+		return
+	}
+
 	// Iterate across the provided old commit IDs
 	for i := range opts.OldCommitIDs {
 		oldCommitID := opts.OldCommitIDs[i]
@@ -0,0 +1,152 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package private
+
+import (
+	stdCtx "context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/context"
+	code_indexer "code.gitea.io/gitea/modules/indexer/code"
+	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/private"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// the names of the queues of the indexers which can be reindexed, by the type
+var reindexQueueNames = map[string]string{
+	"code":   "code_indexer",
+	"issues": "issue_indexer",
+}
+
+// reindexRepo queues the repository to the indexer of the type, it is a variable to be replaced by the tests
+var reindexRepo = func(ctx stdCtx.Context, repo *repo_model.Repository, indexType string) error {
+	if indexType == "code" {
+		return code_indexer.ReindexRepo(ctx, repo)
+	}
+	issue_indexer.UpdateRepoIndexer(ctx, repo)
+	return nil
+}
+
+// Reindex rebuilds the code and/or issue indexes of the given repositories, or of a batch of all the repositories,
+// the repositories are queued to the indexers and opts.Wait waits until the queues are flushed
+func Reindex(ctx *context.PrivateContext) {
+	opts := web.GetForm(ctx).(*private.ReindexOptions)
+	for _, t := range opts.Types {
+		if _, ok := reindexQueueNames[t]; !ok {
+			ctx.JSON(http.StatusBadRequest, private.Response{
+				UserMsg: fmt.Sprintf("Unknown index type %q, it should be code or issues", t),
+			})
+			return
+		}
+		if t == "code" && !setting.Indexer.RepoIndexerEnabled {
+			ctx.JSON(http.StatusBadRequest, private.Response{
+				UserMsg: "The code indexer is disabled, set [indexer] REPO_INDEXER_ENABLED to enable it",
+			})
+			return
+		}
+		if t == "issues" && setting.Indexer.IssueType == "db" {
+			ctx.JSON(http.StatusBadRequest, private.Response{
+				UserMsg: `The issue indexer is the "db" one which searches the database, it has no index to rebuild`,
+			})
+			return
+		}
+	}
+
+	// resolve the given repositories first, so a typo doesn't leave a half done reindexing
+	result := &private.ReindexResult{}
+	var repos []*repo_model.Repository
+	for _, fullName := range opts.Repos {
+		ownerName, repoName, ok := strings.Cut(fullName, "/")
+		if !ok || ownerName == "" || repoName == "" {
+			ctx.JSON(http.StatusBadRequest, private.Response{
+				UserMsg: fmt.Sprintf("Invalid repository %q, it should be owner/name", fullName),
+			})
+			return
+		}
+		repo, err := repo_model.GetRepositoryByOwnerAndName(ctx, ownerName, repoName)
+		if err != nil {
+			if repo_model.IsErrRepoNotExist(err) {
+				ctx.JSON(http.StatusNotFound, private.Response{
+					UserMsg: fmt.Sprintf("Repository %s doesn't exist", fullName),
+				})
+				return
+			}
+			log.Error("Unable to get repository %s: %v", fullName, err)
+			ctx.JSON(http.StatusInternalServerError, private.Response{
+				Err: fmt.Sprintf("Unable to get repository %s: %v", fullName, err),
+			})
+			return
+		}
+		repos = append(repos, repo)
+	}
+	if len(opts.Repos) == 0 {
+		if opts.BatchSize < 1 {
+			opts.BatchSize = repo_model.RepositoryListDefaultPageSize
+		}
+		var err error
+		if result.Total, err = db.GetEngine(ctx).Count(new(repo_model.Repository)); err == nil {
+			err = db.GetEngine(ctx).Where("id > ?", opts.AfterID).OrderBy("id").Limit(opts.BatchSize).Find(&repos)
+		}
+		if err != nil {
+			log.Error("Unable to list the repositories: %v", err)
+			ctx.JSON(http.StatusInternalServerError, private.Response{
+				Err: fmt.Sprintf("Unable to list the repositories: %v", err),
+			})
+			return
+		}
+	}
+
+	for _, repo := range repos {
+		queued := false
+		for _, t := range opts.Types {
+			if t == "code" && repo.IsEmpty {
+				// an empty repository has no code to index
+				continue
+			}
+			if err := reindexRepo(ctx, repo, t); err != nil {
+				log.Error("Unable to reindex the %s of %s: %v", t, repo.FullName(), err)
+				ctx.JSON(http.StatusInternalServerError, private.Response{
+					Err: fmt.Sprintf("Unable to reindex the %s of %s: %v", t, repo.FullName(), err),
+				})
+				return
+			}
+			queued = true
+		}
+		// the repositories with nothing to index are not reported, but the next batch starts after them
+		if queued {
+			result.Repos = append(result.Repos, repo.FullName())
+		}
+		result.LastID = repo.ID
+	}
+
+	if opts.Wait {
+		for _, t := range opts.Types {
+			if err := flushQueueByName(ctx, reindexQueueNames[t]); err != nil {
+				ctx.JSON(http.StatusRequestTimeout, private.Response{
+					UserMsg: fmt.Sprintf("Unable to wait for the %s indexer: %v", t, err),
+				})
+				return
+			}
+		}
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// flushQueueByName makes the handler of the named queue process all its items
+func flushQueueByName(ctx *context.PrivateContext, name string) error {
+	for _, q := range queue.GetManager().ManagedQueues() {
+		if q.GetName() == name {
+			return q.FlushWithContext(ctx, 0)
+		}
+	}
+	return fmt.Errorf("no queue named %q", name)
+}
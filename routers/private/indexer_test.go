@@ -0,0 +1,97 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package private
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/private"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+	"code.gitea.io/gitea/modules/web"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/builder"
+)
+
+func TestReindexTypes(t *testing.T) {
+	defer func(enabled bool, issueType string) {
+		setting.Indexer.RepoIndexerEnabled, setting.Indexer.IssueType = enabled, issueType
+	}(setting.Indexer.RepoIndexerEnabled, setting.Indexer.IssueType)
+	setting.Indexer.RepoIndexerEnabled = false
+	setting.Indexer.IssueType = "db"
+
+	for types, msg := range map[string]string{
+		"wiki":   `Unknown index type "wiki", it should be code or issues`,
+		"code":   "The code indexer is disabled, set [indexer] REPO_INDEXER_ENABLED to enable it",
+		"issues": `The issue indexer is the "db" one which searches the database, it has no index to rebuild`,
+	} {
+		ctx, resp := test.MockPrivateContext(t, "POST /api/internal/indexer/reindex")
+		web.SetForm(ctx, &private.ReindexOptions{Types: []string{types}})
+		Reindex(ctx)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, types)
+		var result private.Response
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+		assert.Equal(t, msg, result.UserMsg)
+	}
+}
+
+func TestReindexBatches(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	defer func(enabled bool, fn func(context.Context, *repo_model.Repository, string) error) {
+		setting.Indexer.RepoIndexerEnabled, reindexRepo = enabled, fn
+	}(setting.Indexer.RepoIndexerEnabled, reindexRepo)
+	setting.Indexer.RepoIndexerEnabled = true
+	queued := map[int64][]string{}
+	reindexRepo = func(ctx context.Context, repo *repo_model.Repository, indexType string) error {
+		queued[repo.ID] = append(queued[repo.ID], indexType)
+		return nil
+	}
+	reindex := func(opts *private.ReindexOptions) *private.ReindexResult {
+		ctx, resp := test.MockPrivateContext(t, "POST /api/internal/indexer/reindex")
+		web.SetForm(ctx, opts)
+		Reindex(ctx)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		result := &private.ReindexResult{}
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), result))
+		return result
+	}
+
+	// all the repositories are listed batch by batch, the empty ones have no code to index
+	var reported []string
+	var afterID int64
+	batches := 0
+	for {
+		result := reindex(&private.ReindexOptions{Types: []string{"code"}, AfterID: afterID, BatchSize: 10})
+		if result.LastID == 0 {
+			assert.Empty(t, result.Repos)
+			break
+		}
+		assert.Greater(t, result.LastID, afterID)
+		batches++
+		reported = append(reported, result.Repos...)
+		afterID = result.LastID
+	}
+	total := unittest.GetCount(t, &repo_model.Repository{})
+	empty := int(unittest.GetCountByCond(t, "repository", builder.Eq{"is_empty": true}))
+	assert.Equal(t, (total+9)/10, batches)
+	assert.Len(t, reported, total-empty)
+	assert.Len(t, queued, total-empty)
+	assert.NotContains(t, reported, "user10/repo6")
+
+	// the issues of an empty repository are still indexed
+	queued = map[int64][]string{}
+	result := reindex(&private.ReindexOptions{Repos: []string{"user10/repo6"}, Types: []string{"code", "issues"}})
+	assert.Equal(t, []string{"user10/repo6"}, result.Repos)
+	assert.Equal(t, map[int64][]string{6: {"issues"}}, queued)
+
+	result = reindex(&private.ReindexOptions{Repos: []string{"user10/repo6"}, Types: []string{"code"}})
+	assert.Empty(t, result.Repos)
+	assert.EqualValues(t, 6, result.LastID)
+}
@@ -5,10 +5,12 @@
 package private
 
 import (
+	"crypto/subtle"
 	"net/http"
 	"strings"
 
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/private"
 	"code.gitea.io/gitea/modules/setting"
@@ -37,6 +39,33 @@ func CheckInternalToken(next http.Handler) http.Handler {
 	})
 }
 
+// CheckManagerToken check manager token is set, it is used by the manager listener instead of the internal token
+func CheckManagerToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		tokens := req.Header.Get("Authorization")
+		fields := strings.SplitN(tokens, " ", 2)
+		if setting.ManagerToken == "" {
+			log.Warn(`The MANAGER_TOKEN setting is missing from the configuration file: %q, manager API can't work.`, setting.CustomConf)
+			managerForbidden(w, "The manager token is not configured on the server")
+			return
+		}
+		// the listener may be reachable from other hosts, so don't leak the token by the comparison time
+		if len(fields) != 2 || fields[0] != "Bearer" || subtle.ConstantTimeCompare([]byte(fields[1]), []byte(setting.ManagerToken)) != 1 {
+			log.Warn("Forbidden attempt to access manager url %s from %s", req.URL.Path, req.RemoteAddr)
+			managerForbidden(w, "Invalid manager token")
+		} else {
+			next.ServeHTTP(w, req)
+		}
+	})
+}
+
+// managerForbidden responds like the manager APIs do, so the manager commands can show the message
+func managerForbidden(w http.ResponseWriter, userMsg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	_ = json.NewEncoder(w).Encode(private.Response{UserMsg: userMsg})
+}
+
 // bind binding an obj to a handler
 func bind[T any](_ T) any {
 	return func(ctx *context.PrivateContext) {
@@ -57,6 +86,7 @@ func Routes() *web.Route {
 	r.Use(chi_middleware.RealIP)
 
 	r.Post("/ssh/authorized_keys", AuthorizedPublicKeyByContent)
+	r.Post("/ssh/check_key", CheckPublicKey)
 	r.Post("/ssh/{id}/update/{repoid}", UpdatePublicKeyInRepo)
 	r.Post("/ssh/log", bind(private.SSHLogOption{}), SSHLog)
 	r.Post("/hook/pre-receive/{owner}/{repo}", RepoAssignment, bind(private.HookOptions{}), HookPreReceive)
@@ -65,20 +95,42 @@ func Routes() *web.Route {
 	r.Post("/hook/set-default-branch/{owner}/{repo}/{branch}", RepoAssignment, SetDefaultBranch)
 	r.Get("/serv/none/{keyid}", ServNoCommand)
 	r.Get("/serv/command/{keyid}/{owner}/{repo}", ServCommand)
+	registerManagerRoutes(r)
+	r.Post("/mail/send", SendEmail)
+	r.Post("/restore_repo", RestoreRepo)
+	r.Post("/indexer/reindex", bind(private.ReindexOptions{}), Reindex)
+	r.Post("/actions/generate_actions_runner_token", GenerateActionsRunnerToken)
+
+	return r
+}
+
+// ManagerRoutes registers the manager APIs routes for the "[server] MANAGER_LISTEN_ADDR" listener.
+// These APIs will be invoked by `gitea manager --manager-addr` from other hosts, so they are authenticated by the MANAGER_TOKEN.
+func ManagerRoutes() *web.Route {
+	r := web.NewRoute()
+	r.Use(context.PrivateContexter())
+	r.Use(CheckManagerToken)
+	registerManagerRoutes(r)
+	return r
+}
+
+func registerManagerRoutes(r *web.Route) {
 	r.Post("/manager/shutdown", Shutdown)
 	r.Post("/manager/restart", Restart)
 	r.Post("/manager/reload-templates", ReloadTemplates)
 	r.Post("/manager/flush-queues", bind(private.FlushOptions{}), FlushQueues)
 	r.Post("/manager/pause-logging", PauseLogging)
 	r.Post("/manager/resume-logging", ResumeLogging)
+	r.Get("/manager/show-logging", ShowLogging)
 	r.Post("/manager/release-and-reopen-logging", ReleaseReopenLogging)
 	r.Post("/manager/set-log-sql", SetLogSQL)
+	r.Post("/manager/set-setting", bind(private.SetSettingOptions{}), SetSetting)
+	r.Post("/manager/maintenance", bind(private.MaintenanceOptions{}), SetMaintenance)
+	r.Post("/manager/ssh-read-only", bind(private.SSHReadOnlyOptions{}), SetSSHReadOnly)
+	r.Post("/manager/fsck-repos", bind(private.FsckReposOptions{}), FsckRepos)
 	r.Post("/manager/add-logger", bind(private.LoggerOptions{}), AddLogger)
 	r.Post("/manager/remove-logger/{logger}/{writer}", RemoveLogger)
 	r.Get("/manager/processes", Processes)
-	r.Post("/mail/send", SendEmail)
-	r.Post("/restore_repo", RestoreRepo)
-	r.Post("/actions/generate_actions_runner_token", GenerateActionsRunnerToken)
-
-	return r
+	r.Get("/manager/stats", Stats)
+	r.Post("/manager/processes/{pid}/cancel", CancelProcess)
 }
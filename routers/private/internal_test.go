@@ -0,0 +1,48 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package private
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckManagerToken(t *testing.T) {
+	oldInternalToken, oldManagerToken := setting.InternalToken, setting.ManagerToken
+	defer func() {
+		setting.InternalToken, setting.ManagerToken = oldInternalToken, oldManagerToken
+	}()
+	setting.InternalToken = "internal-token"
+
+	handler := CheckManagerToken(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	check := func(authorization string) int {
+		req := httptest.NewRequest("POST", "/manager/shutdown", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	// the manager listener doesn't work without the manager token
+	setting.ManagerToken = ""
+	assert.Equal(t, http.StatusForbidden, check(""))
+	assert.Equal(t, http.StatusForbidden, check("Bearer "))
+
+	setting.ManagerToken = "manager-token"
+	assert.Equal(t, http.StatusOK, check("Bearer manager-token"))
+	assert.Equal(t, http.StatusForbidden, check(""))
+	assert.Equal(t, http.StatusForbidden, check("manager-token"))
+	assert.Equal(t, http.StatusForbidden, check("Bearer manager-token2"))
+	// the internal token is only accepted by the internal routes
+	assert.Equal(t, http.StatusForbidden, check("Bearer internal-token"))
+}
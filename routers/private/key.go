@@ -7,7 +7,9 @@ import (
 	"net/http"
 
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/private"
 	"code.gitea.io/gitea/modules/timeutil"
 )
@@ -59,3 +61,65 @@ func AuthorizedPublicKeyByContent(ctx *context.PrivateContext) {
 	}
 	ctx.PlainText(http.StatusOK, publicKey.AuthorizedString())
 }
+
+// CheckPublicKey checks whether the key found by its content (like AuthorizedPublicKeyByContent) or by its fingerprint
+// would be accepted by the SSH server: it must not be a principal and the owner of a user key must be able to log in.
+func CheckPublicKey(ctx *context.PrivateContext) {
+	content, fingerprint := ctx.FormString("content"), ctx.FormString("fingerprint")
+
+	var keys []*asymkey_model.PublicKey
+	if content != "" {
+		key, err := asymkey_model.SearchPublicKeyByContent(ctx, content)
+		if err != nil && !asymkey_model.IsErrKeyNotExist(err) {
+			ctx.JSON(http.StatusInternalServerError, private.Response{
+				Err: err.Error(),
+			})
+			return
+		} else if err == nil {
+			keys = append(keys, key)
+		}
+	} else if fingerprint != "" {
+		var err error
+		if keys, err = asymkey_model.SearchPublicKey(0, fingerprint); err != nil {
+			ctx.JSON(http.StatusInternalServerError, private.Response{
+				Err: err.Error(),
+			})
+			return
+		}
+	} else {
+		ctx.JSON(http.StatusBadRequest, private.Response{
+			UserMsg: "No key content or fingerprint provided",
+		})
+		return
+	}
+
+	userMsg := "Cannot find key"
+	for _, key := range keys {
+		if key.Type == asymkey_model.KeyTypePrincipal {
+			continue
+		}
+		if key.Type == asymkey_model.KeyTypeDeploy {
+			ctx.PlainText(http.StatusOK, "success")
+			return
+		}
+		owner, err := user_model.GetUserByID(ctx, key.OwnerID)
+		if err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				continue
+			}
+			log.Error("Unable to get owner with id: %d for public key: %d Error: %v", key.OwnerID, key.ID, err)
+			ctx.JSON(http.StatusInternalServerError, private.Response{
+				Err: err.Error(),
+			})
+			return
+		}
+		if owner.IsActive && !owner.ProhibitLogin {
+			ctx.PlainText(http.StatusOK, "success")
+			return
+		}
+		userMsg = "The owner of the key is disabled"
+	}
+	ctx.JSON(http.StatusForbidden, private.Response{
+		UserMsg: userMsg,
+	})
+}
@@ -4,8 +4,11 @@
 package private
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/modules/context"
@@ -16,15 +19,17 @@ import (
 	"code.gitea.io/gitea/modules/queue"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/templates"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/maintenance"
 )
 
-// ReloadTemplates reloads all the templates
+// ReloadTemplates reloads all the templates, the running ones are kept if the new ones fail to compile
 func ReloadTemplates(ctx *context.PrivateContext) {
 	err := templates.ReloadHTMLTemplates()
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, private.Response{
-			UserMsg: fmt.Sprintf("Template error: %v", err),
+			UserMsg: fmt.Sprintf("The templates are not reloaded, the running ones are kept: %s", templates.HandleTemplateCompileError(err)),
 		})
 		return
 	}
@@ -50,25 +55,72 @@ func FlushQueues(ctx *context.PrivateContext) {
 	}
 	err := queue.GetManager().FlushAll(ctx, opts.Timeout)
 	if err != nil {
+		msg := fmt.Sprintf("%v", err)
+		if remaining := remainingQueueItems(); remaining != "" {
+			msg += "\nRemaining items:\n" + remaining
+		}
 		ctx.JSON(http.StatusRequestTimeout, private.Response{
-			UserMsg: fmt.Sprintf("%v", err),
+			UserMsg: msg,
 		})
+		return
 	}
 	ctx.PlainText(http.StatusOK, "success")
 }
 
-// PauseLogging pauses logging
+// remainingQueueItems returns the queues which still have items, one "name: count" per line
+func remainingQueueItems() string {
+	var lines []string
+	for _, q := range queue.GetManager().ManagedQueues() {
+		if n := q.GetQueueItemNumber(); n > 0 {
+			lines = append(lines, fmt.Sprintf("%s: %d", q.GetName(), n))
+		}
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// PauseLogging pauses logging, or only the logger (or its writer) given by the "logger" (and "writer") parameters
 func PauseLogging(ctx *context.PrivateContext) {
+	if logger := ctx.FormString("logger"); logger != "" {
+		setLoggerPaused(ctx, logger, ctx.FormString("writer"), true)
+		return
+	}
 	log.GetManager().PauseAll()
 	ctx.PlainText(http.StatusOK, "success")
 }
 
-// ResumeLogging resumes logging
+// ResumeLogging resumes logging, or only the logger (or its writer) given by the "logger" (and "writer") parameters
 func ResumeLogging(ctx *context.PrivateContext) {
+	if logger := ctx.FormString("logger"); logger != "" {
+		setLoggerPaused(ctx, logger, ctx.FormString("writer"), false)
+		return
+	}
 	log.GetManager().ResumeAll()
 	ctx.PlainText(http.StatusOK, "success")
 }
 
+func setLoggerPaused(ctx *context.PrivateContext, logger, writer string, paused bool) {
+	if err := log.GetManager().SetLoggerPaused(logger, writer, paused); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, util.ErrNotExist) {
+			status = http.StatusNotFound
+		}
+		ctx.JSON(status, private.Response{
+			UserMsg: fmt.Sprintf("Failed to change the paused state of the logger %q (writer %q): %v", logger, writer, err),
+		})
+		return
+	}
+	ctx.PlainText(http.StatusOK, "success")
+}
+
+// ShowLogging returns the loggers and their paused state
+func ShowLogging(ctx *context.PrivateContext) {
+	ctx.JSON(http.StatusOK, private.LoggingStatus{
+		Paused:  log.GetManager().GetPauseChan() != nil,
+		Loggers: log.GetManager().DumpLoggers(),
+	})
+}
+
 // ReleaseReopenLogging releases and reopens logging files
 func ReleaseReopenLogging(ctx *context.PrivateContext) {
 	if err := releasereopen.GetManager().ReleaseReopen(); err != nil {
@@ -86,6 +138,52 @@ func SetLogSQL(ctx *context.PrivateContext) {
 	ctx.PlainText(http.StatusOK, "success")
 }
 
+// SetSetting changes an allowed setting of the running process
+func SetSetting(ctx *context.PrivateContext) {
+	opts := web.GetForm(ctx).(*private.SetSettingOptions)
+	if err := setting.SetRuntimeSetting(opts.Name, opts.Value); err != nil {
+		ctx.JSON(http.StatusBadRequest, private.Response{
+			UserMsg: err.Error(),
+		})
+		return
+	}
+	log.Info("Setting %s has been set to %q by the manager command", opts.Name, opts.Value)
+	ctx.PlainText(http.StatusOK, "success")
+}
+
+// SetMaintenance turns the maintenance mode on or off, it is saved so it survives restarts
+func SetMaintenance(ctx *context.PrivateContext) {
+	opts := web.GetForm(ctx).(*private.MaintenanceOptions)
+	if err := maintenance.Set(ctx, maintenance.State{Enabled: opts.Enabled, Message: opts.Message}); err != nil {
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: fmt.Sprintf("Failed to change the maintenance mode: %v", err),
+		})
+		return
+	}
+	if opts.Enabled {
+		log.Info("Maintenance mode has been turned on by the manager command")
+	} else {
+		log.Info("Maintenance mode has been turned off by the manager command")
+	}
+	ctx.PlainText(http.StatusOK, "success")
+}
+
+// SetSSHReadOnly turns the read-only mode of the pushes over SSH on or off, it isn't saved to the config file
+func SetSSHReadOnly(ctx *context.PrivateContext) {
+	opts := web.GetForm(ctx).(*private.SSHReadOnlyOptions)
+	if opts.Enabled {
+		if opts.Message != "" {
+			setting.SSH.ReadOnlyMessage = opts.Message
+		}
+		setting.SSH.ReadOnlyUntil = opts.Until
+		log.Info("SSH read-only mode has been turned on by the manager command")
+	} else {
+		log.Info("SSH read-only mode has been turned off by the manager command")
+	}
+	setting.SSH.ReadOnly = opts.Enabled
+	ctx.PlainText(http.StatusOK, "success")
+}
+
 // RemoveLogger removes a logger
 func RemoveLogger(ctx *context.PrivateContext) {
 	logger := ctx.Params("logger")
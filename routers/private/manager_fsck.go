@@ -0,0 +1,109 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package private
+
+import (
+	stdCtx "context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/private"
+	"code.gitea.io/gitea/modules/web"
+	repo_service "code.gitea.io/gitea/services/repository"
+
+	"xorm.io/builder"
+)
+
+// FsckRepos runs "git fsck" on the given repositories, or on all the repositories with the health check enabled,
+// at most opts.Concurrency at the same time, and responds with the repositories which have problems
+func FsckRepos(ctx *context.PrivateContext) {
+	opts := web.GetForm(ctx).(*private.FsckReposOptions)
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+
+	// resolve the given repositories first, so a typo doesn't report a half done check
+	var repos []*repo_model.Repository
+	for _, fullName := range opts.Repos {
+		ownerName, repoName, ok := strings.Cut(fullName, "/")
+		if !ok || ownerName == "" || repoName == "" {
+			ctx.JSON(http.StatusBadRequest, private.Response{
+				UserMsg: fmt.Sprintf("Invalid repository %q, it should be owner/name", fullName),
+			})
+			return
+		}
+		repo, err := repo_model.GetRepositoryByOwnerAndName(ctx, ownerName, repoName)
+		if err != nil {
+			if repo_model.IsErrRepoNotExist(err) {
+				ctx.JSON(http.StatusNotFound, private.Response{
+					UserMsg: fmt.Sprintf("Repository %s doesn't exist", fullName),
+				})
+				return
+			}
+			log.Error("Unable to get repository %s: %v", fullName, err)
+			ctx.JSON(http.StatusInternalServerError, private.Response{
+				Err: fmt.Sprintf("Unable to get repository %s: %v", fullName, err),
+			})
+			return
+		}
+		repos = append(repos, repo)
+	}
+
+	result := &private.FsckReposResult{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	repoCh := make(chan *repo_model.Repository)
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for repo := range repoCh {
+				err := repo_service.GitFsckRepo(ctx, repo, opts.Timeout, nil)
+				mu.Lock()
+				result.Checked++
+				if err != nil {
+					result.Problems = append(result.Problems, private.FsckRepoProblem{Repo: repo.FullName(), Error: strings.TrimSpace(err.Error())})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var err error
+	if len(repos) > 0 {
+		for _, repo := range repos {
+			repoCh <- repo
+		}
+	} else {
+		err = db.Iterate(ctx, builder.Expr("id>0 AND is_fsck_enabled=?", true), func(ctx stdCtx.Context, repo *repo_model.Repository) error {
+			select {
+			case <-ctx.Done():
+				return db.ErrCancelledf("before fsck of %s", repo.FullName())
+			case repoCh <- repo:
+				return nil
+			}
+		})
+	}
+	close(repoCh)
+	wg.Wait()
+	if err != nil {
+		log.Error("Unable to check the repositories: %v", err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: fmt.Sprintf("Unable to check the repositories: %v", err),
+		})
+		return
+	}
+
+	sort.Slice(result.Problems, func(i, j int) bool {
+		return result.Problems[i].Repo < result.Problems[j].Repo
+	})
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,66 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package private
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/private"
+	"code.gitea.io/gitea/modules/test"
+	"code.gitea.io/gitea/modules/web"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/builder"
+)
+
+func TestFsckRepos(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+
+	fsck := func(opts *private.FsckReposOptions) (int, []byte) {
+		ctx, resp := test.MockPrivateContext(t, "POST /api/internal/manager/fsck-repos")
+		web.SetForm(ctx, opts)
+		FsckRepos(ctx)
+		return resp.Code, resp.Body.Bytes()
+	}
+
+	// the repositories are resolved before any of them is checked
+	code, _ := fsck(&private.FsckReposOptions{Repos: []string{"user2/repo1", "user2"}})
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = fsck(&private.FsckReposOptions{Repos: []string{"user2/repo1", "user2/no-such-repo"}})
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, body := fsck(&private.FsckReposOptions{Repos: []string{"user2/repo1"}})
+	assert.Equal(t, http.StatusOK, code)
+	result := &private.FsckReposResult{}
+	assert.NoError(t, json.Unmarshal(body, result))
+	assert.Equal(t, 1, result.Checked)
+	assert.Empty(t, result.Problems)
+
+	// a repository whose objects are lost has a problem
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	assert.NoError(t, os.RemoveAll(filepath.Join(repo.RepoPath(), "objects")))
+	assert.NoError(t, os.MkdirAll(filepath.Join(repo.RepoPath(), "objects"), 0o755))
+	code, body = fsck(&private.FsckReposOptions{Repos: []string{"user2/repo1", "user2/repo2"}, Concurrency: 2})
+	assert.Equal(t, http.StatusOK, code)
+	result = &private.FsckReposResult{}
+	assert.NoError(t, json.Unmarshal(body, result))
+	assert.Equal(t, 2, result.Checked)
+	if assert.Len(t, result.Problems, 1) {
+		assert.Equal(t, "user2/repo1", result.Problems[0].Repo)
+		assert.NotEmpty(t, result.Problems[0].Error)
+	}
+
+	// without repositories, all the ones with the health check enabled are checked
+	code, body = fsck(&private.FsckReposOptions{Concurrency: 4})
+	assert.Equal(t, http.StatusOK, code)
+	result = &private.FsckReposResult{}
+	assert.NoError(t, json.Unmarshal(body, result))
+	assert.EqualValues(t, unittest.GetCountByCond(t, "repository", builder.Eq{"is_fsck_enabled": true}), result.Checked)
+}
@@ -5,6 +5,7 @@ package private
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -15,6 +16,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/private"
 	process_module "code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/util"
 )
 
 // Processes prints out the processes
@@ -71,6 +73,42 @@ func Processes(ctx *context.PrivateContext) {
 	}
 }
 
+// CancelProcess cancels a non-system process and reports whether it has been found
+func CancelProcess(ctx *context.PrivateContext) {
+	pid := ctx.Params("pid")
+	process, err := process_module.GetManager().TryCancel(process_module.IDType(pid))
+	switch {
+	case errors.Is(err, util.ErrNotExist):
+		ctx.JSON(http.StatusNotFound, private.Response{
+			UserMsg: fmt.Sprintf("Process %s not found, it may have finished already", pid),
+		})
+		return
+	case errors.Is(err, util.ErrPermissionDenied):
+		ctx.JSON(http.StatusForbidden, private.Response{
+			UserMsg: fmt.Sprintf("Process %s is a system process, it can't be cancelled", pid),
+		})
+		return
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: err.Error(),
+		})
+		return
+	}
+	log.Info("Process %s (%s) has been cancelled by the manager", pid, process.Description)
+
+	// the process finishes when it notices the cancellation, a process blocked on I/O might not notice it soon
+	for i := 0; i < 10 && process_module.GetManager().IsRunning(process.PID); i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	msg := fmt.Sprintf("Process %s (%s) has been cancelled and has finished", pid, process.Description)
+	if process_module.GetManager().IsRunning(process.PID) {
+		msg = fmt.Sprintf("Process %s (%s) has been cancelled but hasn't finished yet", pid, process.Description)
+	}
+	ctx.JSON(http.StatusOK, private.Response{
+		UserMsg: msg,
+	})
+}
+
 func writeProcesses(out io.Writer, processes []*process_module.Process, processCount int, goroutineCount int64, indent string, flat bool) error {
 	if goroutineCount > 0 {
 		if _, err := fmt.Fprintf(out, "%sTotal Number of Goroutines: %d\n", indent, goroutineCount); err != nil {
@@ -101,7 +139,7 @@ func writeProcesses(out io.Writer, processes []*process_module.Process, processC
 func writeProcess(out io.Writer, process *process_module.Process, indent string, flat bool) error {
 	sb := &bytes.Buffer{}
 	if flat {
-		if process.ParentPID != "" {
+		if process.ParentPID == "" {
 			_, _ = fmt.Fprintf(sb, "%s+ PID: %s\t\tType: %s\n", indent, process.PID, process.Type)
 		} else {
 			_, _ = fmt.Fprintf(sb, "%s+ PID: %s:%s\tType: %s\n", indent, process.ParentPID, process.PID, process.Type)
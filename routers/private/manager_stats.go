@@ -0,0 +1,66 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package private
+
+import (
+	"net/http"
+	"runtime"
+	"sort"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/private"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// Stats responds with a snapshot of the live counters of the process: the database connection pool,
+// the queues, the running git processes, the goroutines and the memory
+func Stats(ctx *context.PrivateContext) {
+	dbStats := db.ConnectionStats()
+	stats := &private.ManagerStats{
+		StartTime: setting.AppStartTime,
+		Database: private.DatabaseStats{
+			MaxOpenConnections: dbStats.MaxOpenConnections,
+			OpenConnections:    dbStats.OpenConnections,
+			InUse:              dbStats.InUse,
+			Idle:               dbStats.Idle,
+			WaitCount:          dbStats.WaitCount,
+			WaitDuration:       dbStats.WaitDuration,
+		},
+		Queues:       []private.QueueStats{},
+		GitProcesses: int64(process.GetManager().CountByType(process.GitProcessType)),
+		Goroutines:   runtime.NumGoroutine(),
+	}
+
+	for _, mq := range queue.GetManager().ManagedQueues() {
+		stats.Queues = append(stats.Queues, private.QueueStats{
+			Name:          mq.GetName(),
+			Type:          mq.GetType(),
+			Items:         mq.GetQueueItemNumber(),
+			Workers:       mq.GetWorkerNumber(),
+			ActiveWorkers: mq.GetWorkerActiveNumber(),
+			MaxWorkers:    mq.GetWorkerMaxNumber(),
+		})
+	}
+	sort.Slice(stats.Queues, func(i, j int) bool {
+		return stats.Queues[i].Name < stats.Queues[j].Name
+	})
+
+	m := new(runtime.MemStats)
+	runtime.ReadMemStats(m)
+	stats.Memory = private.MemoryStats{
+		Alloc:       m.Alloc,
+		TotalAlloc:  m.TotalAlloc,
+		Sys:         m.Sys,
+		HeapAlloc:   m.HeapAlloc,
+		HeapInuse:   m.HeapInuse,
+		HeapObjects: m.HeapObjects,
+		StackInuse:  m.StackInuse,
+		NumGC:       m.NumGC,
+	}
+
+	ctx.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,47 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package private
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"testing"
+
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/private"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+
+	stats := func() *private.ManagerStats {
+		ctx, resp := test.MockPrivateContext(t, "GET /api/internal/manager/stats")
+		Stats(ctx)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		stats := &private.ManagerStats{}
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), stats))
+		return stats
+	}
+
+	before := stats()
+	assert.Positive(t, before.Goroutines)
+	assert.Positive(t, before.Memory.Sys)
+	assert.True(t, sort.SliceIsSorted(before.Queues, func(i, j int) bool {
+		return before.Queues[i].Name < before.Queues[j].Name
+	}))
+
+	// the git processes are counted from the process manager, the other processes are not
+	_, _, finishedGit := process.GetManager().AddTypedContext(context.Background(), "git: stats test", process.GitProcessType, false)
+	_, _, finishedNormal := process.GetManager().AddContext(context.Background(), "stats test")
+	assert.Equal(t, before.GitProcesses+1, stats().GitProcesses)
+	finishedGit()
+	finishedNormal()
+	assert.Equal(t, before.GitProcesses, stats().GitProcesses)
+}
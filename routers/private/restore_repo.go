@@ -4,12 +4,16 @@
 package private
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 
+	user_model "code.gitea.io/gitea/models/user"
 	myCtx "code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/private"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/services/migrations"
 )
 
@@ -36,18 +40,36 @@ func RestoreRepo(ctx *myCtx.PrivateContext) {
 		return
 	}
 
-	if err := migrations.RestoreRepository(
+	repo, err := migrations.RestoreRepository(
 		ctx,
 		params.RepoDir,
 		params.OwnerName,
 		params.RepoName,
 		params.Units,
 		params.Validation,
-	); err != nil {
-		ctx.JSON(http.StatusInternalServerError, private.Response{
-			Err: err.Error(),
-		})
-	} else {
-		ctx.PlainText(http.StatusOK, "success")
+	)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case user_model.IsErrUserNotExist(err):
+			status = http.StatusNotFound
+		case errors.Is(err, util.ErrAlreadyExist):
+			status = http.StatusConflict
+		case errors.Is(err, util.ErrInvalidArgument):
+			status = http.StatusBadRequest
+		}
+		if status == http.StatusInternalServerError {
+			ctx.JSON(status, private.Response{
+				Err: err.Error(),
+			})
+		} else {
+			ctx.JSON(status, private.Response{
+				UserMsg: err.Error(),
+			})
+		}
+		return
 	}
+	ctx.JSON(http.StatusOK, private.Response{
+		UserMsg: fmt.Sprintf("Restore repo %s/%s successfully", repo.OwnerName, repo.Name),
+	})
 }
@@ -75,6 +75,30 @@ func ServNoCommand(ctx *context.PrivateContext) {
 	ctx.JSON(http.StatusOK, &results)
 }
 
+// servTracer logs the steps of the access decision of ServCommand when "gitea serv --trace" asks for it
+type servTracer struct {
+	enabled bool
+	prefix  string
+}
+
+func (t *servTracer) trace(format string, args ...any) {
+	if t.enabled {
+		log.Info("%s"+format, append([]any{t.prefix}, args...)...)
+	}
+}
+
+// reject traces the reason of the denial and responds with it
+func (t *servTracer) reject(ctx *context.PrivateContext, status int, resp private.Response) {
+	if t.enabled {
+		reason := resp.UserMsg
+		if reason == "" {
+			reason = resp.Err
+		}
+		t.trace("denied (status %d): %s", status, reason)
+	}
+	ctx.JSON(status, resp)
+}
+
 // ServCommand returns information about the provided keyid
 func ServCommand(ctx *context.PrivateContext) {
 	keyID := ctx.ParamsInt64(":keyid")
@@ -82,6 +106,12 @@ func ServCommand(ctx *context.PrivateContext) {
 	repoName := ctx.Params(":repo")
 	mode := perm.AccessMode(ctx.FormInt("mode"))
 
+	tr := &servTracer{
+		enabled: ctx.FormBool("trace"),
+		prefix:  fmt.Sprintf("serv trace: key-%d %s/%s: ", keyID, ownerName, repoName),
+	}
+	tr.trace("requested mode %s with verbs %v from %s", mode.String(), ctx.FormStrings("verb"), ctx.RemoteAddr())
+
 	// Set the basic parts of the results to return
 	results := private.ServCommandResults{
 		RepoName:  repoName,
@@ -95,6 +125,14 @@ func ServCommand(ctx *context.PrivateContext) {
 		modeString = "write to"
 	}
 
+	// In the read-only mode nothing can be pushed over SSH, but fetching and cloning still work
+	if mode > perm.AccessModeRead && setting.SSH.ReadOnly {
+		tr.reject(ctx, http.StatusForbidden, private.Response{
+			UserMsg: setting.SSHReadOnlyRejectMessage(),
+		})
+		return
+	}
+
 	// The default unit we're trying to look at is code
 	unitType := unit.TypeCode
 
@@ -106,25 +144,27 @@ func ServCommand(ctx *context.PrivateContext) {
 		results.IsWiki = true
 		results.RepoName = repoName[:len(repoName)-5]
 	}
+	tr.trace("looking at unit %s of %s/%s", unitType.String(), results.OwnerName, results.RepoName)
 
 	owner, err := user_model.GetUserByName(ctx, results.OwnerName)
 	if err != nil {
 		if user_model.IsErrUserNotExist(err) {
 			// User is fetching/cloning a non-existent repository
 			log.Warn("Failed authentication attempt (cannot find repository: %s/%s) from %s", results.OwnerName, results.RepoName, ctx.RemoteAddr())
-			ctx.JSON(http.StatusNotFound, private.Response{
+			tr.reject(ctx, http.StatusNotFound, private.Response{
 				UserMsg: fmt.Sprintf("Cannot find repository: %s/%s", results.OwnerName, results.RepoName),
 			})
 			return
 		}
 		log.Error("Unable to get repository owner: %s/%s Error: %v", results.OwnerName, results.RepoName, err)
-		ctx.JSON(http.StatusForbidden, private.Response{
+		tr.reject(ctx, http.StatusForbidden, private.Response{
 			UserMsg: fmt.Sprintf("Unable to get repository owner: %s/%s %v", results.OwnerName, results.RepoName, err),
 		})
 		return
 	}
+	tr.trace("found owner %d:%s (organization: %t, active: %t)", owner.ID, owner.Name, owner.IsOrganization(), owner.IsActive)
 	if !owner.IsOrganization() && !owner.IsActive {
-		ctx.JSON(http.StatusForbidden, private.Response{
+		tr.reject(ctx, http.StatusForbidden, private.Response{
 			UserMsg: "Repository cannot be accessed, you could retry it later",
 		})
 		return
@@ -136,11 +176,12 @@ func ServCommand(ctx *context.PrivateContext) {
 	if err != nil {
 		if repo_model.IsErrRepoNotExist(err) {
 			repoExist = false
+			tr.trace("repository doesn't exist")
 			for _, verb := range ctx.FormStrings("verb") {
 				if verb == "git-upload-pack" {
 					// User is fetching/cloning a non-existent repository
 					log.Warn("Failed authentication attempt (cannot find repository: %s/%s) from %s", results.OwnerName, results.RepoName, ctx.RemoteAddr())
-					ctx.JSON(http.StatusNotFound, private.Response{
+					tr.reject(ctx, http.StatusNotFound, private.Response{
 						UserMsg: fmt.Sprintf("Cannot find repository: %s/%s", results.OwnerName, results.RepoName),
 					})
 					return
@@ -148,7 +189,7 @@ func ServCommand(ctx *context.PrivateContext) {
 			}
 		} else {
 			log.Error("Unable to get repository: %s/%s Error: %v", results.OwnerName, results.RepoName, err)
-			ctx.JSON(http.StatusInternalServerError, private.Response{
+			tr.reject(ctx, http.StatusInternalServerError, private.Response{
 				Err: fmt.Sprintf("Unable to get repository: %s/%s %v", results.OwnerName, results.RepoName, err),
 			})
 			return
@@ -159,16 +200,17 @@ func ServCommand(ctx *context.PrivateContext) {
 		repo.Owner = owner
 		repo.OwnerName = ownerName
 		results.RepoID = repo.ID
+		tr.trace("found repository %d (private: %t, mirror: %t, archived: %t)", repo.ID, repo.IsPrivate, repo.IsMirror, repo.IsArchived)
 
 		if repo.IsBeingCreated() {
-			ctx.JSON(http.StatusInternalServerError, private.Response{
+			tr.reject(ctx, http.StatusInternalServerError, private.Response{
 				Err: "Repository is being created, you could retry after it finished",
 			})
 			return
 		}
 
 		if repo.IsBroken() {
-			ctx.JSON(http.StatusInternalServerError, private.Response{
+			tr.reject(ctx, http.StatusInternalServerError, private.Response{
 				Err: "Repository is in a broken state",
 			})
 			return
@@ -176,7 +218,7 @@ func ServCommand(ctx *context.PrivateContext) {
 
 		// We can shortcut at this point if the repo is a mirror
 		if mode > perm.AccessModeRead && repo.IsMirror {
-			ctx.JSON(http.StatusForbidden, private.Response{
+			tr.reject(ctx, http.StatusForbidden, private.Response{
 				UserMsg: fmt.Sprintf("Mirror Repository %s/%s is read-only", results.OwnerName, results.RepoName),
 			})
 			return
@@ -187,13 +229,13 @@ func ServCommand(ctx *context.PrivateContext) {
 	key, err := asymkey_model.GetPublicKeyByID(keyID)
 	if err != nil {
 		if asymkey_model.IsErrKeyNotExist(err) {
-			ctx.JSON(http.StatusNotFound, private.Response{
+			tr.reject(ctx, http.StatusNotFound, private.Response{
 				UserMsg: fmt.Sprintf("Cannot find key: %d", keyID),
 			})
 			return
 		}
 		log.Error("Unable to get public key: %d Error: %v", keyID, err)
-		ctx.JSON(http.StatusInternalServerError, private.Response{
+		tr.reject(ctx, http.StatusInternalServerError, private.Response{
 			Err: fmt.Sprintf("Unable to get key: %d  Error: %v", keyID, err),
 		})
 		return
@@ -201,10 +243,11 @@ func ServCommand(ctx *context.PrivateContext) {
 	results.KeyName = key.Name
 	results.KeyID = key.ID
 	results.UserID = key.OwnerID
+	tr.trace("found key %d:%s owned by %d (deploy key: %t, principal: %t)", key.ID, key.Name, key.OwnerID, key.Type == asymkey_model.KeyTypeDeploy, key.Type == asymkey_model.KeyTypePrincipal)
 
 	// If repo doesn't exist, deploy key doesn't make sense
 	if !repoExist && key.Type == asymkey_model.KeyTypeDeploy {
-		ctx.JSON(http.StatusNotFound, private.Response{
+		tr.reject(ctx, http.StatusNotFound, private.Response{
 			UserMsg: fmt.Sprintf("Cannot find repository %s/%s", results.OwnerName, results.RepoName),
 		})
 		return
@@ -220,19 +263,20 @@ func ServCommand(ctx *context.PrivateContext) {
 		deployKey, err = asymkey_model.GetDeployKeyByRepo(ctx, key.ID, repo.ID)
 		if err != nil {
 			if asymkey_model.IsErrDeployKeyNotExist(err) {
-				ctx.JSON(http.StatusNotFound, private.Response{
+				tr.reject(ctx, http.StatusNotFound, private.Response{
 					UserMsg: fmt.Sprintf("Public (Deploy) Key: %d:%s is not authorized to %s %s/%s.", key.ID, key.Name, modeString, results.OwnerName, results.RepoName),
 				})
 				return
 			}
 			log.Error("Unable to get deploy for public (deploy) key: %d in %-v Error: %v", key.ID, repo, err)
-			ctx.JSON(http.StatusInternalServerError, private.Response{
+			tr.reject(ctx, http.StatusInternalServerError, private.Response{
 				Err: fmt.Sprintf("Unable to get Deploy Key for Public Key: %d:%s in %s/%s.", key.ID, key.Name, results.OwnerName, results.RepoName),
 			})
 			return
 		}
 		results.DeployKeyID = deployKey.ID
 		results.KeyName = deployKey.Name
+		tr.trace("found deploy key %d:%s with mode %s", deployKey.ID, deployKey.Name, deployKey.Mode.String())
 
 		// FIXME: Deploy keys aren't really the owner of the repo pushing changes
 		// however we don't have good way of representing deploy keys in hook.go
@@ -248,20 +292,21 @@ func ServCommand(ctx *context.PrivateContext) {
 		user, err = user_model.GetUserByID(ctx, key.OwnerID)
 		if err != nil {
 			if user_model.IsErrUserNotExist(err) {
-				ctx.JSON(http.StatusUnauthorized, private.Response{
+				tr.reject(ctx, http.StatusUnauthorized, private.Response{
 					UserMsg: fmt.Sprintf("Public Key: %d:%s owner %d does not exist.", key.ID, key.Name, key.OwnerID),
 				})
 				return
 			}
 			log.Error("Unable to get owner: %d for public key: %d:%s Error: %v", key.OwnerID, key.ID, key.Name, err)
-			ctx.JSON(http.StatusInternalServerError, private.Response{
+			tr.reject(ctx, http.StatusInternalServerError, private.Response{
 				Err: fmt.Sprintf("Unable to get Owner: %d for Deploy Key: %d:%s in %s/%s.", key.OwnerID, key.ID, key.Name, ownerName, repoName),
 			})
 			return
 		}
+		tr.trace("resolved user %d:%s (active: %t, prohibit login: %t, restricted: %t)", user.ID, user.Name, user.IsActive, user.ProhibitLogin, user.IsRestricted)
 
 		if !user.IsActive || user.ProhibitLogin {
-			ctx.JSON(http.StatusForbidden, private.Response{
+			tr.reject(ctx, http.StatusForbidden, private.Response{
 				UserMsg: "Your account is disabled.",
 			})
 			return
@@ -275,7 +320,7 @@ func ServCommand(ctx *context.PrivateContext) {
 
 	// Don't allow pushing if the repo is archived
 	if repoExist && mode > perm.AccessModeRead && repo.IsArchived {
-		ctx.JSON(http.StatusUnauthorized, private.Response{
+		tr.reject(ctx, http.StatusUnauthorized, private.Response{
 			UserMsg: fmt.Sprintf("Repo: %s/%s is archived.", results.OwnerName, results.RepoName),
 		})
 		return
@@ -289,8 +334,9 @@ func ServCommand(ctx *context.PrivateContext) {
 			(user != nil && user.IsRestricted) || // user will be nil if the key is a deploykey
 			setting.Service.RequireSignInView) {
 		if key.Type == asymkey_model.KeyTypeDeploy {
+			tr.trace("checking deploy key mode %s against requested mode %s", deployKey.Mode.String(), mode.String())
 			if deployKey.Mode < mode {
-				ctx.JSON(http.StatusUnauthorized, private.Response{
+				tr.reject(ctx, http.StatusUnauthorized, private.Response{
 					UserMsg: fmt.Sprintf("Deploy Key: %d:%s is not authorized to %s %s/%s.", key.ID, key.Name, modeString, results.OwnerName, results.RepoName),
 				})
 				return
@@ -299,56 +345,61 @@ func ServCommand(ctx *context.PrivateContext) {
 			// Because of the special ref "refs/for" we will need to delay write permission check
 			if git.SupportProcReceive && unitType == unit.TypeCode {
 				mode = perm.AccessModeRead
+				tr.trace("write permission is checked later by the hooks (proc-receive), checking read permission")
 			}
 
 			perm, err := access_model.GetUserRepoPermission(ctx, repo, user)
 			if err != nil {
 				log.Error("Unable to get permissions for %-v with key %d in %-v Error: %v", user, key.ID, repo, err)
-				ctx.JSON(http.StatusInternalServerError, private.Response{
+				tr.reject(ctx, http.StatusInternalServerError, private.Response{
 					Err: fmt.Sprintf("Unable to get permissions for user %d:%s with key %d in %s/%s Error: %v", user.ID, user.Name, key.ID, results.OwnerName, results.RepoName, err),
 				})
 				return
 			}
 
 			userMode := perm.UnitAccessMode(unitType)
+			tr.trace("checking user mode %s on unit %s against requested mode %s", userMode.String(), unitType.String(), mode.String())
 
 			if userMode < mode {
 				log.Warn("Failed authentication attempt for %s with key %s (not authorized to %s %s/%s) from %s", user.Name, key.Name, modeString, ownerName, repoName, ctx.RemoteAddr())
-				ctx.JSON(http.StatusUnauthorized, private.Response{
+				tr.reject(ctx, http.StatusUnauthorized, private.Response{
 					UserMsg: fmt.Sprintf("User: %d:%s with Key: %d:%s is not authorized to %s %s/%s.", user.ID, user.Name, key.ID, key.Name, modeString, ownerName, repoName),
 				})
 				return
 			}
 		}
+	} else if repoExist {
+		tr.trace("permission check skipped, reading a public repository")
 	}
 
 	// We already know we aren't using a deploy key
 	if !repoExist {
 		owner, err := user_model.GetUserByName(ctx, ownerName)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, private.Response{
+			tr.reject(ctx, http.StatusInternalServerError, private.Response{
 				Err: fmt.Sprintf("Unable to get owner: %s %v", results.OwnerName, err),
 			})
 			return
 		}
 
 		if owner.IsOrganization() && !setting.Repository.EnablePushCreateOrg {
-			ctx.JSON(http.StatusForbidden, private.Response{
+			tr.reject(ctx, http.StatusForbidden, private.Response{
 				UserMsg: "Push to create is not enabled for organizations.",
 			})
 			return
 		}
 		if !owner.IsOrganization() && !setting.Repository.EnablePushCreateUser {
-			ctx.JSON(http.StatusForbidden, private.Response{
+			tr.reject(ctx, http.StatusForbidden, private.Response{
 				UserMsg: "Push to create is not enabled for users.",
 			})
 			return
 		}
 
+		tr.trace("creating the repository by push")
 		repo, err = repo_service.PushCreateRepo(ctx, user, owner, results.RepoName)
 		if err != nil {
 			log.Error("pushCreateRepo: %v", err)
-			ctx.JSON(http.StatusNotFound, private.Response{
+			tr.reject(ctx, http.StatusNotFound, private.Response{
 				UserMsg: fmt.Sprintf("Cannot find repository: %s/%s", results.OwnerName, results.RepoName),
 			})
 			return
@@ -360,22 +411,24 @@ func ServCommand(ctx *context.PrivateContext) {
 		// Ensure the wiki is enabled before we allow access to it
 		if _, err := repo.GetUnit(ctx, unit.TypeWiki); err != nil {
 			if repo_model.IsErrUnitTypeNotExist(err) {
-				ctx.JSON(http.StatusForbidden, private.Response{
+				tr.reject(ctx, http.StatusForbidden, private.Response{
 					UserMsg: "repository wiki is disabled",
 				})
 				return
 			}
 			log.Error("Failed to get the wiki unit in %-v Error: %v", repo, err)
-			ctx.JSON(http.StatusInternalServerError, private.Response{
+			tr.reject(ctx, http.StatusInternalServerError, private.Response{
 				Err: fmt.Sprintf("Failed to get the wiki unit in %s/%s Error: %v", ownerName, repoName, err),
 			})
 			return
 		}
 
+		tr.trace("wiki is enabled")
+
 		// Finally if we're trying to touch the wiki we should init it
 		if err = wiki_service.InitWiki(ctx, repo); err != nil {
 			log.Error("Failed to initialize the wiki in %-v Error: %v", repo, err)
-			ctx.JSON(http.StatusInternalServerError, private.Response{
+			tr.reject(ctx, http.StatusInternalServerError, private.Response{
 				Err: fmt.Sprintf("Failed to initialize the wiki in %s/%s Error: %v", ownerName, repoName, err),
 			})
 			return
@@ -392,6 +445,7 @@ func ServCommand(ctx *context.PrivateContext) {
 		results.RepoName,
 		results.RepoID)
 
+	tr.trace("allowed as user %d:%s (deploy key: %d)", results.UserID, results.UserName, results.DeployKeyID)
 	ctx.JSON(http.StatusOK, results)
 	// We will update the keys in a different call.
 }
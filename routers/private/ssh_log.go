@@ -15,12 +15,19 @@ import (
 
 // SSHLog hook to response ssh log
 func SSHLog(ctx *context.PrivateContext) {
-	if !setting.Log.EnableSSHLog {
+	opts := web.GetForm(ctx).(*private.SSHLogOption)
+
+	// The trace has been asked for explicitly by "gitea serv --trace"
+	if opts.IsTrace {
+		log.Info("serv trace: %v", opts.Message)
 		ctx.Status(http.StatusOK)
 		return
 	}
 
-	opts := web.GetForm(ctx).(*private.SSHLogOption)
+	if !setting.Log.EnableSSHLog {
+		ctx.Status(http.StatusOK)
+		return
+	}
 
 	if opts.IsError {
 		log.Error("ssh: %v", opts.Message)
@@ -0,0 +1,43 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package web
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/services/maintenance"
+)
+
+const tplMaintenance base.TplName = "status/503"
+
+// maintenanceSignInPaths are still served in the maintenance mode, so the admins can sign in
+var maintenanceSignInPaths = []string{"/user/login", "/user/logout", "/user/two_factor", "/user/webauthn", "/user/oauth2/"}
+
+// maintenanceMode responds 503 to the non-admin requests when the maintenance mode is enabled
+func maintenanceMode(ctx *context.Context) {
+	state := maintenance.Get()
+	if !state.Enabled || (ctx.Doer != nil && ctx.Doer.IsAdmin) {
+		return
+	}
+	for _, p := range maintenanceSignInPaths {
+		if strings.HasPrefix(ctx.Req.URL.Path, p) {
+			return
+		}
+	}
+
+	message := state.Message
+	if message == "" {
+		message = ctx.Tr("maintenance_desc")
+	}
+	if !strings.Contains(ctx.Req.Header.Get("Accept"), "text/html") {
+		ctx.PlainText(http.StatusServiceUnavailable, message)
+		return
+	}
+	ctx.Data["Title"] = ctx.Tr("maintenance")
+	ctx.Data["MaintenanceMessage"] = message
+	ctx.HTML(http.StatusServiceUnavailable, tplMaintenance)
+}
@@ -536,6 +536,10 @@ func ArtifactsDownloadView(ctx *context_module.Context) {
 		ctx.Error(http.StatusNotFound, "artifact not found")
 		return
 	}
+	if artifacts[0].Status == actions_model.ArtifactStatusExpired {
+		ctx.Error(http.StatusNotFound, "artifact has expired")
+		return
+	}
 
 	ctx.Resp.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.zip; filename*=UTF-8''%s.zip", url.PathEscape(artifactName), artifactName))
 
@@ -272,6 +272,8 @@ func ForkPost(ctx *context.Context) {
 			maxCreationLimit := ctxUser.MaxCreationLimit()
 			msg := ctx.TrN(maxCreationLimit, "repo.form.reach_limit_of_creation_1", "repo.form.reach_limit_of_creation_n", maxCreationLimit)
 			ctx.RenderWithErr(msg, tplFork, &form)
+		case repo_model.IsErrRepoSizeQuotaExceeded(err):
+			ctx.RenderWithErr(ctx.Tr("repo.form.reach_size_quota", base.FileSize(ctxUser.MaxRepoSize)), tplFork, &form)
 		case repo_model.IsErrRepoAlreadyExist(err):
 			ctx.RenderWithErr(ctx.Tr("repo.settings.new_owner_has_same_repo"), tplFork, &form)
 		case repo_model.IsErrRepoFilesAlreadyExist(err):
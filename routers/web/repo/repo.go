@@ -187,6 +187,8 @@ func handleCreateError(ctx *context.Context, owner *user_model.User, err error,
 		maxCreationLimit := owner.MaxCreationLimit()
 		msg := ctx.TrN(maxCreationLimit, "repo.form.reach_limit_of_creation_1", "repo.form.reach_limit_of_creation_n", maxCreationLimit)
 		ctx.RenderWithErr(msg, tpl, form)
+	case repo_model.IsErrRepoSizeQuotaExceeded(err):
+		ctx.RenderWithErr(ctx.Tr("repo.form.reach_size_quota", base.FileSize(owner.MaxRepoSize)), tpl, form)
 	case repo_model.IsErrRepoAlreadyExist(err):
 		ctx.Data["Err_RepoName"] = true
 		ctx.RenderWithErr(ctx.Tr("form.repo_name_been_taken"), tpl, form)
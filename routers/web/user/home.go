@@ -35,8 +35,6 @@ import (
 	issue_service "code.gitea.io/gitea/services/issue"
 	pull_service "code.gitea.io/gitea/services/pull"
 
-	"github.com/keybase/go-crypto/openpgp"
-	"github.com/keybase/go-crypto/openpgp/armor"
 	"xorm.io/builder"
 )
 
@@ -780,37 +778,11 @@ func ShowGPGKeys(ctx *context.Context) {
 		return
 	}
 
-	entities := make([]*openpgp.Entity, 0)
-	failedEntitiesID := make([]string, 0)
-	for _, k := range keys {
-		e, err := asymkey_model.GPGKeyToEntity(k)
-		if err != nil {
-			if asymkey_model.IsErrGPGKeyImportNotExist(err) {
-				failedEntitiesID = append(failedEntitiesID, k.KeyID)
-				continue // Skip previous import without backup of imported armored key
-			}
-			ctx.ServerError("ShowGPGKeys", err)
-			return
-		}
-		entities = append(entities, e)
-	}
 	var buf bytes.Buffer
-
-	headers := make(map[string]string)
-	if len(failedEntitiesID) > 0 { // If some key need re-import to be exported
-		headers["Note"] = fmt.Sprintf("The keys with the following IDs couldn't be exported and need to be reuploaded %s", strings.Join(failedEntitiesID, ", "))
-	} else if len(entities) == 0 {
-		headers["Note"] = "This user hasn't uploaded any GPG keys."
-	}
-	writer, _ := armor.Encode(&buf, "PGP PUBLIC KEY BLOCK", headers)
-	for _, e := range entities {
-		err = e.Serialize(writer) // TODO find why key are exported with a different cipherTypeByte as original (should not be blocking but strange)
-		if err != nil {
-			ctx.ServerError("ShowGPGKeys", err)
-			return
-		}
+	if err := asymkey_model.WriteArmoredGPGKeys(&buf, keys, nil); err != nil {
+		ctx.ServerError("ShowGPGKeys", err)
+		return
 	}
-	writer.Close()
 	ctx.PlainTextBytes(http.StatusOK, buf.Bytes())
 }
 
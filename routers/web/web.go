@@ -146,6 +146,9 @@ func Routes() *web.Route {
 	// Get user from session if logged in.
 	mid = append(mid, auth_service.Auth(buildAuthGroup()))
 
+	// The maintenance mode only lets the admins in, it needs the signed-in user
+	mid = append(mid, maintenanceMode)
+
 	// GetHead allows a HEAD request redirect to GET if HEAD method is not defined for that route
 	mid = append(mid, middleware.GetHead)
 
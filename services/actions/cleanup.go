@@ -0,0 +1,136 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+const cleanupBatchSize = 100
+
+// CleanupOptions represents the options of a cleanup, a zero duration skips the category
+type CleanupOptions struct {
+	ArtifactsOlderThan time.Duration
+	LogsOlderThan      time.Duration
+	DryRun             bool
+}
+
+// RetentionCleanupOptions returns the cleanup options of the retention days of the actions settings
+func RetentionCleanupOptions() CleanupOptions {
+	return CleanupOptions{
+		ArtifactsOlderThan: time.Duration(setting.Actions.ArtifactRetentionDays) * 24 * time.Hour,
+		LogsOlderThan:      time.Duration(setting.Actions.LogRetentionDays) * 24 * time.Hour,
+	}
+}
+
+// CleanupStats represents the number of the removed items of a category and their size in bytes
+type CleanupStats struct {
+	Count int64
+	Size  int64
+}
+
+// CleanupResult represents the result of a cleanup
+type CleanupResult struct {
+	Artifacts CleanupStats
+	Logs      CleanupStats
+}
+
+// Cleanup removes the expired artifacts and task logs, in the dry-run mode nothing is removed but the result is the same
+func Cleanup(ctx context.Context, opts CleanupOptions) (*CleanupResult, error) {
+	result := &CleanupResult{}
+	var err error
+	if opts.ArtifactsOlderThan > 0 {
+		if result.Artifacts, err = CleanupArtifacts(ctx, opts.ArtifactsOlderThan, opts.DryRun); err != nil {
+			return result, err
+		}
+	}
+	if opts.LogsOlderThan > 0 {
+		if result.Logs, err = CleanupLogs(ctx, opts.LogsOlderThan, opts.DryRun); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// CleanupArtifacts removes the files of the artifacts created before olderThan ago from the storage and marks them expired
+func CleanupArtifacts(ctx context.Context, olderThan time.Duration, dryRun bool) (CleanupStats, error) {
+	var stats CleanupStats
+	before := timeutil.TimeStamp(time.Now().Add(-olderThan).Unix())
+	var lastID int64
+	for {
+		artifacts, err := actions_model.FindArtifactsToExpire(ctx, before, lastID, cleanupBatchSize)
+		if err != nil {
+			return stats, fmt.Errorf("find artifacts to expire: %w", err)
+		}
+		for _, artifact := range artifacts {
+			lastID = artifact.ID
+			if !dryRun {
+				// the artifacts which have never been uploaded completely have no file
+				if artifact.StoragePath != "" {
+					if err := storage.ActionsArtifacts.Delete(artifact.StoragePath); err != nil {
+						log.Warn("Cannot remove the file %q of artifact %d: %v", artifact.StoragePath, artifact.ID, err)
+						continue
+					}
+				}
+				if err := actions_model.SetArtifactExpired(ctx, artifact.ID); err != nil {
+					log.Warn("Cannot mark artifact %d as expired: %v", artifact.ID, err)
+					continue
+				}
+			}
+			stats.Count++
+			// the file in the storage is the compressed one if the artifact has been uploaded compressed
+			if artifact.FileCompressedSize > 0 {
+				stats.Size += artifact.FileCompressedSize
+			} else {
+				stats.Size += artifact.FileSize
+			}
+		}
+		if len(artifacts) < cleanupBatchSize {
+			return stats, nil
+		}
+	}
+}
+
+// CleanupLogs removes the logs of the tasks stopped before olderThan ago and marks them expired
+func CleanupLogs(ctx context.Context, olderThan time.Duration, dryRun bool) (CleanupStats, error) {
+	var stats CleanupStats
+	before := timeutil.TimeStamp(time.Now().Add(-olderThan).Unix())
+	var lastID int64
+	for {
+		tasks, err := actions_model.FindOldTasksToExpire(ctx, before, lastID, cleanupBatchSize)
+		if err != nil {
+			return stats, fmt.Errorf("find tasks to expire: %w", err)
+		}
+		for _, task := range tasks {
+			lastID = task.ID
+			if !dryRun {
+				if err := actions.RemoveLogs(ctx, task.LogInStorage, task.LogFilename); err != nil {
+					log.Warn("Cannot remove the logs of task %d: %v", task.ID, err)
+					continue
+				}
+				// the indexes are useless without the logs and can be large
+				task.LogIndexes = nil
+				task.LogExpired = true
+				if err := actions_model.UpdateTask(ctx, task, "log_indexes", "log_expired"); err != nil {
+					log.Warn("Cannot mark the logs of task %d as expired: %v", task.ID, err)
+					continue
+				}
+			}
+			stats.Count++
+			stats.Size += task.LogSize
+		}
+		if len(tasks) < cleanupBatchSize {
+			return stats, nil
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCleanup(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	defer func(enabled bool) {
+		setting.Actions.Enabled = enabled
+		assert.NoError(t, storage.Init())
+	}(setting.Actions.Enabled)
+	setting.Actions.Enabled = true
+	assert.NoError(t, storage.Init())
+
+	artifact := &actions_model.ActionArtifact{
+		RunID:              792,
+		RepoID:             4,
+		ArtifactName:       "artifact",
+		ArtifactPath:       "artifact.txt",
+		StoragePath:        "26/1/artifact.txt.gz",
+		FileSize:           1024,
+		FileCompressedSize: 100,
+		Status:             actions_model.ArtifactStatusUploadConfirmed,
+		CreatedUnix:        timeutil.TimeStamp(time.Now().Add(-48 * time.Hour).Unix()),
+	}
+	_, err := db.GetEngine(db.DefaultContext).NoAutoTime().Insert(artifact)
+	assert.NoError(t, err)
+	_, err = storage.ActionsArtifacts.Save(artifact.StoragePath, strings.NewReader("content"), -1)
+	assert.NoError(t, err)
+
+	// nothing is old enough
+	result, err := Cleanup(db.DefaultContext, CleanupOptions{ArtifactsOlderThan: 72 * time.Hour, LogsOlderThan: 100 * 365 * 24 * time.Hour})
+	assert.NoError(t, err)
+	assert.Equal(t, CleanupResult{}, *result)
+
+	opts := CleanupOptions{ArtifactsOlderThan: 24 * time.Hour, LogsOlderThan: 24 * time.Hour, DryRun: true}
+	expected := CleanupResult{
+		Artifacts: CleanupStats{Count: 1, Size: 100},
+		Logs:      CleanupStats{Count: 1, Size: 90179},
+	}
+	result, err = Cleanup(db.DefaultContext, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, *result)
+	unittest.AssertExistsAndLoadBean(t, &actions_model.ActionArtifact{ID: artifact.ID, Status: actions_model.ArtifactStatusUploadConfirmed})
+	assert.False(t, unittest.AssertExistsAndLoadBean(t, &actions_model.ActionTask{ID: 47}).LogExpired)
+	_, err = storage.ActionsArtifacts.Stat(artifact.StoragePath)
+	assert.NoError(t, err)
+
+	opts.DryRun = false
+	result, err = Cleanup(db.DefaultContext, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, *result)
+	unittest.AssertExistsAndLoadBean(t, &actions_model.ActionArtifact{ID: artifact.ID, Status: actions_model.ArtifactStatusExpired})
+	assert.True(t, unittest.AssertExistsAndLoadBean(t, &actions_model.ActionTask{ID: 47}).LogExpired)
+	_, err = storage.ActionsArtifacts.Stat(artifact.StoragePath)
+	assert.Error(t, err)
+
+	// the expired ones are not removed again
+	result, err = Cleanup(db.DefaultContext, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, CleanupResult{}, *result)
+}
@@ -4,9 +4,13 @@
 package auth
 
 import (
+	"context"
+
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
 	user_model "code.gitea.io/gitea/models/user"
+
+	"xorm.io/builder"
 )
 
 // DeleteSource deletes a AuthSource record in DB.
@@ -38,3 +42,47 @@ func DeleteSource(source *auth.Source) error {
 	_, err = db.GetEngine(db.DefaultContext).ID(source.ID).Delete(new(auth.Source))
 	return err
 }
+
+// DeleteSourceCascadeResult reports the users affected by DeleteSourceCascade
+type DeleteSourceCascadeResult struct {
+	ConvertedUsers   int64 // the users of the source converted to local accounts
+	UnlinkedAccounts int64 // the external accounts of the source unlinked from their users
+}
+
+// DeleteSourceCascade deletes a AuthSource record in DB like DeleteSource, but instead of refusing it while the source
+// is in use, its users are converted to local accounts (prohibited from logging in if prohibitLogin is true),
+// which need a password to sign in, and the external accounts linked with it are unlinked
+func DeleteSourceCascade(ctx context.Context, source *auth.Source, prohibitLogin bool) (*DeleteSourceCascadeResult, error) {
+	result := &DeleteSourceCascadeResult{}
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		cols := []string{"login_type", "login_source", "login_name"}
+		if prohibitLogin {
+			cols = append(cols, "prohibit_login")
+		}
+		converted, err := db.GetEngine(ctx).Where(builder.Eq{"login_source": source.ID}).Cols(cols...).NoAutoTime().
+			Update(&user_model.User{LoginType: auth.Plain, ProhibitLogin: true})
+		if err != nil {
+			return err
+		}
+		result.ConvertedUsers = converted
+
+		unlinked, err := db.GetEngine(ctx).Where(builder.Eq{"login_source_id": source.ID}).Delete(new(user_model.ExternalLoginUser))
+		if err != nil {
+			return err
+		}
+		result.UnlinkedAccounts = unlinked
+
+		_, err = db.GetEngine(ctx).ID(source.ID).Delete(new(auth.Source))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if registerableSource, ok := source.Cfg.(auth.RegisterableSource); ok {
+		if err := registerableSource.UnregisterSource(); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
@@ -5,6 +5,7 @@ package ldap
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -19,10 +20,32 @@ import (
 	user_service "code.gitea.io/gitea/services/user"
 )
 
+// ErrSyncSkipped is returned by SyncUsers when the LDAP search fails or finds nothing to synchronize,
+// the users are left unchanged
+var ErrSyncSkipped = errors.New("ldap synchronization skipped")
+
+// SyncResult is the number of the users changed by a synchronization
+type SyncResult struct {
+	Created     int
+	Updated     int
+	Deactivated int
+}
+
 // Sync causes this ldap source to synchronize its users with the db
 func (source *Source) Sync(ctx context.Context, updateExisting bool) error {
+	// a skipped synchronization is logged, the next one may succeed
+	if _, err := source.SyncUsers(ctx, updateExisting); err != nil && !errors.Is(err, ErrSyncSkipped) {
+		return err
+	}
+	return nil
+}
+
+// SyncUsers synchronizes the users of this ldap source with the db and returns the number of the changed users
+func (source *Source) SyncUsers(ctx context.Context, updateExisting bool) (*SyncResult, error) {
 	log.Trace("Doing: SyncExternalUsers[%s]", source.authSource.Name)
 
+	result := &SyncResult{}
+
 	isAttributeSSHPublicKeySet := len(strings.TrimSpace(source.AttributeSSHPublicKey)) > 0
 	var sshKeysNeedUpdate bool
 
@@ -30,12 +53,12 @@ func (source *Source) Sync(ctx context.Context, updateExisting bool) error {
 	users, err := user_model.GetUsersBySource(source.authSource)
 	if err != nil {
 		log.Error("SyncExternalUsers: %v", err)
-		return err
+		return nil, err
 	}
 	select {
 	case <-ctx.Done():
 		log.Warn("SyncExternalUsers: Cancelled before update of %s", source.authSource.Name)
-		return db.ErrCancelledf("Before update of %s", source.authSource.Name)
+		return result, db.ErrCancelledf("Before update of %s", source.authSource.Name)
 	default:
 	}
 
@@ -51,13 +74,13 @@ func (source *Source) Sync(ctx context.Context, updateExisting bool) error {
 	sr, err := source.SearchEntries()
 	if err != nil {
 		log.Error("SyncExternalUsers LDAP source failure [%s], skipped", source.authSource.Name)
-		return nil
+		return result, fmt.Errorf("%w: LDAP search failed: %v", ErrSyncSkipped, err)
 	}
 
 	if len(sr) == 0 {
 		if !source.AllowDeactivateAll {
 			log.Error("LDAP search found no entries but did not report an error. Refusing to deactivate all users")
-			return nil
+			return result, fmt.Errorf("%w: LDAP search found no entries, refusing to deactivate all users", ErrSyncSkipped)
 		}
 		log.Warn("LDAP search found no entries but did not report an error. All users will be deactivated as per settings")
 	}
@@ -67,7 +90,7 @@ func (source *Source) Sync(ctx context.Context, updateExisting bool) error {
 
 	groupTeamMapping, err := auth_module.UnmarshalGroupTeamMapping(source.GroupTeamMap)
 	if err != nil {
-		return err
+		return result, err
 	}
 
 	for _, su := range sr {
@@ -81,7 +104,7 @@ func (source *Source) Sync(ctx context.Context, updateExisting bool) error {
 					log.Error("RewriteAllPublicKeys: %v", err)
 				}
 			}
-			return db.ErrCancelledf("During update of %s before completed update of users", source.authSource.Name)
+			return result, db.ErrCancelledf("During update of %s before completed update of users", source.authSource.Name)
 		default:
 		}
 		if len(su.Username) == 0 && len(su.Mail) == 0 {
@@ -130,6 +153,8 @@ func (source *Source) Sync(ctx context.Context, updateExisting bool) error {
 			err = user_model.CreateUser(usr, overwriteDefault)
 			if err != nil {
 				log.Error("SyncExternalUsers[%s]: Error creating user %s: %v", source.authSource.Name, su.Username, err)
+			} else {
+				result.Created++
 			}
 
 			if err == nil && isAttributeSSHPublicKeySet {
@@ -173,6 +198,8 @@ func (source *Source) Sync(ctx context.Context, updateExisting bool) error {
 				err = user_model.UpdateUser(ctx, usr, emailChanged, "full_name", "email", "is_admin", "is_restricted", "is_active")
 				if err != nil {
 					log.Error("SyncExternalUsers[%s]: Error updating user %s: %v", source.authSource.Name, usr.Name, err)
+				} else {
+					result.Updated++
 				}
 			}
 
@@ -201,7 +228,7 @@ func (source *Source) Sync(ctx context.Context, updateExisting bool) error {
 	select {
 	case <-ctx.Done():
 		log.Warn("SyncExternalUsers: Cancelled during update of %s before delete users", source.authSource.Name)
-		return db.ErrCancelledf("During update of %s before delete users", source.authSource.Name)
+		return result, db.ErrCancelledf("During update of %s before delete users", source.authSource.Name)
 	default:
 	}
 
@@ -214,12 +241,15 @@ func (source *Source) Sync(ctx context.Context, updateExisting bool) error {
 
 			log.Trace("SyncExternalUsers[%s]: Deactivating user %s", source.authSource.Name, usr.Name)
 
+			wasActive := usr.IsActive
 			usr.IsActive = false
 			err = user_model.UpdateUserCols(ctx, usr, "is_active")
 			if err != nil {
 				log.Error("SyncExternalUsers[%s]: Error deactivating user %s: %v", source.authSource.Name, usr.Name, err)
+			} else if wasActive {
+				result.Deactivated++
 			}
 		}
 	}
-	return nil
+	return result, nil
 }
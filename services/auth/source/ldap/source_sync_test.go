@@ -0,0 +1,48 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package ldap_test
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/services/auth/source/ldap"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	unittest.MainTest(m, &unittest.TestOptions{
+		GiteaRootPath: filepath.Join("..", "..", "..", ".."),
+	})
+}
+
+func TestSyncUsersSkipped(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	// nothing listens on the port any more, the LDAP search fails
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	assert.NoError(t, listener.Close())
+
+	source := &ldap.Source{Host: "127.0.0.1", Port: port, SecurityProtocol: ldap.SecurityProtocolUnencrypted, AllowDeactivateAll: true}
+	source.SetAuthSource(&auth_model.Source{ID: 100, Type: auth_model.LDAP, Name: "unreachable"})
+
+	result, err := source.SyncUsers(context.Background(), true)
+	assert.ErrorIs(t, err, ldap.ErrSyncSkipped)
+	assert.Equal(t, &ldap.SyncResult{}, result)
+	// the scheduled synchronization only logs it
+	assert.NoError(t, source.Sync(context.Background(), true))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = source.SyncUsers(ctx, true)
+	assert.True(t, db.IsErrCancelled(err))
+}
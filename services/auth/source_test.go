@@ -0,0 +1,67 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package auth
+
+import (
+	"path/filepath"
+	"testing"
+
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/services/auth/source/smtp"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	unittest.MainTest(m, &unittest.TestOptions{
+		GiteaRootPath: filepath.Join("..", ".."),
+	})
+}
+
+func TestDeleteSourceCascade(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	source := &auth_model.Source{
+		Type:     auth_model.SMTP,
+		Name:     "cascade-smtp",
+		IsActive: true,
+		Cfg:      &smtp.Source{Auth: "PLAIN", Host: "localhost", Port: 25},
+	}
+	assert.NoError(t, auth_model.CreateSource(source))
+
+	// the users 2 and 4 sign in with the source, the user 4 also has an account of another source linked
+	for _, id := range []int64{2, 4} {
+		_, err := db.GetEngine(db.DefaultContext).ID(id).Cols("login_type", "login_source", "login_name").
+			Update(&user_model.User{LoginType: auth_model.SMTP, LoginSource: source.ID, LoginName: "smtp-user"})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, db.Insert(db.DefaultContext, []*user_model.ExternalLoginUser{
+		{ExternalID: "ext-2", UserID: 2, LoginSourceID: source.ID},
+		{ExternalID: "ext-4", UserID: 4, LoginSourceID: source.ID},
+		{ExternalID: "ext-other", UserID: 4, LoginSourceID: source.ID + 1},
+	}))
+
+	result, err := DeleteSourceCascade(db.DefaultContext, source, true)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, result.ConvertedUsers)
+	assert.EqualValues(t, 2, result.UnlinkedAccounts)
+
+	for _, id := range []int64{2, 4} {
+		user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: id})
+		assert.Equal(t, auth_model.Plain, user.LoginType)
+		assert.Zero(t, user.LoginSource)
+		assert.Empty(t, user.LoginName)
+		assert.True(t, user.ProhibitLogin)
+	}
+	// the other users are untouched
+	assert.False(t, unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 5}).ProhibitLogin)
+
+	// only the account of the other source is left
+	unittest.AssertCount(t, &user_model.ExternalLoginUser{LoginSourceID: source.ID}, 0)
+	unittest.AssertCount(t, &user_model.ExternalLoginUser{LoginSourceID: source.ID + 1}, 1)
+	unittest.AssertNotExistsBean(t, &auth_model.Source{ID: source.ID})
+}
@@ -82,6 +82,12 @@ func ToNotificationThread(n *activities_model.Notification) *api.NotificationThr
 			URL:     n.Repository.Link(),
 			HTMLURL: n.Repository.HTMLURL(),
 		}
+	case activities_model.NotificationSourceSystem:
+		result.Subject = &api.NotificationSubject{
+			Type:    api.NotifySubjectSystem,
+			Title:   n.Message,
+			HTMLURL: n.HTMLURL(),
+		}
 	}
 
 	return result
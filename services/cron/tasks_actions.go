@@ -7,6 +7,7 @@ import (
 	"context"
 
 	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	actions_service "code.gitea.io/gitea/services/actions"
 )
@@ -18,6 +19,7 @@ func initActionsTasks() {
 	registerStopZombieTasks()
 	registerStopEndlessTasks()
 	registerCancelAbandonedJobs()
+	registerCleanupActions()
 }
 
 func registerStopZombieTasks() {
@@ -49,3 +51,19 @@ func registerCancelAbandonedJobs() {
 		return actions_service.CancelAbandonedJobs(ctx)
 	})
 }
+
+func registerCleanupActions() {
+	RegisterTaskFatal("cleanup_actions", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@midnight",
+	}, func(ctx context.Context, _ *user_model.User, cfg Config) error {
+		result, err := actions_service.Cleanup(ctx, actions_service.RetentionCleanupOptions())
+		if err != nil {
+			return err
+		}
+		log.Info("Removed %d expired actions artifacts (%d bytes) and the logs of %d tasks (%d bytes)",
+			result.Artifacts.Count, result.Artifacts.Size, result.Logs.Count, result.Logs.Size)
+		return nil
+	})
+}
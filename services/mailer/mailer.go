@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"hash/fnv"
 	"io"
@@ -383,6 +384,17 @@ var mailQueue *queue.WorkerPoolQueue[*Message]
 // Sender sender for sending mail synchronously
 var Sender gomail.Sender
 
+func newSender() gomail.Sender {
+	switch setting.MailService.Protocol {
+	case "sendmail":
+		return &sendmailSender{}
+	case "dummy":
+		return &dummySender{}
+	default:
+		return &smtpSender{}
+	}
+}
+
 // NewContext start mail queue service
 func NewContext(ctx context.Context) {
 	// Need to check if mailQueue is nil because in during reinstall (user had installed
@@ -392,14 +404,7 @@ func NewContext(ctx context.Context) {
 		return
 	}
 
-	switch setting.MailService.Protocol {
-	case "sendmail":
-		Sender = &sendmailSender{}
-	case "dummy":
-		Sender = &dummySender{}
-	default:
-		Sender = &smtpSender{}
-	}
+	Sender = newSender()
 
 	subjectTemplates, bodyTemplates = templates.Mailer(ctx)
 
@@ -421,6 +426,19 @@ func NewContext(ctx context.Context) {
 	go graceful.GetManager().RunWithCancel(mailQueue)
 }
 
+// SendSync sends the mail synchronously by the configured mailer without the mail queue,
+// so it also works if NewContext hasn't been called (eg: in the command line)
+func SendSync(msg *Message) error {
+	if setting.MailService == nil {
+		return errors.New("mail service is not enabled")
+	}
+	sender := Sender
+	if sender == nil {
+		sender = newSender()
+	}
+	return gomail.Send(sender, msg.ToMessage())
+}
+
 // SendAsync send mail asynchronously
 func SendAsync(msg *Message) {
 	SendAsyncs([]*Message{msg})
@@ -0,0 +1,57 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package maintenance
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+
+	system_model "code.gitea.io/gitea/models/system"
+)
+
+// State represents the maintenance mode, when it is enabled the web frontend only serves the admins
+type State struct {
+	Enabled bool
+	Message string // shown to the users instead of the default message if not empty
+}
+
+var state atomic.Pointer[State]
+
+// Init loads the maintenance mode saved in the system settings, so it survives restarts
+func Init(ctx context.Context) error {
+	settings, err := system_model.GetSettings(ctx, []string{system_model.KeyMaintenanceEnabled, system_model.KeyMaintenanceMessage})
+	if err != nil {
+		return err
+	}
+	s := &State{}
+	if v, ok := settings[system_model.KeyMaintenanceEnabled]; ok {
+		s.Enabled, _ = strconv.ParseBool(v.SettingValue)
+	}
+	if v, ok := settings[system_model.KeyMaintenanceMessage]; ok {
+		s.Message = v.SettingValue
+	}
+	state.Store(s)
+	return nil
+}
+
+// Get returns the current maintenance mode
+func Get() State {
+	if s := state.Load(); s != nil {
+		return *s
+	}
+	return State{}
+}
+
+// Set changes the maintenance mode and saves it in the system settings
+func Set(ctx context.Context, s State) error {
+	if err := system_model.SetSettingNoVersion(ctx, system_model.KeyMaintenanceMessage, s.Message); err != nil {
+		return err
+	}
+	if err := system_model.SetSettingNoVersion(ctx, system_model.KeyMaintenanceEnabled, strconv.FormatBool(s.Enabled)); err != nil {
+		return err
+	}
+	state.Store(&s)
+	return nil
+}
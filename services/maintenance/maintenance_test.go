@@ -0,0 +1,32 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package maintenance
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintenanceState(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	assert.NoError(t, Init(db.DefaultContext))
+	assert.Equal(t, State{}, Get())
+
+	assert.NoError(t, Set(db.DefaultContext, State{Enabled: true, Message: "upgrading"}))
+	assert.Equal(t, State{Enabled: true, Message: "upgrading"}, Get())
+
+	// the state is loaded again after a restart
+	state.Store(nil)
+	assert.NoError(t, Init(db.DefaultContext))
+	assert.Equal(t, State{Enabled: true, Message: "upgrading"}, Get())
+
+	assert.NoError(t, Set(db.DefaultContext, State{}))
+	assert.NoError(t, Init(db.DefaultContext))
+	assert.Equal(t, State{}, Get())
+}
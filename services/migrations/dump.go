@@ -5,17 +5,18 @@ package migrations
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
@@ -23,6 +24,7 @@ import (
 	"code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
 
 	"github.com/google/uuid"
 	"gopkg.in/yaml.v3"
@@ -664,6 +666,72 @@ func DumpRepository(ctx context.Context, baseDir, ownerName string, opts base.Mi
 	return nil
 }
 
+// repoDumpUnitPaths maps the units which can be dumped and restored to their data paths in the dump directory
+var repoDumpUnitPaths = map[string]string{
+	"wiki":           "wiki",
+	"issues":         "issue.yml",
+	"labels":         "label.yml",
+	"releases":       "release.yml",
+	"release_assets": "release_assets",
+	"milestones":     "milestone.yml",
+	"pull_requests":  "pull_request.yml",
+	"comments":       "comments",
+}
+
+// repoDumpUnitAliases maps the alternative unit names to the names used by the dump
+var repoDumpUnitAliases = map[string]string{
+	"pulls": "pull_requests",
+}
+
+// RepoDumpUnits returns the sorted names of the units which can be dumped and restored
+func RepoDumpUnits() []string {
+	units := make([]string, 0, len(repoDumpUnitPaths))
+	for unit := range repoDumpUnitPaths {
+		units = append(units, unit)
+	}
+	sort.Strings(units)
+	return units
+}
+
+func errInvalidRepoDumpUnit(unit string) error {
+	return fmt.Errorf("invalid unit %q, valid units are: %s", unit, strings.Join(RepoDumpUnits(), ", "))
+}
+
+// ParseRepoDumpUnits parses the comma separated unit names and checks them against the known units,
+// the aliases are replaced by the unit names they stand for
+func ParseRepoDumpUnits(s string) ([]string, error) {
+	var units []string
+	for _, unit := range strings.Split(s, ",") {
+		unit = strings.ToLower(strings.TrimSpace(unit))
+		if unit == "" {
+			continue
+		}
+		if name, ok := repoDumpUnitAliases[unit]; ok {
+			unit = name
+		}
+		if _, ok := repoDumpUnitPaths[unit]; !ok {
+			return nil, errInvalidRepoDumpUnit(unit)
+		}
+		units = append(units, unit)
+	}
+	return units, nil
+}
+
+// MissingRepoDumpUnits returns the units whose data doesn't exist in the dump directory
+func MissingRepoDumpUnits(baseDir string, units []string) []string {
+	var missing []string
+	for _, unit := range units {
+		p, ok := repoDumpUnitPaths[unit]
+		if !ok {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(baseDir, p)); os.IsNotExist(err) {
+			missing = append(missing, unit)
+		}
+	}
+	return missing
+}
+
 func updateOptionsUnits(opts *base.MigrateOptions, units []string) error {
 	if len(units) == 0 {
 		opts.Wiki = true
@@ -696,42 +764,65 @@ func updateOptionsUnits(opts *base.MigrateOptions, units []string) error {
 			case "pull_requests":
 				opts.PullRequests = true
 			default:
-				return errors.New("invalid unit: " + unit)
+				return errInvalidRepoDumpUnit(unit)
 			}
 		}
 	}
 	return nil
 }
 
-// RestoreRepository restore a repository from the disk directory
-func RestoreRepository(ctx context.Context, baseDir, ownerName, repoName string, units []string, validation bool) error {
+// RestoreRepository restore a repository from the disk directory as ownerName/repoName and returns the created repository,
+// an empty owner or repository name defaults to the one of the dumped repository. The target repository must not exist.
+func RestoreRepository(ctx context.Context, baseDir, ownerName, repoName string, units []string, validation bool) (*repo_model.Repository, error) {
 	doer, err := user_model.GetAdminUser(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	uploader := NewGiteaLocalUploader(ctx, doer, ownerName, repoName)
 	downloader, err := NewRepositoryRestorer(ctx, baseDir, ownerName, repoName, validation)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	opts, err := downloader.getRepoOptions()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if ownerName == "" {
+		ownerName = opts["owner"]
+	}
+	if repoName == "" {
+		repoName = opts["name"]
 	}
+	if ownerName == "" || repoName == "" {
+		return nil, util.NewInvalidArgumentErrorf("the owner or the repository name is missing in the dump, it must be given")
+	}
+	owner, err := user_model.GetUserByName(ctx, ownerName)
+	if err != nil {
+		return nil, err
+	}
+	// check the target before restoring anything, an existing repository must not be clobbered
+	if err := repo_model.CheckCreateRepository(doer, owner, repoName, false); err != nil {
+		return nil, err
+	}
+	downloader.repoOwner, downloader.repoName = owner.Name, repoName
+	downloader.dumpOwner, downloader.dumpName = opts["owner"], opts["name"]
+	uploader := NewGiteaLocalUploader(ctx, doer, owner.Name, repoName)
 	tp, _ := strconv.Atoi(opts["service_type"])
 
 	migrateOpts := base.MigrateOptions{
 		GitServiceType: structs.GitServiceType(tp),
 	}
 	if err := updateOptionsUnits(&migrateOpts, units); err != nil {
-		return err
+		return nil, err
 	}
 
 	if err = migrateRepository(doer, downloader, uploader, migrateOpts, nil); err != nil {
 		if err1 := uploader.Rollback(); err1 != nil {
 			log.Error("rollback failed: %v", err1)
 		}
-		return err
+		return nil, err
+	}
+	if err := updateMigrationPosterIDByGitService(ctx, structs.GitServiceType(tp)); err != nil {
+		return nil, err
 	}
-	return updateMigrationPosterIDByGitService(ctx, structs.GitServiceType(tp))
+	return uploader.repo, nil
 }
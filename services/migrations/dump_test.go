@@ -0,0 +1,81 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package migrations
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRepoDumpUnits(t *testing.T) {
+	units, err := ParseRepoDumpUnits("")
+	assert.NoError(t, err)
+	assert.Empty(t, units)
+
+	units, err = ParseRepoDumpUnits(" Issues, pull_requests,,releases")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"issues", "pull_requests", "releases"}, units)
+
+	units, err = ParseRepoDumpUnits("issues,Pulls")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"issues", "pull_requests"}, units)
+
+	_, err = ParseRepoDumpUnits("issues,prs")
+	assert.ErrorContains(t, err, `invalid unit "prs"`)
+}
+
+func TestMissingRepoDumpUnits(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "issue.yml"), nil, 0o644))
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "wiki"), 0o755))
+	assert.Equal(t, []string{"pull_requests", "releases"}, MissingRepoDumpUnits(dir, []string{"issues", "pull_requests", "wiki", "releases"}))
+}
+
+func TestValidateRepoDump(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	problems := ValidateRepoDump(git.DefaultContext, dir, nil, true)
+	assert.Len(t, problems, 1)
+	assert.ErrorContains(t, problems[0], "it is not a repository dump")
+
+	// issue.yml and milestone.yml are validated by the JSON schemas which are only found from the root directory,
+	// the pull requests are checked the same way
+	write("repo.yml", "name: test\nowner: user2\n")
+	write("label.yml", "- name: bug\n  color: ee0701\n")
+	write("pull_request.yml", "- number: 1\n  title: first\n  labels:\n  - name: bug\n  patch_url: git/pulls/1.patch\n")
+	write("git/pulls/1.patch", "")
+	write("comments/1.yml", "- issue_index: 1\n  content: ok\n")
+	assert.NoError(t, git.InitRepository(git.DefaultContext, filepath.Join(dir, "git"), true))
+	assert.Empty(t, ValidateRepoDump(git.DefaultContext, dir, nil, false))
+
+	write("pull_request.yml", `- number: 1
+  title: first
+  labels:
+  - name: feature
+- number: 1
+  title: same number
+  patch_url: git/pulls/2.patch
+`)
+	write("comments/2.yml", "- issue_index: 2\n  content: orphan\n")
+	problems = ValidateRepoDump(git.DefaultContext, dir, nil, true)
+	if assert.Len(t, problems, 4) {
+		assert.ErrorContains(t, problems[0], `pull_request.yml: the pull request #1 references the unknown label "feature"`)
+		assert.ErrorContains(t, problems[1], "pull_request.yml: the pull request #1 has the number of the pull request #1")
+		assert.ErrorContains(t, problems[2], `the patch of the pull request #1 "git/pulls/2.patch" doesn't exist`)
+		assert.ErrorContains(t, problems[3], "comments/2.yml: there is no issue or pull request #2")
+	}
+	assert.Len(t, ValidateRepoDump(git.DefaultContext, dir, nil, false), 1)
+
+	// only the problems of the given units are reported
+	assert.Empty(t, ValidateRepoDump(git.DefaultContext, dir, []string{"releases"}, false))
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	base "code.gitea.io/gitea/modules/migration"
 
@@ -23,6 +24,10 @@ type RepositoryRestorer struct {
 	repoOwner  string
 	repoName   string
 	validation bool
+
+	// the owner and name of the dumped repository, the pull requests reference it by them
+	dumpOwner string
+	dumpName  string
 }
 
 // NewRepositoryRestorer creates a repository restorer which could restore repository from a dumped folder
@@ -243,6 +248,12 @@ func (r *RepositoryRestorer) GetPullRequests(page, perPage int) ([]*base.PullReq
 	for _, pr := range pulls {
 		pr.PatchURL = "file://" + filepath.Join(r.baseDir, pr.PatchURL)
 		CheckAndEnsureSafePR(pr, "", r)
+		// the repository may be restored under another owner or name
+		for _, branch := range []*base.PullRequestBranch{&pr.Head, &pr.Base} {
+			if r.dumpName != "" && strings.EqualFold(branch.OwnerName, r.dumpOwner) && strings.EqualFold(branch.RepoName, r.dumpName) {
+				branch.OwnerName, branch.RepoName = r.repoOwner, r.repoName
+			}
+		}
 	}
 	return pulls, true, nil
 }
@@ -0,0 +1,257 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/git"
+	base "code.gitea.io/gitea/modules/migration"
+	"code.gitea.io/gitea/modules/util"
+
+	"gopkg.in/yaml.v3"
+)
+
+// repoDumpValidator collects the problems of a repository dump, it reads the dump but never changes it
+type repoDumpValidator struct {
+	ctx       context.Context
+	baseDir   string
+	units     []string
+	reportAll bool
+	problems  []error
+
+	numbers    map[int64]string // the kinds of the issues and the pull requests by their numbers
+	indexes    map[int64]bool   // the numbers and the foreign indexes, the comments and the reviews are stored by them
+	milestones map[string]bool  // nil if the milestones are not restored
+	labels     map[string]bool  // nil if the labels are not restored
+}
+
+func (v *repoDumpValidator) fail(file, format string, args ...any) {
+	v.problems = append(v.problems, fmt.Errorf("%s: %s", file, fmt.Sprintf(format, args...)))
+}
+
+// stopped reports whether the validation should stop, at the first problem unless all of them are reported
+func (v *repoDumpValidator) stopped() bool {
+	return len(v.problems) > 0 && !v.reportAll
+}
+
+func (v *repoDumpValidator) hasUnit(units ...string) bool {
+	if len(v.units) == 0 {
+		return true
+	}
+	for _, unit := range units {
+		if util.SliceContainsString(v.units, unit) {
+			return true
+		}
+	}
+	return false
+}
+
+// load unmarshals the YAML file of the dump, a missing file isn't a problem as the unit is skipped when restoring
+func (v *repoDumpValidator) load(file string, data any) bool {
+	p := filepath.Join(v.baseDir, file)
+	var err error
+	switch data.(type) {
+	case *[]*base.Issue, *[]*base.Milestone:
+		err = base.Load(p, data, true) // they have a JSON schema
+	default:
+		var bs []byte
+		if bs, err = os.ReadFile(p); err == nil {
+			err = yaml.Unmarshal(bs, data)
+		}
+	}
+	if os.IsNotExist(err) {
+		return false
+	} else if err != nil {
+		v.fail(file, "%v", err)
+		return false
+	}
+	return true
+}
+
+// checkFile checks that the file referenced by the dump exists
+func (v *repoDumpValidator) checkFile(file, what, p string) {
+	if isFile, _ := util.IsFile(filepath.Join(v.baseDir, p)); !isFile {
+		v.fail(file, "%s %q doesn't exist", what, p)
+	}
+}
+
+// checkGit checks that all the objects reachable from the refs of the git repository exist
+func (v *repoDumpValidator) checkGit(dir string) {
+	if isDir, _ := util.IsDir(filepath.Join(v.baseDir, dir)); !isDir {
+		v.fail(dir, "the git repository doesn't exist")
+		return
+	}
+	_, stderr, err := git.NewCommand(v.ctx, "fsck", "--no-dangling", "--connectivity-only").RunStdString(&git.RunOpts{Dir: filepath.Join(v.baseDir, dir)})
+	if err != nil {
+		v.fail(dir, "the git repository is corrupt: %s", strings.TrimSpace(stderr))
+	}
+}
+
+func (v *repoDumpValidator) checkIssue(file, kind string, number, foreignIndex int64, milestone string, labels []*base.Label) {
+	if other, ok := v.numbers[number]; ok {
+		v.fail(file, "the %s #%d has the number of the %s #%d", kind, number, other, number)
+	}
+	v.numbers[number] = kind
+	v.indexes[number], v.indexes[foreignIndex] = true, true
+	if milestone != "" && v.milestones != nil && !v.milestones[milestone] {
+		v.fail(file, "the %s #%d references the unknown milestone %q", kind, number, milestone)
+	}
+	for _, label := range labels {
+		if v.labels != nil && !v.labels[label.Name] {
+			v.fail(file, "the %s #%d references the unknown label %q", kind, number, label.Name)
+		}
+	}
+}
+
+// checkIssueFiles checks that the files of the directory belong to the issues or the pull requests and can be loaded
+func (v *repoDumpValidator) checkIssueFiles(dir string, data func() any) {
+	entries, err := os.ReadDir(filepath.Join(v.baseDir, dir))
+	if os.IsNotExist(err) {
+		return
+	} else if err != nil {
+		v.fail(dir, "%v", err)
+		return
+	}
+	for _, entry := range entries {
+		file := filepath.Join(dir, entry.Name())
+		index, err := strconv.ParseInt(strings.TrimSuffix(entry.Name(), ".yml"), 10, 64)
+		if err != nil || !strings.HasSuffix(entry.Name(), ".yml") {
+			v.fail(file, "unexpected file, it should be named by the number of an issue or a pull request")
+		} else if !v.indexes[index] {
+			v.fail(file, "there is no issue or pull request #%d", index)
+		} else {
+			v.load(file, data())
+		}
+		if v.stopped() {
+			return
+		}
+	}
+}
+
+func (v *repoDumpValidator) validate() {
+	if isFile, _ := util.IsFile(filepath.Join(v.baseDir, "repo.yml")); !isFile {
+		v.fail("repo.yml", "the file doesn't exist, it is not a repository dump")
+		return
+	}
+	opts := map[string]string{}
+	v.load("repo.yml", &opts)
+	var topics struct {
+		Topics []string `yaml:"topics"`
+	}
+	v.load("topic.yml", &topics)
+	if v.stopped() {
+		return
+	}
+
+	if v.hasUnit("milestones") {
+		var milestones []*base.Milestone
+		if v.load("milestone.yml", &milestones) {
+			v.milestones = map[string]bool{}
+			for _, m := range milestones {
+				v.milestones[m.Title] = true
+			}
+		}
+	}
+	if v.hasUnit("labels") {
+		var labels []*base.Label
+		if v.load("label.yml", &labels) {
+			v.labels = map[string]bool{}
+			for _, l := range labels {
+				v.labels[l.Name] = true
+			}
+		}
+	}
+	if v.stopped() {
+		return
+	}
+
+	if v.hasUnit("releases", "release_assets") {
+		var releases []*base.Release
+		if v.load("release.yml", &releases) && v.hasUnit("release_assets") {
+			for _, rel := range releases {
+				for _, asset := range rel.Assets {
+					// the assets which weren't dumped are still referenced by their URLs
+					if asset.DownloadURL != nil && !strings.Contains(*asset.DownloadURL, "://") {
+						v.checkFile("release.yml", fmt.Sprintf("the asset %q of the release %q", asset.Name, rel.TagName), *asset.DownloadURL)
+					}
+				}
+			}
+		}
+		if v.stopped() {
+			return
+		}
+	}
+
+	// the comments need the issues and the pull requests they belong to
+	if v.hasUnit("issues", "comments") {
+		var issues []*base.Issue
+		if v.load("issue.yml", &issues) {
+			for _, issue := range issues {
+				v.checkIssue("issue.yml", "issue", issue.Number, issue.GetForeignIndex(), issue.Milestone, issue.Labels)
+			}
+		}
+		if v.stopped() {
+			return
+		}
+	}
+	if v.hasUnit("pull_requests", "comments") {
+		var pulls []*base.PullRequest
+		if v.load("pull_request.yml", &pulls) {
+			for _, pr := range pulls {
+				v.checkIssue("pull_request.yml", "pull request", pr.Number, pr.GetForeignIndex(), pr.Milestone, pr.Labels)
+				if pr.PatchURL != "" && !strings.Contains(pr.PatchURL, "://") {
+					v.checkFile("pull_request.yml", fmt.Sprintf("the patch of the pull request #%d", pr.Number), pr.PatchURL)
+				}
+			}
+		}
+		if v.stopped() {
+			return
+		}
+	}
+
+	if v.hasUnit("comments") {
+		v.checkIssueFiles("comments", func() any { return &[]*base.Comment{} })
+		if v.stopped() {
+			return
+		}
+		v.checkIssueFiles("reviews", func() any { return &[]*base.Review{} })
+		if v.stopped() {
+			return
+		}
+	}
+
+	v.checkGit("git")
+	if v.hasUnit("wiki") && !v.stopped() {
+		if isDir, _ := util.IsDir(filepath.Join(v.baseDir, "wiki")); isDir {
+			v.checkGit("wiki")
+		}
+	}
+}
+
+// ValidateRepoDump checks the dump directory of a repository without restoring it: the YAML files must be parsable
+// (and valid against their schema if they have one), the files they reference must exist, the issues, the pull requests
+// and their comments must be consistent, and the objects of the git repositories must exist.
+// Only the given units are checked, all of them if none is given.
+// The first problem is returned unless all the problems are reported.
+func ValidateRepoDump(ctx context.Context, baseDir string, units []string, reportAll bool) []error {
+	v := &repoDumpValidator{
+		ctx:       ctx,
+		baseDir:   baseDir,
+		units:     units,
+		reportAll: reportAll,
+		numbers:   map[int64]string{},
+		indexes:   map[int64]bool{},
+	}
+	v.validate()
+	if !reportAll && len(v.problems) > 1 {
+		return v.problems[:1]
+	}
+	return v.problems
+}
@@ -33,6 +33,11 @@ func AdoptRepository(ctx context.Context, doer, u *user_model.User, opts repo_mo
 			Limit: u.MaxRepoCreation,
 		}
 	}
+	if !doer.IsAdmin {
+		if err := repo_model.CheckRepoSizeQuota(ctx, u, 0); err != nil {
+			return nil, err
+		}
+	}
 
 	if len(opts.DefaultBranch) == 0 {
 		opts.DefaultBranch = setting.Repository.DefaultBranch
@@ -236,14 +241,16 @@ type unadoptedRepositories struct {
 	end          int
 }
 
-func (unadopted *unadoptedRepositories) add(repository string) {
+func (unadopted *unadoptedRepositories) add(repository string) error {
 	if unadopted.index >= unadopted.start && unadopted.index < unadopted.end {
 		unadopted.repositories = append(unadopted.repositories, repository)
 	}
 	unadopted.index++
+	return nil
 }
 
-func checkUnadoptedRepositories(ctx context.Context, userName string, repoNamesToCheck []string, unadopted *unadoptedRepositories) error {
+// checkUnadoptedRepositories calls fn for each repository of the user which isn't in the database
+func checkUnadoptedRepositories(ctx context.Context, userName string, repoNamesToCheck []string, fn func(repository string) error) error {
 	if len(repoNamesToCheck) == 0 {
 		return nil
 	}
@@ -275,7 +282,10 @@ func checkUnadoptedRepositories(ctx context.Context, userName string, repoNamesT
 	}
 	for _, repoName := range repoNamesToCheck {
 		if !repoNames.Contains(repoName) {
-			unadopted.add(path.Join(userName, repoName)) // These are not used as filepaths - but as reponames - therefore use path.Join not filepath.Join
+			// These are not used as filepaths - but as reponames - therefore use path.Join not filepath.Join
+			if err := fn(path.Join(userName, repoName)); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
@@ -283,6 +293,23 @@ func checkUnadoptedRepositories(ctx context.Context, userName string, repoNamesT
 
 // ListUnadoptedRepositories lists all the unadopted repositories that match the provided query
 func ListUnadoptedRepositories(ctx context.Context, query string, opts *db.ListOptions) ([]string, int, error) {
+	start := (opts.Page - 1) * opts.PageSize
+	unadopted := &unadoptedRepositories{
+		repositories: make([]string, 0, opts.PageSize),
+		start:        start,
+		end:          start + opts.PageSize,
+		index:        0,
+	}
+	if err := IterateUnadoptedRepositories(ctx, query, unadopted.add); err != nil {
+		return nil, 0, err
+	}
+	return unadopted.repositories, unadopted.index, nil
+}
+
+// IterateUnadoptedRepositories calls fn with the name ("owner/repo") of each unadopted repository that matches the provided query,
+// the query is a glob of the owner and optionally a glob of the repository separated by "/".
+// The repositories are found by walking the repository root, so fn is called with the names in the order of the directories.
+func IterateUnadoptedRepositories(ctx context.Context, query string, fn func(repoName string) error) error {
 	globUser, _ := glob.Compile("*")
 	globRepo, _ := glob.Compile("*")
 
@@ -302,17 +329,8 @@ func ListUnadoptedRepositories(ctx context.Context, query string, opts *db.ListO
 	}
 	var repoNamesToCheck []string
 
-	start := (opts.Page - 1) * opts.PageSize
-	unadopted := &unadoptedRepositories{
-		repositories: make([]string, 0, opts.PageSize),
-		start:        start,
-		end:          start + opts.PageSize,
-		index:        0,
-	}
-
 	var userName string
 
-	// We're going to iterate by pagesize.
 	root := filepath.Clean(setting.RepoRootPath)
 	if err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
@@ -326,7 +344,7 @@ func ListUnadoptedRepositories(ctx context.Context, query string, opts *db.ListO
 
 		if !strings.ContainsRune(path[len(root)+1:], filepath.Separator) {
 			// Got a new user
-			if err = checkUnadoptedRepositories(ctx, userName, repoNamesToCheck, unadopted); err != nil {
+			if err = checkUnadoptedRepositories(ctx, userName, repoNamesToCheck, fn); err != nil {
 				return err
 			}
 			repoNamesToCheck = repoNamesToCheck[:0]
@@ -349,7 +367,7 @@ func ListUnadoptedRepositories(ctx context.Context, query string, opts *db.ListO
 
 		repoNamesToCheck = append(repoNamesToCheck, name)
 		if len(repoNamesToCheck) >= setting.Database.IterateBufferSize {
-			if err = checkUnadoptedRepositories(ctx, userName, repoNamesToCheck, unadopted); err != nil {
+			if err = checkUnadoptedRepositories(ctx, userName, repoNamesToCheck, fn); err != nil {
 				return err
 			}
 			repoNamesToCheck = repoNamesToCheck[:0]
@@ -357,12 +375,8 @@ func ListUnadoptedRepositories(ctx context.Context, query string, opts *db.ListO
 		}
 		return filepath.SkipDir
 	}); err != nil {
-		return nil, 0, err
-	}
-
-	if err := checkUnadoptedRepositories(ctx, userName, repoNamesToCheck, unadopted); err != nil {
-		return nil, 0, err
+		return err
 	}
 
-	return unadopted.repositories, unadopted.index, nil
+	return checkUnadoptedRepositories(ctx, userName, repoNamesToCheck, fn)
 }
@@ -35,7 +35,9 @@ func GitFsckRepos(ctx context.Context, timeout time.Duration, args git.TrustedCm
 				return db.ErrCancelledf("before fsck of %s", repo.FullName())
 			default:
 			}
-			return GitFsckRepo(ctx, repo, timeout, args)
+			// we can ignore the error here because it will be logged in GitFsckRepo
+			_ = GitFsckRepo(ctx, repo, timeout, args)
+			return nil
 		},
 	); err != nil {
 		log.Trace("Error: GitFsck: %v", err)
@@ -47,14 +49,16 @@ func GitFsckRepos(ctx context.Context, timeout time.Duration, args git.TrustedCm
 }
 
 // GitFsckRepo calls 'git fsck' to check an individual repository's health.
+// The problems are logged, stored as a repository notice and returned.
 func GitFsckRepo(ctx context.Context, repo *repo_model.Repository, timeout time.Duration, args git.TrustedCmdArgs) error {
 	log.Trace("Running health check on repository %-v", repo)
 	repoPath := repo.RepoPath()
 	if err := git.Fsck(ctx, repoPath, timeout, args); err != nil {
 		log.Warn("Failed to health check repository (%-v): %v", repo, err)
-		if err = system_model.CreateRepositoryNotice("Failed to health check repository (%s): %v", repo.FullName(), err); err != nil {
-			log.Error("CreateRepositoryNotice: %v", err)
+		if errNotice := system_model.CreateRepositoryNotice("Failed to health check repository (%s): %v", repo.FullName(), err); errNotice != nil {
+			log.Error("CreateRepositoryNotice: %v", errNotice)
 		}
+		return err
 	}
 	return nil
 }
@@ -147,6 +151,11 @@ func gatherMissingRepoRecords(ctx context.Context) (repo_model.RepositoryList, e
 	return repos, nil
 }
 
+// FindMissingRepositories returns all repository records that lost Git files.
+func FindMissingRepositories(ctx context.Context) (repo_model.RepositoryList, error) {
+	return gatherMissingRepoRecords(ctx)
+}
+
 // DeleteMissingRepositories deletes all repository records that lost Git files.
 func DeleteMissingRepositories(ctx context.Context, doer *user_model.User) error {
 	repos, err := gatherMissingRepoRecords(ctx)
@@ -0,0 +1,43 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repository
+
+import (
+	"os"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindMissingRepositories(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+
+	repoIDs := func() []int64 {
+		repos, err := FindMissingRepositories(db.DefaultContext)
+		assert.NoError(t, err)
+		ids := make([]int64, 0, len(repos))
+		for _, repo := range repos {
+			ids = append(ids, repo.ID)
+		}
+		return ids
+	}
+
+	// not all the fixture repositories have Git files
+	missing := repoIDs()
+	assert.NotContains(t, missing, int64(1))
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	assert.NoError(t, os.Rename(repo.RepoPath(), repo.RepoPath()+".moved"))
+	defer func() {
+		assert.NoError(t, os.Rename(repo.RepoPath()+".moved", repo.RepoPath()))
+	}()
+	assert.ElementsMatch(t, append(missing, 1), repoIDs())
+
+	// the repositories are only found, not deleted
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+}
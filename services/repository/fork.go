@@ -57,6 +57,12 @@ func ForkRepository(ctx context.Context, doer, owner *user_model.User, opts Fork
 			Limit: owner.MaxRepoCreation,
 		}
 	}
+	// the fork is a copy of the base repository, so it needs as much room
+	if !doer.IsAdmin {
+		if err := repo_model.CheckRepoSizeQuota(ctx, owner, opts.BaseRepo.Size); err != nil {
+			return nil, err
+		}
+	}
 
 	forkedRepo, err := repo_model.GetUserFork(ctx, opts.BaseRepo.ID, owner.ID)
 	if err != nil {
@@ -45,4 +45,15 @@ func TestForkRepository(t *testing.T) {
 	})
 	assert.Nil(t, fork2)
 	assert.True(t, repo_model.IsErrReachLimitOfRepo(err))
+
+	setting.Repository.AllowForkWithoutMaximumLimit = true
+
+	// the fork doesn't fit in the size quota of the owner, except when an admin forks
+	user.MaxRepoSize = 1
+	repo.Size = 1024
+	_, err = ForkRepository(git.DefaultContext, user, user, ForkRepoOptions{BaseRepo: repo, Name: "test"})
+	assert.True(t, repo_model.IsErrRepoSizeQuotaExceeded(err))
+	admin := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1})
+	_, err = ForkRepository(git.DefaultContext, admin, user, ForkRepoOptions{BaseRepo: repo, Name: "test"})
+	assert.True(t, IsErrForkAlreadyExist(err))
 }
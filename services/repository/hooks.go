@@ -6,6 +6,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"code.gitea.io/gitea/models/db"
 	repo_model "code.gitea.io/gitea/models/repo"
@@ -20,29 +21,60 @@ import (
 // SyncRepositoryHooks rewrites all repositories' pre-receive, update and post-receive hooks
 // to make sure the binary and custom conf path are up-to-date.
 func SyncRepositoryHooks(ctx context.Context) error {
+	return SyncRepositoryHooksConcurrently(ctx, 1)
+}
+
+// SyncRepositoryHooksConcurrently is like SyncRepositoryHooks but rewrites the hooks of at most concurrency
+// repositories at the same time, it stops at the first error.
+func SyncRepositoryHooksConcurrently(ctx context.Context, concurrency int) error {
 	log.Trace("Doing: SyncRepositoryHooks")
 
-	if err := db.Iterate(
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var syncErr error
+	var errOnce sync.Once
+	var wg sync.WaitGroup
+	repoCh := make(chan *repo_model.Repository)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for repo := range repoCh {
+				if ctx.Err() != nil {
+					continue // drain the repositories sent before the cancellation
+				}
+				if err := syncRepositoryHook(repo); err != nil {
+					errOnce.Do(func() {
+						syncErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+	// the repositories are loaded by batches, not all at once
+	err := db.Iterate(
 		ctx,
 		builder.Gt{"id": 0},
 		func(ctx context.Context, repo *repo_model.Repository) error {
 			select {
 			case <-ctx.Done():
 				return db.ErrCancelledf("before sync repository hooks for %s", repo.FullName())
-			default:
-			}
-
-			if err := repo_module.CreateDelegateHooks(repo.RepoPath()); err != nil {
-				return fmt.Errorf("SyncRepositoryHook: %w", err)
-			}
-			if repo.HasWiki() {
-				if err := repo_module.CreateDelegateHooks(repo.WikiPath()); err != nil {
-					return fmt.Errorf("SyncRepositoryHook: %w", err)
-				}
+			case repoCh <- repo:
+				return nil
 			}
-			return nil
 		},
-	); err != nil {
+	)
+	close(repoCh)
+	wg.Wait()
+	if syncErr != nil {
+		return syncErr
+	} else if err != nil {
 		return err
 	}
 
@@ -50,6 +82,22 @@ func SyncRepositoryHooks(ctx context.Context) error {
 	return nil
 }
 
+// syncRepositoryHook is SyncRepositoryHook, it can be replaced by the tests
+var syncRepositoryHook = SyncRepositoryHook
+
+// SyncRepositoryHook rewrites the hooks of a repository and its wiki
+func SyncRepositoryHook(repo *repo_model.Repository) error {
+	if err := repo_module.CreateDelegateHooks(repo.RepoPath()); err != nil {
+		return fmt.Errorf("SyncRepositoryHook: %w", err)
+	}
+	if repo.HasWiki() {
+		if err := repo_module.CreateDelegateHooks(repo.WikiPath()); err != nil {
+			return fmt.Errorf("SyncRepositoryHook: %w", err)
+		}
+	}
+	return nil
+}
+
 // GenerateGitHooks generates git hooks from a template repository
 func GenerateGitHooks(ctx context.Context, templateRepo, generateRepo *repo_model.Repository) error {
 	generateGitRepo, err := git.OpenRepository(ctx, generateRepo.RepoPath())
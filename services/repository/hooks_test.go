@@ -0,0 +1,82 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repository
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncRepositoryHooksConcurrently(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	defer func(old func(*repo_model.Repository) error) { syncRepositoryHook = old }(syncRepositoryHook)
+
+	repoCount, err := db.GetEngine(db.DefaultContext).Count(&repo_model.Repository{})
+	assert.NoError(t, err)
+
+	t.Run("Concurrency", func(t *testing.T) {
+		const concurrency = 3
+		var mu sync.Mutex
+		synced := map[int64]bool{}
+		inFlight, maxInFlight := 0, 0
+		allStarted := make(chan struct{})
+		syncRepositoryHook = func(repo *repo_model.Repository) error {
+			mu.Lock()
+			synced[repo.ID] = true
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+				if maxInFlight == concurrency {
+					close(allStarted)
+				}
+			}
+			mu.Unlock()
+
+			// the first syncs wait for each other, so they run at the same time
+			select {
+			case <-allStarted:
+			case <-time.After(5 * time.Second):
+			}
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			return nil
+		}
+
+		assert.NoError(t, SyncRepositoryHooksConcurrently(db.DefaultContext, concurrency))
+		assert.Len(t, synced, int(repoCount))
+		assert.Equal(t, concurrency, maxInFlight)
+	})
+
+	t.Run("StopOnFirstError", func(t *testing.T) {
+		var mu sync.Mutex
+		var syncedIDs []int64
+		syncRepositoryHook = func(repo *repo_model.Repository) error {
+			mu.Lock()
+			defer mu.Unlock()
+			syncedIDs = append(syncedIDs, repo.ID)
+			if repo.ID == 2 {
+				return errors.New("sync failed")
+			}
+			return nil
+		}
+
+		assert.EqualError(t, SyncRepositoryHooksConcurrently(db.DefaultContext, 1), "sync failed")
+		// the repositories after the failed one are not synced
+		assert.Equal(t, []int64{1, 2}, syncedIDs)
+
+		syncedIDs = nil
+		assert.EqualError(t, SyncRepositoryHooksConcurrently(db.DefaultContext, 4), "sync failed")
+		assert.Less(t, len(syncedIDs), int(repoCount))
+	})
+}
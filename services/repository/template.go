@@ -69,6 +69,11 @@ func GenerateRepository(ctx context.Context, doer, owner *user_model.User, templ
 			Limit: owner.MaxRepoCreation,
 		}
 	}
+	if !doer.IsAdmin {
+		if err := repo_model.CheckRepoSizeQuota(ctx, owner, 0); err != nil {
+			return nil, err
+		}
+	}
 
 	var generateRepo *repo_model.Repository
 	if err = db.WithTx(ctx, func(ctx context.Context) error {
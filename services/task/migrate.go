@@ -13,6 +13,7 @@ import (
 	"code.gitea.io/gitea/models/db"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
@@ -29,6 +30,8 @@ func handleCreateError(owner *user_model.User, err error) error {
 	switch {
 	case repo_model.IsErrReachLimitOfRepo(err):
 		return fmt.Errorf("you have already reached your limit of %d repositories", owner.MaxCreationLimit())
+	case repo_model.IsErrRepoSizeQuotaExceeded(err):
+		return fmt.Errorf("your repositories have exceeded the size quota of %s", base.FileSize(owner.MaxRepoSize))
 	case repo_model.IsErrRepoAlreadyExist(err):
 		return errors.New("the repository name is already used")
 	case db.IsErrNameReserved(err):
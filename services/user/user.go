@@ -26,6 +26,7 @@ import (
 	"code.gitea.io/gitea/services/agit"
 	"code.gitea.io/gitea/services/packages"
 	container_service "code.gitea.io/gitea/services/packages/container"
+	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 // RenameUser renames a user
@@ -292,6 +293,58 @@ func DeleteUser(ctx context.Context, u *user_model.User, purge bool) error {
 	return nil
 }
 
+// TransferUserRepositories transfers all repositories owned by the user to the new owner and returns the number of transferred repositories.
+// All repositories are checked before transferring, so nothing is transferred if the new owner can't own any of them.
+func TransferUserRepositories(ctx context.Context, doer, u, newOwner *user_model.User) (int, error) {
+	if newOwner.ID == u.ID {
+		return 0, fmt.Errorf("can't transfer the repositories of %s to the same user", u.Name)
+	}
+	if !newOwner.IsOrganization() && (!newOwner.IsActive || newOwner.ProhibitLogin) {
+		return 0, fmt.Errorf("%s is not an active user, it can't own repositories", newOwner.Name)
+	}
+
+	var repos []*repo_model.Repository
+	for page := 1; ; page++ {
+		pageRepos, _, err := repo_model.GetUserRepositories(&repo_model.SearchRepoOptions{
+			ListOptions: db.ListOptions{
+				PageSize: repo_model.RepositoryListDefaultPageSize,
+				Page:     page,
+			},
+			Private: true,
+			OwnerID: u.ID,
+			Actor:   u,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("GetUserRepositories: %w", err)
+		}
+		if len(pageRepos) == 0 {
+			break
+		}
+		repos = append(repos, pageRepos...)
+	}
+	if len(repos) == 0 {
+		return 0, nil
+	}
+
+	if !newOwner.IsAdmin && newOwner.MaxCreationLimit() > -1 && newOwner.NumRepos+len(repos) > newOwner.MaxCreationLimit() {
+		return 0, fmt.Errorf("%s can't own %d more repositories, the limit is %d", newOwner.Name, len(repos), newOwner.MaxCreationLimit())
+	}
+	for _, repo := range repos {
+		if exist, err := repo_model.IsRepositoryModelOrDirExist(ctx, newOwner, repo.Name); err != nil {
+			return 0, err
+		} else if exist {
+			return 0, fmt.Errorf("%s already has a repository named %s", newOwner.Name, repo.Name)
+		}
+	}
+
+	for i, repo := range repos {
+		if err := repo_service.TransferOwnership(ctx, doer, newOwner, repo, nil); err != nil {
+			return i, fmt.Errorf("unable to transfer repository %s to %s: %w", repo.FullName(), newOwner.Name, err)
+		}
+	}
+	return len(repos), nil
+}
+
 // DeleteInactiveUsers deletes all inactive users and email addresses.
 func DeleteInactiveUsers(ctx context.Context, olderThan time.Duration) error {
 	users, err := user_model.GetInactiveUsers(ctx, olderThan)
@@ -85,10 +85,15 @@ func TestDumpRestore(t *testing.T) {
 		//
 
 		newreponame := "restored"
-		err = migrations.RestoreRepository(ctx, d, repo.OwnerName, newreponame, []string{
+		restored, err := migrations.RestoreRepository(ctx, d, repo.OwnerName, newreponame, []string{
 			"labels", "issues", "comments", "milestones", "pull_requests",
 		}, false)
 		assert.NoError(t, err)
+		assert.Equal(t, newreponame, restored.Name)
+
+		// the existing repository is never overwritten
+		_, err = migrations.RestoreRepository(ctx, d, repo.OwnerName, newreponame, nil, false)
+		assert.True(t, repo_model.IsErrRepoAlreadyExist(err))
 
 		newrepo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{Name: newreponame})
 